@@ -13,10 +13,18 @@ import (
 	"github.com/drazan344/taskflow-go/internal/auth"
 	"github.com/drazan344/taskflow-go/internal/config"
 	"github.com/drazan344/taskflow-go/internal/database"
+	"github.com/drazan344/taskflow-go/internal/digest"
+	"github.com/drazan344/taskflow-go/internal/events"
 	"github.com/drazan344/taskflow-go/internal/handlers"
 	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/mailer"
 	"github.com/drazan344/taskflow-go/internal/middleware"
 	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/notify"
+	"github.com/drazan344/taskflow-go/internal/subscribers"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+	"github.com/drazan344/taskflow-go/internal/webpush"
 	"github.com/drazan344/taskflow-go/internal/websocket"
 	"github.com/drazan344/taskflow-go/pkg/logger"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -76,6 +84,9 @@ func main() {
 	if err := runMigrations(db); err != nil {
 		logger.WithError(err).Fatal("Failed to run database migrations")
 	}
+	if err := models.MigrateTagNames(db.DB); err != nil {
+		logger.WithError(err).Fatal("Failed to migrate tag names")
+	}
 
 	// Initialize services
 	jwtService := auth.NewJWTService(cfg)
@@ -85,20 +96,98 @@ func main() {
 	jobClient := jobs.NewClient(cfg.GetRedisAddr())
 	defer jobClient.Close()
 
-	// Initialize WebSocket hub
+	// Initialize domain event bus
+	eventBus, err := events.NewBus(cfg, redis.Client, logger.Logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create event bus")
+	}
+
+	// Initialize WebSocket hub; with the redis driver, the hubs of all API
+	// instances share messages and presence
 	wsHub := websocket.NewHub(logger)
+	switch cfg.WebSocket.Driver {
+	case "", "memory":
+	case "redis":
+		if err := wsHub.StartCluster(context.Background(), redis, websocket.ClusterOptions{
+			Prefix:      cfg.WebSocket.Prefix,
+			NodeID:      cfg.WebSocket.NodeID,
+			PresenceTTL: cfg.WebSocket.PresenceTTL,
+		}); err != nil {
+			logger.WithError(err).Fatal("Failed to start WebSocket cluster")
+		}
+	default:
+		logger.WithField("driver", cfg.WebSocket.Driver).Fatal("Unknown WebSocket driver")
+	}
 	go wsHub.Run() // Start the hub in a separate goroutine
 
+	// Notification templates are shared by the handlers and subscribers
+	notificationTemplates := templates.New(db.DB, cfg.Server.PublicURL)
+
+	// Initialize mailer
+	mail, err := mailer.New(cfg, logger.Logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create mailer")
+	}
+
+	// Webhook and push deliveries go to URLs that users choose, so they only
+	// reach public addresses and the networks allowed for webhooks
+	webhookGuard, err := webhooks.NewGuard(cfg.Webhooks.AllowedNetworks)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid webhook configuration")
+	}
+
+	// Initialize Web Push; push notifications are off without VAPID keys
+	var pushClient *webpush.Client
+	var pusher notify.Pusher
+	if cfg.Push.VAPIDPrivateKey != "" {
+		pushClient, err = webpush.New(webpush.Options{
+			Keys:       webpush.Keys{Public: cfg.Push.VAPIDPublicKey, Private: cfg.Push.VAPIDPrivateKey},
+			Subject:    cfg.Push.Subject,
+			ServiceURL: cfg.Push.ServiceURL,
+			Guard:      webhookGuard,
+			TTL:        cfg.Push.TTL,
+			Timeout:    cfg.Push.Timeout,
+		})
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to create push client")
+		}
+		pusher = notify.NewWebPush(db.DB, pushClient, logger.Logger)
+	}
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService, logger)
-	userHandler := handlers.NewUserHandler(db.DB, logger)
-	taskHandler := handlers.NewTaskHandler(db.DB, logger)
+	authHandler := handlers.NewAuthHandler(authService, logger, eventBus)
+	userHandler := handlers.NewUserHandler(db.DB, logger, eventBus)
+	taskHandler := handlers.NewTaskHandler(db.DB, logger, eventBus)
 	tenantHandler := handlers.NewTenantHandler(db.DB, logger)
-	notificationHandler := handlers.NewNotificationHandler(db.DB, logger)
-	wsHandler := handlers.NewWebSocketHandler(wsHub, logger)
+	notificationHandler := handlers.NewNotificationHandler(db.DB, logger, wsHub)
+	wsHandler := handlers.NewWebSocketHandler(db.DB, wsHub, logger)
+	calendarHandler := handlers.NewCalendarHandler(db.DB, logger)
+	sprintHandler := handlers.NewSprintHandler(db.DB, logger)
+	checklistHandler := handlers.NewChecklistHandler(db.DB, logger, wsHub, eventBus)
+	importHandler := handlers.NewImportHandler(db.DB, logger, jobClient)
+	exportHandler := handlers.NewExportHandler(db.DB, logger, jobClient, cfg)
+	webhookHandler := handlers.NewWebhookHandler(db.DB, logger, jobClient, webhookGuard, cfg)
+	inboundHandler := handlers.NewInboundEmailHandler(db.DB, logger, eventBus, cfg)
+	notificationTemplateHandler := handlers.NewNotificationTemplateHandler(db.DB, logger, notificationTemplates)
+	pushHandler := handlers.NewPushHandler(db.DB, logger, pushClient)
+
+	// Notifications from subscribers and jobs are delivered on every channel
+	// by one dispatcher
+	dispatcher := notify.New(db.DB, notificationTemplates, mail, wsHandler, pusher, eventBus,
+		digest.NewSchedule(cfg.Notifications.DigestHour, cfg.Notifications.DigestWeekday), cfg.Notifications.CollapseWindow, logger.Logger)
+
+	// Subscribe consumers to domain events and start delivering them
+	subscribers.Subscribe(eventBus, "websocket", subscribers.NewWebSocket(db.DB, wsHandler, logger.Logger))
+	subscribers.Subscribe(eventBus, "notifications", subscribers.NewNotifications(db.DB, jobClient, notificationTemplates.AppURL()))
+	subscribers.Subscribe(eventBus, "search", subscribers.NewSearch(db.DB))
+	subscribers.Subscribe(eventBus, "audit", subscribers.NewAudit(db.DB))
+	subscribers.Subscribe(eventBus, "webhooks", subscribers.NewWebhooks(db.DB, jobClient, cfg.Webhooks.MaxRetries))
+	if err := eventBus.Start(context.Background()); err != nil {
+		logger.WithError(err).Fatal("Failed to start event bus")
+	}
 
 	// Initialize background job server
-	jobServer := jobs.NewServer(cfg, db.DB, logger.Logger)
+	jobServer := jobs.NewServer(cfg, db.DB, logger.Logger, mail, dispatcher, webhookGuard)
 	go func() {
 		logger.Info("Starting background job server...")
 		if err := jobServer.Start(); err != nil {
@@ -106,14 +195,25 @@ func main() {
 		}
 	}()
 
+	// Initialize periodic job scheduler
+	jobScheduler, err := jobs.NewScheduler(cfg, logger.Logger)
+	if err != nil {
+		logger.WithError(err).Fatal("Failed to create job scheduler")
+	}
+	if err := jobScheduler.Start(); err != nil {
+		logger.WithError(err).Fatal("Failed to start job scheduler")
+	}
+
 	// Setup routes
-	router := setupRoutes(cfg, db, redis, jwtService, authHandler, userHandler, taskHandler, tenantHandler, notificationHandler, wsHandler, logger)
+	router := setupRoutes(cfg, db, redis, jwtService, authHandler, userHandler, taskHandler, tenantHandler, notificationHandler, wsHandler, calendarHandler, sprintHandler, checklistHandler, importHandler, exportHandler, webhookHandler, inboundHandler, notificationTemplateHandler, pushHandler, logger)
 
 	// Create HTTP server
 	server := &http.Server{
 		Addr:    cfg.GetServerAddr(),
 		Handler: router,
 	}
+	// Event streams never finish on their own, so end them on shutdown
+	server.RegisterOnShutdown(wsHandler.CloseStreams)
 
 	// Start server in a goroutine
 	go func() {
@@ -130,7 +230,8 @@ func main() {
 
 	logger.Info("Shutting down server...")
 
-	// Shutdown background job server
+	// Shutdown background job server and scheduler
+	jobScheduler.Shutdown()
 	jobServer.Shutdown()
 
 	// Give outstanding requests 30 seconds to complete
@@ -141,6 +242,14 @@ func main() {
 		logger.WithError(err).Fatal("Server forced to shutdown")
 	}
 
+	// Leave the WebSocket cluster's presence
+	wsHub.StopCluster()
+
+	// Deliver events published by the last requests before exiting
+	if err := eventBus.Close(); err != nil {
+		logger.WithError(err).Error("Failed to close event bus")
+	}
+
 	logger.Info("Server exited")
 }
 
@@ -155,6 +264,15 @@ func setupRoutes(
 	tenantHandler *handlers.TenantHandler,
 	notificationHandler *handlers.NotificationHandler,
 	wsHandler *handlers.WebSocketHandler,
+	calendarHandler *handlers.CalendarHandler,
+	sprintHandler *handlers.SprintHandler,
+	checklistHandler *handlers.ChecklistHandler,
+	importHandler *handlers.ImportHandler,
+	exportHandler *handlers.ExportHandler,
+	webhookHandler *handlers.WebhookHandler,
+	inboundHandler *handlers.InboundEmailHandler,
+	notificationTemplateHandler *handlers.NotificationTemplateHandler,
+	pushHandler *handlers.PushHandler,
 	logger *logger.Logger,
 ) *gin.Engine {
 	router := gin.New()
@@ -228,6 +346,15 @@ func setupRoutes(
 
 		// Tenant invitation routes (public but require valid token)
 		public.POST("/invitations/:token/accept", tenantHandler.AcceptInvitation)
+
+		// Calendar feeds (public but require a valid feed token)
+		public.GET("/calendar/:token", calendarHandler.ServeFeed)
+
+		// Export downloads (public but require a signed link)
+		public.GET("/exports/:id/download", exportHandler.DownloadExport)
+
+		// Inbound email (public but require the mail provider's shared secret)
+		public.POST("/inbound/email", inboundHandler.ReceiveEmail)
 	}
 
 	// Protected routes (authentication required)
@@ -271,6 +398,30 @@ func setupRoutes(
 			tasks.POST("/:id/attachments", taskHandler.AddAttachment)
 			tasks.GET("/:id/attachments", taskHandler.ListAttachments)
 			tasks.DELETE("/attachments/:attachment_id", taskHandler.DeleteAttachment)
+			tasks.GET("/:id/relations", taskHandler.ListRelations)
+			tasks.POST("/:id/relations", taskHandler.CreateRelation)
+			tasks.DELETE("/:id/relations/:relation_id", taskHandler.DeleteRelation)
+			tasks.POST("/:id/watch", taskHandler.WatchTask)
+			tasks.DELETE("/:id/watch", taskHandler.UnwatchTask)
+			tasks.GET("/:id/checklist", checklistHandler.ListChecklist)
+			tasks.POST("/:id/checklist", checklistHandler.AddChecklistItem)
+			tasks.PUT("/:id/checklist/order", checklistHandler.ReorderChecklist)
+			tasks.PUT("/:id/checklist/:item_id", checklistHandler.UpdateChecklistItem)
+			tasks.DELETE("/:id/checklist/:item_id", checklistHandler.DeleteChecklistItem)
+			tasks.POST("/:id/checklist/:item_id/convert", checklistHandler.ConvertChecklistItem)
+		}
+
+		// Saved view routes
+		views := protected.Group("/views")
+		{
+			views.GET("", taskHandler.ListViews)
+			views.POST("", taskHandler.CreateView)
+			views.GET("/:id", taskHandler.GetView)
+			views.PUT("/:id", taskHandler.UpdateView)
+			views.DELETE("/:id", taskHandler.DeleteView)
+			views.PUT("/:id/default", taskHandler.SetDefaultView)
+			views.DELETE("/:id/default", taskHandler.ClearDefaultView)
+			views.GET("/:id/tasks", taskHandler.RunView)
 		}
 
 		// Project management
@@ -279,8 +430,37 @@ func setupRoutes(
 			projects.GET("", taskHandler.ListProjects)
 			projects.POST("", middleware.RequireManagerOrAdmin(), taskHandler.CreateProject)
 			projects.GET("/:id", taskHandler.GetProject)
-			projects.PUT("/:id", middleware.RequireManagerOrAdmin(), taskHandler.UpdateProject)
-			projects.DELETE("/:id", middleware.RequireManagerOrAdmin(), taskHandler.DeleteProject)
+			projects.PUT("/:id", taskHandler.UpdateProject)
+			projects.DELETE("/:id", taskHandler.DeleteProject)
+			projects.POST("/:id/archive", taskHandler.ArchiveProject)
+			projects.POST("/:id/unarchive", taskHandler.UnarchiveProject)
+			projects.GET("/:id/stats", taskHandler.GetProjectStats)
+			projects.GET("/:id/members", taskHandler.ListProjectMembers)
+			projects.POST("/:id/members", taskHandler.AddProjectMember)
+			projects.PUT("/:id/members/:user_id", taskHandler.UpdateProjectMember)
+			projects.DELETE("/:id/members/:user_id", taskHandler.RemoveProjectMember)
+			projects.GET("/:id/timeline", taskHandler.GetProjectTimeline)
+			projects.POST("/:id/timeline/shift", taskHandler.ShiftTimelineTask)
+			projects.GET("/:id/sprints", sprintHandler.ListSprints)
+			projects.POST("/:id/sprints", sprintHandler.CreateSprint)
+			projects.GET("/:id/velocity", sprintHandler.GetVelocity)
+			projects.GET("/:id/inbound-addresses", inboundHandler.ListInboundAddresses)
+			projects.POST("/:id/inbound-addresses", inboundHandler.CreateInboundAddress)
+			projects.DELETE("/:id/inbound-addresses/:address_id", inboundHandler.RevokeInboundAddress)
+		}
+
+		// Sprint routes
+		sprints := protected.Group("/sprints")
+		{
+			sprints.GET("/:id", sprintHandler.GetSprint)
+			sprints.PUT("/:id", sprintHandler.UpdateSprint)
+			sprints.DELETE("/:id", sprintHandler.DeleteSprint)
+			sprints.POST("/:id/start", sprintHandler.StartSprint)
+			sprints.POST("/:id/close", sprintHandler.CloseSprint)
+			sprints.GET("/:id/report", sprintHandler.GetSprintReport)
+			sprints.GET("/:id/burndown", sprintHandler.GetBurndown)
+			sprints.POST("/:id/tasks", sprintHandler.AddSprintTasks)
+			sprints.DELETE("/:id/tasks/:task_id", sprintHandler.RemoveSprintTask)
 		}
 
 		// Tag management
@@ -291,6 +471,49 @@ func setupRoutes(
 			tags.GET("/:id", taskHandler.GetTag)
 			tags.PUT("/:id", taskHandler.UpdateTag)
 			tags.DELETE("/:id", taskHandler.DeleteTag)
+			tags.POST("/:id/merge", middleware.RequireManagerOrAdmin(), taskHandler.MergeTag)
+		}
+
+		// Task imports
+		imports := protected.Group("/imports")
+		imports.Use(middleware.RequireManagerOrAdmin())
+		{
+			imports.GET("", importHandler.ListImports)
+			imports.POST("", importHandler.UploadImport)
+			imports.GET("/:id", importHandler.GetImport)
+			imports.POST("/:id/run", importHandler.RunImport)
+			imports.POST("/:id/undo", importHandler.UndoImport)
+		}
+
+		// Task and project exports
+		exports := protected.Group("/exports")
+		{
+			exports.GET("", exportHandler.ListExports)
+			exports.POST("", exportHandler.CreateExport)
+			exports.GET("/:id", exportHandler.GetExport)
+		}
+
+		// Outgoing webhooks (admin only)
+		hooks := protected.Group("/webhooks")
+		hooks.Use(middleware.RequireAdmin())
+		{
+			hooks.GET("", webhookHandler.ListWebhooks)
+			hooks.POST("", webhookHandler.CreateWebhook)
+			hooks.GET("/:id", webhookHandler.GetWebhook)
+			hooks.PUT("/:id", webhookHandler.UpdateWebhook)
+			hooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+			hooks.POST("/:id/rotate-secret", webhookHandler.RotateWebhookSecret)
+			hooks.GET("/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+			hooks.GET("/:id/deliveries/:delivery_id", webhookHandler.GetWebhookDelivery)
+			hooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.RedeliverWebhookDelivery)
+		}
+
+		// Calendar feed management
+		calendarFeeds := protected.Group("/calendar-feeds")
+		{
+			calendarFeeds.GET("", calendarHandler.ListFeeds)
+			calendarFeeds.POST("", calendarHandler.CreateFeed)
+			calendarFeeds.DELETE("/:id", calendarHandler.RevokeFeed)
 		}
 
 		// Tenant management (admin only)
@@ -314,18 +537,48 @@ func setupRoutes(
 			notifications.PUT("/mark-all-read", notificationHandler.MarkAllAsRead)
 			notifications.GET("/settings", notificationHandler.GetNotificationSettings)
 			notifications.PUT("/settings", notificationHandler.UpdateNotificationSettings)
+			notifications.GET("/quiet-hours", notificationHandler.GetQuietHours)
+			notifications.PUT("/quiet-hours", notificationHandler.UpdateQuietHours)
+			notifications.PUT("/do-not-disturb", notificationHandler.SetDoNotDisturb)
+			notifications.DELETE("/do-not-disturb", notificationHandler.ClearDoNotDisturb)
 			notifications.GET("/:id", notificationHandler.GetNotification)
+			notifications.GET("/:id/events", notificationHandler.ListNotificationEvents)
 			notifications.PUT("/:id/read", notificationHandler.MarkAsRead)
 			notifications.PUT("/:id/unread", notificationHandler.MarkAsUnread)
 			notifications.DELETE("/:id", notificationHandler.DeleteNotification)
 		}
 
+		// Notification template overrides (admin only)
+		notificationTemplates := protected.Group("/notification-templates")
+		notificationTemplates.Use(middleware.RequireAdmin())
+		{
+			notificationTemplates.GET("", notificationTemplateHandler.ListNotificationTemplates)
+			notificationTemplates.POST("", notificationTemplateHandler.CreateNotificationTemplate)
+			notificationTemplates.GET("/catalog", notificationTemplateHandler.GetNotificationTemplateCatalog)
+			notificationTemplates.POST("/preview", notificationTemplateHandler.PreviewNotificationTemplate)
+			notificationTemplates.GET("/:id", notificationTemplateHandler.GetNotificationTemplate)
+			notificationTemplates.PUT("/:id", notificationTemplateHandler.UpdateNotificationTemplate)
+			notificationTemplates.DELETE("/:id", notificationTemplateHandler.DeleteNotificationTemplate)
+		}
+
+		// Web Push subscriptions of the current user's browsers
+		push := protected.Group("/push")
+		{
+			push.GET("/vapid-public-key", pushHandler.GetVAPIDPublicKey)
+			push.GET("/subscriptions", pushHandler.ListPushSubscriptions)
+			push.POST("/subscriptions", pushHandler.CreatePushSubscription)
+			push.DELETE("/subscriptions/:id", pushHandler.DeletePushSubscription)
+		}
+
 		// WebSocket routes
 		ws := protected.Group("/ws")
 		{
 			ws.GET("", wsHandler.HandleConnection())
 			ws.GET("/online-users", wsHandler.GetOnlineUsers)
 		}
+
+		// Server-Sent Events for clients that cannot hold a WebSocket
+		protected.GET("/events", wsHandler.StreamEvents)
 	}
 
 	// Handle 404
@@ -344,6 +597,29 @@ func runMigrations(db *database.DB) error {
 		// &models.UserSession{}, // Depends on User
 		&models.Project{},
 		&models.Tag{},
+		&models.TaskRelation{},
+		&models.TaskWatcher{},
+		&models.CalendarFeed{},
+		&models.SavedView{},
+		&models.ProjectMember{},
+		&models.Sprint{},
+		&models.SprintTask{},
+		&models.SprintSnapshot{},
+		&models.ChecklistItem{},
+		&models.Import{},
+		&models.ImportRecord{},
+		&models.Export{},
+		&models.AuditLog{},
+		&models.SearchDocument{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.InboundAddress{},
+		&models.InboundMessage{},
+		&models.NotificationTemplate{},
+		&models.NotificationQueue{},
+		&models.NotificationDelivery{},
+		&models.PushSubscription{},
+		&models.NotificationEvent{},
 		// &models.Task{}, // Depends on User
 		// &models.TaskComment{}, // Depends on User  
 		// &models.TaskAttachment{}, // Depends on User
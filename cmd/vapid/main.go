@@ -0,0 +1,20 @@
+// Command vapid generates a VAPID key pair for Web Push and prints it in
+// the form of the PUSH_ settings in .env
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/drazan344/taskflow-go/internal/webpush"
+)
+
+func main() {
+	keys, err := webpush.GenerateKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("PUSH_VAPID_PUBLIC_KEY=%s\n", keys.Public)
+	fmt.Printf("PUSH_VAPID_PRIVATE_KEY=%s\n", keys.Private)
+}
@@ -308,7 +308,14 @@ func (s *Service) RefreshTokens(req *RefreshTokenRequest) (*LoginResponse, error
 
 // Logout invalidates user session
 func (s *Service) Logout(sessionID uuid.UUID) error {
-	if err := s.db.Model(&models.UserSession{}).Where("id = ?", sessionID).Update("is_active", false).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserSession{}).Where("id = ?", sessionID).Update("is_active", false).Error; err != nil {
+			return err
+		}
+		// The browser stops receiving push notifications once its session ends
+		return tx.Unscoped().Where("session_id = ?", sessionID).Delete(&models.PushSubscription{}).Error
+	})
+	if err != nil {
 		return errors.InternalServer("Failed to logout", err)
 	}
 	return nil
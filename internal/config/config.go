@@ -17,6 +17,12 @@ type Config struct {
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
 	Log      LogConfig      `mapstructure:"log"`
 	Worker   WorkerConfig   `mapstructure:"worker"`
+	Events   EventsConfig   `mapstructure:"events"`
+	Webhooks WebhooksConfig `mapstructure:"webhooks"`
+	Inbound  InboundConfig  `mapstructure:"inbound"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
+	Push     PushConfig     `mapstructure:"push"`
+	WebSocket WebSocketConfig `mapstructure:"websocket"`
 }
 
 type DatabaseConfig struct {
@@ -43,21 +49,30 @@ type JWTConfig struct {
 }
 
 type ServerConfig struct {
-	Host    string `mapstructure:"host"`
-	Port    int    `mapstructure:"port"`
-	GinMode string `mapstructure:"gin_mode"`
+	Host      string `mapstructure:"host"`
+	Port      int    `mapstructure:"port"`
+	GinMode   string `mapstructure:"gin_mode"`
+	PublicURL string `mapstructure:"public_url"` // base URL used in links sent to users
 }
 
 type EmailConfig struct {
-	SMTPHost     string `mapstructure:"smtp_host"`
-	SMTPPort     int    `mapstructure:"smtp_port"`
-	SMTPUser     string `mapstructure:"smtp_user"`
-	SMTPPassword string `mapstructure:"smtp_password"`
+	Driver       string        `mapstructure:"driver"` // smtp, file or memory
+	From         string        `mapstructure:"from"`
+	FromName     string        `mapstructure:"from_name"`
+	SMTPHost     string        `mapstructure:"smtp_host"`
+	SMTPPort     int           `mapstructure:"smtp_port"`
+	SMTPUser     string        `mapstructure:"smtp_user"`
+	SMTPPassword string        `mapstructure:"smtp_password"`
+	SMTPTLS      string        `mapstructure:"smtp_tls"` // starttls, tls or none
+	Timeout      time.Duration `mapstructure:"timeout"`
+	FileDir      string        `mapstructure:"file_dir"` // where the file driver writes .eml files
 }
 
 type StorageConfig struct {
-	UploadPath    string `mapstructure:"upload_path"`
-	MaxUploadSize string `mapstructure:"max_upload_size"`
+	UploadPath    string        `mapstructure:"upload_path"`
+	MaxUploadSize string        `mapstructure:"max_upload_size"`
+	SigningSecret string        `mapstructure:"signing_secret"` // signs download links, defaults to the JWT secret
+	LinkExpiry    time.Duration `mapstructure:"link_expiry"`
 }
 
 type RateLimitConfig struct {
@@ -75,6 +90,57 @@ type WorkerConfig struct {
 	Queues      []string `mapstructure:"queues"`
 }
 
+type EventsConfig struct {
+	Driver        string        `mapstructure:"driver"` // memory or redis
+	Stream        string        `mapstructure:"stream"`
+	MaxLen        int64         `mapstructure:"max_len"`
+	Consumer      string        `mapstructure:"consumer"` // defaults to host name and process ID
+	MaxDeliveries int64         `mapstructure:"max_deliveries"`
+	RetryAfter    time.Duration `mapstructure:"retry_after"`
+}
+
+type WebhooksConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout"`
+	MaxRetries   int           `mapstructure:"max_retries"`
+	DisableAfter int           `mapstructure:"disable_after"` // failed deliveries in a row before a webhook is disabled
+	// AllowedNetworks are internal CIDR ranges webhooks and push endpoints
+	// may post to; private, loopback and link-local addresses are refused
+	// otherwise
+	AllowedNetworks []string `mapstructure:"allowed_networks"`
+}
+
+type InboundConfig struct {
+	Secret  string `mapstructure:"secret"`   // shared with the mail provider; the endpoint is disabled when empty
+	Domain  string `mapstructure:"domain"`   // domain of project+<token>@ addresses
+	MaxSize int64  `mapstructure:"max_size"` // largest accepted raw message in bytes
+}
+
+type NotificationsConfig struct {
+	DigestHour    int    `mapstructure:"digest_hour"`    // local hour digests are sent at
+	DigestWeekday string `mapstructure:"digest_weekday"` // day weekly digests are sent on
+
+	// CollapseWindow is how long an unread notification keeps grouping
+	// notifications with its collapse key after its last update; 0 turns
+	// grouping off
+	CollapseWindow time.Duration `mapstructure:"collapse_window"`
+}
+
+type PushConfig struct {
+	VAPIDPublicKey  string        `mapstructure:"vapid_public_key"`
+	VAPIDPrivateKey string        `mapstructure:"vapid_private_key"` // push is disabled when empty
+	Subject         string        `mapstructure:"subject"`           // mailto: or https: contact sent to push services
+	ServiceURL      string        `mapstructure:"service_url"`       // overrides the push service host, e.g. for a local stand-in
+	TTL             time.Duration `mapstructure:"ttl"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+}
+
+type WebSocketConfig struct {
+	Driver      string        `mapstructure:"driver"`       // memory, or redis to share messages and presence between API instances
+	Prefix      string        `mapstructure:"prefix"`       // Redis channel and key prefix
+	NodeID      string        `mapstructure:"node_id"`      // defaults to host name and process ID
+	PresenceTTL time.Duration `mapstructure:"presence_ttl"` // how long an instance's connections are listed after its last heartbeat
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName(".env")
 	viper.SetConfigType("env")
@@ -124,14 +190,21 @@ func setDefaults() {
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.gin_mode", "debug")
+	viper.SetDefault("server.public_url", "http://localhost:8080")
 
 	// Email defaults
 	viper.SetDefault("email.smtp_host", "smtp.gmail.com")
 	viper.SetDefault("email.smtp_port", 587)
+	viper.SetDefault("email.driver", "smtp")
+	viper.SetDefault("email.from", "noreply@taskflow.local")
+	viper.SetDefault("email.from_name", "TaskFlow")
+	viper.SetDefault("email.timeout", "30s")
+	viper.SetDefault("email.file_dir", "./tmp/mail")
 
 	// Storage defaults
 	viper.SetDefault("storage.upload_path", "./uploads")
 	viper.SetDefault("storage.max_upload_size", "10MB")
+	viper.SetDefault("storage.link_expiry", "24h")
 
 	// Rate limiting defaults
 	viper.SetDefault("rate_limit.requests", 100)
@@ -144,6 +217,38 @@ func setDefaults() {
 	// Worker defaults
 	viper.SetDefault("worker.concurrency", 10)
 	viper.SetDefault("worker.queues", []string{"default", "email", "analytics"})
+
+	// Event bus defaults
+	viper.SetDefault("events.driver", "memory")
+	viper.SetDefault("events.stream", "taskflow:events")
+	viper.SetDefault("events.max_len", 100000)
+	viper.SetDefault("events.max_deliveries", 5)
+	viper.SetDefault("events.retry_after", "1m")
+
+	// Webhook defaults
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.max_retries", 8)
+	viper.SetDefault("webhooks.disable_after", 10)
+	viper.SetDefault("webhooks.allowed_networks", []string{})
+
+	// Inbound email defaults
+	viper.SetDefault("inbound.domain", "inbound.localhost")
+	viper.SetDefault("inbound.max_size", 25<<20)
+
+	// Notification defaults
+	viper.SetDefault("notifications.digest_hour", 8)
+	viper.SetDefault("notifications.digest_weekday", "monday")
+	viper.SetDefault("notifications.collapse_window", "10m")
+
+	// Web Push defaults
+	viper.SetDefault("push.subject", "mailto:support@taskflow.local")
+	viper.SetDefault("push.ttl", "24h")
+	viper.SetDefault("push.timeout", "10s")
+
+	// WebSocket defaults
+	viper.SetDefault("websocket.driver", "memory")
+	viper.SetDefault("websocket.prefix", "taskflow:ws")
+	viper.SetDefault("websocket.presence_ttl", "30s")
 }
 
 func (c *Config) GetDatabaseDSN() string {
@@ -157,4 +262,12 @@ func (c *Config) GetRedisAddr() string {
 
 func (c *Config) GetServerAddr() string {
 	return fmt.Sprintf("%s:%d", c.Server.Host, c.Server.Port)
-}
\ No newline at end of file
+}
+
+// GetSigningSecret returns the secret used to sign download links
+func (c *Config) GetSigningSecret() string {
+	if c.Storage.SigningSecret != "" {
+		return c.Storage.SigningSecret
+	}
+	return c.JWT.Secret
+}
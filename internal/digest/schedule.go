@@ -0,0 +1,106 @@
+// Package digest collects the notifications of users who asked for daily or
+// weekly email and sends them as one email per user at the user's local
+// send time.
+package digest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Schedule is the local time digests are sent at
+type Schedule struct {
+	Hour    int
+	Weekday time.Weekday
+}
+
+// NewSchedule creates a schedule from configuration. An hour outside 0-23
+// sends at 08:00 and an unknown weekday sends weekly digests on Monday.
+func NewSchedule(hour int, weekday string) Schedule {
+	schedule := Schedule{Hour: hour, Weekday: time.Monday}
+	if hour < 0 || hour > 23 {
+		schedule.Hour = 8
+	}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), strings.TrimSpace(weekday)) {
+			schedule.Weekday = day
+		}
+	}
+	return schedule
+}
+
+// Next returns when a notification of the frequency received at now goes
+// out to a user in the timezone. Users without a valid timezone get their
+// digest on UTC time.
+func (s Schedule) Next(now time.Time, timezone string, frequency models.NotificationFrequency) time.Time {
+	location := time.UTC
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			location = loc
+		}
+	}
+
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, 0, 0, 0, location)
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+	if frequency == models.NotificationFrequencyWeekly {
+		for next.Weekday() != s.Weekday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next.UTC()
+}
+
+// Queue adds a notification to the recipient's next digest, or updates the
+// item already queued for it. Stored notifications are left out of the
+// digest if the user reads them in-app first.
+func Queue(db *gorm.DB, notification *models.Notification, frequency models.NotificationFrequency,
+	scheduledAt time.Time, projectName string) error {
+	data := map[string]interface{}{}
+	if projectName != "" {
+		data["project_name"] = projectName
+	}
+	item := &models.NotificationQueue{
+		TenantID:    notification.TenantID,
+		UserID:      notification.UserID,
+		Type:        notification.Type,
+		Channel:     models.NotificationChannelEmail,
+		Frequency:   frequency,
+		Priority:    1,
+		Status:      "pending",
+		ScheduledAt: scheduledAt,
+		Payload: models.NotificationPayload{
+			Subject:   notification.Title,
+			Body:      notification.Message,
+			ActionURL: notification.ActionURL,
+			Data:      data,
+		},
+	}
+	if notification.ID != uuid.Nil {
+		item.NotificationID = &notification.ID
+
+		// A grouped notification replaces the item its first event queued,
+		// so the digest lists the group once
+		result := db.Model(&models.NotificationQueue{}).Select("payload").
+			Where("notification_id = ? AND channel = ? AND status = ?", notification.ID, item.Channel, "pending").
+			Updates(&models.NotificationQueue{Payload: item.Payload})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update digest notification: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+	}
+	if err := db.Create(item).Error; err != nil {
+		return fmt.Errorf("failed to queue digest notification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/mailer"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/templates"
+)
+
+// MaxAttempts is how many times a digest item is sent before it is given up
+const MaxAttempts = 3
+
+// batchSize limits the items loaded by one run
+const batchSize = 1000
+
+// Sender sends the digests that are due
+type Sender struct {
+	db        *gorm.DB
+	templates *templates.Engine
+	mailer    mailer.Mailer
+	logger    *logrus.Logger
+}
+
+// New creates a digest sender
+func New(db *gorm.DB, engine *templates.Engine, mail mailer.Mailer, logger *logrus.Logger) *Sender {
+	return &Sender{db: db, templates: engine, mailer: mail, logger: logger}
+}
+
+// recipient is a user with the items of their digest
+type recipient struct {
+	tenantID uuid.UUID
+	userID   uuid.UUID
+	items    []*models.NotificationQueue
+}
+
+// Send sends every digest that is due and returns how many were sent. A
+// digest that fails to send is retried by later runs; items the user read
+// in-app in the meantime are skipped. Digests of users in quiet hours or
+// do-not-disturb wait until it ends.
+func (s *Sender) Send(ctx context.Context) (int, error) {
+	var items []*models.NotificationQueue
+	if err := s.db.WithContext(ctx).
+		Where("channel = ? AND frequency IN ? AND scheduled_at <= ?", models.NotificationChannelEmail,
+			[]models.NotificationFrequency{models.NotificationFrequencyDaily, models.NotificationFrequencyWeekly}, time.Now()).
+		Where("status = ? OR (status = ? AND retry_count < ?)", "pending", "failed", MaxAttempts).
+		Order("user_id, created_at").
+		Limit(batchSize).
+		Find(&items).Error; err != nil {
+		return 0, fmt.Errorf("failed to load digest items: %w", err)
+	}
+
+	var recipients []*recipient
+	byUser := map[uuid.UUID]*recipient{}
+	for _, item := range items {
+		r, ok := byUser[item.UserID]
+		if !ok {
+			r = &recipient{tenantID: item.TenantID, userID: item.UserID}
+			byUser[item.UserID] = r
+			recipients = append(recipients, r)
+		}
+		r.items = append(r.items, item)
+	}
+
+	sent := 0
+	for _, r := range recipients {
+		ok, err := s.send(ctx, r)
+		if err != nil {
+			return sent, err
+		}
+		if ok {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// send sends the digest of one user. Send failures are recorded on the items
+// rather than returned; the error is only for failures to record them.
+func (s *Sender) send(ctx context.Context, r *recipient) (bool, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", r.userID, r.tenantID).First(&user).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return false, fmt.Errorf("failed to load digest recipient: %w", err)
+	}
+	if err == gorm.ErrRecordNotFound || !user.IsActive() || !user.EnableEmailNotifications {
+		return false, s.skip(ctx, r.items)
+	}
+	if until, quiet := user.QuietUntil(time.Now()); quiet {
+		return false, s.postpone(ctx, r.items, until)
+	}
+
+	items, err := s.unread(ctx, r.items)
+	if err != nil || len(items) == 0 {
+		return false, err
+	}
+
+	data := templates.Data{
+		"recipient_name": user.GetFullName(),
+		"period":         period(items),
+		"count":          len(items),
+		"groups":         groups(items),
+	}
+	content, err := s.templates.Render(ctx, r.tenantID, models.NotificationTypeDigest, models.NotificationChannelEmail, user.Language, data)
+	if err == nil {
+		err = s.mailer.Send(ctx, &mailer.Message{
+			To:      []mail.Address{{Name: user.GetFullName(), Address: user.Email}},
+			Subject: content.Subject,
+			Text:    content.Body,
+			HTML:    content.HTML,
+		})
+	}
+
+	for _, item := range items {
+		if err != nil {
+			item.MarkAsFailed(err)
+			if errors.Is(err, mailer.ErrPermanent) {
+				item.RetryCount = MaxAttempts
+			}
+		} else {
+			item.MarkAsSent()
+		}
+		if saveErr := s.db.WithContext(ctx).Save(item).Error; saveErr != nil {
+			return false, fmt.Errorf("failed to update digest item: %w", saveErr)
+		}
+	}
+
+	if err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"tenant_id": r.tenantID,
+			"user_id":   r.userID,
+			"items":     len(items),
+		}).Warn("Failed to send notification digest")
+		return false, nil
+	}
+	return true, nil
+}
+
+// unread skips the items whose notification was read, archived or deleted
+// and returns the rest
+func (s *Sender) unread(ctx context.Context, items []*models.NotificationQueue) ([]*models.NotificationQueue, error) {
+	var ids []uuid.UUID
+	for _, item := range items {
+		if item.NotificationID != nil {
+			ids = append(ids, *item.NotificationID)
+		}
+	}
+
+	var unreadIDs []uuid.UUID
+	if len(ids) > 0 {
+		if err := s.db.WithContext(ctx).Model(&models.Notification{}).
+			Where("id IN ? AND status = ? AND read_at IS NULL", ids, models.NotificationStatusUnread).
+			Pluck("id", &unreadIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to load digest notifications: %w", err)
+		}
+	}
+	isUnread := make(map[uuid.UUID]bool, len(unreadIDs))
+	for _, id := range unreadIDs {
+		isUnread[id] = true
+	}
+
+	var keep, skipped []*models.NotificationQueue
+	for _, item := range items {
+		if item.NotificationID == nil || isUnread[*item.NotificationID] {
+			keep = append(keep, item)
+		} else {
+			skipped = append(skipped, item)
+		}
+	}
+	return keep, s.skip(ctx, skipped)
+}
+
+// postpone moves items to the end of the user's quiet hours or
+// do-not-disturb
+func (s *Sender) postpone(ctx context.Context, items []*models.NotificationQueue, until time.Time) error {
+	for _, item := range items {
+		item.ScheduledAt = until
+		if err := s.db.WithContext(ctx).Save(item).Error; err != nil {
+			return fmt.Errorf("failed to update digest item: %w", err)
+		}
+	}
+	return nil
+}
+
+// skip marks items as not needing to be sent
+func (s *Sender) skip(ctx context.Context, items []*models.NotificationQueue) error {
+	for _, item := range items {
+		item.MarkAsSkipped()
+		if err := s.db.WithContext(ctx).Save(item).Error; err != nil {
+			return fmt.Errorf("failed to update digest item: %w", err)
+		}
+	}
+	return nil
+}
+
+// period names what a digest covers. Weekly items cover the longer period
+// when a user's daily and weekly items go out together.
+func period(items []*models.NotificationQueue) string {
+	for _, item := range items {
+		if item.Frequency == models.NotificationFrequencyWeekly {
+			return "week"
+		}
+	}
+	return "day"
+}
+
+// groups collects items by project and type. Projects are in name order and
+// items in the order they arrived.
+func groups(items []*models.NotificationQueue) []map[string]interface{} {
+	type key struct {
+		project string
+		kind    models.NotificationType
+	}
+	var order []key
+	grouped := map[key][]map[string]interface{}{}
+	for _, item := range items {
+		project, _ := item.Payload.Data["project_name"].(string)
+		if project == "" {
+			project = "No project"
+		}
+		k := key{project: project, kind: item.Type}
+		if _, ok := grouped[k]; !ok {
+			order = append(order, k)
+		}
+		grouped[k] = append(grouped[k], map[string]interface{}{
+			"title":   item.Payload.Subject,
+			"message": item.Payload.Body,
+			"url":     item.Payload.ActionURL,
+		})
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].project != order[j].project {
+			return order[i].project < order[j].project
+		}
+		return order[i].kind < order[j].kind
+	})
+
+	result := make([]map[string]interface{}, 0, len(order))
+	for _, k := range order {
+		result = append(result, map[string]interface{}{
+			"project": k.project,
+			"label":   label(k.kind),
+			"items":   grouped[k],
+		})
+	}
+	return result
+}
+
+// label turns a notification type such as task_assigned into "Task assigned"
+func label(notificationType models.NotificationType) string {
+	text := strings.ReplaceAll(string(notificationType), "_", " ")
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
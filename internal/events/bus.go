@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"github.com/drazan344/taskflow-go/internal/config"
+)
+
+// Handler processes an event delivered to a subscriber. Returning an error
+// asks the bus to deliver the event again where the transport supports it.
+type Handler func(ctx context.Context, event *Event) error
+
+// Publisher publishes domain events
+type Publisher interface {
+	Publish(ctx context.Context, events ...*Event) error
+}
+
+// Bus delivers published events to every subscriber. Subscribers are
+// registered before Start and each one receives events on its own, in the
+// order they were published.
+type Bus interface {
+	Publisher
+
+	// Subscribe registers a named handler for the given event types, or for
+	// every event when no types are given. Names must be unique and stable
+	// because durable transports track delivery per name.
+	Subscribe(name string, handler Handler, types ...Type)
+
+	// Start begins delivering events to subscribers
+	Start(ctx context.Context) error
+
+	// Close stops delivery and waits for handlers that are running
+	Close() error
+}
+
+// subscription is a handler registered on a bus
+type subscription struct {
+	name    string
+	handler Handler
+	types   map[Type]bool
+}
+
+// newSubscription creates a subscription for the given types
+func newSubscription(name string, handler Handler, types []Type) *subscription {
+	sub := &subscription{name: name, handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[Type]bool, len(types))
+		for _, t := range types {
+			sub.types[t] = true
+		}
+	}
+	return sub
+}
+
+// wants checks if the subscription handles events of the type
+func (s *subscription) wants(t Type) bool {
+	return s.types == nil || s.types[t]
+}
+
+// handle runs the handler, turning a panic into an error so one broken
+// subscriber cannot take the delivery loop down
+func (s *subscription) handle(ctx context.Context, event *Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber %s panicked: %v", s.name, r)
+		}
+	}()
+	return s.handler(ctx, event)
+}
+
+// logFailure records a failed delivery
+func logFailure(logger *logrus.Logger, sub *subscription, event *Event, err error) {
+	logger.WithError(err).WithFields(logrus.Fields{
+		"subscriber": sub.name,
+		"event_id":   event.ID,
+		"event_type": event.Type,
+		"tenant_id":  event.TenantID,
+	}).Error("Event subscriber failed")
+}
+
+// Publish wraps a payload in an event and publishes it, logging failures
+// instead of returning them. Handlers call it after their change has been
+// committed, where a lost event must not fail the request.
+func Publish(ctx context.Context, publisher Publisher, logger *logrus.Logger, tenantID uuid.UUID, actorID *uuid.UUID, payload Payload) {
+	if publisher == nil {
+		return
+	}
+	event, err := New(tenantID, actorID, payload)
+	if err == nil {
+		err = publisher.Publish(ctx, event)
+	}
+	if err != nil {
+		logger.WithError(err).WithFields(logrus.Fields{
+			"event_type": payload.EventType(),
+			"tenant_id":  tenantID,
+		}).Error("Failed to publish event")
+	}
+}
+
+// NewBus creates the event bus selected in the configuration
+func NewBus(cfg *config.Config, client *redis.Client, logger *logrus.Logger) (Bus, error) {
+	switch cfg.Events.Driver {
+	case "", "memory":
+		return NewMemoryBus(logger), nil
+	case "redis":
+		return NewRedisBus(client, RedisOptions{
+			Stream:        cfg.Events.Stream,
+			MaxLen:        cfg.Events.MaxLen,
+			Consumer:      cfg.Events.Consumer,
+			MaxDeliveries: cfg.Events.MaxDeliveries,
+			RetryAfter:    cfg.Events.RetryAfter,
+		}, logger), nil
+	}
+	return nil, fmt.Errorf("unknown event bus driver %q", cfg.Events.Driver)
+}
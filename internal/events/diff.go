@@ -0,0 +1,70 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// Change is a field whose value differs between two snapshots. Field is the
+// JSON name of the field and the values are in their JSON form.
+type Change struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ignoredFields change on every write and are left out of diffs
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Diff lists the fields that differ between two snapshots of the same type,
+// sorted by field name
+func Diff(before, after interface{}) []Change {
+	old, err := fields(before)
+	if err != nil {
+		return nil
+	}
+	current, err := fields(after)
+	if err != nil {
+		return nil
+	}
+
+	names := map[string]bool{}
+	for name := range old {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	changes := []Change{}
+	for name := range names {
+		if ignoredFields[name] || reflect.DeepEqual(old[name], current[name]) {
+			continue
+		}
+		changes = append(changes, Change{Field: name, Old: old[name], New: current[name]})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// HasChange checks if the field is among the changes
+func HasChange(changes []Change, field string) bool {
+	for _, change := range changes {
+		if change.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fields returns the JSON fields of a snapshot
+func fields(snapshot interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	err = json.Unmarshal(data, &values)
+	return values, err
+}
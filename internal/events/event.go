@@ -0,0 +1,104 @@
+// Package events carries domain events from the handlers that change data to
+// the parts of the system that react to those changes. Handlers publish an
+// event once the change has been committed, and each subscriber (WebSocket
+// broadcasting, notifications, search indexing, the audit log) receives it
+// independently, so a slow or failing subscriber does not hold up the others.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies the kind of a domain event
+type Type string
+
+const (
+	TypeTaskCreated          Type = "task.created"
+	TypeTaskUpdated          Type = "task.updated"
+	TypeTaskDeleted          Type = "task.deleted"
+	TypeCommentAdded         Type = "comment.added"
+	TypeProjectCreated       Type = "project.created"
+	TypeProjectUpdated       Type = "project.updated"
+	TypeProjectArchived      Type = "project.archived"
+	TypeProjectUnarchived    Type = "project.unarchived"
+	TypeProjectDeleted       Type = "project.deleted"
+	TypeProjectMemberAdded   Type = "project.member_added"
+	TypeProjectMemberUpdated Type = "project.member_updated"
+	TypeProjectMemberRemoved Type = "project.member_removed"
+	TypeUserRegistered       Type = "user.registered"
+	TypeUserInvited          Type = "user.invited"
+	TypeUserUpdated          Type = "user.updated"
+	TypeUserDeleted          Type = "user.deleted"
+	TypeNotificationCreated  Type = "notification.created"
+)
+
+// Types lists every event type, in the order they are documented
+var Types = []Type{
+	TypeTaskCreated, TypeTaskUpdated, TypeTaskDeleted, TypeCommentAdded,
+	TypeProjectCreated, TypeProjectUpdated, TypeProjectArchived, TypeProjectUnarchived, TypeProjectDeleted,
+	TypeProjectMemberAdded, TypeProjectMemberUpdated, TypeProjectMemberRemoved,
+	TypeUserRegistered, TypeUserInvited, TypeUserUpdated, TypeUserDeleted,
+	TypeNotificationCreated,
+}
+
+// IsValid checks if the type is a known event type
+func (t Type) IsValid() bool {
+	for _, known := range Types {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the typed body of an event
+type Payload interface {
+	EventType() Type
+}
+
+// Event is the envelope every domain event travels in. Data holds the JSON
+// encoded payload so events can cross process boundaries unchanged.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       Type            `json:"type"`
+	TenantID   uuid.UUID       `json:"tenant_id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// New wraps a payload in an event of the payload's type
+func New(tenantID uuid.UUID, actorID *uuid.UUID, payload Payload) (*Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", payload.EventType(), err)
+	}
+	return &Event{
+		ID:         uuid.New(),
+		Type:       payload.EventType(),
+		TenantID:   tenantID,
+		ActorID:    actorID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}, nil
+}
+
+// Decode unmarshals the event data into the payload type matching the event
+func (e *Event) Decode(payload Payload) error {
+	if payload.EventType() != e.Type {
+		return fmt.Errorf("cannot decode %s event into %s payload", e.Type, payload.EventType())
+	}
+	if err := json.Unmarshal(e.Data, payload); err != nil {
+		return fmt.Errorf("failed to decode %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// IsActor checks if the given user caused the event
+func (e *Event) IsActor(userID uuid.UUID) bool {
+	return e.ActorID != nil && *e.ActorID == userID
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrClosed is returned when publishing on a bus that has been closed
+var ErrClosed = errors.New("event bus is closed")
+
+// memoryBuffer is the number of events each subscriber of a MemoryBus can
+// fall behind before new events for it are dropped
+const memoryBuffer = 1024
+
+// MemoryBus delivers events within the process. Each subscriber has its own
+// queue and goroutine, so events are delivered in order per subscriber and a
+// slow subscriber only delays itself. Events are lost when the process exits
+// or a subscriber's queue is full; failed deliveries are logged, not retried.
+type MemoryBus struct {
+	logger *logrus.Logger
+	subs   []*memorySubscriber
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// memorySubscriber is a subscription with its own queue
+type memorySubscriber struct {
+	*subscription
+	queue chan *Event
+}
+
+// NewMemoryBus creates an in-process event bus
+func NewMemoryBus(logger *logrus.Logger) *MemoryBus {
+	return &MemoryBus{logger: logger}
+}
+
+// Subscribe registers a handler
+func (b *MemoryBus) Subscribe(name string, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs = append(b.subs, &memorySubscriber{
+		subscription: newSubscription(name, handler, types),
+		queue:        make(chan *Event, memoryBuffer),
+	})
+}
+
+// Start begins delivering events to subscribers until the bus is closed
+func (b *MemoryBus) Start(ctx context.Context) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		b.wg.Add(1)
+		go b.deliver(ctx, sub)
+	}
+	return nil
+}
+
+// Publish queues events for every subscriber that handles them
+func (b *MemoryBus) Publish(ctx context.Context, events ...*Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+	for _, event := range events {
+		for _, sub := range b.subs {
+			if !sub.wants(event.Type) {
+				continue
+			}
+			select {
+			case sub.queue <- event:
+			default:
+				b.logger.WithFields(logrus.Fields{
+					"subscriber": sub.name,
+					"event_id":   event.ID,
+					"event_type": event.Type,
+				}).Warn("Event subscriber queue is full, dropping event")
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events and waits for subscribers to drain their queues
+func (b *MemoryBus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, sub := range b.subs {
+		close(sub.queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
+
+// deliver runs a subscriber's handler for each queued event
+func (b *MemoryBus) deliver(ctx context.Context, sub *memorySubscriber) {
+	defer b.wg.Done()
+
+	for event := range sub.queue {
+		if err := sub.handle(ctx, event); err != nil {
+			logFailure(b.logger, sub.subscription, event, err)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Snapshots describe an entity as it was when the event happened. They carry
+// the entity's own fields only, never its relations, so payloads stay small
+// and safe to hand to external subscribers.
+
+// TaskSnapshot is the state of a task
+type TaskSnapshot struct {
+	ID             uuid.UUID           `json:"id"`
+	Title          string              `json:"title"`
+	Description    string              `json:"description"`
+	Status         models.TaskStatus   `json:"status"`
+	Priority       models.TaskPriority `json:"priority"`
+	ProjectID      *uuid.UUID          `json:"project_id,omitempty"`
+	ParentID       *uuid.UUID          `json:"parent_id,omitempty"`
+	CreatorID      uuid.UUID           `json:"creator_id"`
+	AssigneeID     *uuid.UUID          `json:"assignee_id,omitempty"`
+	StartDate      *time.Time          `json:"start_date,omitempty"`
+	DueDate        *time.Time          `json:"due_date,omitempty"`
+	CompletedAt    *time.Time          `json:"completed_at,omitempty"`
+	EstimatedHours *float64            `json:"estimated_hours,omitempty"`
+	ActualHours    *float64            `json:"actual_hours,omitempty"`
+	StoryPoints    *float64            `json:"story_points,omitempty"`
+	IsMilestone    bool                `json:"is_milestone"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// ProjectSnapshot is the state of a project
+type ProjectSnapshot struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Color       string             `json:"color"`
+	IsActive    bool               `json:"is_active"`
+	IsPrivate   bool               `json:"is_private"`
+	DefaultRole models.ProjectRole `json:"default_role"`
+	StartDate   *time.Time         `json:"start_date,omitempty"`
+	EndDate     *time.Time         `json:"end_date,omitempty"`
+	ArchivedAt  *time.Time         `json:"archived_at,omitempty"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// UserSnapshot is the public state of a user account
+type UserSnapshot struct {
+	ID        uuid.UUID         `json:"id"`
+	Email     string            `json:"email"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Role      models.UserRole   `json:"role"`
+	Status    models.UserStatus `json:"status"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CommentSnapshot is the state of a task comment
+type CommentSnapshot struct {
+	ID        uuid.UUID  `json:"id"`
+	TaskID    uuid.UUID  `json:"task_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewTaskSnapshot captures the current state of a task
+func NewTaskSnapshot(task *models.Task) TaskSnapshot {
+	return TaskSnapshot{
+		ID:             task.ID,
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         task.Status,
+		Priority:       task.Priority,
+		ProjectID:      task.ProjectID,
+		ParentID:       task.ParentID,
+		CreatorID:      task.CreatorID,
+		AssigneeID:     task.AssigneeID,
+		StartDate:      task.StartDate,
+		DueDate:        task.DueDate,
+		CompletedAt:    task.CompletedAt,
+		EstimatedHours: task.EstimatedHours,
+		ActualHours:    task.ActualHours,
+		StoryPoints:    task.StoryPoints,
+		IsMilestone:    task.IsMilestone,
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+	}
+}
+
+// NewProjectSnapshot captures the current state of a project
+func NewProjectSnapshot(project *models.Project) ProjectSnapshot {
+	return ProjectSnapshot{
+		ID:          project.ID,
+		Name:        project.Name,
+		Description: project.Description,
+		Color:       project.Color,
+		IsActive:    project.IsActive,
+		IsPrivate:   project.IsPrivate,
+		DefaultRole: project.DefaultRole,
+		StartDate:   project.StartDate,
+		EndDate:     project.EndDate,
+		ArchivedAt:  project.ArchivedAt,
+		CreatedAt:   project.CreatedAt,
+		UpdatedAt:   project.UpdatedAt,
+	}
+}
+
+// NewUserSnapshot captures the current state of a user
+func NewUserSnapshot(user *models.User) UserSnapshot {
+	return UserSnapshot{
+		ID:        user.ID,
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// NewCommentSnapshot captures a task comment
+func NewCommentSnapshot(comment *models.TaskComment) CommentSnapshot {
+	return CommentSnapshot{
+		ID:        comment.ID,
+		TaskID:    comment.TaskID,
+		UserID:    comment.UserID,
+		ParentID:  comment.ParentID,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// TaskCreated is published when a task is created
+type TaskCreated struct {
+	Task TaskSnapshot `json:"task"`
+}
+
+// TaskUpdated is published when fields of a task change
+type TaskUpdated struct {
+	Task    TaskSnapshot `json:"task"`
+	Changes []Change     `json:"changes"`
+}
+
+// TaskDeleted is published when a task is deleted
+type TaskDeleted struct {
+	Task TaskSnapshot `json:"task"`
+}
+
+// CommentAdded is published when a comment is added to a task
+type CommentAdded struct {
+	Task    TaskSnapshot    `json:"task"`
+	Comment CommentSnapshot `json:"comment"`
+}
+
+// ProjectCreated is published when a project is created
+type ProjectCreated struct {
+	Project ProjectSnapshot `json:"project"`
+}
+
+// ProjectUpdated is published when fields of a project change
+type ProjectUpdated struct {
+	Project ProjectSnapshot `json:"project"`
+	Changes []Change        `json:"changes"`
+}
+
+// ProjectArchived is published when a project is archived
+type ProjectArchived struct {
+	Project ProjectSnapshot `json:"project"`
+}
+
+// ProjectUnarchived is published when an archived project is restored
+type ProjectUnarchived struct {
+	Project ProjectSnapshot `json:"project"`
+}
+
+// ProjectDeleted is published when a project is deleted
+type ProjectDeleted struct {
+	Project ProjectSnapshot `json:"project"`
+}
+
+// ProjectMemberAdded is published when a user is given a role in a project
+type ProjectMemberAdded struct {
+	Project ProjectSnapshot    `json:"project"`
+	UserID  uuid.UUID          `json:"user_id"`
+	Role    models.ProjectRole `json:"role"`
+}
+
+// ProjectMemberUpdated is published when the role of a project member changes
+type ProjectMemberUpdated struct {
+	Project      ProjectSnapshot    `json:"project"`
+	UserID       uuid.UUID          `json:"user_id"`
+	Role         models.ProjectRole `json:"role"`
+	PreviousRole models.ProjectRole `json:"previous_role"`
+}
+
+// ProjectMemberRemoved is published when a user loses their role in a project
+type ProjectMemberRemoved struct {
+	Project ProjectSnapshot    `json:"project"`
+	UserID  uuid.UUID          `json:"user_id"`
+	Role    models.ProjectRole `json:"role"`
+}
+
+// UserRegistered is published when a user signs up
+type UserRegistered struct {
+	User UserSnapshot `json:"user"`
+}
+
+// UserInvited is published when someone is invited to join a tenant
+type UserInvited struct {
+	InvitationID uuid.UUID       `json:"invitation_id"`
+	Email        string          `json:"email"`
+	Role         models.UserRole `json:"role"`
+	InvitedBy    uuid.UUID       `json:"invited_by"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+}
+
+// UserUpdated is published when fields of a user account change
+type UserUpdated struct {
+	User    UserSnapshot `json:"user"`
+	Changes []Change     `json:"changes"`
+}
+
+// UserDeleted is published when a user account is deleted
+type UserDeleted struct {
+	User UserSnapshot `json:"user"`
+}
+
+// NotificationCreated is published when a notification is stored for a user
+type NotificationCreated struct {
+	NotificationID uuid.UUID               `json:"notification_id"`
+	UserID         uuid.UUID               `json:"user_id"`
+	Type           models.NotificationType `json:"type"`
+}
+
+func (TaskCreated) EventType() Type          { return TypeTaskCreated }
+func (TaskUpdated) EventType() Type          { return TypeTaskUpdated }
+func (TaskDeleted) EventType() Type          { return TypeTaskDeleted }
+func (CommentAdded) EventType() Type         { return TypeCommentAdded }
+func (ProjectCreated) EventType() Type       { return TypeProjectCreated }
+func (ProjectUpdated) EventType() Type       { return TypeProjectUpdated }
+func (ProjectArchived) EventType() Type      { return TypeProjectArchived }
+func (ProjectUnarchived) EventType() Type    { return TypeProjectUnarchived }
+func (ProjectDeleted) EventType() Type       { return TypeProjectDeleted }
+func (ProjectMemberAdded) EventType() Type   { return TypeProjectMemberAdded }
+func (ProjectMemberUpdated) EventType() Type { return TypeProjectMemberUpdated }
+func (ProjectMemberRemoved) EventType() Type { return TypeProjectMemberRemoved }
+func (UserRegistered) EventType() Type       { return TypeUserRegistered }
+func (UserInvited) EventType() Type          { return TypeUserInvited }
+func (UserUpdated) EventType() Type          { return TypeUserUpdated }
+func (UserDeleted) EventType() Type          { return TypeUserDeleted }
+func (NotificationCreated) EventType() Type  { return TypeNotificationCreated }
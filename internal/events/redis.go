@@ -0,0 +1,248 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// RedisOptions configures a RedisBus
+type RedisOptions struct {
+	// Stream is the Redis stream events are appended to
+	Stream string
+	// MaxLen caps the stream length; older entries are trimmed approximately
+	MaxLen int64
+	// Consumer names this process within each subscriber's consumer group
+	Consumer string
+	// MaxDeliveries is how often an event is tried before it is given up on
+	MaxDeliveries int64
+	// RetryAfter is how long a failed event stays pending before it is retried
+	RetryAfter time.Duration
+}
+
+const (
+	redisBatchSize = 50
+	redisBlock     = 5 * time.Second
+)
+
+// RedisBus delivers events through a Redis stream. Every subscriber reads the
+// stream through its own consumer group, so each one receives every event
+// once across all API instances and keeps its own position. Events a handler
+// fails on stay pending and are retried after RetryAfter, up to
+// MaxDeliveries times.
+type RedisBus struct {
+	client *redis.Client
+	opts   RedisOptions
+	logger *logrus.Logger
+	subs   []*subscription
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+}
+
+// NewRedisBus creates an event bus on top of a Redis stream
+func NewRedisBus(client *redis.Client, opts RedisOptions, logger *logrus.Logger) *RedisBus {
+	if opts.Stream == "" {
+		opts.Stream = "taskflow:events"
+	}
+	if opts.Consumer == "" {
+		host, _ := os.Hostname()
+		opts.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if opts.MaxDeliveries <= 0 {
+		opts.MaxDeliveries = 5
+	}
+	if opts.RetryAfter <= 0 {
+		opts.RetryAfter = time.Minute
+	}
+	return &RedisBus{client: client, opts: opts, logger: logger}
+}
+
+// Subscribe registers a handler
+func (b *RedisBus) Subscribe(name string, handler Handler, types ...Type) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs = append(b.subs, newSubscription(name, handler, types))
+}
+
+// Start creates the consumer groups and begins reading the stream
+func (b *RedisBus) Start(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ctx, b.cancel = context.WithCancel(ctx)
+	for _, sub := range b.subs {
+		// New groups start at the end of the stream; existing groups resume
+		// where they left off
+		err := b.client.XGroupCreateMkStream(ctx, b.opts.Stream, sub.name, "$").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			b.cancel()
+			return fmt.Errorf("failed to create consumer group %s: %w", sub.name, err)
+		}
+	}
+	for _, sub := range b.subs {
+		b.wg.Add(1)
+		go b.consume(ctx, sub)
+	}
+	return nil
+}
+
+// Publish appends events to the stream
+func (b *RedisBus) Publish(ctx context.Context, events ...*Event) error {
+	pipe := b.client.Pipeline()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: b.opts.Stream,
+			MaxLen: b.opts.MaxLen,
+			Approx: b.opts.MaxLen > 0,
+			Values: map[string]interface{}{"type": string(event.Type), "event": data},
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish events: %w", err)
+	}
+	return nil
+}
+
+// Close stops reading the stream and waits for running handlers
+func (b *RedisBus) Close() error {
+	b.mu.Lock()
+	cancel := b.cancel
+	b.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	b.wg.Wait()
+	return nil
+}
+
+// consume reads new events for a subscriber and periodically retries the
+// ones it failed on
+func (b *RedisBus) consume(ctx context.Context, sub *subscription) {
+	defer b.wg.Done()
+
+	lastRetry := time.Now()
+	for ctx.Err() == nil {
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    sub.name,
+			Consumer: b.opts.Consumer,
+			Streams:  []string{b.opts.Stream, ">"},
+			Count:    redisBatchSize,
+			Block:    redisBlock,
+		}).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			if ctx.Err() != nil {
+				return
+			}
+			b.logger.WithError(err).WithField("subscriber", sub.name).Error("Failed to read event stream")
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				b.process(ctx, sub, message)
+			}
+		}
+
+		if time.Since(lastRetry) >= b.opts.RetryAfter {
+			b.retry(ctx, sub)
+			lastRetry = time.Now()
+		}
+	}
+}
+
+// retry claims events that have been pending longer than RetryAfter and
+// delivers them again, giving up on those that failed too often
+func (b *RedisBus) retry(ctx context.Context, sub *subscription) {
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: b.opts.Stream,
+		Group:  sub.name,
+		Idle:   b.opts.RetryAfter,
+		Start:  "-",
+		End:    "+",
+		Count:  redisBatchSize,
+	}).Result()
+	if err != nil {
+		b.logger.WithError(err).WithField("subscriber", sub.name).Error("Failed to list pending events")
+		return
+	}
+
+	ids := make([]string, 0, len(pending))
+	for _, entry := range pending {
+		if entry.RetryCount >= b.opts.MaxDeliveries {
+			b.logger.WithFields(logrus.Fields{
+				"subscriber": sub.name,
+				"message_id": entry.ID,
+				"deliveries": entry.RetryCount,
+			}).Error("Giving up on event after repeated failures")
+			b.ack(ctx, sub, entry.ID)
+			continue
+		}
+		ids = append(ids, entry.ID)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	messages, err := b.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   b.opts.Stream,
+		Group:    sub.name,
+		Consumer: b.opts.Consumer,
+		MinIdle:  b.opts.RetryAfter,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		b.logger.WithError(err).WithField("subscriber", sub.name).Error("Failed to claim pending events")
+		return
+	}
+	for _, message := range messages {
+		b.process(ctx, sub, message)
+	}
+}
+
+// process decodes a stream entry and hands it to the subscriber. The entry is
+// acknowledged unless the handler fails, in which case it stays pending.
+func (b *RedisBus) process(ctx context.Context, sub *subscription, message redis.XMessage) {
+	raw, _ := message.Values["event"].(string)
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		b.logger.WithError(err).WithFields(logrus.Fields{
+			"subscriber": sub.name,
+			"message_id": message.ID,
+		}).Error("Discarding malformed event")
+		b.ack(ctx, sub, message.ID)
+		return
+	}
+
+	if sub.wants(event.Type) {
+		if err := sub.handle(ctx, &event); err != nil {
+			logFailure(b.logger, sub, &event, err)
+			return
+		}
+	}
+	b.ack(ctx, sub, message.ID)
+}
+
+// ack marks a stream entry as handled by the subscriber
+func (b *RedisBus) ack(ctx context.Context, sub *subscription, id string) {
+	if err := b.client.XAck(ctx, b.opts.Stream, sub.name, id).Err(); err != nil {
+		b.logger.WithError(err).WithFields(logrus.Fields{
+			"subscriber": sub.name,
+			"message_id": id,
+		}).Error("Failed to acknowledge event")
+	}
+}
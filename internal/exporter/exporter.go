@@ -0,0 +1,478 @@
+// Package exporter writes tasks and projects to CSV, XLSX and NDJSON files in
+// the background and signs the links used to download them.
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/pkg/storage"
+	"github.com/drazan344/taskflow-go/pkg/xlsx"
+)
+
+// batchSize is the number of tasks or projects loaded at a time
+const batchSize = 500
+
+// Exporter generates export files
+type Exporter struct {
+	db     *gorm.DB
+	store  *storage.Local
+	logger *logrus.Logger
+	ttl    time.Duration
+}
+
+// New creates a new exporter. Finished files can be downloaded for ttl.
+func New(db *gorm.DB, store *storage.Local, logger *logrus.Logger, ttl time.Duration) *Exporter {
+	return &Exporter{
+		db:     db,
+		store:  store,
+		logger: logger,
+		ttl:    ttl,
+	}
+}
+
+// Run generates the file of a queued export. It returns the finished export,
+// or nil when the export was not waiting to run.
+func (e *Exporter) Run(ctx context.Context, exportID uuid.UUID) (*models.Export, error) {
+	db := e.db.WithContext(ctx)
+
+	var export models.Export
+	if err := db.Where("id = ?", exportID).First(&export).Error; err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	if export.Status != models.ExportStatusQueued {
+		e.logger.WithFields(logrus.Fields{
+			"export_id": export.ID,
+			"status":    export.Status,
+		}).Warn("Skipping export that is not queued")
+		return nil, nil
+	}
+
+	now := time.Now()
+	export.Status = models.ExportStatusRunning
+	export.StartedAt = &now
+	if err := e.save(db, &export); err != nil {
+		return nil, fmt.Errorf("failed to start export: %w", err)
+	}
+
+	if err := e.generate(db, &export); err != nil {
+		finished := time.Now()
+		export.Status = models.ExportStatusFailed
+		export.Failure = err.Error()
+		export.FinishedAt = &finished
+		if saveErr := e.save(db, &export); saveErr != nil {
+			e.logger.WithError(saveErr).WithField("export_id", export.ID).Error("Failed to record export failure")
+		}
+		return nil, err
+	}
+
+	finished := time.Now()
+	expires := finished.Add(e.ttl)
+	export.Status = models.ExportStatusCompleted
+	export.FinishedAt = &finished
+	export.ExpiresAt = &expires
+	if err := e.save(db, &export); err != nil {
+		return nil, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	e.logger.WithFields(logrus.Fields{
+		"export_id": export.ID,
+		"rows":      export.Rows,
+		"size":      export.FileSize,
+	}).Info("Export finished")
+	return &export, nil
+}
+
+// generate writes the export file to storage
+func (e *Exporter) generate(db *gorm.DB, export *models.Export) error {
+	var user models.User
+	if err := db.Where("id = ? AND tenant_id = ?", export.RequestedBy, export.TenantID).First(&user).Error; err != nil {
+		return fmt.Errorf("requesting user not found: %w", err)
+	}
+
+	key := fmt.Sprintf("exports/%s/%s.%s", export.TenantID, export.ID, export.Format)
+	file, err := e.store.Create(key)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	out := &countingWriter{w: file}
+
+	w := &writer{db: db, export: export, user: &user}
+	switch export.Format {
+	case models.ExportFormatCSV:
+		err = w.writeCSV(out)
+	case models.ExportFormatXLSX:
+		err = w.writeXLSX(out)
+	case models.ExportFormatNDJSON:
+		err = w.writeNDJSON(out)
+	default:
+		err = fmt.Errorf("unsupported export format %q", export.Format)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := e.store.Remove(key); removeErr != nil {
+			e.logger.WithError(removeErr).WithField("export_id", export.ID).Warn("Failed to remove incomplete export file")
+		}
+		return err
+	}
+
+	export.StorageKey = key
+	export.FileSize = out.n
+	export.Rows = w.rows
+	export.FileName = fmt.Sprintf("%s-%s.%s", export.Resource, export.CreatedAt.UTC().Format("20060102-150405"), export.Format)
+	return nil
+}
+
+// Expire removes the files of exports whose download links have expired
+func (e *Exporter) Expire(ctx context.Context) (int, error) {
+	db := e.db.WithContext(ctx)
+
+	var exports []models.Export
+	if err := db.Where("status = ? AND expires_at < ?", models.ExportStatusCompleted, time.Now()).Find(&exports).Error; err != nil {
+		return 0, fmt.Errorf("failed to fetch expired exports: %w", err)
+	}
+
+	expired := 0
+	for i := range exports {
+		if err := e.store.Remove(exports[i].StorageKey); err != nil {
+			e.logger.WithError(err).WithField("export_id", exports[i].ID).Warn("Failed to remove expired export file")
+			continue
+		}
+		if err := db.Model(&exports[i]).Updates(map[string]interface{}{
+			"status":      models.ExportStatusExpired,
+			"storage_key": "",
+		}).Error; err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// save writes the progress columns of an export
+func (e *Exporter) save(db *gorm.DB, export *models.Export) error {
+	return db.Model(export).
+		Select("status", "rows", "file_name", "storage_key", "file_size", "failure", "started_at", "finished_at", "expires_at").
+		Updates(export).Error
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// writer writes the rows of one export
+type writer struct {
+	db     *gorm.DB
+	export *models.Export
+	user   *models.User
+	rows   int
+}
+
+// writeCSV writes a single table. Comments and activity of a task are joined
+// into extra columns of its row.
+func (w *writer) writeCSV(out io.Writer) error {
+	cw := csv.NewWriter(out)
+	emit := func(values []string) error {
+		return cw.Write(values)
+	}
+
+	var err error
+	if w.export.Resource == models.ExportResourceProjects {
+		if err := emit(projectColumns); err != nil {
+			return err
+		}
+		err = w.projects(func(r *projectRecord) error { return emit(r.values()) })
+	} else {
+		columns := append([]string{}, taskColumns...)
+		if w.export.IncludeComments {
+			columns = append(columns, "comments")
+		}
+		if w.export.IncludeActivity {
+			columns = append(columns, "activity")
+		}
+		if err := emit(columns); err != nil {
+			return err
+		}
+		err = w.tasks(w.export.IncludeComments, w.export.IncludeActivity, func(r *taskRecord) error {
+			return emit(r.values(w.export.IncludeComments, w.export.IncludeActivity))
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeXLSX writes a workbook. Comments and activity get sheets of their own.
+func (w *writer) writeXLSX(out io.Writer) error {
+	book := xlsx.NewWriter(out)
+
+	if w.export.Resource == models.ExportResourceProjects {
+		if err := book.AddSheet("Projects"); err != nil {
+			return err
+		}
+		if err := book.WriteRow(projectColumns); err != nil {
+			return err
+		}
+		if err := w.projects(func(r *projectRecord) error { return book.WriteRow(r.values()) }); err != nil {
+			return err
+		}
+		return book.Close()
+	}
+
+	if err := book.AddSheet("Tasks"); err != nil {
+		return err
+	}
+	if err := book.WriteRow(taskColumns); err != nil {
+		return err
+	}
+	if err := w.tasks(false, false, func(r *taskRecord) error { return book.WriteRow(r.values(false, false)) }); err != nil {
+		return err
+	}
+
+	if w.export.IncludeComments {
+		if err := book.AddSheet("Comments"); err != nil {
+			return err
+		}
+		if err := book.WriteRow(commentColumns); err != nil {
+			return err
+		}
+		if err := w.comments(func(r *commentRecord) error { return book.WriteRow(r.values()) }); err != nil {
+			return err
+		}
+	}
+	if w.export.IncludeActivity {
+		if err := book.AddSheet("Activity"); err != nil {
+			return err
+		}
+		if err := book.WriteRow(activityColumns); err != nil {
+			return err
+		}
+		if err := w.activity(func(r *activityRecord) error { return book.WriteRow(r.values()) }); err != nil {
+			return err
+		}
+	}
+
+	return book.Close()
+}
+
+// writeNDJSON writes one JSON object per line. Comments and activity are
+// nested in their task.
+func (w *writer) writeNDJSON(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	if w.export.Resource == models.ExportResourceProjects {
+		return w.projects(func(r *projectRecord) error { return encoder.Encode(r) })
+	}
+	return w.tasks(w.export.IncludeComments, w.export.IncludeActivity, func(r *taskRecord) error {
+		return encoder.Encode(r)
+	})
+}
+
+// taskQuery selects the tasks of the export the requesting user can see
+func (w *writer) taskQuery() *gorm.DB {
+	query := w.db.Model(&models.Task{}).Where("tasks.tenant_id = ?", w.user.TenantID)
+	query = models.ScopeVisibleTasks(w.db, query, w.user)
+	return w.export.Filter.Apply(w.db, query, w.user)
+}
+
+// tasks loads the exported tasks in batches, attaching their comments and
+// activity when requested
+func (w *writer) tasks(comments, activity bool, fn func(*taskRecord) error) error {
+	var batch []models.Task
+	var fnErr error
+	result := w.taskQuery().
+		Preload("Project").
+		Preload("Creator").
+		Preload("Assignee").
+		Preload("Tags").
+		Order("tasks.created_at ASC").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			ids := make([]uuid.UUID, len(batch))
+			for i := range batch {
+				ids[i] = batch[i].ID
+			}
+
+			records := make([]taskRecord, len(batch))
+			index := make(map[uuid.UUID]*taskRecord, len(batch))
+			for i := range batch {
+				records[i] = newTaskRecord(&batch[i])
+				index[batch[i].ID] = &records[i]
+			}
+
+			if comments {
+				var rows []models.TaskComment
+				if err := w.db.Preload("User").Where("task_id IN ?", ids).Order("created_at ASC").Find(&rows).Error; err != nil {
+					return err
+				}
+				for i := range rows {
+					record := index[rows[i].TaskID]
+					record.Comments = append(record.Comments, newCommentRecord(&rows[i], record.Title))
+				}
+			}
+			if activity {
+				var rows []models.TaskActivity
+				if err := w.db.Preload("User").Where("task_id IN ?", ids).Order("created_at ASC").Find(&rows).Error; err != nil {
+					return err
+				}
+				for i := range rows {
+					record := index[rows[i].TaskID]
+					record.Activity = append(record.Activity, newActivityRecord(&rows[i], record.Title))
+				}
+			}
+
+			for i := range records {
+				if fnErr = fn(&records[i]); fnErr != nil {
+					return fnErr
+				}
+				w.rows++
+			}
+			return nil
+		})
+	if fnErr != nil {
+		return fnErr
+	}
+	return result.Error
+}
+
+// comments streams the comments of the exported tasks
+func (w *writer) comments(fn func(*commentRecord) error) error {
+	var batch []models.TaskComment
+	var fnErr error
+	result := w.db.
+		Preload("User").
+		Preload("Task").
+		Where("task_id IN (?)", w.taskQuery().Select("tasks.id")).
+		Order("created_at ASC").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				record := newCommentRecord(&batch[i], batch[i].Task.Title)
+				if fnErr = fn(&record); fnErr != nil {
+					return fnErr
+				}
+			}
+			return nil
+		})
+	if fnErr != nil {
+		return fnErr
+	}
+	return result.Error
+}
+
+// activity streams the activity of the exported tasks
+func (w *writer) activity(fn func(*activityRecord) error) error {
+	var batch []models.TaskActivity
+	var fnErr error
+	result := w.db.
+		Preload("User").
+		Preload("Task").
+		Where("task_id IN (?)", w.taskQuery().Select("tasks.id")).
+		Order("created_at ASC").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				record := newActivityRecord(&batch[i], batch[i].Task.Title)
+				if fnErr = fn(&record); fnErr != nil {
+					return fnErr
+				}
+			}
+			return nil
+		})
+	if fnErr != nil {
+		return fnErr
+	}
+	return result.Error
+}
+
+// projects loads the exported projects in batches with their task counts
+func (w *writer) projects(fn func(*projectRecord) error) error {
+	filter := w.export.Filter
+	query := w.db.Where("tenant_id = ? AND id IN (?)", w.user.TenantID, models.VisibleProjectIDs(w.db, w.user))
+	if filter.ProjectID != nil {
+		query = query.Where("id = ?", *filter.ProjectID)
+	} else if !filter.IncludeArchived {
+		query = query.Where("is_active = ?", true)
+	}
+	if filter.Search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filter.Search)+"%")
+	}
+
+	var batch []models.Project
+	var fnErr error
+	result := query.Order("name ASC").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		ids := make([]uuid.UUID, len(batch))
+		for i := range batch {
+			ids[i] = batch[i].ID
+		}
+
+		var counts []struct {
+			ProjectID uuid.UUID
+			Total     int
+			Completed int
+			Overdue   int
+		}
+		if err := w.db.Model(&models.Task{}).
+			Select(`project_id,
+				COUNT(*) AS total,
+				COUNT(CASE WHEN status = ? THEN 1 END) AS completed,
+				COUNT(CASE WHEN due_date < ? AND status NOT IN ? THEN 1 END) AS overdue`,
+				models.TaskStatusCompleted, time.Now(), []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusCanceled}).
+			Where("project_id IN ?", ids).
+			Group("project_id").
+			Scan(&counts).Error; err != nil {
+			return err
+		}
+		byProject := make(map[uuid.UUID]int, len(counts))
+		for i, count := range counts {
+			byProject[count.ProjectID] = i
+		}
+
+		for i := range batch {
+			project := &batch[i]
+			record := projectRecord{
+				ID:          project.ID,
+				Name:        project.Name,
+				Description: project.Description,
+				Archived:    project.IsArchived(),
+				Private:     project.IsPrivate,
+				StartDate:   project.StartDate,
+				EndDate:     project.EndDate,
+				CreatedAt:   project.CreatedAt,
+			}
+			if j, ok := byProject[project.ID]; ok {
+				record.Tasks = counts[j].Total
+				record.CompletedTasks = counts[j].Completed
+				record.OverdueTasks = counts[j].Overdue
+			}
+			if fnErr = fn(&record); fnErr != nil {
+				return fnErr
+			}
+			w.rows++
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return result.Error
+}
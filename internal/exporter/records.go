@@ -0,0 +1,233 @@
+package exporter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Columns of the tabular exports. Task columns use the field names the
+// importer understands so exported files can be imported again.
+var (
+	taskColumns = []string{
+		"id", "title", "description", "status", "priority", "project", "assignee", "creator", "tags",
+		"parent_id", "start_date", "due_date", "completed_at", "estimated_hours", "actual_hours",
+		"story_points", "is_milestone", "created_at", "updated_at",
+	}
+	commentColumns  = []string{"task_id", "task", "author", "content", "created_at"}
+	activityColumns = []string{"task_id", "task", "user", "action", "field", "old_value", "new_value", "description", "created_at"}
+	projectColumns  = []string{
+		"id", "name", "description", "archived", "private", "start_date", "end_date",
+		"tasks", "completed_tasks", "overdue_tasks", "created_at",
+	}
+)
+
+// taskRecord is an exported task
+type taskRecord struct {
+	ID             uuid.UUID        `json:"id"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description,omitempty"`
+	Status         string           `json:"status"`
+	Priority       string           `json:"priority"`
+	ProjectID      *uuid.UUID       `json:"project_id,omitempty"`
+	Project        string           `json:"project,omitempty"`
+	Assignee       string           `json:"assignee,omitempty"`
+	Creator        string           `json:"creator,omitempty"`
+	Tags           []string         `json:"tags"`
+	ParentID       *uuid.UUID       `json:"parent_id,omitempty"`
+	StartDate      *time.Time       `json:"start_date,omitempty"`
+	DueDate        *time.Time       `json:"due_date,omitempty"`
+	CompletedAt    *time.Time       `json:"completed_at,omitempty"`
+	EstimatedHours *float64         `json:"estimated_hours,omitempty"`
+	ActualHours    *float64         `json:"actual_hours,omitempty"`
+	StoryPoints    *float64         `json:"story_points,omitempty"`
+	IsMilestone    bool             `json:"is_milestone"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Comments       []commentRecord  `json:"comments,omitempty"`
+	Activity       []activityRecord `json:"activity,omitempty"`
+}
+
+// commentRecord is an exported task comment
+type commentRecord struct {
+	TaskID    uuid.UUID `json:"-"`
+	TaskTitle string    `json:"-"`
+	Author    string    `json:"author"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// activityRecord is an exported task activity entry
+type activityRecord struct {
+	TaskID      uuid.UUID `json:"-"`
+	TaskTitle   string    `json:"-"`
+	User        string    `json:"user"`
+	Action      string    `json:"action"`
+	Field       string    `json:"field,omitempty"`
+	OldValue    string    `json:"old_value,omitempty"`
+	NewValue    string    `json:"new_value,omitempty"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// projectRecord is an exported project with its task counts
+type projectRecord struct {
+	ID             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description,omitempty"`
+	Archived       bool       `json:"archived"`
+	Private        bool       `json:"private"`
+	StartDate      *time.Time `json:"start_date,omitempty"`
+	EndDate        *time.Time `json:"end_date,omitempty"`
+	Tasks          int        `json:"tasks"`
+	CompletedTasks int        `json:"completed_tasks"`
+	OverdueTasks   int        `json:"overdue_tasks"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// newTaskRecord flattens a task loaded with its project, users and tags
+func newTaskRecord(task *models.Task) taskRecord {
+	record := taskRecord{
+		ID:             task.ID,
+		Title:          task.Title,
+		Description:    task.Description,
+		Status:         string(task.Status),
+		Priority:       string(task.Priority),
+		ProjectID:      task.ProjectID,
+		Creator:        task.Creator.Email,
+		Tags:           make([]string, 0, len(task.Tags)),
+		ParentID:       task.ParentID,
+		StartDate:      task.StartDate,
+		DueDate:        task.DueDate,
+		CompletedAt:    task.CompletedAt,
+		EstimatedHours: task.EstimatedHours,
+		ActualHours:    task.ActualHours,
+		StoryPoints:    task.StoryPoints,
+		IsMilestone:    task.IsMilestone,
+		CreatedAt:      task.CreatedAt,
+		UpdatedAt:      task.UpdatedAt,
+	}
+	if task.Project != nil {
+		record.Project = task.Project.Name
+	}
+	if task.Assignee != nil {
+		record.Assignee = task.Assignee.Email
+	}
+	for _, tag := range task.Tags {
+		record.Tags = append(record.Tags, tag.Name)
+	}
+	return record
+}
+
+// newCommentRecord flattens a comment loaded with its author
+func newCommentRecord(comment *models.TaskComment, taskTitle string) commentRecord {
+	return commentRecord{
+		TaskID:    comment.TaskID,
+		TaskTitle: taskTitle,
+		Author:    comment.User.Email,
+		Content:   comment.Content,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// newActivityRecord flattens an activity entry loaded with its user
+func newActivityRecord(activity *models.TaskActivity, taskTitle string) activityRecord {
+	return activityRecord{
+		TaskID:      activity.TaskID,
+		TaskTitle:   taskTitle,
+		User:        activity.User.Email,
+		Action:      activity.Action,
+		Field:       activity.Field,
+		OldValue:    activity.OldValue,
+		NewValue:    activity.NewValue,
+		Description: activity.Description,
+		CreatedAt:   activity.CreatedAt,
+	}
+}
+
+// values returns the cells of a task row, followed by its comments and
+// activity when the export includes them
+func (r *taskRecord) values(comments, activity bool) []string {
+	values := []string{
+		r.ID.String(), r.Title, r.Description, r.Status, r.Priority, r.Project, r.Assignee, r.Creator,
+		strings.Join(r.Tags, ", "), formatID(r.ParentID), formatTime(r.StartDate), formatTime(r.DueDate),
+		formatTime(r.CompletedAt), formatFloat(r.EstimatedHours), formatFloat(r.ActualHours),
+		formatFloat(r.StoryPoints), strconv.FormatBool(r.IsMilestone), formatTime(&r.CreatedAt), formatTime(&r.UpdatedAt),
+	}
+	if comments {
+		lines := make([]string, len(r.Comments))
+		for i, comment := range r.Comments {
+			lines[i] = "[" + formatTime(&comment.CreatedAt) + "] " + comment.Author + ": " + comment.Content
+		}
+		values = append(values, strings.Join(lines, "\n"))
+	}
+	if activity {
+		lines := make([]string, len(r.Activity))
+		for i, entry := range r.Activity {
+			lines[i] = "[" + formatTime(&entry.CreatedAt) + "] " + entry.User + ": " + entry.summary()
+		}
+		values = append(values, strings.Join(lines, "\n"))
+	}
+	return values
+}
+
+// values returns the cells of a comment row
+func (r *commentRecord) values() []string {
+	return []string{r.TaskID.String(), r.TaskTitle, r.Author, r.Content, formatTime(&r.CreatedAt)}
+}
+
+// values returns the cells of an activity row
+func (r *activityRecord) values() []string {
+	return []string{
+		r.TaskID.String(), r.TaskTitle, r.User, r.Action, r.Field, r.OldValue, r.NewValue, r.Description,
+		formatTime(&r.CreatedAt),
+	}
+}
+
+// summary describes an activity entry in one line
+func (r *activityRecord) summary() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	if r.Field == "" {
+		return r.Action
+	}
+	return r.Action + " " + r.Field + ": " + r.OldValue + " -> " + r.NewValue
+}
+
+// values returns the cells of a project row
+func (r *projectRecord) values() []string {
+	return []string{
+		r.ID.String(), r.Name, r.Description, strconv.FormatBool(r.Archived), strconv.FormatBool(r.Private),
+		formatTime(r.StartDate), formatTime(r.EndDate), strconv.Itoa(r.Tasks), strconv.Itoa(r.CompletedTasks),
+		strconv.Itoa(r.OverdueTasks), formatTime(&r.CreatedAt),
+	}
+}
+
+// formatTime formats an optional time as RFC 3339 in UTC
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatFloat formats an optional number without trailing zeros
+func formatFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+// formatID formats an optional ID
+func formatID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,43 @@
+package exporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Sign returns the signature of a download link for an export that is valid
+// until the given Unix time
+func Sign(secret string, exportID uuid.UUID, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "export:%s:%d", exportID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature and expiry of a download link
+func Verify(secret string, exportID uuid.UUID, expires, signature string) bool {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, exportID, unix)), []byte(signature))
+}
+
+// DownloadURL builds the signed download link of a finished export. The link
+// expires together with the export file.
+func DownloadURL(baseURL, secret string, export *models.Export) string {
+	if !export.IsDownloadable() || export.ExpiresAt == nil {
+		return ""
+	}
+	expires := export.ExpiresAt.Unix()
+	return fmt.Sprintf("%s/api/v1/exports/%s/download?expires=%d&signature=%s",
+		strings.TrimRight(baseURL, "/"), export.ID, expires, Sign(secret, export.ID, expires))
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/drazan344/taskflow-go/internal/auth"
+	"github.com/drazan344/taskflow-go/internal/events"
 	"github.com/drazan344/taskflow-go/internal/middleware"
 	"github.com/drazan344/taskflow-go/internal/models"
 	"github.com/drazan344/taskflow-go/pkg/errors"
@@ -15,13 +16,15 @@ import (
 type AuthHandler struct {
 	authService *auth.Service
 	logger      *logger.Logger
+	events      events.Publisher
 }
 
 // NewAuthHandler creates a new authentication handler
-func NewAuthHandler(authService *auth.Service, logger *logger.Logger) *AuthHandler {
+func NewAuthHandler(authService *auth.Service, logger *logger.Logger, publisher events.Publisher) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
 		logger:      logger,
+		events:      publisher,
 	}
 }
 
@@ -121,6 +124,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		WithField("tenant_id", response.User.TenantID).
 		Info("User registered successfully")
 
+	publishEvent(c, h.events, h.logger, response.User.TenantID, response.User.ID,
+		events.UserRegistered{User: events.NewUserSnapshot(response.User)})
+
 	c.JSON(http.StatusCreated, middleware.SuccessResponse(response, "Registration successful"))
 }
 
@@ -0,0 +1,471 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/ical"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// CalendarHandler handles iCalendar feed subscriptions
+type CalendarHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+}
+
+// NewCalendarHandler creates a new calendar handler
+func NewCalendarHandler(db *gorm.DB, logger *logger.Logger) *CalendarHandler {
+	return &CalendarHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+// calendarFeedResponse is a feed together with its subscription URL
+type calendarFeedResponse struct {
+	models.CalendarFeed
+	URL string `json:"url"`
+}
+
+// ListFeeds returns the calendar feeds owned by the current user
+// @Summary List calendar feeds
+// @Description Get the iCalendar feeds owned by the current user
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /calendar-feeds [get]
+func (h *CalendarHandler) ListFeeds(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return
+	}
+
+	var feeds []models.CalendarFeed
+	if err := h.db.
+		Preload("Project").
+		Where("tenant_id = ? AND user_id = ? AND revoked_at IS NULL", tenantID, userID).
+		Order("created_at DESC").
+		Find(&feeds).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch calendar feeds")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch calendar feeds"))
+		return
+	}
+
+	result := make([]calendarFeedResponse, 0, len(feeds))
+	for _, feed := range feeds {
+		result = append(result, calendarFeedResponse{CalendarFeed: feed, URL: feedURL(c, feed.Token)})
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(result))
+}
+
+// CreateFeed creates a new secret calendar feed URL
+// @Summary Create calendar feed
+// @Description Create a secret iCalendar feed of the current user's assigned tasks, or of a project's tasks when project_id is set
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateCalendarFeedRequest true "Feed data"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /calendar-feeds [post]
+func (h *CalendarHandler) CreateFeed(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req requests.CreateCalendarFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	name := req.Name
+	if req.ProjectID != nil {
+		project, _, _, ok := authorizeProject(c, h.db, *req.ProjectID, models.ProjectRoleViewer)
+		if !ok {
+			return
+		}
+		if name == "" {
+			name = project.Name
+		}
+	} else if name == "" {
+		name = "My tasks"
+	}
+
+	token, err := generateFeedToken()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate feed token")
+		response.InternalServerError(c, "Failed to create calendar feed")
+		return
+	}
+
+	feed := &models.CalendarFeed{
+		TenantModel: models.TenantModel{TenantID: tenantID},
+		UserID:      userID,
+		ProjectID:   req.ProjectID,
+		Name:        name,
+		Token:       token,
+	}
+
+	if err := h.db.Create(feed).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create calendar feed")
+		response.InternalServerError(c, "Failed to create calendar feed")
+		return
+	}
+
+	response.Created(c, calendarFeedResponse{CalendarFeed: *feed, URL: feedURL(c, feed.Token)}, "Calendar feed created successfully")
+}
+
+// RevokeFeed revokes a calendar feed token
+// @Summary Revoke calendar feed
+// @Description Revoke a calendar feed so its URL stops working
+// @Tags calendar
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Feed ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /calendar-feeds/{id} [delete]
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return
+	}
+
+	feedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid feed ID"))
+		return
+	}
+
+	var feed models.CalendarFeed
+	if err := h.db.Where("id = ? AND tenant_id = ? AND user_id = ? AND revoked_at IS NULL", feedID, tenantID, userID).
+		First(&feed).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Calendar feed not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch calendar feed"))
+		return
+	}
+
+	feed.Revoke()
+	if err := h.db.Model(&feed).Update("revoked_at", feed.RevokedAt).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to revoke calendar feed")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to revoke calendar feed"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Calendar feed revoked successfully"))
+}
+
+// ServeFeed renders a calendar feed as text/calendar
+// @Summary Get calendar feed
+// @Description Public iCalendar (RFC 5545) feed of tasks with a due date, authenticated by its secret token. Supports If-None-Match.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token path string true "Feed token, optionally suffixed with .ics"
+// @Param type query string false "Component type: event (default) or todo"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Failure 404 {object} map[string]interface{}
+// @Router /calendar/{token} [get]
+func (h *CalendarHandler) ServeFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var feed models.CalendarFeed
+	if err := h.db.
+		Preload("User.Tenant").
+		Preload("Project").
+		Where("token = ? AND revoked_at IS NULL", token).
+		First(&feed).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Calendar feed not found"))
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch calendar feed")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch calendar feed"))
+		return
+	}
+
+	if !feed.User.IsActive() || !feed.User.Tenant.IsActive() {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("Calendar feed not found"))
+		return
+	}
+
+	component := ical.ComponentEvent
+	if c.Query("type") == "todo" {
+		component = ical.ComponentTodo
+	}
+
+	loc := time.UTC
+	if feed.User.Timezone != "" {
+		if l, err := time.LoadLocation(feed.User.Timezone); err == nil {
+			loc = l
+		}
+	}
+
+	// Feeds only ever show what their owner can currently read
+	query := models.ScopeVisibleTasks(h.db,
+		h.db.Model(&models.Task{}).Where("tenant_id = ? AND due_date IS NOT NULL", feed.TenantID), &feed.User)
+	if feed.ProjectID != nil {
+		query = query.Where("project_id = ?", *feed.ProjectID)
+	} else {
+		query = query.Where("assignee_id = ?", feed.UserID)
+	}
+
+	// A cheap fingerprint answers unchanged feeds with 304 before any task is
+	// loaded
+	etag, lastUpdated, err := h.fingerprint(&feed, query, loc, component)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fingerprint calendar feed")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to build calendar feed"))
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastUpdated.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "private, max-age=300")
+
+	h.touchFeed(&feed)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var tasks []models.Task
+	if err := query.Session(&gorm.Session{}).
+		Preload("Project").
+		Preload("Tags").
+		Order("due_date ASC").
+		Find(&tasks).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch calendar tasks")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to build calendar feed"))
+		return
+	}
+
+	cal := &ical.Calendar{
+		ProdID:   "-//TaskFlow//TaskFlow Calendar//EN",
+		Name:     "TaskFlow: " + feed.Name,
+		Location: loc,
+		Entries:  make([]ical.Entry, 0, len(tasks)),
+	}
+	for _, task := range tasks {
+		cal.Entries = append(cal.Entries, taskCalendarEntry(&task, component, loc))
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Write(&buf); err != nil {
+		h.logger.WithError(err).Error("Failed to render calendar feed")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to build calendar feed"))
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="taskflow.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+// fingerprint returns the ETag of a feed and when it last changed, without
+// loading its tasks. Besides the tasks it covers the names of their projects
+// and tags, which the feed shows as categories. Tasks, tags and tag links
+// that were removed change the counts.
+func (h *CalendarHandler) fingerprint(feed *models.CalendarFeed, query *gorm.DB, loc *time.Location,
+	component ical.ComponentType) (string, time.Time, error) {
+	var tasks struct {
+		Count       int64
+		LastUpdated *time.Time
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("COUNT(*) AS count, MAX(tasks.updated_at) AS last_updated").
+		Scan(&tasks).Error; err != nil {
+		return "", time.Time{}, err
+	}
+
+	var projectsUpdated *time.Time
+	if err := h.db.Unscoped().Model(&models.Project{}).
+		Where("id IN (?)", query.Session(&gorm.Session{}).Select("tasks.project_id")).
+		Select("MAX(GREATEST(updated_at, deleted_at))").
+		Scan(&projectsUpdated).Error; err != nil {
+		return "", time.Time{}, err
+	}
+
+	var tags struct {
+		Count       int64
+		LastUpdated *time.Time
+	}
+	if err := h.db.Unscoped().Model(&models.Tag{}).
+		Joins("JOIN task_tags ON task_tags.tag_id = tags.id").
+		Where("task_tags.task_id IN (?)", query.Session(&gorm.Session{}).Select("tasks.id")).
+		Select("COUNT(*) AS count, MAX(GREATEST(tags.updated_at, tags.deleted_at)) AS last_updated").
+		Scan(&tags).Error; err != nil {
+		return "", time.Time{}, err
+	}
+
+	lastUpdated := feed.UpdatedAt
+	for _, t := range []*time.Time{tasks.LastUpdated, projectsUpdated, tags.LastUpdated} {
+		if t != nil && t.After(lastUpdated) {
+			lastUpdated = *t
+		}
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d|%d|%s|%s",
+		feed.ID, tasks.Count, tags.Count, lastUpdated.UnixNano(), loc.String(), component)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, lastUpdated, nil
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag. Weak
+// validators match too, as RFC 9110 asks for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// touchFeed records when a feed was last polled
+func (h *CalendarHandler) touchFeed(feed *models.CalendarFeed) {
+	now := time.Now()
+	if err := h.db.Model(feed).UpdateColumn("last_accessed_at", now).Error; err != nil {
+		h.logger.WithError(err).WithField("feed_id", feed.ID).Warn("Failed to record calendar feed access")
+	}
+}
+
+// taskCalendarEntry converts a task into an iCalendar entry
+func taskCalendarEntry(task *models.Task, component ical.ComponentType, loc *time.Location) ical.Entry {
+	due := task.DueDate.In(loc)
+	entry := ical.Entry{
+		Type:        component,
+		UID:         task.ID.String() + "@taskflow",
+		Summary:     task.Title,
+		Description: task.Description,
+		Due:         due,
+		AllDay:      due.Hour() == 0 && due.Minute() == 0 && due.Second() == 0,
+		Completed:   task.CompletedAt,
+		Priority:    calendarPriority(task.Priority),
+		Modified:    task.UpdatedAt,
+	}
+
+	if task.Project != nil {
+		entry.Categories = append(entry.Categories, task.Project.Name)
+	}
+	for _, tag := range task.Tags {
+		entry.Categories = append(entry.Categories, tag.Name)
+	}
+
+	switch task.Status {
+	case models.TaskStatusCompleted:
+		if component == ical.ComponentTodo {
+			entry.Status = "COMPLETED"
+		} else {
+			entry.Status = "CONFIRMED"
+		}
+	case models.TaskStatusCanceled:
+		entry.Status = "CANCELLED"
+	case models.TaskStatusInProgress, models.TaskStatusInReview:
+		if component == ical.ComponentTodo {
+			entry.Status = "IN-PROCESS"
+		} else {
+			entry.Status = "CONFIRMED"
+		}
+	default:
+		if component == ical.ComponentTodo {
+			entry.Status = "NEEDS-ACTION"
+		} else {
+			entry.Status = "CONFIRMED"
+		}
+	}
+
+	return entry
+}
+
+// calendarPriority maps task priorities onto the RFC 5545 1-9 scale
+func calendarPriority(priority models.TaskPriority) int {
+	switch priority {
+	case models.TaskPriorityUrgent:
+		return 1
+	case models.TaskPriorityHigh:
+		return 3
+	case models.TaskPriorityMedium:
+		return 5
+	case models.TaskPriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// feedURL builds the public subscription URL for a feed token
+func feedURL(c *gin.Context, token string) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/api/v1/calendar/%s.ics", scheme, c.Request.Host, token)
+}
+
+// generateFeedToken returns a random URL-safe feed token
+func generateFeedToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
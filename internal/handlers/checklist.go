@@ -0,0 +1,493 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/internal/websocket"
+	"github.com/drazan344/taskflow-go/pkg/errors"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// ChecklistHandler handles checklist items inside tasks
+type ChecklistHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+	hub       *websocket.Hub
+	events    events.Publisher
+}
+
+// NewChecklistHandler creates a new checklist handler
+func NewChecklistHandler(db *gorm.DB, logger *logger.Logger, hub *websocket.Hub, publisher events.Publisher) *ChecklistHandler {
+	return &ChecklistHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+		hub:       hub,
+		events:    publisher,
+	}
+}
+
+// ListChecklist returns the checklist of a task
+// @Summary List checklist items
+// @Description Get the checklist items of a task in order
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.ChecklistItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist [get]
+func (h *ChecklistHandler) ListChecklist(c *gin.Context) {
+	task, _, ok := h.loadTask(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	items, err := h.checklist(h.db, task.ID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch checklist")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch checklist"))
+		return
+	}
+
+	progress, _ := models.ChecklistProgress(items)
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
+		"items":    items,
+		"progress": progress,
+	}))
+}
+
+// AddChecklistItem appends an item to the checklist of a task
+// @Summary Add checklist item
+// @Description Append an item to the checklist of a task
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param request body requests.CreateChecklistItemRequest true "Checklist item"
+// @Success 201 {object} models.ChecklistItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist [post]
+func (h *ChecklistHandler) AddChecklistItem(c *gin.Context) {
+	task, user, ok := h.loadTask(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	var req requests.CreateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.AssigneeID != nil && !h.checkAssignee(c, task.TenantID, *req.AssigneeID) {
+		return
+	}
+
+	item := &models.ChecklistItem{
+		TenantModel: models.TenantModel{TenantID: task.TenantID},
+		TaskID:      task.ID,
+		Text:        req.Text,
+		AssigneeID:  req.AssigneeID,
+		DueDate:     req.DueDate,
+		CreatedBy:   user.ID,
+	}
+	if req.IsDone {
+		item.SetDone(true, user.ID)
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var last struct {
+			Position *int
+		}
+		if err := tx.Model(&models.ChecklistItem{}).Select("MAX(position) AS position").
+			Where("task_id = ?", task.ID).Scan(&last).Error; err != nil {
+			return err
+		}
+		if last.Position != nil {
+			item.Position = *last.Position + 1
+		}
+
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		recordTaskActivity(tx, h.logger, task, user.ID, "checklist_item_added", "checklist", "", item.Text, "")
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to add checklist item")
+		response.InternalServerError(c, "Failed to add checklist item")
+		return
+	}
+
+	h.broadcast(task, user.ID, "item_added", item)
+
+	response.Created(c, item, "Checklist item added successfully")
+}
+
+// UpdateChecklistItem changes a checklist item
+// @Summary Update checklist item
+// @Description Change the text, done flag, assignee or due date of a checklist item
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param item_id path string true "Checklist item ID"
+// @Param request body requests.UpdateChecklistItemRequest true "Checklist item changes"
+// @Success 200 {object} models.ChecklistItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist/{item_id} [put]
+func (h *ChecklistHandler) UpdateChecklistItem(c *gin.Context) {
+	task, user, item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.AssigneeID != nil && !h.checkAssignee(c, task.TenantID, *req.AssigneeID) {
+		return
+	}
+
+	action, oldValue, newValue := "checklist_item_updated", "", ""
+	if req.Text != nil && *req.Text != item.Text {
+		oldValue = item.Text
+		item.Text = *req.Text
+		newValue = item.Text
+	}
+	if req.IsDone != nil && *req.IsDone != item.IsDone {
+		item.SetDone(*req.IsDone, user.ID)
+		if item.IsDone {
+			action = "checklist_item_completed"
+		} else {
+			action = "checklist_item_reopened"
+		}
+	}
+	switch {
+	case req.ClearAssignee:
+		item.AssigneeID = nil
+	case req.AssigneeID != nil:
+		item.AssigneeID = req.AssigneeID
+	}
+	switch {
+	case req.ClearDueDate:
+		item.DueDate = nil
+	case req.DueDate != nil:
+		item.DueDate = req.DueDate
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(item).
+			Select("text", "is_done", "done_at", "done_by", "assignee_id", "due_date").
+			Updates(item).Error; err != nil {
+			return err
+		}
+		recordTaskActivity(tx, h.logger, task, user.ID, action, "checklist", oldValue, newValue, item.Text)
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update checklist item")
+		response.InternalServerError(c, "Failed to update checklist item")
+		return
+	}
+
+	h.broadcast(task, user.ID, "item_updated", item)
+
+	response.Success(c, item, "Checklist item updated successfully")
+}
+
+// DeleteChecklistItem removes an item from a checklist
+// @Summary Delete checklist item
+// @Description Remove an item from the checklist of a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param item_id path string true "Checklist item ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist/{item_id} [delete]
+func (h *ChecklistHandler) DeleteChecklistItem(c *gin.Context) {
+	task, user, item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(item).Error; err != nil {
+			return err
+		}
+		recordTaskActivity(tx, h.logger, task, user.ID, "checklist_item_removed", "checklist", item.Text, "", "")
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to delete checklist item")
+		response.InternalServerError(c, "Failed to delete checklist item")
+		return
+	}
+
+	h.broadcast(task, user.ID, "item_removed", gin.H{"id": item.ID})
+
+	response.Success(c, nil, "Checklist item deleted successfully")
+}
+
+// ReorderChecklist changes the order of a checklist
+// @Summary Reorder checklist
+// @Description Set the order of the checklist items of a task. Every item must be listed exactly once.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param request body requests.ReorderChecklistRequest true "Item IDs in their new order"
+// @Success 200 {array} models.ChecklistItem
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist/order [put]
+func (h *ChecklistHandler) ReorderChecklist(c *gin.Context) {
+	task, user, ok := h.loadTask(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	var req requests.ReorderChecklistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	var items []models.ChecklistItem
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		current, err := h.checklist(tx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		positions := make(map[uuid.UUID]int, len(req.ItemIDs))
+		for i, id := range req.ItemIDs {
+			positions[id] = i
+		}
+		if len(positions) != len(req.ItemIDs) || len(positions) != len(current) {
+			return errors.BadRequest("item_ids must list every checklist item of the task exactly once", nil)
+		}
+		for i := range current {
+			position, ok := positions[current[i].ID]
+			if !ok {
+				return errors.BadRequest("item_ids must list every checklist item of the task exactly once", nil)
+			}
+			if current[i].Position == position {
+				continue
+			}
+			current[i].Position = position
+			if err := tx.Model(&current[i]).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		recordTaskActivity(tx, h.logger, task, user.ID, "checklist_reordered", "checklist", "", "", "")
+		items, err = h.checklist(tx, task.ID)
+		return err
+	})
+	if appErr, ok := err.(*errors.AppError); ok {
+		response.BadRequest(c, appErr.Message)
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to reorder checklist")
+		response.InternalServerError(c, "Failed to reorder checklist")
+		return
+	}
+
+	h.broadcast(task, user.ID, "reordered", items)
+
+	response.Success(c, items, "Checklist reordered successfully")
+}
+
+// ConvertChecklistItem turns a checklist item into a subtask
+// @Summary Convert checklist item to subtask
+// @Description Create a subtask from a checklist item, keeping its text, assignee, due date and done state, and remove the item
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param item_id path string true "Checklist item ID"
+// @Success 201 {object} models.Task
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/checklist/{item_id}/convert [post]
+func (h *ChecklistHandler) ConvertChecklistItem(c *gin.Context) {
+	task, user, item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	subtask := &models.Task{
+		TenantModel: models.TenantModel{TenantID: task.TenantID},
+		Title:       item.Text,
+		Status:      models.TaskStatusTodo,
+		Priority:    task.Priority,
+		DueDate:     item.DueDate,
+		CreatorID:   user.ID,
+		AssigneeID:  item.AssigneeID,
+		ProjectID:   task.ProjectID,
+		ParentID:    &task.ID,
+	}
+	if item.IsDone {
+		subtask.MarkAsCompleted()
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(subtask).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(item).Error; err != nil {
+			return err
+		}
+		recordTaskActivity(tx, h.logger, task, user.ID, "checklist_item_converted", "checklist", item.Text, subtask.ID.String(),
+			"Checklist item converted into a subtask")
+		recordTaskActivity(tx, h.logger, subtask, user.ID, "created", "", "", "", "Created from a checklist item")
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to convert checklist item")
+		response.InternalServerError(c, "Failed to convert checklist item")
+		return
+	}
+
+	h.broadcast(task, user.ID, "item_converted", gin.H{"id": item.ID, "subtask": subtask})
+	publishEvent(c, h.events, h.logger, subtask.TenantID, user.ID, events.TaskCreated{Task: events.NewTaskSnapshot(subtask)})
+
+	response.Created(c, subtask, "Checklist item converted to a subtask")
+}
+
+// loadTask authorizes the task in the path for the current user
+func (h *ChecklistHandler) loadTask(c *gin.Context, min models.ProjectRole) (*models.Task, *models.User, bool) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return nil, nil, false
+	}
+
+	return authorizeTask(c, h.db, taskID, min)
+}
+
+// loadItem authorizes an editor of the task in the path and fetches the checklist item
+func (h *ChecklistHandler) loadItem(c *gin.Context) (*models.Task, *models.User, *models.ChecklistItem, bool) {
+	itemID, err := uuid.Parse(c.Param("item_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid checklist item ID"))
+		return nil, nil, nil, false
+	}
+
+	task, user, ok := h.loadTask(c, models.ProjectRoleEditor)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	var item models.ChecklistItem
+	if err := h.db.Where("id = ? AND task_id = ?", itemID, task.ID).First(&item).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Checklist item not found"))
+			return nil, nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch checklist item"))
+		return nil, nil, nil, false
+	}
+
+	return task, user, &item, true
+}
+
+// checkAssignee verifies a checklist assignee belongs to the tenant
+func (h *ChecklistHandler) checkAssignee(c *gin.Context, tenantID, userID uuid.UUID) bool {
+	var count int64
+	if err := h.db.Model(&models.User{}).Where("id = ? AND tenant_id = ?", userID, tenantID).Count(&count).Error; err != nil {
+		response.InternalServerError(c, "Failed to verify assignee")
+		return false
+	}
+	if count == 0 {
+		response.NotFound(c, "Assignee not found")
+		return false
+	}
+	return true
+}
+
+// checklist returns the items of a task in order
+func (h *ChecklistHandler) checklist(db *gorm.DB, taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	var items []models.ChecklistItem
+	err := db.Where("task_id = ?", taskID).Order("position ASC, created_at ASC").Find(&items).Error
+	return items, err
+}
+
+// broadcast notifies the subscribers of the task about a checklist change
+func (h *ChecklistHandler) broadcast(task *models.Task, userID uuid.UUID, action string, data interface{}) {
+	payload := gin.H{
+		"task_id": task.ID,
+		"action":  action,
+		"user_id": userID,
+		"data":    data,
+	}
+
+	public, err := taskIsPublic(h.db, task)
+	if err != nil {
+		h.logger.WithError(err).WithField("task_id", task.ID).Warn("Failed to resolve checklist update topics")
+		return
+	}
+	h.hub.Broadcast(task.TenantID, websocket.ContentTopics(task.ProjectID, &task.ID, public), websocket.MessageTypeChecklist, payload)
+}
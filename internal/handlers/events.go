@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+)
+
+// publishEvent announces a committed change on the event bus. The request
+// may be cancelled once the response is written, so the event is published
+// on a context that outlives it. Failures are logged: the change itself has
+// already succeeded.
+func publishEvent(c *gin.Context, publisher events.Publisher, log *logger.Logger, tenantID, actorID uuid.UUID, payload events.Payload) {
+	ctx := context.WithoutCancel(c.Request.Context())
+	events.Publish(ctx, publisher, log.Logger, tenantID, &actorID, payload)
+}
@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/config"
+	"github.com/drazan344/taskflow-go/internal/exporter"
+	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/storage"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// ExportHandler handles background exports of tasks and projects
+type ExportHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+	jobs      *jobs.Client
+	store     *storage.Local
+	config    *config.Config
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(db *gorm.DB, logger *logger.Logger, jobClient *jobs.Client, cfg *config.Config) *ExportHandler {
+	return &ExportHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+		jobs:      jobClient,
+		store:     storage.NewLocal(cfg.Storage.UploadPath),
+		config:    cfg,
+	}
+}
+
+// CreateExport queues an export of tasks or projects
+// @Summary Create export
+// @Description Queue an export of the tasks or projects the current user can see as CSV, XLSX or NDJSON. A signed download link is sent as a notification when the file is ready.
+// @Tags exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateExportRequest true "Export options"
+// @Success 202 {object} models.Export
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /exports [post]
+func (h *ExportHandler) CreateExport(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req requests.CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	filter := req.Filter
+	if req.ViewID != nil {
+		view, ok := h.loadView(c, user, *req.ViewID)
+		if !ok {
+			return
+		}
+		filter = view.Filter
+		if view.Visibility == models.ViewVisibilityProject && view.ProjectID != nil {
+			filter.ProjectID = view.ProjectID
+		}
+	}
+	if filter.ProjectID != nil {
+		if _, _, _, ok := authorizeProject(c, h.db, *filter.ProjectID, models.ProjectRoleViewer); !ok {
+			return
+		}
+	}
+
+	export := &models.Export{
+		TenantModel:     models.TenantModel{TenantID: user.TenantID},
+		RequestedBy:     user.ID,
+		Resource:        req.Resource,
+		Format:          req.Format,
+		Status:          models.ExportStatusQueued,
+		Filter:          filter,
+		IncludeComments: req.IncludeComments && req.Resource == models.ExportResourceTasks,
+		IncludeActivity: req.IncludeActivity && req.Resource == models.ExportResourceTasks,
+	}
+	if err := h.db.Create(export).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create export")
+		response.InternalServerError(c, "Failed to create export")
+		return
+	}
+
+	if err := h.jobs.EnqueueDataExport(jobs.GenerateReportPayload{
+		BaseJobPayload: jobs.BaseJobPayload{TenantID: user.TenantID, UserID: user.ID},
+		ExportID:       export.ID,
+		ReportType:     string(export.Resource),
+		Format:         string(export.Format),
+		DeliveryMethod: "download",
+	}); err != nil {
+		h.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to enqueue export")
+		h.db.Model(export).Updates(map[string]interface{}{
+			"status":  models.ExportStatusFailed,
+			"failure": "the export could not be queued",
+		})
+		response.InternalServerError(c, "Failed to queue export")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, middleware.SuccessResponse(export))
+}
+
+// ListExports returns the exports of the current user
+// @Summary List exports
+// @Description Get the exports requested by the current user, newest first
+// @Tags exports
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} response.PaginationResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /exports [get]
+func (h *ExportHandler) ListExports(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var pagination requests.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response.BadRequest(c, "Invalid pagination parameters", err.Error())
+		return
+	}
+	pagination.DefaultPagination()
+
+	if validationErrors := h.validator.ValidateStruct(&pagination); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	query := h.db.Model(&models.Export{}).Where("tenant_id = ? AND requested_by = ?", user.TenantID, user.ID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to count exports")
+		response.InternalServerError(c, "Failed to fetch exports")
+		return
+	}
+
+	var exports []models.Export
+	if err := query.
+		Offset(pagination.GetOffset()).
+		Limit(pagination.PerPage).
+		Order("created_at DESC").
+		Find(&exports).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch exports")
+		response.InternalServerError(c, "Failed to fetch exports")
+		return
+	}
+
+	for i := range exports {
+		h.sign(&exports[i])
+	}
+
+	response.Paginated(c, exports, pagination.Page, pagination.PerPage, total)
+}
+
+// GetExport returns the status of an export and its download link once ready
+// @Summary Get export
+// @Description Get the status of an export. Finished exports include a signed download link that expires with the file.
+// @Tags exports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} models.Export
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /exports/{id} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid export ID"))
+		return
+	}
+
+	var export models.Export
+	if err := h.db.Where("id = ? AND tenant_id = ? AND requested_by = ?", exportID, user.TenantID, user.ID).
+		First(&export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Export not found")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch export")
+		response.InternalServerError(c, "Failed to fetch export")
+		return
+	}
+
+	h.sign(&export)
+	c.JSON(http.StatusOK, middleware.SuccessResponse(export))
+}
+
+// DownloadExport serves an export file through a signed link
+// @Summary Download export
+// @Description Download an export file. The link is signed and stops working when it expires.
+// @Tags exports
+// @Produce octet-stream
+// @Param id path string true "Export ID"
+// @Param expires query int true "Expiry of the link as a Unix time"
+// @Param signature query string true "Link signature"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Router /exports/{id}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid export ID"))
+		return
+	}
+
+	if !exporter.Verify(h.config.GetSigningSecret(), exportID, c.Query("expires"), c.Query("signature")) {
+		c.JSON(http.StatusForbidden, middleware.ErrorResponse("Invalid or expired download link"))
+		return
+	}
+
+	var export models.Export
+	if err := h.db.Where("id = ?", exportID).First(&export).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Export not found")
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch export")
+		response.InternalServerError(c, "Failed to fetch export")
+		return
+	}
+	if !export.IsDownloadable() {
+		c.JSON(http.StatusGone, middleware.ErrorResponse("Export is no longer available"))
+		return
+	}
+
+	file, err := h.store.Open(export.StorageKey)
+	if err != nil {
+		h.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to open export file")
+		c.JSON(http.StatusGone, middleware.ErrorResponse("Export is no longer available"))
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, export.FileSize, export.Format.ContentType(), file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="%s"`, export.FileName),
+	})
+}
+
+// sign fills the signed download link of a finished export
+func (h *ExportHandler) sign(export *models.Export) {
+	export.DownloadURL = exporter.DownloadURL(h.config.Server.PublicURL, h.config.GetSigningSecret(), export)
+}
+
+// loadView fetches a saved view whose filter should be exported
+func (h *ExportHandler) loadView(c *gin.Context, user *models.User, viewID uuid.UUID) (*models.SavedView, bool) {
+	var view models.SavedView
+	if err := h.db.Where("id = ? AND tenant_id = ?", viewID, user.TenantID).First(&view).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("View not found"))
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch saved view")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch view"))
+		return nil, false
+	}
+
+	if !view.CanView(user) {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("View not found"))
+		return nil, false
+	}
+
+	return &view, true
+}
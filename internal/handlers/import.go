@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/importer"
+	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// maxImportFileSize is the largest import file accepted, in bytes
+const maxImportFileSize = 10 << 20
+
+// ImportHandler handles bulk task imports
+type ImportHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+	jobs      *jobs.Client
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(db *gorm.DB, logger *logger.Logger, jobClient *jobs.Client) *ImportHandler {
+	return &ImportHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+		jobs:      jobClient,
+	}
+}
+
+// ListImports returns the imports of the current tenant
+// @Summary List imports
+// @Description Get the task imports of the current tenant, newest first. Only admins see imports started by other users.
+// @Tags imports
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Param status query string false "Filter by import status"
+// @Success 200 {object} response.PaginationResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /imports [get]
+func (h *ImportHandler) ListImports(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var pagination requests.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response.BadRequest(c, "Invalid pagination parameters", err.Error())
+		return
+	}
+	pagination.DefaultPagination()
+
+	if validationErrors := h.validator.ValidateStruct(&pagination); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	query := h.scope(user)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Model(&models.Import{}).Count(&total).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to count imports")
+		response.InternalServerError(c, "Failed to fetch imports")
+		return
+	}
+
+	var imports []models.Import
+	if err := query.
+		Omit("data").
+		Offset(pagination.GetOffset()).
+		Limit(pagination.PerPage).
+		Order("created_at DESC").
+		Find(&imports).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch imports")
+		response.InternalServerError(c, "Failed to fetch imports")
+		return
+	}
+
+	response.Paginated(c, imports, pagination.Page, pagination.PerPage, total)
+}
+
+// UploadImport stores an import file so it can be mapped and run
+// @Summary Upload import file
+// @Description Upload a CSV file, a Jira CSV export or a Trello board JSON export. CSV uploads return the file headers and a suggested field mapping.
+// @Tags imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Import file (max 10 MB)"
+// @Param source formData string false "csv, jira or trello; guessed from the file extension when omitted"
+// @Param project_id formData string false "Project for rows that do not name one"
+// @Success 201 {object} models.Import
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /imports [post]
+func (h *ImportHandler) UploadImport(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("An import file is required"))
+		return
+	}
+	if file.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, middleware.ErrorResponse("Import files are limited to 10 MB"))
+		return
+	}
+
+	source := models.ImportSource(strings.ToLower(c.PostForm("source")))
+	if source == "" {
+		source = models.ImportSourceCSV
+		if strings.EqualFold(filepath.Ext(file.Filename), ".json") {
+			source = models.ImportSourceTrello
+		}
+	}
+	if source != models.ImportSourceCSV && source != models.ImportSourceJira && source != models.ImportSourceTrello {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Source must be csv, jira or trello"))
+		return
+	}
+
+	imp := &models.Import{
+		TenantModel: models.TenantModel{TenantID: user.TenantID},
+		CreatedBy:   user.ID,
+		Source:      source,
+		Status:      models.ImportStatusUploaded,
+		FileName:    filepath.Base(file.Filename),
+	}
+
+	if value := c.PostForm("project_id"); value != "" {
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+			return
+		}
+		if _, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleEditor); !ok {
+			return
+		}
+		imp.ProjectID = &projectID
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Failed to read import file"))
+		return
+	}
+	defer reader.Close()
+
+	imp.Data, err = io.ReadAll(io.LimitReader(reader, maxImportFileSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Failed to read import file"))
+		return
+	}
+
+	// Reject files that are not in the announced format before they are stored
+	if source == models.ImportSourceCSV {
+		if imp.Headers, err = importer.ReadHeaders(imp.Data); err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(err.Error()))
+			return
+		}
+		imp.Mapping = importer.SuggestMapping(imp.Headers)
+	} else {
+		records, err := importer.Parse(imp)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(err.Error()))
+			return
+		}
+		imp.TotalRows = len(records)
+	}
+
+	if err := h.db.Create(imp).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to store import")
+		response.InternalServerError(c, "Failed to store import")
+		return
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"import_id": imp.ID,
+		"source":    imp.Source,
+	}).Info("Import uploaded")
+	response.Created(c, imp, "Import uploaded successfully")
+}
+
+// GetImport returns the status, progress and row errors of an import
+// @Summary Get import
+// @Description Get the status, progress, summary and row errors of an import
+// @Tags imports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Import ID"
+// @Success 200 {object} models.Import
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /imports/{id} [get]
+func (h *ImportHandler) GetImport(c *gin.Context) {
+	imp, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(imp))
+}
+
+// RunImport queues an import, either as a dry run that only validates the
+// rows or as a real run that creates the tasks
+// @Summary Run import
+// @Description Queue an uploaded import. CSV imports need a mapping of task fields to columns. A dry run reports row errors without creating anything.
+// @Tags imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Import ID"
+// @Param request body requests.RunImportRequest true "Run options"
+// @Success 202 {object} models.Import
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /imports/{id}/run [post]
+func (h *ImportHandler) RunImport(c *gin.Context) {
+	imp, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	var req requests.RunImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if !imp.CanRun() {
+		response.Conflict(c, "Import cannot be run while it is "+string(imp.Status))
+		return
+	}
+
+	if imp.Source == models.ImportSourceCSV {
+		if len(req.Mapping) > 0 {
+			imp.Mapping = req.Mapping
+		}
+		if err := importer.ValidateMapping(imp.Headers, imp.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(err.Error()))
+			return
+		}
+	}
+	if req.UserMap != nil {
+		imp.UserMap = req.UserMap
+	}
+
+	previous := imp.Status
+	imp.Status = models.ImportStatusQueued
+	imp.DryRun = req.DryRun
+	if err := h.db.Model(imp).Select("status", "dry_run", "mapping", "user_map").Updates(imp).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to queue import")
+		response.InternalServerError(c, "Failed to queue import")
+		return
+	}
+
+	if err := h.jobs.EnqueueImport(jobs.ImportRunPayload{
+		BaseJobPayload: jobs.BaseJobPayload{TenantID: imp.TenantID, UserID: imp.CreatedBy},
+		ImportID:       imp.ID,
+	}); err != nil {
+		h.logger.WithError(err).WithField("import_id", imp.ID).Error("Failed to enqueue import")
+		h.db.Model(imp).Update("status", previous)
+		response.InternalServerError(c, "Failed to queue import")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, middleware.SuccessResponse(imp))
+}
+
+// UndoImport removes everything an import created
+// @Summary Undo import
+// @Description Remove the projects, tags, tasks, comments and checklist items created by an import. Projects and tags that other tasks use by now are kept.
+// @Tags imports
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Import ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /imports/{id}/undo [post]
+func (h *ImportHandler) UndoImport(c *gin.Context) {
+	imp, ok := h.loadImport(c)
+	if !ok {
+		return
+	}
+
+	if !imp.CanUndo() {
+		response.Conflict(c, "Only completed or failed imports can be undone")
+		return
+	}
+
+	kept, err := importer.New(h.db, h.logger.Logger).Undo(c.Request.Context(), imp)
+	if err != nil {
+		h.logger.WithError(err).WithField("import_id", imp.ID).Error("Failed to undo import")
+		response.InternalServerError(c, "Failed to undo import")
+		return
+	}
+
+	h.logger.WithField("import_id", imp.ID).Info("Import undone")
+	response.Success(c, gin.H{
+		"import": imp,
+		"kept":   kept,
+	}, "Import undone successfully")
+}
+
+// scope restricts an import query to the imports the user may see
+func (h *ImportHandler) scope(user *models.User) *gorm.DB {
+	query := h.db.Where("tenant_id = ?", user.TenantID)
+	if !user.IsAdmin() {
+		query = query.Where("created_by = ?", user.ID)
+	}
+	return query
+}
+
+// loadImport loads the import named in the path, without its file contents
+func (h *ImportHandler) loadImport(c *gin.Context) (*models.Import, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return nil, false
+	}
+
+	importID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid import ID"))
+		return nil, false
+	}
+
+	var imp models.Import
+	if err := h.scope(user).Omit("data").Where("id = ?", importID).First(&imp).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Import not found")
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch import")
+		response.InternalServerError(c, "Failed to fetch import")
+		return nil, false
+	}
+
+	return &imp, true
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/config"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/inbound"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/storage"
+	"gorm.io/gorm"
+)
+
+// InboundSecretHeader carries the secret shared with the mail provider
+const InboundSecretHeader = "X-Inbound-Secret"
+
+// InboundEmailHandler handles email sent to project addresses and the
+// management of those addresses
+type InboundEmailHandler struct {
+	db      *gorm.DB
+	logger  *logger.Logger
+	gateway *inbound.Gateway
+	config  config.InboundConfig
+}
+
+// NewInboundEmailHandler creates a new inbound email handler
+func NewInboundEmailHandler(db *gorm.DB, logger *logger.Logger, publisher events.Publisher, cfg *config.Config) *InboundEmailHandler {
+	return &InboundEmailHandler{
+		db:      db,
+		logger:  logger,
+		gateway: inbound.New(db, storage.NewLocal(cfg.Storage.UploadPath), publisher, logger.Logger),
+		config:  cfg.Inbound,
+	}
+}
+
+// ReceiveEmail files a raw email as a task, or as a comment when it replies to
+// an email that created a task
+// @Summary Receive inbound email
+// @Description Accept a raw RFC 822 message from the mail provider. Mail to project+<token>@ addresses creates a task in the project; replies are added as comments on the task they answer when it is in the same project. Everything is filed under the user who created the address. Attachments are added to the task.
+// @Tags inbound
+// @Accept plain
+// @Produce json
+// @Param X-Inbound-Secret header string true "Secret shared with the mail provider"
+// @Success 200 {object} inbound.Result
+// @Success 201 {object} inbound.Result
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /inbound/email [post]
+func (h *InboundEmailHandler) ReceiveEmail(c *gin.Context) {
+	if h.config.Secret == "" {
+		c.JSON(http.StatusServiceUnavailable, middleware.ErrorResponse("Inbound email is not configured"))
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader(InboundSecretHeader)), []byte(h.config.Secret)) != 1 {
+		response.Unauthorized(c, "Invalid inbound secret")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, h.config.MaxSize)
+	msg, err := inbound.Parse(body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, middleware.ErrorResponse("Email is too large"))
+			return
+		}
+		response.BadRequest(c, "Invalid email", err.Error())
+		return
+	}
+
+	result, err := h.gateway.Receive(c.Request.Context(), msg)
+	switch {
+	case errors.Is(err, inbound.ErrUnknownAddress):
+		response.NotFound(c, "No recipient is an active project address")
+		return
+	case errors.Is(err, inbound.ErrProjectArchived):
+		response.Conflict(c, "Project is archived")
+		return
+	case errors.Is(err, inbound.ErrOwnerNoAccess):
+		response.Forbidden(c, "The address owner can no longer add to the project")
+		return
+	case err != nil:
+		h.logger.WithError(err).WithField("message_id", msg.MessageID).Error("Failed to file inbound email")
+		response.InternalServerError(c, "Failed to file email")
+		return
+	}
+
+	if result.Duplicate {
+		c.JSON(http.StatusOK, middleware.SuccessResponse(result, "Email was already received"))
+		return
+	}
+	response.Created(c, result, "Email received successfully")
+}
+
+// ListInboundAddresses returns the active email addresses of a project
+// @Summary List project email addresses
+// @Description Get the active project+<token>@ addresses that file email into the project
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {array} models.InboundAddress
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/inbound-addresses [get]
+func (h *InboundEmailHandler) ListInboundAddresses(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
+		return
+	}
+
+	var addresses []models.InboundAddress
+	if err := h.db.Where("project_id = ? AND tenant_id = ? AND revoked_at IS NULL", project.ID, project.TenantID).
+		Order("created_at ASC").Find(&addresses).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch inbound addresses")
+		response.InternalServerError(c, "Failed to fetch inbound addresses")
+		return
+	}
+
+	for i := range addresses {
+		addresses[i].Address = h.address(addresses[i].Token)
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(addresses))
+}
+
+// CreateInboundAddress creates a new email address for a project
+// @Summary Create project email address
+// @Description Create a project+<token>@ address. Email sent to it creates tasks in the project, and replies add comments. All mail is filed under the current user, with the sender's From address noted in the text, since it cannot be verified.
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 201 {object} models.InboundAddress
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/inbound-addresses [post]
+func (h *InboundEmailHandler) CreateInboundAddress(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
+		return
+	}
+
+	token, err := generateInboundToken()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate inbound address token")
+		response.InternalServerError(c, "Failed to create inbound address")
+		return
+	}
+
+	address := &models.InboundAddress{
+		TenantModel: models.TenantModel{TenantID: project.TenantID},
+		ProjectID:   project.ID,
+		Token:       token,
+		CreatedBy:   user.ID,
+	}
+
+	if err := h.db.Create(address).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create inbound address")
+		response.InternalServerError(c, "Failed to create inbound address")
+		return
+	}
+
+	address.Address = h.address(address.Token)
+	response.Created(c, address, "Inbound address created successfully")
+}
+
+// RevokeInboundAddress stops an email address from accepting mail
+// @Summary Revoke project email address
+// @Description Revoke a project email address. Mail sent to it afterwards, including replies to earlier mail, is rejected.
+// @Tags inbound
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param address_id path string true "Address ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/inbound-addresses/{address_id} [delete]
+func (h *InboundEmailHandler) RevokeInboundAddress(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	addressID, err := uuid.Parse(c.Param("address_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid address ID")
+		return
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
+		return
+	}
+
+	var address models.InboundAddress
+	if err := h.db.Where("id = ? AND project_id = ? AND tenant_id = ? AND revoked_at IS NULL", addressID, project.ID, project.TenantID).
+		First(&address).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Inbound address not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to fetch inbound address")
+		return
+	}
+
+	address.Revoke()
+	if err := h.db.Model(&address).Update("revoked_at", address.RevokedAt).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to revoke inbound address")
+		response.InternalServerError(c, "Failed to revoke inbound address")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Inbound address revoked successfully"))
+}
+
+// address builds the email address of a token
+func (h *InboundEmailHandler) address(token string) string {
+	return fmt.Sprintf("project+%s@%s", token, h.config.Domain)
+}
+
+// generateInboundToken returns a random token short enough to keep the local
+// part of the address within the 64 characters allowed by RFC 5321
+func generateInboundToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/drazan344/taskflow-go/internal/middleware"
 	"github.com/drazan344/taskflow-go/internal/models"
 	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/internal/websocket"
 	"github.com/drazan344/taskflow-go/pkg/errors"
 	"github.com/drazan344/taskflow-go/pkg/logger"
 	"github.com/drazan344/taskflow-go/pkg/response"
@@ -20,14 +21,17 @@ type NotificationHandler struct {
 	db        *gorm.DB
 	logger    *logger.Logger
 	validator *validator.Validator
+	hub       *websocket.Hub
 }
 
-// NewNotificationHandler creates a new notification handler
-func NewNotificationHandler(db *gorm.DB, logger *logger.Logger) *NotificationHandler {
+// NewNotificationHandler creates a new notification handler. Do-not-disturb
+// changes are shown in the presence of the hub.
+func NewNotificationHandler(db *gorm.DB, logger *logger.Logger, hub *websocket.Hub) *NotificationHandler {
 	return &NotificationHandler{
 		db:        db,
 		logger:    logger,
 		validator: validator.New(),
+		hub:       hub,
 	}
 }
 
@@ -67,7 +71,7 @@ func (h *NotificationHandler) ListNotifications(c *gin.Context) {
 	}
 
 	// Build query
-	query := h.db.Where("user_id = ?", userID)
+	query := h.db.Where("user_id = ? AND hidden = ?", userID, false)
 
 	// Apply filters
 	if status := c.Query("status"); status != "" {
@@ -146,6 +150,53 @@ func (h *NotificationHandler) GetNotification(c *gin.Context) {
 	response.Success(c, notification)
 }
 
+// ListNotificationEvents returns the events grouped into a notification
+// @Summary List notification events
+// @Description Expand a grouped notification into the events it stands for, newest first. Notifications without a collapse key have no events.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {array} models.NotificationEvent
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 404 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /notifications/{id}/events [get]
+func (h *NotificationHandler) ListNotificationEvents(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	notificationID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid notification ID")
+		return
+	}
+
+	var notification models.Notification
+	if err := h.db.Select("id").Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if appErr := errors.HandleDBError(err, "notification"); appErr != nil {
+			response.NotFound(c, appErr.Message)
+			return
+		}
+		h.logger.WithError(err).Error("Failed to fetch notification")
+		response.InternalServerError(c, "Failed to fetch notification")
+		return
+	}
+
+	var events []models.NotificationEvent
+	if err := h.db.Where("notification_id = ?", notification.ID).Order("created_at DESC").Find(&events).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch notification events")
+		response.InternalServerError(c, "Failed to fetch notification events")
+		return
+	}
+
+	response.Success(c, events)
+}
+
 // MarkAsRead marks a notification as read
 // @Summary Mark notification as read
 // @Description Mark a specific notification as read
@@ -326,7 +377,7 @@ func (h *NotificationHandler) DeleteNotification(c *gin.Context) {
 
 // GetUnreadCount returns the count of unread notifications
 // @Summary Get unread notification count
-// @Description Get the count of unread notifications for the current user
+// @Description Get the count of unread notifications for the current user. A grouped notification counts once.
 // @Tags notifications
 // @Produce json
 // @Security BearerAuth
@@ -343,7 +394,7 @@ func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
 
 	var count int64
 	if err := h.db.Model(&models.Notification{}).
-		Where("user_id = ? AND read_at IS NULL", userID).
+		Where("user_id = ? AND hidden = ? AND read_at IS NULL", userID, false).
 		Count(&count).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to count unread notifications")
 		response.InternalServerError(c, "Failed to get unread count")
@@ -467,3 +518,251 @@ func (h *NotificationHandler) UpdateNotificationSettings(c *gin.Context) {
 
 	response.Success(c, preferences, "Notification settings updated successfully")
 }
+
+// maxDoNotDisturb is the longest do-not-disturb that can be turned on
+const maxDoNotDisturb = 30 * 24 * time.Hour
+
+// GetQuietHours returns the current user's quiet hours and do-not-disturb
+// @Summary Get quiet hours
+// @Description Get the quiet hours and do-not-disturb of the current user, and whether email and push notifications are currently held
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /notifications/quiet-hours [get]
+func (h *NotificationHandler) GetQuietHours(c *gin.Context) {
+	user, ok := h.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, quietHoursResponse(user))
+}
+
+// UpdateQuietHours changes the current user's quiet hours
+// @Summary Update quiet hours
+// @Description Change the quiet hours of the current user. During quiet hours and on muted weekends, email and push notifications are held and delivered when they end; urgent task notifications break through when allowed. Times are HH:MM in the user's timezone, and an end before the start runs past midnight.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.UpdateQuietHoursRequest true "Quiet hours"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /notifications/quiet-hours [put]
+func (h *NotificationHandler) UpdateQuietHours(c *gin.Context) {
+	user, ok := h.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateQuietHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.Enabled != nil {
+		user.QuietHoursEnabled = *req.Enabled
+	}
+	if req.Start != nil {
+		user.QuietHoursStart = *req.Start
+	}
+	if req.End != nil {
+		user.QuietHoursEnd = *req.End
+	}
+	if req.Weekends != nil {
+		user.QuietWeekends = *req.Weekends
+	}
+	if req.AllowUrgent != nil {
+		user.QuietAllowUrgent = *req.AllowUrgent
+	}
+	if user.QuietHoursEnabled {
+		if user.QuietHoursStart == "" || user.QuietHoursEnd == "" {
+			response.BadRequest(c, "Quiet hours need a start and an end time")
+			return
+		}
+		if user.QuietHoursStart == user.QuietHoursEnd {
+			response.BadRequest(c, "Quiet hours must start and end at different times")
+			return
+		}
+	}
+
+	if err := h.db.Model(user).Updates(map[string]interface{}{
+		"quiet_hours_enabled": user.QuietHoursEnabled,
+		"quiet_hours_start":   user.QuietHoursStart,
+		"quiet_hours_end":     user.QuietHoursEnd,
+		"quiet_weekends":      user.QuietWeekends,
+		"quiet_allow_urgent":  user.QuietAllowUrgent,
+	}).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update quiet hours")
+		response.InternalServerError(c, "Failed to update quiet hours")
+		return
+	}
+	h.releaseHeld(user)
+
+	response.Success(c, quietHoursResponse(user), "Quiet hours updated successfully")
+}
+
+// SetDoNotDisturb turns on do-not-disturb for the current user
+// @Summary Turn on do-not-disturb
+// @Description Hold email and push notifications for a number of minutes or until a time, at most 30 days ahead. Other users see do-not-disturb in WebSocket presence.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.SetDoNotDisturbRequest true "Duration"
+// @Success 200 {object} response.APIResponse
+// @Failure 400 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /notifications/do-not-disturb [put]
+func (h *NotificationHandler) SetDoNotDisturb(c *gin.Context) {
+	user, ok := h.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	var req requests.SetDoNotDisturbRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	now := time.Now()
+	var until time.Time
+	switch {
+	case req.Minutes != nil && req.Until != nil:
+		response.BadRequest(c, "Give either minutes or until, not both")
+		return
+	case req.Minutes != nil:
+		until = now.Add(time.Duration(*req.Minutes) * time.Minute)
+	case req.Until != nil:
+		until = *req.Until
+	default:
+		response.BadRequest(c, "Either minutes or until is required")
+		return
+	}
+	if !until.After(now) || until.Sub(now) > maxDoNotDisturb {
+		response.BadRequest(c, "Do-not-disturb must end within the next 30 days")
+		return
+	}
+
+	if err := h.updateDoNotDisturb(user, &until); err != nil {
+		h.logger.WithError(err).Error("Failed to turn on do-not-disturb")
+		response.InternalServerError(c, "Failed to turn on do-not-disturb")
+		return
+	}
+
+	response.Success(c, quietHoursResponse(user), "Do-not-disturb turned on")
+}
+
+// ClearDoNotDisturb turns off do-not-disturb for the current user
+// @Summary Turn off do-not-disturb
+// @Description Turn off do-not-disturb. Notifications it held are delivered unless quiet hours are still on.
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.APIResponse
+// @Failure 401 {object} response.APIResponse
+// @Failure 500 {object} response.APIResponse
+// @Router /notifications/do-not-disturb [delete]
+func (h *NotificationHandler) ClearDoNotDisturb(c *gin.Context) {
+	user, ok := h.loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.updateDoNotDisturb(user, nil); err != nil {
+		h.logger.WithError(err).Error("Failed to turn off do-not-disturb")
+		response.InternalServerError(c, "Failed to turn off do-not-disturb")
+		return
+	}
+	h.releaseHeld(user)
+
+	response.Success(c, quietHoursResponse(user), "Do-not-disturb turned off")
+}
+
+// loadCurrentUser loads the current user, writing the error response when
+// that fails
+func (h *NotificationHandler) loadCurrentUser(c *gin.Context) (*models.User, bool) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return nil, false
+	}
+
+	var user models.User
+	if err := h.db.First(&user, userID).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch user")
+		response.InternalServerError(c, "Failed to fetch user")
+		return nil, false
+	}
+	return &user, true
+}
+
+// updateDoNotDisturb stores the user's do-not-disturb and shows it in
+// presence
+func (h *NotificationHandler) updateDoNotDisturb(user *models.User, until *time.Time) error {
+	if err := h.db.Model(user).Update("do_not_disturb_until", until).Error; err != nil {
+		return err
+	}
+	user.DoNotDisturbUntil = until
+	if h.hub != nil {
+		h.hub.SetDoNotDisturb(user.TenantID, user.ID, until)
+	}
+	return nil
+}
+
+// releaseHeld makes the user's held notifications due now, so they go out
+// on the next release unless the user is still quiet
+func (h *NotificationHandler) releaseHeld(user *models.User) {
+	now := time.Now()
+	if _, quiet := user.QuietUntil(now); quiet {
+		return
+	}
+	if err := h.db.Model(&models.NotificationQueue{}).
+		Where("user_id = ? AND frequency = ? AND status = ? AND scheduled_at > ?",
+			user.ID, models.NotificationFrequencyImmediate, "pending", now).
+		Update("scheduled_at", now).Error; err != nil {
+		h.logger.WithError(err).WithField("user_id", user.ID).Warn("Failed to release held notifications")
+	}
+}
+
+// quietHoursResponse describes the user's quiet hours and whether they are
+// quiet now
+func quietHoursResponse(user *models.User) gin.H {
+	now := time.Now()
+	result := gin.H{
+		"enabled":        user.QuietHoursEnabled,
+		"start":          user.QuietHoursStart,
+		"end":            user.QuietHoursEnd,
+		"weekends":       user.QuietWeekends,
+		"allow_urgent":   user.QuietAllowUrgent,
+		"timezone":       user.Location().String(),
+		"do_not_disturb": user.DoNotDisturb(now),
+		"quiet":          false,
+	}
+	if user.DoNotDisturb(now) {
+		result["do_not_disturb_until"] = user.DoNotDisturbUntil
+	}
+	if until, quiet := user.QuietUntil(now); quiet {
+		result["quiet"] = true
+		result["quiet_until"] = until
+	}
+	return result
+}
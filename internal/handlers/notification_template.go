@@ -0,0 +1,387 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// NotificationTemplateHandler handles the tenant's overrides of notification
+// templates
+type NotificationTemplateHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+	templates *templates.Engine
+}
+
+// NewNotificationTemplateHandler creates a new notification template handler
+func NewNotificationTemplateHandler(db *gorm.DB, logger *logger.Logger, engine *templates.Engine) *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+		templates: engine,
+	}
+}
+
+// notificationTemplateCatalogEntry is a notification type with its variables
+// and built-in content on every channel
+type notificationTemplateCatalogEntry struct {
+	Type      models.NotificationType                          `json:"type"`
+	Variables []templates.Variable                             `json:"variables"`
+	Defaults  map[models.NotificationChannel]templates.Content `json:"defaults"`
+}
+
+// GetNotificationTemplateCatalog lists the notification types with templates
+// @Summary Notification template catalog
+// @Description Get every notification type with the variables its templates can use and its built-in English content per channel. Variables available to every type are listed under builtin.
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /notification-templates/catalog [get]
+func (h *NotificationTemplateHandler) GetNotificationTemplateCatalog(c *gin.Context) {
+	definitions := templates.Definitions()
+	entries := make([]notificationTemplateCatalogEntry, 0, len(definitions))
+	for i := range definitions {
+		definition := &definitions[i]
+		entry := notificationTemplateCatalogEntry{
+			Type:      definition.Type,
+			Variables: definition.Variables,
+			Defaults:  map[models.NotificationChannel]templates.Content{},
+		}
+		for _, channel := range templates.Channels {
+			entry.Defaults[channel] = definition.Content(channel)
+		}
+		entries = append(entries, entry)
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
+		"builtin":  templates.Builtin,
+		"channels": templates.Channels,
+		"types":    entries,
+	}))
+}
+
+// ListNotificationTemplates returns the tenant's template overrides
+// @Summary List notification templates
+// @Description Get the notification templates the tenant overrides
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Notification type"
+// @Param channel query string false "Channel"
+// @Param language query string false "Language"
+// @Success 200 {array} models.NotificationTemplate
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates [get]
+func (h *NotificationTemplateHandler) ListNotificationTemplates(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	query := h.db.Where("tenant_id = ?", tenantID)
+	if notificationType := c.Query("type"); notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+	if channel := c.Query("channel"); channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+	if language := c.Query("language"); language != "" {
+		query = query.Where("language = ?", templates.Languages(language)[0])
+	}
+
+	var list []models.NotificationTemplate
+	if err := query.Order("type ASC, channel ASC, language ASC").Find(&list).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch notification templates")
+		response.InternalServerError(c, "Failed to fetch notification templates")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(list))
+}
+
+// CreateNotificationTemplate overrides a notification template for the tenant
+// @Summary Create notification template
+// @Description Override the content of a notification type on one channel and language. Templates use Go template syntax, e.g. {{.task_title}}, and must declare every variable they use.
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateNotificationTemplateRequest true "Template"
+// @Success 201 {object} models.NotificationTemplate
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates [post]
+func (h *NotificationTemplateHandler) CreateNotificationTemplate(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	var req requests.CreateNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	tmpl := &models.NotificationTemplate{
+		TenantID:  &tenantID,
+		Type:      req.Type,
+		Channel:   req.Channel,
+		Language:  req.Language,
+		Name:      req.Name,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		HTMLBody:  req.HTMLBody,
+		Variables: req.Variables,
+		IsActive:  true,
+	}
+	if tmpl.Variables == nil {
+		tmpl.Variables = []string{}
+	}
+	if validationErrors := templates.Validate(tmpl); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.NotificationTemplate{}).
+		Where("tenant_id = ? AND type = ? AND channel = ? AND language = ?", tenantID, tmpl.Type, tmpl.Channel, tmpl.Language).
+		Count(&count).Error; err != nil {
+		response.InternalServerError(c, "Failed to check notification templates")
+		return
+	}
+	if count > 0 {
+		response.Conflict(c, "A template for this type, channel and language already exists")
+		return
+	}
+
+	if err := h.db.Create(tmpl).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create notification template")
+		response.InternalServerError(c, "Failed to create notification template")
+		return
+	}
+
+	response.Created(c, tmpl, "Notification template created successfully")
+}
+
+// GetNotificationTemplate returns a template override of the tenant
+// @Summary Get notification template
+// @Description Get a notification template of the tenant
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} models.NotificationTemplate
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates/{id} [get]
+func (h *NotificationTemplateHandler) GetNotificationTemplate(c *gin.Context) {
+	tmpl, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(tmpl))
+}
+
+// UpdateNotificationTemplate changes a template override of the tenant
+// @Summary Update notification template
+// @Description Change a notification template. The changed template is validated like a new one.
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Param request body requests.UpdateNotificationTemplateRequest true "Changes"
+// @Success 200 {object} models.NotificationTemplate
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates/{id} [put]
+func (h *NotificationTemplateHandler) UpdateNotificationTemplate(c *gin.Context) {
+	tmpl, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.Name != nil {
+		tmpl.Name = *req.Name
+	}
+	if req.Subject != nil {
+		tmpl.Subject = *req.Subject
+	}
+	if req.Body != nil {
+		tmpl.Body = *req.Body
+	}
+	if req.HTMLBody != nil {
+		tmpl.HTMLBody = *req.HTMLBody
+	}
+	if req.Variables != nil {
+		tmpl.Variables = req.Variables
+	}
+	if req.IsActive != nil {
+		tmpl.IsActive = *req.IsActive
+	}
+	if validationErrors := templates.Validate(tmpl); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if err := h.db.Model(tmpl).Select("name", "subject", "body", "html_body", "variables", "is_active").Updates(tmpl).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update notification template")
+		response.InternalServerError(c, "Failed to update notification template")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(tmpl, "Notification template updated successfully"))
+}
+
+// DeleteNotificationTemplate removes a template override, restoring the
+// default content
+// @Summary Delete notification template
+// @Description Delete a notification template so the global or built-in template is used again
+// @Tags notification-templates
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Template ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates/{id} [delete]
+func (h *NotificationTemplateHandler) DeleteNotificationTemplate(c *gin.Context) {
+	tmpl, ok := h.loadTemplate(c)
+	if !ok {
+		return
+	}
+
+	// Deleted for good so the same type, channel and language can be
+	// overridden again
+	if err := h.db.Unscoped().Delete(tmpl).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to delete notification template")
+		response.InternalServerError(c, "Failed to delete notification template")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Notification template deleted successfully"))
+}
+
+// PreviewNotificationTemplate renders a notification with example values
+// @Summary Preview notification template
+// @Description Render a notification with the tenant's branding and example values. Without a subject and body, the template currently used for the language is rendered.
+// @Tags notification-templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.PreviewNotificationTemplateRequest true "Template to preview"
+// @Success 200 {object} templates.Rendered
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /notification-templates/preview [post]
+func (h *NotificationTemplateHandler) PreviewNotificationTemplate(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	var req requests.PreviewNotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+	if _, ok := templates.Lookup(req.Type); !ok {
+		response.BadRequest(c, "Notification type has no templates")
+		return
+	}
+
+	content := templates.Content{Subject: req.Subject, Body: req.Body, HTML: req.HTMLBody}
+	if req.Subject == "" && req.Body == "" {
+		if content, err = h.templates.Effective(c.Request.Context(), tenantID, req.Type, req.Channel, req.Language); err != nil {
+			h.logger.WithError(err).Error("Failed to load notification template")
+			response.InternalServerError(c, "Failed to load notification template")
+			return
+		}
+	}
+
+	rendered, err := h.templates.Preview(c.Request.Context(), tenantID, req.Type, req.Channel, content, req.Data)
+	if err != nil {
+		response.BadRequest(c, "Failed to render template", err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(rendered))
+}
+
+// loadTemplate fetches the tenant template named in the path
+func (h *NotificationTemplateHandler) loadTemplate(c *gin.Context) (*models.NotificationTemplate, bool) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return nil, false
+	}
+
+	templateID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid template ID")
+		return nil, false
+	}
+
+	var tmpl models.NotificationTemplate
+	if err := h.db.Where("id = ? AND tenant_id = ?", templateID, tenantID).First(&tmpl).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Notification template not found")
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch notification template")
+		response.InternalServerError(c, "Failed to fetch notification template")
+		return nil, false
+	}
+
+	return &tmpl, true
+}
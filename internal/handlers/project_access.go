@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"gorm.io/gorm"
+)
+
+// authorizeProject loads a project of the current tenant and checks the current
+// user holds at least min in it. Projects the user cannot read are reported as
+// missing so private projects are not disclosed. Archived projects reject
+// commenter and editor actions; owners can still manage the project itself.
+func authorizeProject(c *gin.Context, db *gorm.DB, projectID uuid.UUID, min models.ProjectRole) (*models.Project, *models.User, models.ProjectRole, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+
+	var project models.Project
+	if err := db.Where("id = ? AND tenant_id = ?", projectID, user.TenantID).First(&project).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project not found"))
+			return nil, nil, models.ProjectRoleNone, false
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+
+	role, err := models.ProjectRoleOf(db, user, &project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to check project permissions"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+	if !role.CanRead() {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project not found"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+	if !role.AtLeast(min) {
+		c.JSON(http.StatusForbidden, middleware.ErrorResponse("Insufficient project permissions"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+	if project.IsArchived() && min.AtLeast(models.ProjectRoleCommenter) && !min.CanManage() {
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("Project is archived"))
+		return nil, nil, models.ProjectRoleNone, false
+	}
+
+	return &project, user, role, true
+}
+
+// authorizeTask loads a task of the current tenant and checks the current user
+// holds at least min in the task's project. Tasks outside any project are
+// editable by every user of the tenant; tasks of archived projects are read-only.
+func authorizeTask(c *gin.Context, db *gorm.DB, taskID uuid.UUID, min models.ProjectRole) (*models.Task, *models.User, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return nil, nil, false
+	}
+
+	var task models.Task
+	if err := db.Where("id = ? AND tenant_id = ?", taskID, user.TenantID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch task"))
+		return nil, nil, false
+	}
+
+	project, role, err := taskAccess(db, user, &task)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to check project permissions"))
+		return nil, nil, false
+	}
+	if !role.CanRead() {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
+		return nil, nil, false
+	}
+	if project != nil && project.IsArchived() && min.AtLeast(models.ProjectRoleCommenter) {
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("Project is archived"))
+		return nil, nil, false
+	}
+	if !role.AtLeast(min) {
+		c.JSON(http.StatusForbidden, middleware.ErrorResponse("Insufficient project permissions"))
+		return nil, nil, false
+	}
+
+	return &task, user, true
+}
+
+// taskRole returns the effective role of a user on a task, limited to viewer
+// when the task's project is archived
+func taskRole(db *gorm.DB, user *models.User, task *models.Task) (models.ProjectRole, error) {
+	project, role, err := taskAccess(db, user, task)
+	if err != nil || project == nil {
+		return role, err
+	}
+	return archivedRole(project, role), nil
+}
+
+// taskAccess returns the project of a task, if any, and the role of a user on
+// the task regardless of archiving
+func taskAccess(db *gorm.DB, user *models.User, task *models.Task) (*models.Project, models.ProjectRole, error) {
+	if task.ProjectID == nil {
+		if user.IsAdmin() {
+			return nil, models.ProjectRoleOwner, nil
+		}
+		return nil, models.ProjectRoleEditor, nil
+	}
+	return projectAccess(db, user, *task.ProjectID)
+}
+
+// projectRole returns the effective role of a user in a project, or no role
+// when the project does not exist in the user's tenant. Roles in archived
+// projects are limited to viewer.
+func projectRole(db *gorm.DB, user *models.User, projectID uuid.UUID) (models.ProjectRole, error) {
+	project, role, err := projectAccess(db, user, projectID)
+	if err != nil || project == nil {
+		return role, err
+	}
+	return archivedRole(project, role), nil
+}
+
+// projectAccess loads a project of the user's tenant with the user's role in
+// it. The project is nil when it does not exist.
+func projectAccess(db *gorm.DB, user *models.User, projectID uuid.UUID) (*models.Project, models.ProjectRole, error) {
+	var project models.Project
+	if err := db.Where("id = ? AND tenant_id = ?", projectID, user.TenantID).First(&project).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ProjectRoleNone, nil
+		}
+		return nil, models.ProjectRoleNone, err
+	}
+	role, err := models.ProjectRoleOf(db, user, &project)
+	return &project, role, err
+}
+
+// archivedRole limits a role to read access while the project is archived
+func archivedRole(project *models.Project, role models.ProjectRole) models.ProjectRole {
+	if project.IsArchived() && role.CanRead() {
+		return models.ProjectRoleViewer
+	}
+	return role
+}
+
+// taskIsPublic reports whether a task is visible to the whole tenant, being
+// in a public project or in none
+func taskIsPublic(db *gorm.DB, task *models.Task) (bool, error) {
+	return models.ProjectIsPublic(db, task.TenantID, task.ProjectID)
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"gorm.io/gorm"
+)
+
+// ListProjectMembers returns the members of a project
+// @Summary List project members
+// @Description Get the users with an explicit role in a project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {array} models.ProjectMember
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/members [get]
+func (h *TaskHandler) ListProjectMembers(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+		return
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	var members []models.ProjectMember
+	if err := h.db.
+		Preload("User").
+		Where("project_id = ?", project.ID).
+		Order("created_at ASC").
+		Find(&members).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch project members")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project members"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(members))
+}
+
+// AddProjectMember grants a user a role in a project
+// @Summary Add project member
+// @Description Add a user of the tenant to a project with a role
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param request body requests.AddProjectMemberRequest true "Member data"
+// @Success 201 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/members [post]
+func (h *TaskHandler) AddProjectMember(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
+		return
+	}
+
+	var req requests.AddProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.User{}).Where("id = ? AND tenant_id = ?", req.UserID, project.TenantID).Count(&count).Error; err != nil {
+		response.InternalServerError(c, "Failed to verify user")
+		return
+	}
+	if count == 0 {
+		response.NotFound(c, "User not found")
+		return
+	}
+
+	if err := h.db.Model(&models.ProjectMember{}).Where("project_id = ? AND user_id = ?", project.ID, req.UserID).Count(&count).Error; err != nil {
+		response.InternalServerError(c, "Failed to verify membership")
+		return
+	}
+	if count > 0 {
+		response.Conflict(c, "User is already a member of this project")
+		return
+	}
+
+	member := &models.ProjectMember{
+		ProjectID: project.ID,
+		UserID:    req.UserID,
+		TenantID:  project.TenantID,
+		Role:      models.ProjectRole(req.Role),
+		AddedBy:   &user.ID,
+	}
+	if err := h.db.Create(member).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to add project member")
+		response.InternalServerError(c, "Failed to add project member")
+		return
+	}
+
+	if err := h.db.Preload("User").Where("project_id = ? AND user_id = ?", member.ProjectID, member.UserID).First(member).Error; err != nil {
+		h.logger.WithError(err).Warn("Failed to reload project member")
+	}
+
+	publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectMemberAdded{
+		Project: events.NewProjectSnapshot(project),
+		UserID:  member.UserID,
+		Role:    member.Role,
+	})
+
+	response.Created(c, member, "Project member added successfully")
+}
+
+// UpdateProjectMember changes the role of a project member
+// @Summary Update project member
+// @Description Change the role of a user in a project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Param request body requests.UpdateProjectMemberRequest true "Role data"
+// @Success 200 {object} models.ProjectMember
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/members/{user_id} [put]
+func (h *TaskHandler) UpdateProjectMember(c *gin.Context) {
+	project, member, ok := h.loadProjectMember(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateProjectMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	role := models.ProjectRole(req.Role)
+	if member.Role == models.ProjectRoleOwner && role != models.ProjectRoleOwner {
+		if last, err := h.isLastOwner(project.ID); err != nil {
+			response.InternalServerError(c, "Failed to verify project owners")
+			return
+		} else if last {
+			response.Conflict(c, "A project must keep at least one owner")
+			return
+		}
+	}
+
+	if err := h.db.Model(member).Where("project_id = ? AND user_id = ?", member.ProjectID, member.UserID).
+		Update("role", role).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update project member")
+		response.InternalServerError(c, "Failed to update project member")
+		return
+	}
+	previous := member.Role
+	member.Role = role
+
+	if previous != role {
+		actorID, _ := middleware.GetCurrentUserID(c)
+		publishEvent(c, h.events, h.logger, project.TenantID, actorID, events.ProjectMemberUpdated{
+			Project:      events.NewProjectSnapshot(project),
+			UserID:       member.UserID,
+			Role:         role,
+			PreviousRole: previous,
+		})
+	}
+
+	response.Success(c, member, "Project member updated successfully")
+}
+
+// RemoveProjectMember removes a user from a project
+// @Summary Remove project member
+// @Description Remove a user's explicit role in a project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/members/{user_id} [delete]
+func (h *TaskHandler) RemoveProjectMember(c *gin.Context) {
+	project, member, ok := h.loadProjectMember(c)
+	if !ok {
+		return
+	}
+
+	if member.Role == models.ProjectRoleOwner {
+		if last, err := h.isLastOwner(project.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to verify project owners"))
+			return
+		} else if last {
+			c.JSON(http.StatusConflict, middleware.ErrorResponse("A project must keep at least one owner"))
+			return
+		}
+	}
+
+	if err := h.db.Where("project_id = ? AND user_id = ?", member.ProjectID, member.UserID).
+		Delete(&models.ProjectMember{}).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to remove project member")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to remove project member"))
+		return
+	}
+
+	actorID, _ := middleware.GetCurrentUserID(c)
+	publishEvent(c, h.events, h.logger, project.TenantID, actorID, events.ProjectMemberRemoved{
+		Project: events.NewProjectSnapshot(project),
+		UserID:  member.UserID,
+		Role:    member.Role,
+	})
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Project member removed successfully"))
+}
+
+// loadProjectMember authorizes a project owner and fetches the member in the path
+func (h *TaskHandler) loadProjectMember(c *gin.Context) (*models.Project, *models.ProjectMember, bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+		return nil, nil, false
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid user ID"))
+		return nil, nil, false
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var member models.ProjectMember
+	if err := h.db.Preload("User").Where("project_id = ? AND user_id = ?", project.ID, userID).First(&member).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project member not found"))
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project member"))
+		return nil, nil, false
+	}
+
+	return project, &member, true
+}
+
+// isLastOwner checks if a project has a single explicit owner left
+func (h *TaskHandler) isLastOwner(projectID uuid.UUID) (bool, error) {
+	var owners int64
+	err := h.db.Model(&models.ProjectMember{}).
+		Where("project_id = ? AND role = ?", projectID, models.ProjectRoleOwner).
+		Count(&owners).Error
+	return owners <= 1, err
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"gorm.io/gorm"
+)
+
+// Bounds of the throughput window in weeks
+const (
+	defaultThroughputWeeks = 8
+	maxThroughputWeeks     = 52
+)
+
+// closedTaskStatuses are the statuses of tasks that need no more work
+var closedTaskStatuses = []models.TaskStatus{models.TaskStatusCompleted, models.TaskStatusCanceled}
+
+// GetProjectStats returns aggregated statistics and a health score for a project
+// @Summary Get project statistics
+// @Description Get task counts, completion, hours, throughput, cycle time and a health score for a project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param weeks query int false "Weeks of throughput history" default(8)
+// @Success 200 {object} models.ProjectStats
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/stats [get]
+func (h *TaskHandler) GetProjectStats(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+		return
+	}
+
+	weeks := defaultThroughputWeeks
+	if value := c.Query("weeks"); value != "" {
+		weeks, err = strconv.Atoi(value)
+		if err != nil || weeks < 1 || weeks > maxThroughputWeeks {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse("weeks must be between 1 and 52"))
+			return
+		}
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	stats, err := h.projectStats(project, weeks, time.Now().UTC())
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to compute project statistics")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to compute project statistics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(stats))
+}
+
+// projectStats computes the statistics of a project with aggregate queries
+func (h *TaskHandler) projectStats(project *models.Project, weeks int, now time.Time) (*models.ProjectStats, error) {
+	stats := &models.ProjectStats{
+		ProjectID:         project.ID,
+		ByStatus:          map[models.TaskStatus]int64{},
+		ByPriority:        map[models.TaskPriority]int64{},
+		OverdueByPriority: map[models.TaskPriority]int64{},
+		GeneratedAt:       now,
+	}
+
+	tasks := func() *gorm.DB {
+		return h.db.Model(&models.Task{}).Where("tenant_id = ? AND project_id = ?", project.TenantID, project.ID)
+	}
+
+	var byStatus []struct {
+		Status models.TaskStatus
+		Count  int64
+	}
+	if err := tasks().Select("status, COUNT(*) AS count").Group("status").Scan(&byStatus).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range byStatus {
+		stats.ByStatus[row.Status] = row.Count
+		stats.Total += row.Count
+		if row.Status != models.TaskStatusCompleted && row.Status != models.TaskStatusCanceled {
+			stats.Open += row.Count
+		}
+	}
+
+	var byPriority []struct {
+		Priority models.TaskPriority
+		Count    int64
+	}
+	if err := tasks().Select("priority, COUNT(*) AS count").Group("priority").Scan(&byPriority).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range byPriority {
+		stats.ByPriority[row.Priority] = row.Count
+	}
+
+	var overdue []struct {
+		Priority models.TaskPriority
+		Count    int64
+	}
+	if err := tasks().
+		Select("priority, COUNT(*) AS count").
+		Where("due_date < ? AND status NOT IN ?", now, closedTaskStatuses).
+		Group("priority").
+		Scan(&overdue).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range overdue {
+		stats.OverdueByPriority[row.Priority] = row.Count
+		stats.Overdue += row.Count
+	}
+
+	// Mirrors Task.GetProgress: top-level tasks count by their completed
+	// subtasks, then by their checklist, or as done or not otherwise
+	var completion struct {
+		Percent float64
+	}
+	subtasks := h.db.Model(&models.Task{}).
+		Select("parent_id, COUNT(*) AS total, SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS done", models.TaskStatusCompleted).
+		Where("tenant_id = ? AND parent_id IS NOT NULL", project.TenantID).
+		Group("parent_id")
+	checklists := h.db.Model(&models.ChecklistItem{}).
+		Select("task_id, COUNT(*) AS total, SUM(CASE WHEN is_done THEN 1 ELSE 0 END) AS done").
+		Where("tenant_id = ?", project.TenantID).
+		Group("task_id")
+	if err := h.db.Table("tasks AS t").
+		Select(`COALESCE(AVG(CASE WHEN s.total IS NOT NULL THEN s.done * 100.0 / s.total
+			WHEN l.total IS NOT NULL THEN l.done * 100.0 / l.total
+			WHEN t.status = ? THEN 100.0 ELSE 0 END), 0) AS percent`, models.TaskStatusCompleted).
+		Joins("LEFT JOIN (?) AS s ON s.parent_id = t.id", subtasks).
+		Joins("LEFT JOIN (?) AS l ON l.task_id = t.id", checklists).
+		Where("t.tenant_id = ? AND t.project_id = ? AND t.parent_id IS NULL AND t.status <> ? AND t.deleted_at IS NULL",
+			project.TenantID, project.ID, models.TaskStatusCanceled).
+		Scan(&completion).Error; err != nil {
+		return nil, err
+	}
+	stats.CompletionPercent = math.Round(completion.Percent*10) / 10
+
+	var totals struct {
+		Estimated          float64
+		Actual             float64
+		CompletedEstimated float64
+		CompletedActual    float64
+		LatestOpenDue      *time.Time
+		LateCompletions    int64
+		AvgLateDays        *float64
+		AvgCycleDays       *float64
+	}
+	if err := tasks().
+		Select(`COALESCE(SUM(estimated_hours), 0) AS estimated,
+			COALESCE(SUM(actual_hours), 0) AS actual,
+			COALESCE(SUM(CASE WHEN status = @completed THEN estimated_hours END), 0) AS completed_estimated,
+			COALESCE(SUM(CASE WHEN status = @completed THEN actual_hours END), 0) AS completed_actual,
+			MAX(CASE WHEN status NOT IN @closed THEN due_date END) AS latest_open_due,
+			COUNT(CASE WHEN status = @completed AND completed_at > due_date THEN 1 END) AS late_completions,
+			AVG(CASE WHEN status = @completed AND completed_at > due_date
+				THEN EXTRACT(EPOCH FROM completed_at - due_date) / 86400 END) AS avg_late_days,
+			AVG(CASE WHEN status = @completed AND completed_at IS NOT NULL
+				THEN EXTRACT(EPOCH FROM completed_at - COALESCE(start_date, created_at)) / 86400 END) AS avg_cycle_days`,
+			map[string]interface{}{"completed": models.TaskStatusCompleted, "closed": closedTaskStatuses}).
+		Where("status <> ?", models.TaskStatusCanceled).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	stats.Hours = models.ProjectHours{
+		Estimated:          totals.Estimated,
+		Actual:             totals.Actual,
+		CompletedEstimated: totals.CompletedEstimated,
+		CompletedActual:    totals.CompletedActual,
+	}
+	if totals.CompletedEstimated > 0 {
+		variance := math.Round((totals.CompletedActual-totals.CompletedEstimated)/totals.CompletedEstimated*1000) / 10
+		stats.Hours.VariancePercent = &variance
+	}
+	if totals.AvgCycleDays != nil {
+		cycle := math.Round(*totals.AvgCycleDays*10) / 10
+		stats.AvgCycleTimeDays = &cycle
+	}
+	stats.Slip.LateCompletions = totals.LateCompletions
+	if totals.AvgLateDays != nil {
+		stats.Slip.AvgLateDays = math.Round(*totals.AvgLateDays*10) / 10
+	}
+
+	since := models.WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	var throughput []struct {
+		Week  time.Time
+		Count int64
+	}
+	if err := tasks().
+		Select("date_trunc('week', completed_at AT TIME ZONE 'UTC') AS week, COUNT(*) AS count").
+		Where("status = ? AND completed_at >= ?", models.TaskStatusCompleted, since).
+		Group("week").
+		Scan(&throughput).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[time.Time]int64, len(throughput))
+	for _, row := range throughput {
+		counts[models.WeekStart(row.Week)] += row.Count
+	}
+	stats.Throughput = models.FillThroughput(counts, since, now)
+
+	stats.ComputeSlip(project, totals.LatestOpenDue, now)
+	stats.ComputeHealth(project)
+
+	return stats, nil
+}
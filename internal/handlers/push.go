@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/internal/webpush"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// PushHandler handles the browsers users register for push notifications
+type PushHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+	client    *webpush.Client
+}
+
+// NewPushHandler creates a new push handler. Without a client, push is not
+// configured and browsers cannot subscribe.
+func NewPushHandler(db *gorm.DB, logger *logger.Logger, client *webpush.Client) *PushHandler {
+	return &PushHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+		client:    client,
+	}
+}
+
+// pushSubscriptionResponse is a push subscription with whether the current
+// session registered it
+type pushSubscriptionResponse struct {
+	models.PushSubscription
+	CurrentSession bool `json:"current_session"`
+}
+
+// GetVAPIDPublicKey returns the key browsers subscribe with
+// @Summary Get VAPID public key
+// @Description Get the application server key to pass to PushManager.subscribe() as applicationServerKey
+// @Tags push
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /push/vapid-public-key [get]
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, middleware.ErrorResponse("Push notifications are not configured"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{"public_key": h.client.PublicKey()}))
+}
+
+// ListPushSubscriptions returns the current user's push subscriptions
+// @Summary List push subscriptions
+// @Description Get the browsers the current user receives push notifications on
+// @Tags push
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} pushSubscriptionResponse
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /push/subscriptions [get]
+func (h *PushHandler) ListPushSubscriptions(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not found")
+		return
+	}
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+	sessionID, _ := middleware.GetSessionID(c)
+
+	var subscriptions []models.PushSubscription
+	if err := h.db.Where("user_id = ? AND tenant_id = ?", userID, tenantID).
+		Order("created_at DESC").Find(&subscriptions).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch push subscriptions")
+		response.InternalServerError(c, "Failed to fetch push subscriptions")
+		return
+	}
+
+	list := make([]pushSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		list = append(list, pushSubscriptionResponse{
+			PushSubscription: subscription,
+			CurrentSession:   subscription.SessionID != nil && *subscription.SessionID == sessionID,
+		})
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(list))
+}
+
+// CreatePushSubscription registers the current browser for push notifications
+// @Summary Create push subscription
+// @Description Register the subscription returned by PushManager.subscribe(). Registering an endpoint again updates its keys and moves it to the current user and session. The subscription is removed when the session logs out.
+// @Tags push
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreatePushSubscriptionRequest true "Subscription"
+// @Success 200 {object} models.PushSubscription
+// @Success 201 {object} models.PushSubscription
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /push/subscriptions [post]
+func (h *PushHandler) CreatePushSubscription(c *gin.Context) {
+	if h.client == nil {
+		c.JSON(http.StatusServiceUnavailable, middleware.ErrorResponse("Push notifications are not configured"))
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not found")
+		return
+	}
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	var req requests.CreatePushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if err := webpush.Validate(&webpush.Subscription{
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}); err != nil {
+		response.BadRequest(c, "Invalid push subscription", err.Error())
+		return
+	}
+	if err := h.client.CheckEndpoint(req.Endpoint); err != nil {
+		response.BadRequest(c, "Invalid push subscription", err.Error())
+		return
+	}
+
+	// The endpoint identifies the browser, so a browser that subscribes
+	// again, possibly for another user, replaces its earlier subscription
+	var subscription models.PushSubscription
+	err = h.db.Where("endpoint = ?", req.Endpoint).First(&subscription).Error
+	created := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !created {
+		h.logger.WithError(err).Error("Failed to fetch push subscription")
+		response.InternalServerError(c, "Failed to register push subscription")
+		return
+	}
+
+	subscription.TenantID = tenantID
+	subscription.UserID = userID
+	subscription.SessionID = nil
+	if sessionID, err := middleware.GetSessionID(c); err == nil {
+		subscription.SessionID = &sessionID
+	}
+	subscription.Endpoint = req.Endpoint
+	subscription.P256dh = req.Keys.P256dh
+	subscription.Auth = req.Keys.Auth
+	subscription.UserAgent = truncateUserAgent(c.Request.UserAgent())
+	subscription.ExpiresAt = nil
+	if req.ExpirationTime != nil {
+		expiresAt := time.UnixMilli(*req.ExpirationTime)
+		subscription.ExpiresAt = &expiresAt
+	}
+
+	if err := h.db.Save(&subscription).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to save push subscription")
+		response.InternalServerError(c, "Failed to register push subscription")
+		return
+	}
+
+	if created {
+		response.Created(c, subscription, "Push subscription created successfully")
+		return
+	}
+	c.JSON(http.StatusOK, middleware.SuccessResponse(subscription, "Push subscription updated successfully"))
+}
+
+// DeletePushSubscription unregisters a browser of the current user
+// @Summary Delete push subscription
+// @Description Stop sending push notifications to a browser
+// @Tags push
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /push/subscriptions/{id} [delete]
+func (h *PushHandler) DeletePushSubscription(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not found")
+		return
+	}
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid subscription ID")
+		return
+	}
+
+	result := h.db.Unscoped().Where("id = ? AND user_id = ? AND tenant_id = ?", id, userID, tenantID).
+		Delete(&models.PushSubscription{})
+	if result.Error != nil {
+		h.logger.WithError(result.Error).Error("Failed to delete push subscription")
+		response.InternalServerError(c, "Failed to delete push subscription")
+		return
+	}
+	if result.RowsAffected == 0 {
+		response.NotFound(c, "Push subscription not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Push subscription deleted successfully"))
+}
+
+// truncateUserAgent fits a user agent into its column
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) > 500 {
+		return userAgent[:500]
+	}
+	return userAgent
+}
@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"gorm.io/gorm"
+)
+
+// ListViews returns the saved views visible to the current user
+// @Summary List saved views
+// @Description Get the current user's private views and the views shared with the tenant or a project
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param project_id query string false "Only views shared with this project"
+// @Success 200 {array} models.SavedView
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views [get]
+func (h *TaskHandler) ListViews(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return
+	}
+
+	query := h.db.
+		Preload("Owner").
+		Preload("Project").
+		Where("tenant_id = ?", user.TenantID).
+		Where("owner_id = ? OR visibility = ? OR (visibility = ? AND project_id IN (?))",
+			user.ID, models.ViewVisibilityTenant, models.ViewVisibilityProject, models.VisibleProjectIDs(h.db, user))
+	if projectID := c.Query("project_id"); projectID != "" {
+		if id, err := uuid.Parse(projectID); err == nil {
+			query = query.Where("project_id = ?", id)
+		}
+	}
+
+	var views []models.SavedView
+	if err := query.Order("name ASC").Find(&views).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch saved views")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch views"))
+		return
+	}
+
+	for i := range views {
+		views[i].IsDefault = user.DefaultViewID != nil && *user.DefaultViewID == views[i].ID
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(views))
+}
+
+// CreateView saves a named task listing
+// @Summary Create saved view
+// @Description Save a filter, sort, column set and view mode under a name
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateSavedViewRequest true "View data"
+// @Success 201 {object} models.SavedView
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views [post]
+func (h *TaskHandler) CreateView(c *gin.Context) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req requests.CreateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	view := &models.SavedView{
+		TenantModel: models.TenantModel{TenantID: user.TenantID},
+		OwnerID:     user.ID,
+		Name:        req.Name,
+		Filter:      req.Filter,
+		Sort:        req.Sort,
+		Order:       req.Order,
+		Columns:     req.Columns,
+		ViewMode:    req.ViewMode,
+		Visibility:  req.Visibility,
+		ProjectID:   req.ProjectID,
+	}
+	if view.Sort == "" {
+		view.Sort = "created_at"
+	}
+	if view.Order == "" {
+		view.Order = "desc"
+	}
+	if view.ViewMode == "" {
+		view.ViewMode = models.ViewMode(user.TaskViewMode)
+		if view.ViewMode == "" {
+			view.ViewMode = models.ViewModeList
+		}
+	}
+	if view.Visibility == "" {
+		view.Visibility = models.ViewVisibilityPrivate
+	}
+
+	if status, message := h.checkViewSharing(user, view); status != 0 {
+		c.JSON(status, middleware.ErrorResponse(message))
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(view).Error; err != nil {
+			return err
+		}
+		if req.IsDefault {
+			return tx.Model(user).Update("default_view_id", view.ID).Error
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create saved view")
+		response.InternalServerError(c, "Failed to create view")
+		return
+	}
+	view.IsDefault = req.IsDefault
+
+	response.Created(c, view, "View created successfully")
+}
+
+// GetView returns a saved view
+// @Summary Get saved view
+// @Description Get a saved view visible to the current user
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} models.SavedView
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id} [get]
+func (h *TaskHandler) GetView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+	view.IsDefault = user.DefaultViewID != nil && *user.DefaultViewID == view.ID
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(view))
+}
+
+// UpdateView updates a saved view
+// @Summary Update saved view
+// @Description Update a saved view. Shared views can also be edited by managers and admins.
+// @Tags views
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Param request body requests.UpdateSavedViewRequest true "View update data"
+// @Success 200 {object} models.SavedView
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id} [put]
+func (h *TaskHandler) UpdateView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+
+	if !view.CanEdit(user) {
+		response.Forbidden(c, "You cannot edit this view")
+		return
+	}
+
+	var req requests.UpdateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.Name != nil {
+		view.Name = *req.Name
+	}
+	if req.Filter != nil {
+		view.Filter = *req.Filter
+	}
+	if req.Sort != nil {
+		view.Sort = *req.Sort
+	}
+	if req.Order != nil {
+		view.Order = *req.Order
+	}
+	if req.Columns != nil {
+		view.Columns = req.Columns
+	}
+	if req.ViewMode != nil {
+		view.ViewMode = *req.ViewMode
+	}
+	if req.Visibility != nil {
+		view.Visibility = *req.Visibility
+	}
+	if req.ProjectID != nil {
+		view.ProjectID = req.ProjectID
+	}
+
+	if status, message := h.checkViewSharing(user, view); status != 0 {
+		c.JSON(status, middleware.ErrorResponse(message))
+		return
+	}
+
+	if err := h.db.Omit("Owner", "Project").Save(view).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update saved view")
+		response.InternalServerError(c, "Failed to update view")
+		return
+	}
+
+	response.Success(c, view, "View updated successfully")
+}
+
+// DeleteView deletes a saved view
+// @Summary Delete saved view
+// @Description Delete a saved view
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id} [delete]
+func (h *TaskHandler) DeleteView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+
+	if !view.CanEdit(user) {
+		c.JSON(http.StatusForbidden, middleware.ErrorResponse("You cannot delete this view"))
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(view).Error; err != nil {
+			return err
+		}
+		// Nobody should keep a deleted view as their default
+		return tx.Model(&models.User{}).
+			Where("tenant_id = ? AND default_view_id = ?", view.TenantID, view.ID).
+			Update("default_view_id", nil).Error
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to delete saved view")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete view"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "View deleted successfully"))
+}
+
+// SetDefaultView makes a view the current user's default task listing
+// @Summary Set default view
+// @Description Use a saved view as the current user's default task listing
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id}/default [put]
+func (h *TaskHandler) SetDefaultView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Model(user).Update("default_view_id", view.ID).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to set default view")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to set default view"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Default view updated successfully"))
+}
+
+// ClearDefaultView removes the current user's default view
+// @Summary Clear default view
+// @Description Stop using a saved view as the default task listing
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id}/default [delete]
+func (h *TaskHandler) ClearDefaultView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Model(&models.User{}).
+		Where("id = ? AND default_view_id = ?", user.ID, view.ID).
+		Update("default_view_id", nil).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to clear default view")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to clear default view"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Default view cleared successfully"))
+}
+
+// RunView lists the tasks matched by a saved view
+// @Summary Run saved view
+// @Description Get a paginated list of tasks using a saved view's filter and sort
+// @Tags views
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "View ID"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /views/{id}/tasks [get]
+func (h *TaskHandler) RunView(c *gin.Context) {
+	user, view, ok := h.loadView(c)
+	if !ok {
+		return
+	}
+
+	filter := view.Filter
+	if view.Visibility == models.ViewVisibilityProject && view.ProjectID != nil {
+		filter.ProjectID = view.ProjectID
+	}
+
+	query := models.ScopeVisibleTasks(h.db, h.db.Where("tenant_id = ?", user.TenantID), user)
+	query = h.applyTaskFilter(query, &filter, user)
+
+	h.respondTaskPage(c, query, view.Sort, view.Order)
+}
+
+// loadView fetches the view in the path and checks the current user can see it
+func (h *TaskHandler) loadView(c *gin.Context) (*models.User, *models.SavedView, bool) {
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return nil, nil, false
+	}
+
+	viewID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid view ID"))
+		return nil, nil, false
+	}
+
+	var view models.SavedView
+	if err := h.db.
+		Preload("Owner").
+		Preload("Project").
+		Where("id = ? AND tenant_id = ?", viewID, user.TenantID).
+		First(&view).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("View not found"))
+			return nil, nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch saved view")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch view"))
+		return nil, nil, false
+	}
+
+	// Hide private views of other users entirely
+	if !view.CanView(user) {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("View not found"))
+		return nil, nil, false
+	}
+
+	// Project views are only shared with users who can read the project
+	if view.OwnerID != user.ID && view.Visibility == models.ViewVisibilityProject && view.ProjectID != nil {
+		role, err := projectRole(h.db, user, *view.ProjectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to check project permissions"))
+			return nil, nil, false
+		}
+		if !role.CanRead() {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("View not found"))
+			return nil, nil, false
+		}
+	}
+
+	return user, &view, true
+}
+
+// checkViewSharing validates the visibility of a view, returning an HTTP status and message on failure
+func (h *TaskHandler) checkViewSharing(user *models.User, view *models.SavedView) (int, string) {
+	switch view.Visibility {
+	case models.ViewVisibilityTenant:
+		if !user.CanManage() {
+			return http.StatusForbidden, "Only managers and admins can share views with the whole tenant"
+		}
+	case models.ViewVisibilityProject:
+		if view.ProjectID == nil {
+			return http.StatusBadRequest, "project_id is required for project views"
+		}
+		role, err := projectRole(h.db, user, *view.ProjectID)
+		if err != nil {
+			return http.StatusInternalServerError, "Failed to verify project"
+		}
+		if !role.CanRead() {
+			return http.StatusNotFound, "Project not found"
+		}
+	}
+	return 0, ""
+}
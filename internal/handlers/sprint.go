@@ -0,0 +1,839 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SprintHandler handles sprint planning and reporting
+type SprintHandler struct {
+	db        *gorm.DB
+	logger    *logger.Logger
+	validator *validator.Validator
+}
+
+// NewSprintHandler creates a new sprint handler
+func NewSprintHandler(db *gorm.DB, logger *logger.Logger) *SprintHandler {
+	return &SprintHandler{
+		db:        db,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+// VelocityEntry is the velocity of one closed sprint
+type VelocityEntry struct {
+	SprintID  uuid.UUID           `json:"sprint_id"`
+	Name      string              `json:"name"`
+	EndDate   time.Time           `json:"end_date"`
+	Unit      models.EstimateUnit `json:"unit"`
+	Committed float64             `json:"committed"`
+	Completed float64             `json:"completed"`
+}
+
+// ListSprints returns the sprints of a project
+// @Summary List sprints
+// @Description Get the sprints of a project, most recent first
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param status query string false "Filter by status"
+// @Success 200 {array} models.Sprint
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/sprints [get]
+func (h *SprintHandler) ListSprints(c *gin.Context) {
+	project, ok := h.loadProject(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	query := h.db.Where("tenant_id = ? AND project_id = ?", project.TenantID, project.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var sprints []models.Sprint
+	if err := query.Order("start_date DESC").Find(&sprints).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch sprints")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch sprints"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(sprints))
+}
+
+// CreateSprint plans a new sprint in a project
+// @Summary Create sprint
+// @Description Plan a new sprint in a project
+// @Tags sprints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param request body requests.CreateSprintRequest true "Sprint data"
+// @Success 201 {object} models.Sprint
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/sprints [post]
+func (h *SprintHandler) CreateSprint(c *gin.Context) {
+	project, ok := h.loadProject(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	var req requests.CreateSprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if !req.EndDate.After(req.StartDate) {
+		response.BadRequest(c, "End date must be after start date")
+		return
+	}
+
+	sprint := &models.Sprint{
+		TenantModel:  models.TenantModel{TenantID: project.TenantID},
+		ProjectID:    project.ID,
+		Name:         req.Name,
+		Goal:         req.Goal,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		Status:       models.SprintStatusPlanned,
+		EstimateUnit: req.EstimateUnit,
+	}
+	if sprint.EstimateUnit == "" {
+		sprint.EstimateUnit = models.EstimateUnitHours
+	}
+
+	if err := h.db.Create(sprint).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create sprint")
+		response.InternalServerError(c, "Failed to create sprint")
+		return
+	}
+
+	h.logger.WithField("sprint_id", sprint.ID).Info("Sprint created successfully")
+	response.Created(c, sprint, "Sprint created successfully")
+}
+
+// GetSprint returns a sprint with its tasks
+// @Summary Get sprint
+// @Description Get a sprint with its member tasks
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Success 200 {object} models.Sprint
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id} [get]
+func (h *SprintHandler) GetSprint(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	if err := h.db.
+		Preload("Tasks.Task.Assignee").
+		Preload("Project").
+		First(sprint, sprint.ID).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch sprint tasks")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch sprint"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(sprint))
+}
+
+// UpdateSprint updates a sprint that is not closed
+// @Summary Update sprint
+// @Description Update the name, goal, dates or estimate unit of a sprint
+// @Tags sprints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Param request body requests.UpdateSprintRequest true "Sprint update data"
+// @Success 200 {object} models.Sprint
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id} [put]
+func (h *SprintHandler) UpdateSprint(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.IsClosed() {
+		response.Conflict(c, "Closed sprints cannot be changed")
+		return
+	}
+
+	var req requests.UpdateSprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.Name != nil {
+		sprint.Name = *req.Name
+	}
+	if req.Goal != nil {
+		sprint.Goal = *req.Goal
+	}
+	if req.StartDate != nil {
+		sprint.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		sprint.EndDate = *req.EndDate
+	}
+	if req.EstimateUnit != nil {
+		sprint.EstimateUnit = *req.EstimateUnit
+	}
+
+	if !sprint.EndDate.After(sprint.StartDate) {
+		response.BadRequest(c, "End date must be after start date")
+		return
+	}
+
+	if err := h.db.Save(sprint).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update sprint")
+		response.InternalServerError(c, "Failed to update sprint")
+		return
+	}
+
+	if sprint.Status == models.SprintStatusActive {
+		h.snapshot(h.db, sprint)
+	}
+
+	response.Success(c, sprint, "Sprint updated successfully")
+}
+
+// DeleteSprint deletes a planned sprint
+// @Summary Delete sprint
+// @Description Delete a sprint that has not been started. Its tasks return to the backlog.
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id} [delete]
+func (h *SprintHandler) DeleteSprint(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.Status != models.SprintStatusPlanned {
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("Only planned sprints can be deleted"))
+		return
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sprint_id = ?", sprint.ID).Delete(&models.SprintTask{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(sprint).Error
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to delete sprint")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete sprint"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Sprint deleted successfully"))
+}
+
+// StartSprint activates a planned sprint
+// @Summary Start sprint
+// @Description Start a planned sprint and record its committed scope. A project can only have one active sprint.
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Success 200 {object} models.Sprint
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id}/start [post]
+func (h *SprintHandler) StartSprint(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.Status != models.SprintStatusPlanned {
+		response.Conflict(c, "Only planned sprints can be started")
+		return
+	}
+
+	var active int64
+	if err := h.db.Model(&models.Sprint{}).
+		Where("tenant_id = ? AND project_id = ? AND status = ?", sprint.TenantID, sprint.ProjectID, models.SprintStatusActive).
+		Count(&active).Error; err != nil {
+		response.InternalServerError(c, "Failed to start sprint")
+		return
+	}
+	if active > 0 {
+		response.Conflict(c, "The project already has an active sprint")
+		return
+	}
+
+	now := time.Now()
+	sprint.Status = models.SprintStatusActive
+	sprint.StartedAt = &now
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(sprint).Updates(map[string]interface{}{
+			"status":     sprint.Status,
+			"started_at": sprint.StartedAt,
+		}).Error; err != nil {
+			return err
+		}
+		// The first snapshot is the committed scope of the sprint
+		_, err := sprint.TakeSnapshot(tx, now)
+		return err
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to start sprint")
+		response.InternalServerError(c, "Failed to start sprint")
+		return
+	}
+
+	response.Success(c, sprint, "Sprint started successfully")
+}
+
+// CloseSprint closes an active sprint and produces its report
+// @Summary Close sprint
+// @Description Close a sprint, producing a report. Unfinished tasks are carried over to another sprint or returned to the backlog.
+// @Tags sprints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Param request body requests.CloseSprintRequest false "Carry-over options"
+// @Success 200 {object} models.SprintReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id}/close [post]
+func (h *SprintHandler) CloseSprint(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.Status != models.SprintStatusActive {
+		response.Conflict(c, "Only active sprints can be closed")
+		return
+	}
+
+	var req requests.CloseSprintRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request data", err.Error())
+			return
+		}
+	}
+
+	var next *models.Sprint
+	if req.CarryOverTo != nil {
+		next = &models.Sprint{}
+		if err := h.db.
+			Where("id = ? AND tenant_id = ? AND project_id = ? AND status <> ?",
+				*req.CarryOverTo, sprint.TenantID, sprint.ProjectID, models.SprintStatusClosed).
+			First(next).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				response.BadRequest(c, "Carry-over sprint must be an open sprint of the same project")
+				return
+			}
+			response.InternalServerError(c, "Failed to fetch carry-over sprint")
+			return
+		}
+		if next.ID == sprint.ID {
+			response.BadRequest(c, "A sprint cannot carry over into itself")
+			return
+		}
+	}
+
+	var members []models.SprintTask
+	if err := h.db.Preload("Task").Where("sprint_id = ?", sprint.ID).Find(&members).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch sprint tasks")
+		response.InternalServerError(c, "Failed to close sprint")
+		return
+	}
+
+	now := time.Now()
+	report, err := h.buildReport(sprint, members, now)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build sprint report")
+		response.InternalServerError(c, "Failed to close sprint")
+		return
+	}
+	if next != nil {
+		report.CarriedOverTo = &next.ID
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := sprint.TakeSnapshot(tx, now); err != nil {
+			return err
+		}
+
+		if next != nil && len(report.IncompleteTasks) > 0 {
+			carried := make([]models.SprintTask, len(report.IncompleteTasks))
+			for i, taskID := range report.IncompleteTasks {
+				carried[i] = models.SprintTask{SprintID: next.ID, TaskID: taskID, CarriedOverFrom: &sprint.ID}
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&carried).Error; err != nil {
+				return err
+			}
+		}
+
+		sprint.Status = models.SprintStatusClosed
+		sprint.ClosedAt = &now
+		sprint.ClosedBy = &userID
+		sprint.Report = report
+		return tx.Model(sprint).Select("status", "closed_at", "closed_by", "report").Updates(sprint).Error
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to close sprint")
+		response.InternalServerError(c, "Failed to close sprint")
+		return
+	}
+
+	if next != nil && next.Status == models.SprintStatusActive {
+		h.snapshot(h.db, next)
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"sprint_id": sprint.ID,
+		"completed": report.Completed,
+	}).Info("Sprint closed successfully")
+
+	response.Success(c, report, "Sprint closed successfully")
+}
+
+// GetSprintReport returns the report of a closed sprint
+// @Summary Get sprint report
+// @Description Get the report produced when a sprint was closed
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Success 200 {object} models.SprintReport
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sprints/{id}/report [get]
+func (h *SprintHandler) GetSprintReport(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	if sprint.Report == nil {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("Sprint has not been closed yet"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(sprint.Report))
+}
+
+// AddSprintTasks adds tasks to a sprint
+// @Summary Add tasks to sprint
+// @Description Add project tasks to a sprint. A task can only belong to one open sprint at a time.
+// @Tags sprints
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Param request body requests.SprintTasksRequest true "Task IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id}/tasks [post]
+func (h *SprintHandler) AddSprintTasks(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.IsClosed() {
+		response.Conflict(c, "Closed sprints cannot be changed")
+		return
+	}
+
+	var req requests.SprintTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	var count int64
+	if err := h.db.Model(&models.Task{}).
+		Where("id IN ? AND tenant_id = ? AND project_id = ?", req.TaskIDs, sprint.TenantID, sprint.ProjectID).
+		Count(&count).Error; err != nil {
+		response.InternalServerError(c, "Failed to verify tasks")
+		return
+	}
+	if int(count) != len(uniqueIDs(req.TaskIDs)) {
+		response.BadRequest(c, "All tasks must belong to the sprint's project")
+		return
+	}
+
+	var busy int64
+	if err := h.db.Model(&models.SprintTask{}).
+		Joins("JOIN sprints ON sprints.id = sprint_tasks.sprint_id").
+		Where("sprint_tasks.task_id IN ? AND sprint_tasks.sprint_id <> ? AND sprints.status <> ? AND sprints.deleted_at IS NULL",
+			req.TaskIDs, sprint.ID, models.SprintStatusClosed).
+		Count(&busy).Error; err != nil {
+		response.InternalServerError(c, "Failed to verify tasks")
+		return
+	}
+	if busy > 0 {
+		response.Conflict(c, "Some tasks already belong to another open sprint")
+		return
+	}
+
+	members := make([]models.SprintTask, 0, len(req.TaskIDs))
+	for _, taskID := range uniqueIDs(req.TaskIDs) {
+		members = append(members, models.SprintTask{SprintID: sprint.ID, TaskID: taskID})
+	}
+	if err := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to add sprint tasks")
+		response.InternalServerError(c, "Failed to add tasks")
+		return
+	}
+
+	if sprint.Status == models.SprintStatusActive {
+		h.snapshot(h.db, sprint)
+	}
+
+	response.Success(c, nil, "Tasks added to sprint successfully")
+}
+
+// RemoveSprintTask removes a task from a sprint
+// @Summary Remove task from sprint
+// @Description Return a task from a sprint to the backlog
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Param task_id path string true "Task ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id}/tasks/{task_id} [delete]
+func (h *SprintHandler) RemoveSprintTask(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+
+	if sprint.IsClosed() {
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("Closed sprints cannot be changed"))
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("task_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	result := h.db.Where("sprint_id = ? AND task_id = ?", sprint.ID, taskID).Delete(&models.SprintTask{})
+	if result.Error != nil {
+		h.logger.WithError(result.Error).Error("Failed to remove sprint task")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to remove task"))
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task is not in this sprint"))
+		return
+	}
+
+	if sprint.Status == models.SprintStatusActive {
+		h.snapshot(h.db, sprint)
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Task removed from sprint successfully"))
+}
+
+// GetBurndown returns the burndown and burnup series of a sprint
+// @Summary Get sprint burndown
+// @Description Get daily scope, completed and remaining work of a sprint with the ideal burndown line
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sprint ID"
+// @Success 200 {array} models.BurndownPoint
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /sprints/{id}/burndown [get]
+func (h *SprintHandler) GetBurndown(c *gin.Context) {
+	sprint, ok := h.loadSprint(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	var snapshots []models.SprintSnapshot
+	if err := h.db.Where("sprint_id = ?", sprint.ID).Order("day ASC").Find(&snapshots).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch sprint snapshots")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch burndown"))
+		return
+	}
+
+	// Today's point is measured live between scheduled snapshots but only
+	// stored by the snapshot job
+	now := time.Now()
+	if sprint.Status == models.SprintStatusActive {
+		tasks, err := sprint.MemberTasks(h.db)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to fetch sprint tasks")
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch burndown"))
+			return
+		}
+		snapshots = append(snapshots, *sprint.Measure(tasks, now))
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
+		"unit":   sprint.EstimateUnit,
+		"points": sprint.Burndown(snapshots, now),
+	}))
+}
+
+// GetVelocity returns the velocity of a project's closed sprints
+// @Summary Get project velocity
+// @Description Get committed and completed work of the most recent closed sprints and their average
+// @Tags sprints
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param limit query int false "Number of sprints" default(6)
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/velocity [get]
+func (h *SprintHandler) GetVelocity(c *gin.Context) {
+	project, ok := h.loadProject(c, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "6"))
+	if limit < 1 || limit > 50 {
+		limit = 6
+	}
+
+	var sprints []models.Sprint
+	if err := h.db.
+		Where("tenant_id = ? AND project_id = ? AND status = ?", project.TenantID, project.ID, models.SprintStatusClosed).
+		Order("end_date DESC").
+		Limit(limit).
+		Find(&sprints).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch closed sprints")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch velocity"))
+		return
+	}
+
+	entries := make([]VelocityEntry, 0, len(sprints))
+	var total float64
+	for i := len(sprints) - 1; i >= 0; i-- {
+		sprint := sprints[i]
+		entry := VelocityEntry{
+			SprintID: sprint.ID,
+			Name:     sprint.Name,
+			EndDate:  sprint.EndDate,
+			Unit:     sprint.EstimateUnit,
+		}
+		if sprint.Report != nil {
+			entry.Committed = sprint.Report.CommittedScope
+			entry.Completed = sprint.Report.Completed
+		}
+		total += entry.Completed
+		entries = append(entries, entry)
+	}
+
+	average := 0.0
+	if len(entries) > 0 {
+		average = total / float64(len(entries))
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
+		"sprints": entries,
+		"average": average,
+	}))
+}
+
+// buildReport summarises a sprint from its members and committed snapshot
+func (h *SprintHandler) buildReport(sprint *models.Sprint, members []models.SprintTask, now time.Time) (*models.SprintReport, error) {
+	tasks := make([]models.Task, len(members))
+	for i := range members {
+		tasks[i] = members[i].Task
+	}
+	final := sprint.Measure(tasks, now)
+
+	report := &models.SprintReport{
+		Unit:            sprint.EstimateUnit,
+		FinalScope:      final.Scope,
+		Completed:       final.Completed,
+		CompletedTasks:  []uuid.UUID{},
+		IncompleteTasks: []uuid.UUID{},
+		DurationDays:    int(models.SprintDay(sprint.EndDate).Sub(models.SprintDay(sprint.StartDate)).Hours() / 24),
+	}
+
+	var committed models.SprintSnapshot
+	err := h.db.Where("sprint_id = ?", sprint.ID).Order("day ASC").First(&committed).Error
+	switch {
+	case err == nil:
+		report.CommittedScope = committed.Scope
+	case err == gorm.ErrRecordNotFound:
+		report.CommittedScope = final.Scope
+	default:
+		return nil, err
+	}
+
+	startedAt := sprint.StartDate
+	if sprint.StartedAt != nil {
+		startedAt = *sprint.StartedAt
+	}
+	for _, member := range members {
+		switch member.Task.Status {
+		case models.TaskStatusCompleted:
+			report.CompletedTasks = append(report.CompletedTasks, member.TaskID)
+		case models.TaskStatusCanceled:
+		default:
+			report.IncompleteTasks = append(report.IncompleteTasks, member.TaskID)
+		}
+		if member.AddedAt.After(startedAt) {
+			report.AddedTasks++
+		}
+	}
+
+	if report.CommittedScope > 0 {
+		report.CompletionRate = report.Completed / report.CommittedScope * 100
+	}
+
+	return report, nil
+}
+
+// snapshot refreshes today's snapshot of a sprint, logging failures
+func (h *SprintHandler) snapshot(db *gorm.DB, sprint *models.Sprint) {
+	if _, err := sprint.TakeSnapshot(db, time.Now()); err != nil {
+		h.logger.WithError(err).WithField("sprint_id", sprint.ID).Warn("Failed to take sprint snapshot")
+	}
+}
+
+// loadSprint fetches the sprint in the path and checks the current user holds
+// at least min in its project
+func (h *SprintHandler) loadSprint(c *gin.Context, min models.ProjectRole) (*models.Sprint, bool) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return nil, false
+	}
+
+	sprintID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid sprint ID"))
+		return nil, false
+	}
+
+	var sprint models.Sprint
+	if err := h.db.Where("id = ? AND tenant_id = ?", sprintID, tenantID).First(&sprint).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Sprint not found"))
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch sprint")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch sprint"))
+		return nil, false
+	}
+
+	if _, _, _, ok := authorizeProject(c, h.db, sprint.ProjectID, min); !ok {
+		return nil, false
+	}
+
+	return &sprint, true
+}
+
+// loadProject fetches the project in the path and checks the current user
+// holds at least min in it
+func (h *SprintHandler) loadProject(c *gin.Context, min models.ProjectRole) (*models.Project, bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+		return nil, false
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, min)
+	return project, ok
+}
+
+// uniqueIDs returns ids without duplicates, keeping their order
+func uniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/websocket"
+)
+
+const (
+	// streamHeartbeat is how often an idle event stream sends a comment, so
+	// proxies keep the connection open
+	streamHeartbeat = 25 * time.Second
+
+	// streamRetry is how long browsers wait before reconnecting, in
+	// milliseconds
+	streamRetry = 3000
+)
+
+// StreamEvents streams the real-time events of the WebSocket hub as
+// Server-Sent Events
+// @Summary Event stream
+// @Description Stream the events a WebSocket connection subscribed to the same topics would receive, as Server-Sent Events for clients that cannot hold a WebSocket. Each event has the hub message as data, its type as event name and its sequence number as id. Reconnecting with the Last-Event-ID header, or the last_event_id query parameter, replays the events missed in the last few minutes; when they are no longer kept, or an event relayed by another node arrives after later ones, a "resync" event is sent and the client should reload its state. A comment is sent every 25 seconds to keep the connection open.
+// @Tags websocket
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param Last-Event-ID header string false "Last event ID received"
+// @Param last_event_id query string false "Last event ID received, for clients that cannot set headers"
+// @Param topics query string false "Comma-separated topics to receive besides the user's own, e.g. tenant,project:<id>,task:<id>"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Router /events [get]
+func (h *WebSocketHandler) StreamEvents(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not found"))
+		return
+	}
+	user, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return
+	}
+
+	var topics []string
+	for _, topic := range strings.Split(c.Query("topics"), ",") {
+		if topic = strings.TrimSpace(topic); topic == "" {
+			continue
+		}
+		normalized, err := websocket.NormalizeTopic(topic)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid topic: "+topic))
+			return
+		}
+		if err := h.authorizeTopic(user, normalized); err != nil {
+			c.JSON(http.StatusForbidden, middleware.ErrorResponse("Cannot subscribe to "+topic+": "+err.Error()))
+			return
+		}
+		topics = append(topics, normalized)
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	// Register before replaying, so nothing published in between is lost
+	stream := h.hub.NewStream(tenantID, userID, user, topics, h.authorizeTopic, c.ClientIP(), c.Request.UserAgent())
+	defer stream.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // stop nginx buffering the stream
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+
+	// Replayed events arrive again from the hub and are not sent twice
+	replayed := map[uint64]bool{}
+	var last uint64 // the highest sequence number sent
+	if lastEventID != "" {
+		seq, err := strconv.ParseUint(lastEventID, 10, 64)
+		var missed []*websocket.Message
+		complete := false
+		if err == nil {
+			missed, complete = stream.Since(seq)
+		}
+		if !complete {
+			writeStreamEvent(c.Writer, "", "resync", []byte("{}"))
+		} else {
+			for _, message := range missed {
+				data, err := json.Marshal(message)
+				if err != nil {
+					h.logger.WithError(err).Error("Failed to marshal missed event")
+					continue
+				}
+				writeStreamEvent(c.Writer, strconv.FormatUint(message.Seq, 10), string(message.Type), data)
+				replayed[message.Seq] = true
+				last = message.Seq
+			}
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case <-h.closed:
+			return
+
+		case data, ok := <-stream.Messages():
+			if !ok {
+				// The hub dropped the stream for falling behind, so the
+				// client reconnects and resumes from its last event
+				return
+			}
+
+			var message struct {
+				Type websocket.MessageType `json:"type"`
+				Seq  uint64                `json:"seq"`
+			}
+			if err := json.Unmarshal(data, &message); err != nil {
+				continue
+			}
+			if replayed[message.Seq] {
+				continue
+			}
+			id := ""
+			switch {
+			case message.Seq == 0:
+			case message.Seq <= last:
+				// Another node's message arrived after later ones were
+				// sent, so resuming from the last event would miss it. It
+				// is sent without an id and the client reloads its state.
+				writeStreamEvent(c.Writer, "", "resync", []byte("{}"))
+			default:
+				id = strconv.FormatUint(message.Seq, 10)
+				last = message.Seq
+			}
+			writeStreamEvent(c.Writer, id, string(message.Type), data)
+			c.Writer.Flush()
+
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent writes one Server-Sent Event. Messages are single-line
+// JSON, so data needs no splitting.
+func writeStreamEvent(w io.Writer, id, event string, data []byte) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
+
+// CloseStreams ends the open event streams, so the server can shut down
+// without waiting for them. Clients reconnect on their own.
+func (h *WebSocketHandler) CloseStreams() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/errors"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListTags returns the tags of the current tenant
+// @Summary List tags
+// @Description Get the tags of the current tenant ordered by name, so nested tags follow their parent
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search tag names"
+// @Success 200 {array} models.Tag
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags [get]
+func (h *TaskHandler) ListTags(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+
+	query := h.db.Where("tenant_id = ?", tenantID)
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	var tags []models.Tag
+	if err := query.Order("LOWER(name) ASC").Find(&tags).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch tags")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(tags))
+}
+
+// CreateTag creates a new tag
+// @Summary Create tag
+// @Description Create a tag. Names are unique per tenant ignoring case; "area/backend" creates a nested tag and any missing parents.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateTagRequest true "Tag data"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags [post]
+func (h *TaskHandler) CreateTag(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+
+	var req requests.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request", err.Error()))
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	name, err := models.NormalizeTagName(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag name", err.Error()))
+		return
+	}
+
+	tag := &models.Tag{
+		TenantModel: models.TenantModel{TenantID: tenantID},
+		Name:        name,
+		Color:       req.Color,
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := models.FindTagByName(tx, tenantID, name)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return errors.Conflict("A tag named \""+existing.Name+"\" already exists", nil)
+		}
+
+		if tag.ParentID, err = ensureTagParent(tx, tenantID, name); err != nil {
+			return err
+		}
+		return tx.Create(tag).Error
+	})
+	if err != nil {
+		h.respondTagError(c, err, "Failed to create tag")
+		return
+	}
+
+	c.JSON(http.StatusCreated, middleware.SuccessResponse(tag, "Tag created successfully"))
+}
+
+// GetTag returns a tag
+// @Summary Get tag
+// @Description Get a tag by ID
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags/{id} [get]
+func (h *TaskHandler) GetTag(c *gin.Context) {
+	tag, ok := h.loadTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(tag))
+}
+
+// UpdateTag renames or recolors a tag
+// @Summary Update tag
+// @Description Update a tag. Renaming a tag also renames the tags nested below it.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Param request body requests.UpdateTagRequest true "Tag data"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags/{id} [put]
+func (h *TaskHandler) UpdateTag(c *gin.Context) {
+	tag, ok := h.loadTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request data"))
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	var name string
+	if req.Name != nil {
+		var err error
+		if name, err = models.NormalizeTagName(*req.Name); err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag name", err.Error()))
+			return
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if name != "" && name != tag.Name {
+			if err := renameTagTree(tx, tag, name); err != nil {
+				return err
+			}
+		}
+		if req.Color != nil {
+			tag.Color = *req.Color
+			return tx.Model(tag).Update("color", tag.Color).Error
+		}
+		return nil
+	})
+	if err != nil {
+		h.respondTagError(c, err, "Failed to update tag")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(tag, "Tag updated successfully"))
+}
+
+// DeleteTag deletes a tag
+// @Summary Delete tag
+// @Description Delete a tag without nested tags
+// @Tags tags
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags/{id} [delete]
+func (h *TaskHandler) DeleteTag(c *gin.Context) {
+	tag, ok := h.loadTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	descendants, err := models.TagDescendants(h.db, tag)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch nested tags"))
+		return
+	}
+	if len(descendants) > 0 {
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("Tag has nested tags; delete or merge them first"))
+		return
+	}
+
+	if err := h.db.Delete(tag).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to delete tag")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete tag"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Tag deleted successfully"))
+}
+
+// MergeTag merges a tag into another tag
+// @Summary Merge tag
+// @Description Move every task of a tag onto the target tag and delete it. Nested tags move below the target, merging with same-named tags there. Requires manager or admin.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Tag ID to merge away"
+// @Param request body requests.MergeTagRequest true "Target tag"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tags/{id}/merge [post]
+func (h *TaskHandler) MergeTag(c *gin.Context) {
+	source, ok := h.loadTag(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req requests.MergeTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request data"))
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.TargetTagID == source.ID {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("A tag cannot be merged into itself"))
+		return
+	}
+
+	target, ok := h.loadTag(c, req.TargetTagID.String())
+	if !ok {
+		return
+	}
+
+	var moved int64
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		moved, err = mergeTags(tx, source, target)
+		return err
+	})
+	if err != nil {
+		h.respondTagError(c, err, "Failed to merge tags")
+		return
+	}
+
+	h.logger.WithField("source_tag_id", source.ID).
+		WithField("target_tag_id", target.ID).
+		WithField("moved", moved).
+		Info("Tags merged")
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
+		"tag":         target,
+		"moved_tasks": moved,
+	}, "Tags merged successfully"))
+}
+
+// loadTag fetches a tag of the current tenant by ID
+func (h *TaskHandler) loadTag(c *gin.Context, id string) (*models.Tag, bool) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return nil, false
+	}
+
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag ID"))
+		return nil, false
+	}
+
+	var tag models.Tag
+	if err := h.db.Where("id = ? AND tenant_id = ?", tagID, tenantID).First(&tag).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Tag not found"))
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch tag")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tag"))
+		return nil, false
+	}
+
+	return &tag, true
+}
+
+// respondTagError writes application errors raised by tag operations, or a
+// generic failure for anything else
+func (h *TaskHandler) respondTagError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		c.JSON(appErr.Code, middleware.ErrorResponse(appErr.Message, appErr.Details))
+		return
+	}
+	if models.IsTagNameConflict(err) {
+		// Another request created the name after it was checked
+		c.JSON(http.StatusConflict, middleware.ErrorResponse("A tag with this name already exists"))
+		return
+	}
+	h.logger.WithError(err).Error(message)
+	c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(message))
+}
+
+// ensureTagParent returns the parent of a nested tag name, creating it and its
+// own missing parents. Top-level names have no parent.
+func ensureTagParent(tx *gorm.DB, tenantID uuid.UUID, name string) (*uuid.UUID, error) {
+	parentName := models.TagParentName(name)
+	if parentName == "" {
+		return nil, nil
+	}
+
+	parent, err := models.FindTagByName(tx, tenantID, parentName)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		parent = &models.Tag{
+			TenantModel: models.TenantModel{TenantID: tenantID},
+			Name:        parentName,
+		}
+		if parent.ParentID, err = ensureTagParent(tx, tenantID, parentName); err != nil {
+			return nil, err
+		}
+		if err := tx.Create(parent).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &parent.ID, nil
+}
+
+// renameTagTree renames a tag and rewrites the names of the tags nested below it
+func renameTagTree(tx *gorm.DB, tag *models.Tag, name string) error {
+	if models.IsTagDescendant(name, tag.Name) {
+		return errors.BadRequest("A tag cannot be moved below itself", nil)
+	}
+
+	existing, err := models.FindTagByName(tx, tag.TenantID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != tag.ID {
+		return errors.Conflict("A tag named \""+existing.Name+"\" already exists", nil)
+	}
+
+	descendants, err := models.TagDescendants(tx, tag)
+	if err != nil {
+		return err
+	}
+
+	// Tags already nested below the new name would clash with the moved ones
+	if !strings.EqualFold(name, tag.Name) {
+		var clashes int64
+		if err := tx.Model(&models.Tag{}).
+			Where("tenant_id = ? AND LEFT(LOWER(name), ?) = LOWER(?)", tag.TenantID, len([]rune(name))+1, name+models.TagPathSeparator).
+			Count(&clashes).Error; err != nil {
+			return err
+		}
+		if clashes > 0 {
+			return errors.Conflict("Tags already exist below \""+name+"\"; merge the tags instead", nil)
+		}
+	}
+
+	parentID, err := ensureTagParent(tx, tag.TenantID, name)
+	if err != nil {
+		return err
+	}
+
+	prefixLength := len([]rune(tag.Name))
+	for i := range descendants {
+		descendantName := name + string([]rune(descendants[i].Name)[prefixLength:])
+		if err := tx.Model(&descendants[i]).Update("name", descendantName).Error; err != nil {
+			return err
+		}
+	}
+
+	tag.Name = name
+	tag.ParentID = parentID
+	return tx.Model(tag).Select("name", "parent_id").Updates(tag).Error
+}
+
+// mergeTags moves the tasks and nested tags of source onto target and deletes
+// source, returning the number of task assignments moved
+func mergeTags(tx *gorm.DB, source, target *models.Tag) (int64, error) {
+	if models.IsTagDescendant(target.Name, source.Name) {
+		return 0, errors.BadRequest("A tag cannot be merged into one of its nested tags", nil)
+	}
+
+	var taskIDs []uuid.UUID
+	if err := tx.Model(&models.TaskTag{}).Where("tag_id = ?", source.ID).Pluck("task_id", &taskIDs).Error; err != nil {
+		return 0, err
+	}
+	if len(taskIDs) > 0 {
+		rows := make([]models.TaskTag, len(taskIDs))
+		for i, taskID := range taskIDs {
+			rows[i] = models.TaskTag{TaskID: taskID, TagID: target.ID}
+		}
+		// Tasks carrying both tags keep a single assignment
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(rows, 500).Error; err != nil {
+			return 0, err
+		}
+		if err := tx.Where("tag_id = ?", source.ID).Delete(&models.TaskTag{}).Error; err != nil {
+			return 0, err
+		}
+	}
+	moved := int64(len(taskIDs))
+
+	descendants, err := models.TagDescendants(tx, source)
+	if err != nil {
+		return 0, err
+	}
+	for i := range descendants {
+		child := &descendants[i]
+		if !strings.EqualFold(models.TagParentName(child.Name), source.Name) {
+			continue
+		}
+
+		name := target.Name + models.TagPathSeparator + models.TagLeafName(child.Name)
+		existing, err := models.FindTagByName(tx, target.TenantID, name)
+		if err != nil {
+			return 0, err
+		}
+		if existing != nil {
+			n, err := mergeTags(tx, child, existing)
+			if err != nil {
+				return 0, err
+			}
+			moved += n
+			continue
+		}
+		if err := renameTagTree(tx, child, name); err != nil {
+			return 0, err
+		}
+	}
+
+	return moved, tx.Delete(source).Error
+}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
 	"github.com/drazan344/taskflow-go/internal/middleware"
 	"github.com/drazan344/taskflow-go/internal/models"
 	"github.com/drazan344/taskflow-go/internal/requests"
@@ -21,14 +23,16 @@ type TaskHandler struct {
 	db        *gorm.DB
 	logger    *logger.Logger
 	validator *validator.Validator
+	events    events.Publisher
 }
 
 // NewTaskHandler creates a new task handler
-func NewTaskHandler(db *gorm.DB, logger *logger.Logger) *TaskHandler {
+func NewTaskHandler(db *gorm.DB, logger *logger.Logger, publisher events.Publisher) *TaskHandler {
 	return &TaskHandler{
 		db:        db,
 		logger:    logger,
 		validator: validator.New(),
+		events:    publisher,
 	}
 }
 
@@ -44,17 +48,34 @@ func NewTaskHandler(db *gorm.DB, logger *logger.Logger) *TaskHandler {
 // @Param status query string false "Filter by status"
 // @Param assignee_id query string false "Filter by assignee ID"
 // @Param project_id query string false "Filter by project ID"
+// @Param priority query string false "Filter by priority (comma separated)"
+// @Param tag_id query string false "Filter by tag ID (comma separated)"
+// @Param search query string false "Search title and description"
+// @Param include_archived query bool false "Include tasks of archived projects"
+// @Param sort query string false "Sort field" default(created_at)
+// @Param order query string false "Sort order" default(desc)
 // @Success 200 {object} map[string]interface{}
 // @Failure 401 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Router /tasks [get]
 func (h *TaskHandler) ListTasks(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+	user, err := middleware.GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
 		return
 	}
 
+	filter := parseTaskFilter(c)
+
+	// Build query, hiding tasks of projects the user cannot read
+	query := models.ScopeVisibleTasks(h.db, h.db.Where("tenant_id = ?", user.TenantID), user)
+	query = h.applyTaskFilter(query, &filter, user)
+
+	h.respondTaskPage(c, query, c.DefaultQuery("sort", "created_at"), c.DefaultQuery("order", "desc"))
+}
+
+// respondTaskPage paginates, sorts and renders a filtered task query
+func (h *TaskHandler) respondTaskPage(c *gin.Context, query *gorm.DB, sort, order string) {
 	// Parse pagination parameters
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "20"))
@@ -68,29 +89,11 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 
 	offset := (page - 1) * perPage
 
-	// Build query
-	query := h.db.Where("tenant_id = ?", tenantID)
-
-	// Apply filters
-	if status := c.Query("status"); status != "" {
-		query = query.Where("status = ?", status)
-	}
-	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
-		if id, err := uuid.Parse(assigneeID); err == nil {
-			query = query.Where("assignee_id = ?", id)
-		}
-	}
-	if projectID := c.Query("project_id"); projectID != "" {
-		if id, err := uuid.Parse(projectID); err == nil {
-			query = query.Where("project_id = ?", id)
-		}
-	}
-
 	var tasks []models.Task
 	var total int64
 
 	// Get total count
-	if err := query.Model(&models.Task{}).Count(&total).Error; err != nil {
+	if err := query.Session(&gorm.Session{}).Model(&models.Task{}).Count(&total).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to count tasks")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tasks"))
 		return
@@ -104,7 +107,7 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		Preload("Tags").
 		Offset(offset).
 		Limit(perPage).
-		Order("created_at DESC").
+		Order(taskOrderClause(sort, order)).
 		Find(&tasks).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to fetch tasks")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tasks"))
@@ -163,7 +166,40 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		ProjectID:      req.ProjectID,
 		ParentID:       req.ParentID,
 		EstimatedHours: req.EstimatedHours,
+		StoryPoints:    req.StoryPoints,
+		StartDate:      req.StartDate,
 		DueDate:        req.DueDate,
+		IsMilestone:    req.IsMilestone,
+	}
+
+	if task.StartDate != nil && task.DueDate != nil && task.DueDate.Before(*task.StartDate) {
+		response.BadRequest(c, "Due date cannot be before start date")
+		return
+	}
+
+	if req.ProjectID != nil {
+		user, err := middleware.GetCurrentUser(c)
+		if err != nil {
+			response.Unauthorized(c, "User not authenticated")
+			return
+		}
+		project, role, err := projectAccess(h.db, user, *req.ProjectID)
+		if err != nil {
+			response.InternalServerError(c, "Failed to check project permissions")
+			return
+		}
+		if !role.CanRead() {
+			response.NotFound(c, "Project not found")
+			return
+		}
+		if project.IsArchived() {
+			response.Conflict(c, "Project is archived")
+			return
+		}
+		if !role.CanEdit() {
+			response.Forbidden(c, "Insufficient project permissions")
+			return
+		}
 	}
 
 	if err := h.db.Create(task).Error; err != nil {
@@ -198,6 +234,8 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		h.logger.WithError(err).Warn("Failed to reload task with relationships")
 	}
 
+	publishEvent(c, h.events, h.logger, tenantID, userID, events.TaskCreated{Task: events.NewTaskSnapshot(task)})
+
 	h.logger.WithField("task_id", task.ID).Info("Task created successfully")
 	response.Created(c, task, "Task created successfully")
 }
@@ -215,15 +253,14 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{}
 // @Router /tasks/{id} [get]
 func (h *TaskHandler) GetTask(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+	taskID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
 		return
 	}
 
-	taskID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+	found, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
@@ -235,17 +272,22 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 		Preload("Tags").
 		Preload("Comments.User").
 		Preload("Attachments").
-		Where("id = ? AND tenant_id = ?", taskID, tenantID).
-		First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
-			return
-		}
+		Preload("Watchers.User").
+		Preload("Checklist", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		First(&task, found.ID).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to fetch task")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch task"))
 		return
 	}
 
+	relations, err := h.loadTaskRelations(user, task.ID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to load task relations")
+	}
+	task.Relations = relations
+
 	c.JSON(http.StatusOK, middleware.SuccessResponse(task))
 }
 
@@ -265,26 +307,14 @@ func (h *TaskHandler) GetTask(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{}
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
 	taskID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
 		return
 	}
 
-	var task models.Task
-	if err := h.db.Where("id = ? AND tenant_id = ?", taskID, tenantID).First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
-			return
-		}
-		h.logger.WithError(err).Error("Failed to fetch task")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch task"))
+	task, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleEditor)
+	if !ok {
 		return
 	}
 
@@ -294,8 +324,32 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	// Moving a task requires edit rights in the destination project too
+	if value, ok := updateData["project_id"].(string); ok && value != "" {
+		projectID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+			return
+		}
+		project, role, err := projectAccess(h.db, user, projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to check project permissions"))
+			return
+		}
+		if project != nil && project.IsArchived() && role.CanRead() {
+			c.JSON(http.StatusConflict, middleware.ErrorResponse("Destination project is archived"))
+			return
+		}
+		if !role.CanEdit() {
+			c.JSON(http.StatusForbidden, middleware.ErrorResponse("Insufficient permissions in the destination project"))
+			return
+		}
+	}
+
+	before := events.NewTaskSnapshot(task)
+
 	// Update allowed fields
-	if err := h.db.Model(&task).Updates(updateData).Error; err != nil {
+	if err := h.db.Model(task).Updates(updateData).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to update task")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to update task"))
 		return
@@ -307,10 +361,15 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		Preload("Assignee").
 		Preload("Project").
 		Preload("Tags").
-		First(&task, task.ID).Error; err != nil {
+		First(task, task.ID).Error; err != nil {
 		h.logger.WithError(err).Warn("Failed to reload task with relationships")
 	}
 
+	after := events.NewTaskSnapshot(task)
+	if changes := events.Diff(before, after); len(changes) > 0 {
+		publishEvent(c, h.events, h.logger, task.TenantID, user.ID, events.TaskUpdated{Task: after, Changes: changes})
+	}
+
 	c.JSON(http.StatusOK, middleware.SuccessResponse(task, "Task updated successfully"))
 }
 
@@ -327,35 +386,25 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{}
 // @Router /tasks/{id} [delete]
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
 	taskID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
 		return
 	}
 
-	var task models.Task
-	if err := h.db.Where("id = ? AND tenant_id = ?", taskID, tenantID).First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
-			return
-		}
-		h.logger.WithError(err).Error("Failed to fetch task")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch task"))
+	task, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleEditor)
+	if !ok {
 		return
 	}
 
-	if err := h.db.Delete(&task).Error; err != nil {
+	if err := h.db.Delete(task).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to delete task")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete task"))
 		return
 	}
 
+	publishEvent(c, h.events, h.logger, task.TenantID, user.ID, events.TaskDeleted{Task: events.NewTaskSnapshot(task)})
+
 	h.logger.WithField("task_id", taskID).Info("Task deleted successfully")
 
 	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Task deleted successfully"))
@@ -368,32 +417,15 @@ type CreateCommentRequest struct {
 
 // Comment-related methods
 func (h *TaskHandler) AddComment(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
-	userID, err := middleware.GetCurrentUserID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
-		return
-	}
-
 	taskID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
 		return
 	}
 
-	// Verify task exists and belongs to tenant
-	var task models.Task
-	if err := h.db.Where("id = ? AND tenant_id = ?", taskID, tenantID).First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to verify task"))
+	// Verify task exists, belongs to tenant and the user may comment on it
+	task, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleCommenter)
+	if !ok {
 		return
 	}
 
@@ -404,9 +436,10 @@ func (h *TaskHandler) AddComment(c *gin.Context) {
 	}
 
 	comment := &models.TaskComment{
-		TaskID:  taskID,
-		UserID:  userID,
-		Content: req.Content,
+		TenantModel: models.TenantModel{TenantID: task.TenantID},
+		TaskID:      task.ID,
+		UserID:      user.ID,
+		Content:     req.Content,
 	}
 
 	if err := h.db.Create(comment).Error; err != nil {
@@ -420,30 +453,23 @@ func (h *TaskHandler) AddComment(c *gin.Context) {
 		h.logger.WithError(err).Warn("Failed to reload comment with user")
 	}
 
+	publishEvent(c, h.events, h.logger, task.TenantID, user.ID, events.CommentAdded{
+		Task:    events.NewTaskSnapshot(task),
+		Comment: events.NewCommentSnapshot(comment),
+	})
+
 	c.JSON(http.StatusCreated, middleware.SuccessResponse(comment, "Comment added successfully"))
 }
 
 func (h *TaskHandler) ListComments(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
 	taskID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
 		return
 	}
 
-	// Verify task exists and belongs to tenant
-	var task models.Task
-	if err := h.db.Where("id = ? AND tenant_id = ?", taskID, tenantID).First(&task).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Task not found"))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to verify task"))
+	// Verify task exists, belongs to tenant and is readable by the user
+	if _, _, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleViewer); !ok {
 		return
 	}
 
@@ -462,27 +488,97 @@ func (h *TaskHandler) ListComments(c *gin.Context) {
 }
 
 func (h *TaskHandler) AddAttachment(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	if _, _, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleEditor); !ok {
+		return
+	}
+
 	c.JSON(http.StatusNotImplemented, middleware.ErrorResponse("Not implemented yet"))
 }
 
 func (h *TaskHandler) ListAttachments(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	if _, _, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleViewer); !ok {
+		return
+	}
+
 	c.JSON(http.StatusNotImplemented, middleware.ErrorResponse("Not implemented yet"))
 }
 
 func (h *TaskHandler) DeleteAttachment(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		return
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid attachment ID"))
+		return
+	}
+
+	var attachment models.TaskAttachment
+	if err := h.db.Where("id = ? AND tenant_id = ?", attachmentID, tenantID).First(&attachment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Attachment not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch attachment"))
+		return
+	}
+
+	if _, _, ok := authorizeTask(c, h.db, attachment.TaskID, models.ProjectRoleEditor); !ok {
+		return
+	}
+
 	c.JSON(http.StatusNotImplemented, middleware.ErrorResponse("Not implemented yet"))
 }
 
 // Project-related methods
+
+// ListProjects returns the projects the current user can read
+// @Summary List projects
+// @Description Get visible projects. Archived projects are hidden unless include_archived or archived is set.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param include_archived query bool false "Include archived projects"
+// @Param archived query bool false "Only archived (true) or only active (false) projects"
+// @Param search query string false "Search project names"
+// @Success 200 {array} models.Project
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects [get]
 func (h *TaskHandler) ListProjects(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+	user, err := middleware.GetCurrentUser(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
 		return
 	}
 
+	query := h.db.Where("id IN (?)", models.VisibleProjectIDs(h.db, user))
+	if archived, err := strconv.ParseBool(c.Query("archived")); err == nil {
+		query = query.Where("is_active = ?", !archived)
+	} else if includeArchived, _ := strconv.ParseBool(c.Query("include_archived")); !includeArchived {
+		query = query.Where("is_active = ?", true)
+	}
+	if search := strings.TrimSpace(c.Query("search")); search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
 	var projects []models.Project
-	if err := h.db.Where("tenant_id = ?", tenantID).Find(&projects).Error; err != nil {
+	if err := query.Order("name ASC").Find(&projects).Error; err != nil {
 		h.logger.WithError(err).Error("Failed to fetch projects")
 		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch projects"))
 		return
@@ -492,9 +588,9 @@ func (h *TaskHandler) ListProjects(c *gin.Context) {
 }
 
 func (h *TaskHandler) CreateProject(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+	user, err := middleware.GetCurrentUser(c)
 	if err != nil {
-		response.Unauthorized(c, "Tenant not found")
+		response.Unauthorized(c, "User not authenticated")
 		return
 	}
 
@@ -516,16 +612,37 @@ func (h *TaskHandler) CreateProject(c *gin.Context) {
 	}
 
 	project := &models.Project{
-		TenantModel: models.TenantModel{TenantID: tenantID},
+		TenantModel: models.TenantModel{TenantID: user.TenantID},
 		Name:        req.Name,
 		Description: req.Description,
 		Color:       req.Color,
 		IsActive:    isActive,
 		StartDate:   req.StartDate,
 		EndDate:     req.EndDate,
+		IsPrivate:   req.IsPrivate,
+		DefaultRole: models.ProjectRole(req.DefaultRole),
+	}
+	if project.DefaultRole == models.ProjectRoleNone {
+		project.DefaultRole = models.ProjectRoleEditor
+	}
+	if !isActive {
+		project.Archive(user.ID)
 	}
 
-	if err := h.db.Create(project).Error; err != nil {
+	// The creator owns the project so they keep access if it is private
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ProjectMember{
+			ProjectID: project.ID,
+			UserID:    user.ID,
+			TenantID:  user.TenantID,
+			Role:      models.ProjectRoleOwner,
+			AddedBy:   &user.ID,
+		}).Error
+	})
+	if err != nil {
 		if appErr := errors.HandleDBError(err, "project"); appErr != nil {
 			response.InternalServerError(c, appErr.Message)
 			return
@@ -535,251 +652,239 @@ func (h *TaskHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectCreated{Project: events.NewProjectSnapshot(project)})
+
 	response.Created(c, project, "Project created successfully")
 }
 
 func (h *TaskHandler) GetProject(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
 	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
 		return
 	}
 
-	var project models.Project
-	if err := h.db.Where("id = ? AND tenant_id = ?", projectID, tenantID).First(&project).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project not found"))
-			return
-		}
-		h.logger.WithError(err).Error("Failed to fetch project")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project"))
+	project, _, role, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleViewer)
+	if !ok {
 		return
 	}
 
+	project.Role = role
+
 	c.JSON(http.StatusOK, middleware.SuccessResponse(project))
 }
 
 func (h *TaskHandler) UpdateProject(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
-	}
-
 	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
 		return
 	}
 
-	var project models.Project
-	if err := h.db.Where("id = ? AND tenant_id = ?", projectID, tenantID).First(&project).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project not found"))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project"))
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
 		return
 	}
 
-	var updateData map[string]interface{}
-	if err := c.ShouldBindJSON(&updateData); err != nil {
+	var req requests.UpdateProjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request data"))
 		return
 	}
 
-	if err := h.db.Model(&project).Updates(updateData).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to update project")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to update project"))
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
 		return
 	}
 
-	c.JSON(http.StatusOK, middleware.SuccessResponse(project, "Project updated successfully"))
-}
-
-func (h *TaskHandler) DeleteProject(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
+	updateData := map[string]interface{}{}
+	if req.Name != nil {
+		updateData["name"] = *req.Name
 	}
-
-	projectID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
-		return
+	if req.Description != nil {
+		updateData["description"] = *req.Description
 	}
-
-	var project models.Project
-	if err := h.db.Where("id = ? AND tenant_id = ?", projectID, tenantID).First(&project).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Project not found"))
-			return
+	if req.Color != nil {
+		updateData["color"] = *req.Color
+	}
+	if req.IsActive != nil && *req.IsActive != project.IsActive {
+		if *req.IsActive {
+			project.Unarchive()
+		} else {
+			project.Archive(user.ID)
 		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch project"))
-		return
+		updateData["is_active"] = project.IsActive
+		updateData["archived_at"] = project.ArchivedAt
+		updateData["archived_by"] = project.ArchivedBy
 	}
-
-	if err := h.db.Delete(&project).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to delete project")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete project"))
-		return
+	if req.StartDate != nil {
+		updateData["start_date"] = *req.StartDate
 	}
-
-	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Project deleted successfully"))
-}
-
-// CreateTagRequest represents a tag creation request
-type CreateTagRequest struct {
-	Name  string `json:"name" binding:"required"`
-	Color string `json:"color"`
-}
-
-// Tag-related methods
-func (h *TaskHandler) ListTags(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
+	if req.EndDate != nil {
+		updateData["end_date"] = *req.EndDate
 	}
-
-	var tags []models.Tag
-	if err := h.db.Where("tenant_id = ?", tenantID).Find(&tags).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to fetch tags")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tags"))
-		return
+	if req.IsPrivate != nil {
+		updateData["is_private"] = *req.IsPrivate
 	}
-
-	c.JSON(http.StatusOK, middleware.SuccessResponse(tags))
-}
-
-func (h *TaskHandler) CreateTag(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
+	if req.DefaultRole != nil {
+		updateData["default_role"] = *req.DefaultRole
 	}
 
-	var req CreateTagRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request", err.Error()))
+	before := events.NewProjectSnapshot(project)
+
+	if err := h.db.Model(project).Updates(updateData).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update project")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to update project"))
 		return
 	}
 
-	tag := &models.Tag{
-		TenantModel: models.TenantModel{TenantID: tenantID},
-		Name:        req.Name,
-		Color:       req.Color,
+	if err := h.db.First(project, project.ID).Error; err != nil {
+		h.logger.WithError(err).Warn("Failed to reload project")
 	}
-
-	if err := h.db.Create(tag).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to create tag")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to create tag"))
-		return
+	after := events.NewProjectSnapshot(project)
+	if changes := events.Diff(before, after); len(changes) > 0 {
+		publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectUpdated{Project: after, Changes: changes})
 	}
 
-	c.JSON(http.StatusCreated, middleware.SuccessResponse(tag, "Tag created successfully"))
+	c.JSON(http.StatusOK, middleware.SuccessResponse(project, "Project updated successfully"))
 }
 
-func (h *TaskHandler) GetTag(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+func (h *TaskHandler) DeleteProject(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
 		return
 	}
 
-	tagID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag ID"))
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
 		return
 	}
 
-	var tag models.Tag
-	if err := h.db.Where("id = ? AND tenant_id = ?", tagID, tenantID).First(&tag).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Tag not found"))
+	if !project.IsArchived() {
+		tenant, err := middleware.GetCurrentTenant(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
 			return
 		}
-		h.logger.WithError(err).Error("Failed to fetch tag")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tag"))
+		if tenant.RequireArchiveBeforeDelete {
+			c.JSON(http.StatusConflict, middleware.ErrorResponse("Project must be archived before it can be deleted"))
+			return
+		}
+	}
+
+	if err := h.db.Delete(project).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to delete project")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete project"))
 		return
 	}
 
-	c.JSON(http.StatusOK, middleware.SuccessResponse(tag))
+	publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectDeleted{Project: events.NewProjectSnapshot(project)})
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Project deleted successfully"))
 }
 
-func (h *TaskHandler) UpdateTag(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
+// ArchiveProject archives a project, making its tasks read-only
+// @Summary Archive project
+// @Description Archive a project. Its tasks, comments and attachments become read-only and it is hidden from default listings.
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/archive [post]
+func (h *TaskHandler) ArchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, true)
+}
+
+// UnarchiveProject restores an archived project
+// @Summary Unarchive project
+// @Description Restore full access to an archived project
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.Project
+// @Failure 401 {object} map[string]interface{}
+// @Failure 403 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/unarchive [post]
+func (h *TaskHandler) UnarchiveProject(c *gin.Context) {
+	h.setProjectArchived(c, false)
+}
+
+// setProjectArchived archives or unarchives the project in the path
+func (h *TaskHandler) setProjectArchived(c *gin.Context, archived bool) {
+	projectID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
+		response.BadRequest(c, "Invalid project ID")
 		return
 	}
 
-	tagID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag ID"))
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleOwner)
+	if !ok {
 		return
 	}
 
-	var tag models.Tag
-	if err := h.db.Where("id = ? AND tenant_id = ?", tagID, tenantID).First(&tag).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Tag not found"))
-			return
+	if project.IsArchived() == archived {
+		if archived {
+			response.Conflict(c, "Project is already archived")
+		} else {
+			response.Conflict(c, "Project is not archived")
 		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tag"))
 		return
 	}
 
-	var updateData map[string]interface{}
-	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid request data"))
-		return
+	message := "Project unarchived successfully"
+	if archived {
+		project.Archive(user.ID)
+		message = "Project archived successfully"
+	} else {
+		project.Unarchive()
 	}
 
-	if err := h.db.Model(&tag).Updates(updateData).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to update tag")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to update tag"))
+	if err := h.db.Model(project).Select("is_active", "archived_at", "archived_by").Updates(project).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to update project archive state")
+		response.InternalServerError(c, "Failed to update project")
 		return
 	}
 
-	c.JSON(http.StatusOK, middleware.SuccessResponse(tag, "Tag updated successfully"))
-}
-
-func (h *TaskHandler) DeleteTag(c *gin.Context) {
-	tenantID, err := middleware.GetCurrentTenantID(c)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("Tenant not found"))
-		return
+	if archived {
+		publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectArchived{Project: events.NewProjectSnapshot(project)})
+	} else {
+		publishEvent(c, h.events, h.logger, project.TenantID, user.ID, events.ProjectUnarchived{Project: events.NewProjectSnapshot(project)})
 	}
 
-	tagID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid tag ID"))
-		return
-	}
+	response.Success(c, project, message)
+}
 
-	var tag models.Tag
-	if err := h.db.Where("id = ? AND tenant_id = ?", tagID, tenantID).First(&tag).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Tag not found"))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch tag"))
-		return
-	}
+// recordActivity appends an entry to a task's activity log
+func (h *TaskHandler) recordActivity(tx *gorm.DB, task *models.Task, userID uuid.UUID, action, field, oldValue, newValue, description string) {
+	recordTaskActivity(tx, h.logger, task, userID, action, field, oldValue, newValue, description)
+}
 
-	if err := h.db.Delete(&tag).Error; err != nil {
-		h.logger.WithError(err).Error("Failed to delete tag")
-		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete tag"))
-		return
+// recordTaskActivity appends an entry to a task's activity log, logging
+// failures instead of aborting the caller
+func recordTaskActivity(tx *gorm.DB, log *logger.Logger, task *models.Task, userID uuid.UUID, action, field, oldValue, newValue, description string) {
+	activity := &models.TaskActivity{
+		TenantModel: models.TenantModel{TenantID: task.TenantID},
+		TaskID:      task.ID,
+		UserID:      userID,
+		Action:      action,
+		Field:       field,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+		Description: description,
 	}
 
-	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Tag deleted successfully"))
-}
\ No newline at end of file
+	if err := tx.Create(activity).Error; err != nil {
+		log.WithError(err).WithField("task_id", task.ID).Warn("Failed to record task activity")
+	}
+}
@@ -0,0 +1,443 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListRelations returns the typed relations of a task
+// @Summary List task relations
+// @Description Get the relations of a task, labelled from the task's point of view
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {array} models.TaskRelationView
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/relations [get]
+func (h *TaskHandler) ListRelations(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	_, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	relations, err := h.loadTaskRelations(user, taskID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch task relations")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch task relations"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(relations))
+}
+
+// CreateRelation links a task to another task in the same tenant
+// @Summary Create task relation
+// @Description Link two tasks with a typed relation. Marking a task as a duplicate can close it and move its watchers to the canonical task.
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param request body requests.CreateTaskRelationRequest true "Relation data"
+// @Success 201 {object} models.TaskRelationView
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/relations [post]
+func (h *TaskHandler) CreateRelation(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid task ID")
+		return
+	}
+
+	current, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+	tenantID, userID := current.TenantID, user.ID
+
+	var req requests.CreateTaskRelationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	if req.TargetTaskID == taskID {
+		response.BadRequest(c, "A task cannot be related to itself")
+		return
+	}
+
+	// Both ends must belong to the current tenant
+	var tasks []models.Task
+	if err := h.db.Where("id IN ? AND tenant_id = ?", []uuid.UUID{taskID, req.TargetTaskID}, tenantID).Find(&tasks).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch tasks")
+		response.InternalServerError(c, "Failed to fetch tasks")
+		return
+	}
+	if len(tasks) != 2 {
+		response.NotFound(c, "Task not found")
+		return
+	}
+
+	other := taskByID(tasks, req.TargetTaskID)
+	otherRole, err := taskRole(h.db, user, other)
+	if err != nil {
+		response.InternalServerError(c, "Failed to check project permissions")
+		return
+	}
+	if !otherRole.CanRead() {
+		response.NotFound(c, "Task not found")
+		return
+	}
+
+	relationType, swapped := req.Type.Normalize()
+	sourceID, targetID := taskID, req.TargetTaskID
+	if swapped {
+		sourceID, targetID = targetID, sourceID
+	}
+
+	// Closing the duplicate changes it, so the user must be able to edit it
+	if relationType == models.TaskRelationDuplicates && req.CloseDuplicate && sourceID == other.ID && !otherRole.CanEdit() {
+		response.Forbidden(c, "Insufficient permissions to close the duplicate task")
+		return
+	}
+
+	var existing int64
+	if err := h.db.Model(&models.TaskRelation{}).
+		Where("tenant_id = ? AND ((source_task_id = ? AND target_task_id = ?) OR (source_task_id = ? AND target_task_id = ?))",
+			tenantID, sourceID, targetID, targetID, sourceID).
+		Count(&existing).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to check existing relations")
+		response.InternalServerError(c, "Failed to create relation")
+		return
+	}
+	if existing > 0 {
+		response.Conflict(c, "These tasks are already related")
+		return
+	}
+
+	if relationType.IsDependency() {
+		cyclic, err := h.dependsOn(tenantID, sourceID, targetID)
+		if err != nil {
+			h.logger.WithError(err).Error("Failed to check task dependencies")
+			response.InternalServerError(c, "Failed to create relation")
+			return
+		}
+		if cyclic {
+			response.Conflict(c, "This dependency would create a cycle")
+			return
+		}
+	}
+
+	relation := &models.TaskRelation{
+		TenantModel:  models.TenantModel{TenantID: tenantID},
+		SourceTaskID: sourceID,
+		TargetTaskID: targetID,
+		Type:         relationType,
+		CreatedBy:    userID,
+	}
+
+	var closed *events.TaskUpdated
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(relation).Error; err != nil {
+			return err
+		}
+
+		source, target := taskByID(tasks, sourceID), taskByID(tasks, targetID)
+		h.recordActivity(tx, source, userID, "relation_added", "relations", "", target.ID.String(),
+			fmt.Sprintf("Linked as %s %s", relationType, target.Title))
+		h.recordActivity(tx, target, userID, "relation_added", "relations", "", source.ID.String(),
+			fmt.Sprintf("Linked as %s %s", relationType.Inverse(), source.Title))
+
+		if relationType == models.TaskRelationDuplicates && req.CloseDuplicate {
+			var err error
+			closed, err = h.closeAsDuplicate(tx, source, target, userID)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to create task relation")
+		response.InternalServerError(c, "Failed to create relation")
+		return
+	}
+
+	if closed != nil {
+		publishEvent(c, h.events, h.logger, tenantID, userID, *closed)
+	}
+
+	if err := h.db.Preload("SourceTask").Preload("TargetTask").First(relation, relation.ID).Error; err != nil {
+		h.logger.WithError(err).Warn("Failed to reload task relation")
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"relation_id": relation.ID,
+		"task_id":     taskID,
+		"type":        relationType,
+	}).Info("Task relation created successfully")
+
+	response.Created(c, relation.ViewFrom(taskID), "Relation created successfully")
+}
+
+// DeleteRelation removes a relation from a task
+// @Summary Delete task relation
+// @Description Remove a relation between two tasks
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Param relation_id path string true "Relation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/relations/{relation_id} [delete]
+func (h *TaskHandler) DeleteRelation(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	task, _, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+	tenantID := task.TenantID
+
+	relationID, err := uuid.Parse(c.Param("relation_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid relation ID"))
+		return
+	}
+
+	var relation models.TaskRelation
+	if err := h.db.
+		Where("id = ? AND tenant_id = ? AND (source_task_id = ? OR target_task_id = ?)", relationID, tenantID, taskID, taskID).
+		First(&relation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse("Relation not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to fetch relation"))
+		return
+	}
+
+	if err := h.db.Delete(&relation).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to delete task relation")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to delete relation"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Relation deleted successfully"))
+}
+
+// WatchTask subscribes the current user to a task
+// @Summary Watch task
+// @Description Start watching a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/watch [post]
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	_, user, ok := authorizeTask(c, h.db, taskID, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	watcher := &models.TaskWatcher{TaskID: taskID, UserID: user.ID}
+	if err := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(watcher).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to watch task")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to watch task"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Task watched successfully"))
+}
+
+// UnwatchTask unsubscribes the current user from a task
+// @Summary Unwatch task
+// @Description Stop watching a task
+// @Tags tasks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /tasks/{id}/watch [delete]
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse("User not authenticated"))
+		return
+	}
+
+	taskID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid task ID"))
+		return
+	}
+
+	if err := h.db.Where("task_id = ? AND user_id = ?", taskID, userID).Delete(&models.TaskWatcher{}).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to unwatch task")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to unwatch task"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Task unwatched successfully"))
+}
+
+// loadTaskRelations returns every relation touching a task, labelled from its
+// side, leaving out related tasks the user cannot read
+func (h *TaskHandler) loadTaskRelations(user *models.User, taskID uuid.UUID) ([]models.TaskRelationView, error) {
+	var relations []models.TaskRelation
+	if err := h.db.
+		Preload("SourceTask").
+		Preload("TargetTask").
+		Where("tenant_id = ? AND (source_task_id = ? OR target_task_id = ?)", user.TenantID, taskID, taskID).
+		Order("created_at ASC").
+		Find(&relations).Error; err != nil {
+		return nil, err
+	}
+
+	otherIDs := make([]uuid.UUID, 0, len(relations))
+	for i := range relations {
+		otherIDs = append(otherIDs, relations[i].ViewFrom(taskID).TaskID)
+	}
+
+	visible := map[uuid.UUID]bool{}
+	if len(otherIDs) > 0 {
+		var visibleIDs []uuid.UUID
+		query := h.db.Model(&models.Task{}).Where("id IN ?", otherIDs)
+		if err := models.ScopeVisibleTasks(h.db, query, user).Pluck("id", &visibleIDs).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range visibleIDs {
+			visible[id] = true
+		}
+	}
+
+	views := make([]models.TaskRelationView, 0, len(relations))
+	for i := range relations {
+		view := relations[i].ViewFrom(taskID)
+		if visible[view.TaskID] {
+			views = append(views, view)
+		}
+	}
+	return views, nil
+}
+
+// closeAsDuplicate cancels a duplicate task and hands its watchers over to the canonical task
+func (h *TaskHandler) closeAsDuplicate(tx *gorm.DB, duplicate, canonical *models.Task, userID uuid.UUID) (*events.TaskUpdated, error) {
+	var closed *events.TaskUpdated
+	if duplicate.Status != models.TaskStatusCompleted && duplicate.Status != models.TaskStatusCanceled {
+		before := events.NewTaskSnapshot(duplicate)
+		oldStatus := duplicate.Status
+		if err := tx.Model(duplicate).Update("status", models.TaskStatusCanceled).Error; err != nil {
+			return nil, err
+		}
+		duplicate.Status = models.TaskStatusCanceled
+		h.recordActivity(tx, duplicate, userID, "status_changed", "status", string(oldStatus), string(models.TaskStatusCanceled),
+			fmt.Sprintf("Closed as duplicate of %s", canonical.Title))
+		after := events.NewTaskSnapshot(duplicate)
+		closed = &events.TaskUpdated{Task: after, Changes: events.Diff(before, after)}
+	}
+
+	var watchers []models.TaskWatcher
+	if err := tx.Where("task_id = ?", duplicate.ID).Find(&watchers).Error; err != nil {
+		return nil, err
+	}
+	if len(watchers) == 0 {
+		return closed, nil
+	}
+
+	moved := make([]models.TaskWatcher, 0, len(watchers))
+	for _, w := range watchers {
+		moved = append(moved, models.TaskWatcher{TaskID: canonical.ID, UserID: w.UserID})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&moved).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", duplicate.ID).Delete(&models.TaskWatcher{}).Error; err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+// taskByID finds a task in a slice by ID
+func taskByID(tasks []models.Task, id uuid.UUID) *models.Task {
+	for i := range tasks {
+		if tasks[i].ID == id {
+			return &tasks[i]
+		}
+	}
+	return nil
+}
+
+// dependsOn reports whether taskID is already blocked, directly or transitively, by blockerID
+func (h *TaskHandler) dependsOn(tenantID, taskID, blockerID uuid.UUID) (bool, error) {
+	visited := map[uuid.UUID]bool{blockerID: true}
+	frontier := []uuid.UUID{blockerID}
+
+	for len(frontier) > 0 {
+		var next []uuid.UUID
+		if err := h.db.Model(&models.TaskRelation{}).
+			Where("tenant_id = ? AND type = ? AND source_task_id IN ?", tenantID, models.TaskRelationBlocks, frontier).
+			Pluck("target_task_id", &next).Error; err != nil {
+			return false, err
+		}
+
+		frontier = frontier[:0]
+		for _, id := range next {
+			if id == taskID {
+				return true, nil
+			}
+			if !visited[id] {
+				visited[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+	}
+
+	return false, nil
+}
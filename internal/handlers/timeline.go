@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"gorm.io/gorm"
+)
+
+// GetProjectTimeline returns the Gantt timeline of a project
+// @Summary Get project timeline
+// @Description Get the project's tasks with computed start and end dates, dependency edges, milestones and slack
+// @Tags projects
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Success 200 {object} models.Timeline
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/timeline [get]
+func (h *TaskHandler) GetProjectTimeline(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse("Invalid project ID"))
+		return
+	}
+
+	project, _, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleViewer)
+	if !ok {
+		return
+	}
+
+	timeline, err := h.loadTimeline(project)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build project timeline")
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse("Failed to build timeline"))
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(timeline))
+}
+
+// ShiftTimelineTask moves a task on the project timeline
+// @Summary Shift task on timeline
+// @Description Move a task's start and due dates by a number of days, optionally pushing every task it blocks by the same amount
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Project ID"
+// @Param request body requests.ShiftTaskRequest true "Shift data"
+// @Success 200 {object} models.Timeline
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /projects/{id}/timeline/shift [post]
+func (h *TaskHandler) ShiftTimelineTask(c *gin.Context) {
+	projectID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid project ID")
+		return
+	}
+
+	var req requests.ShiftTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	project, user, _, ok := authorizeProject(c, h.db, projectID, models.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+	tenantID, userID := project.TenantID, user.ID
+
+	var task models.Task
+	if err := h.db.Where("id = ? AND tenant_id = ? AND project_id = ?", req.TaskID, tenantID, projectID).First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Task not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to fetch task")
+		return
+	}
+
+	if task.StartDate == nil && task.DueDate == nil {
+		response.BadRequest(c, "Task has no dates to shift")
+		return
+	}
+
+	var changes []events.TaskUpdated
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		shifted := map[uuid.UUID]bool{}
+		queue := []models.Task{task}
+
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			if shifted[current.ID] {
+				continue
+			}
+			shifted[current.ID] = true
+
+			change, err := h.shiftTask(tx, &current, req.Days, userID)
+			if err != nil {
+				return err
+			}
+			if change != nil {
+				changes = append(changes, *change)
+			}
+
+			if !req.PushDependents {
+				continue
+			}
+
+			var dependents []models.Task
+			if err := tx.
+				Where("tenant_id = ? AND id IN (?)", tenantID,
+					tx.Model(&models.TaskRelation{}).Select("target_task_id").
+						Where("tenant_id = ? AND type = ? AND source_task_id = ?", tenantID, models.TaskRelationBlocks, current.ID)).
+				Find(&dependents).Error; err != nil {
+				return err
+			}
+			// Dependents in projects the user cannot edit stay where they are
+			for i := range dependents {
+				role, err := taskRole(tx, user, &dependents[i])
+				if err != nil {
+					return err
+				}
+				if role.CanEdit() {
+					queue = append(queue, dependents[i])
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to shift task")
+		response.InternalServerError(c, "Failed to shift task")
+		return
+	}
+
+	for _, change := range changes {
+		publishEvent(c, h.events, h.logger, tenantID, userID, change)
+	}
+
+	timeline, err := h.loadTimeline(project)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to build project timeline")
+		response.InternalServerError(c, "Failed to build timeline")
+		return
+	}
+
+	response.Success(c, timeline, "Task shifted successfully")
+}
+
+// shiftTask moves the start and due dates of a task by the given number of
+// days and returns the change to publish once the shift is committed
+func (h *TaskHandler) shiftTask(tx *gorm.DB, task *models.Task, days int, userID uuid.UUID) (*events.TaskUpdated, error) {
+	before := events.NewTaskSnapshot(task)
+	updates := map[string]interface{}{}
+	var newStart, newDue *time.Time
+	if task.StartDate != nil {
+		start := task.StartDate.AddDate(0, 0, days)
+		newStart = &start
+		updates["start_date"] = start
+	}
+	if task.DueDate != nil {
+		due := task.DueDate.AddDate(0, 0, days)
+		newDue = &due
+		updates["due_date"] = due
+	}
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	oldDue := formatOptionalDate(task.DueDate)
+	if err := tx.Model(task).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	task.StartDate, task.DueDate = newStart, newDue
+
+	h.recordActivity(tx, task, userID, "rescheduled", "due_date", oldDue, formatOptionalDate(newDue),
+		fmt.Sprintf("Shifted by %d day(s) on the timeline", days))
+
+	after := events.NewTaskSnapshot(task)
+	return &events.TaskUpdated{Task: after, Changes: events.Diff(before, after)}, nil
+}
+
+// loadTimeline fetches a project's tasks and dependencies and schedules them
+func (h *TaskHandler) loadTimeline(project *models.Project) (*models.Timeline, error) {
+	var tasks []models.Task
+	if err := h.db.
+		Preload("Subtasks").
+		Preload("Checklist").
+		Where("tenant_id = ? AND project_id = ?", project.TenantID, project.ID).
+		Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]uuid.UUID, len(tasks))
+	for i := range tasks {
+		taskIDs[i] = tasks[i].ID
+	}
+
+	var relations []models.TaskRelation
+	if len(taskIDs) > 0 {
+		if err := h.db.
+			Where("tenant_id = ? AND type = ? AND source_task_id IN ? AND target_task_id IN ?",
+				project.TenantID, models.TaskRelationBlocks, taskIDs, taskIDs).
+			Find(&relations).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return models.BuildTimeline(project, tasks, relations), nil
+}
+
+// formatOptionalDate renders a date for activity logs, or an empty string
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
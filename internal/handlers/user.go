@@ -7,6 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+	"github.com/drazan344/taskflow-go/internal/events"
 	"github.com/drazan344/taskflow-go/internal/middleware"
 	"github.com/drazan344/taskflow-go/internal/models"
 	"github.com/drazan344/taskflow-go/internal/requests"
@@ -22,14 +23,16 @@ type UserHandler struct {
 	db        *gorm.DB
 	logger    *logger.Logger
 	validator *validator.Validator
+	events    events.Publisher
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(db *gorm.DB, logger *logger.Logger) *UserHandler {
+func NewUserHandler(db *gorm.DB, logger *logger.Logger, publisher events.Publisher) *UserHandler {
 	return &UserHandler{
 		db:        db,
 		logger:    logger,
 		validator: validator.New(),
+		events:    publisher,
 	}
 }
 
@@ -251,6 +254,8 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		updateData["status"] = *req.Status
 	}
 
+	before := events.NewUserSnapshot(&user)
+
 	// Update user
 	if len(updateData) > 0 {
 		if err := h.db.Model(&user).Updates(updateData).Error; err != nil {
@@ -265,6 +270,12 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		h.logger.WithError(err).Warn("Failed to reload user")
 	}
 
+	after := events.NewUserSnapshot(&user)
+	if changes := events.Diff(before, after); len(changes) > 0 {
+		actorID, _ := middleware.GetCurrentUserID(c)
+		publishEvent(c, h.events, h.logger, tenantID, actorID, events.UserUpdated{User: after, Changes: changes})
+	}
+
 	h.logger.WithField("user_id", userID).Info("User updated successfully")
 	response.Success(c, user, "User updated successfully")
 }
@@ -312,6 +323,9 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 		return
 	}
 
+	actorID, _ := middleware.GetCurrentUserID(c)
+	publishEvent(c, h.events, h.logger, tenantID, actorID, events.UserDeleted{User: events.NewUserSnapshot(&user)})
+
 	h.logger.WithField("user_id", userID).Info("User deleted successfully")
 
 	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "User deleted successfully"))
@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/drazan344/taskflow-go/internal/config"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/middleware"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/requests"
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+	"github.com/drazan344/taskflow-go/pkg/response"
+	"github.com/drazan344/taskflow-go/pkg/validator"
+	"gorm.io/gorm"
+)
+
+// WebhookHandler handles tenant webhook subscriptions and their delivery logs
+type WebhookHandler struct {
+	db         *gorm.DB
+	logger     *logger.Logger
+	validator  *validator.Validator
+	jobs       *jobs.Client
+	guard      *webhooks.Guard
+	maxRetries int
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(db *gorm.DB, logger *logger.Logger, jobClient *jobs.Client, guard *webhooks.Guard, cfg *config.Config) *WebhookHandler {
+	return &WebhookHandler{
+		db:         db,
+		logger:     logger,
+		validator:  validator.New(),
+		jobs:       jobClient,
+		guard:      guard,
+		maxRetries: cfg.Webhooks.MaxRetries,
+	}
+}
+
+// webhookSecretResponse is a webhook together with its signing secret, which
+// is only shown when it is created or rotated
+type webhookSecretResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// ListWebhooks returns the webhooks of the tenant
+// @Summary List webhooks
+// @Description Get the webhooks registered by the tenant
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.Webhook
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := h.db.Where("tenant_id = ?", tenantID).Order("created_at ASC").Find(&hooks).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch webhooks")
+		response.InternalServerError(c, "Failed to fetch webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(hooks))
+}
+
+// CreateWebhook registers a webhook endpoint
+// @Summary Create webhook
+// @Description Register an endpoint that receives the selected events. Requests are signed with HMAC-SHA256 over the timestamp and body; the secret is only returned once.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body requests.CreateWebhookRequest true "Webhook"
+// @Success 201 {object} webhookSecretResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return
+	}
+
+	userID, err := middleware.GetCurrentUserID(c)
+	if err != nil {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req requests.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+	if validationErrors := h.validateWebhook(req.URL, req.Events); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = webhooks.GenerateSecret(); err != nil {
+			h.logger.WithError(err).Error("Failed to generate webhook secret")
+			response.InternalServerError(c, "Failed to create webhook")
+			return
+		}
+	}
+
+	webhook := &models.Webhook{
+		TenantModel: models.TenantModel{TenantID: tenantID},
+		Name:        req.Name,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Active:      true,
+		CreatedBy:   userID,
+	}
+	if err := h.db.Create(webhook).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create webhook")
+		response.InternalServerError(c, "Failed to create webhook")
+		return
+	}
+
+	h.logger.WithFields(map[string]interface{}{
+		"webhook_id": webhook.ID,
+		"tenant_id":  tenantID,
+		"events":     webhook.Events,
+	}).Info("Webhook created successfully")
+
+	response.Created(c, webhookSecretResponse{Webhook: *webhook, Secret: secret}, "Webhook created successfully")
+}
+
+// GetWebhook returns a webhook
+// @Summary Get webhook
+// @Description Get a webhook and the health of its deliveries
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(webhook))
+}
+
+// UpdateWebhook changes a webhook
+// @Summary Update webhook
+// @Description Update the name, URL, events or state of a webhook. Re-activating a disabled webhook resets its failure count.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param request body requests.UpdateWebhookRequest true "Webhook changes"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req requests.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&req); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	targetURL, subscribed := webhook.URL, webhook.Events
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.URL != nil {
+		targetURL = *req.URL
+		updates["url"] = targetURL
+	}
+	if req.Events != nil {
+		subscribed = req.Events
+		webhook.Events = req.Events
+	}
+	if validationErrors := h.validateWebhook(targetURL, subscribed); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+	if req.Active != nil && *req.Active != webhook.Active {
+		updates["active"] = *req.Active
+		if *req.Active {
+			updates["consecutive_failures"] = 0
+			updates["disabled_at"] = nil
+			updates["disabled_reason"] = ""
+		}
+	}
+
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(webhook).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.Events != nil {
+			return tx.Model(webhook).Select("events").Updates(webhook).Error
+		}
+		return nil
+	})
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to update webhook")
+		response.InternalServerError(c, "Failed to update webhook")
+		return
+	}
+
+	if err := h.db.First(webhook, webhook.ID).Error; err != nil {
+		h.logger.WithError(err).Warn("Failed to reload webhook")
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(webhook, "Webhook updated successfully"))
+}
+
+// DeleteWebhook removes a webhook. Deliveries still queued for it are dropped.
+// @Summary Delete webhook
+// @Description Delete a webhook. Pending deliveries are not sent.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.Delete(webhook).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to delete webhook")
+		response.InternalServerError(c, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(nil, "Webhook deleted successfully"))
+}
+
+// RotateWebhookSecret replaces the signing secret of a webhook
+// @Summary Rotate webhook secret
+// @Description Generate a new signing secret. Deliveries sent from now on are signed with it.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} webhookSecretResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateWebhookSecret(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to generate webhook secret")
+		response.InternalServerError(c, "Failed to rotate webhook secret")
+		return
+	}
+
+	if err := h.db.Model(webhook).Update("secret", secret).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to rotate webhook secret")
+		response.InternalServerError(c, "Failed to rotate webhook secret")
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(webhookSecretResponse{Webhook: *webhook, Secret: secret}, "Webhook secret rotated successfully"))
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook
+// @Summary List webhook deliveries
+// @Description Get the deliveries of a webhook, newest first, with the request and response of their latest attempt
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Delivery status" Enums(pending, succeeded, failed)
+// @Param event_type query string false "Event type"
+// @Param page query int false "Page number" default(1)
+// @Param per_page query int false "Items per page" default(20)
+// @Success 200 {object} response.PaginationResponse
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var pagination requests.PaginationRequest
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		response.BadRequest(c, "Invalid pagination parameters", err.Error())
+		return
+	}
+	pagination.DefaultPagination()
+
+	var filters requests.WebhookDeliveryFiltersRequest
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		response.BadRequest(c, "Invalid filter parameters", err.Error())
+		return
+	}
+
+	if validationErrors := h.validator.ValidateStruct(&pagination); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+	if validationErrors := h.validator.ValidateStruct(&filters); validationErrors != nil {
+		response.ValidationErrors(c, validationErrors)
+		return
+	}
+
+	query := h.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ? AND tenant_id = ?", webhook.ID, webhook.TenantID)
+	if filters.Status != nil {
+		query = query.Where("status = ?", *filters.Status)
+	}
+	if filters.EventType != "" {
+		query = query.Where("event_type = ?", filters.EventType)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to count webhook deliveries")
+		response.InternalServerError(c, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.
+		Offset(pagination.GetOffset()).
+		Limit(pagination.PerPage).
+		Order("created_at DESC").
+		Find(&deliveries).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to fetch webhook deliveries")
+		response.InternalServerError(c, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	response.Paginated(c, deliveries, pagination.Page, pagination.PerPage, total)
+}
+
+// GetWebhookDelivery returns a delivery with its request and response
+// @Summary Get webhook delivery
+// @Description Get a webhook delivery with the request and response of its latest attempt
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetWebhookDelivery(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, ok := h.loadDelivery(c, webhook)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, middleware.SuccessResponse(delivery))
+}
+
+// RedeliverWebhookDelivery sends the event of a delivery again
+// @Summary Redeliver webhook delivery
+// @Description Queue a new delivery of the same event and body. The new delivery has its own ID and log entry.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhookDelivery(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	original, ok := h.loadDelivery(c, webhook)
+	if !ok {
+		return
+	}
+
+	if !webhook.Active {
+		response.Conflict(c, "The webhook is disabled")
+		return
+	}
+
+	delivery := webhooks.NewRedelivery(original)
+	if err := h.db.Create(delivery).Error; err != nil {
+		h.logger.WithError(err).Error("Failed to create webhook delivery")
+		response.InternalServerError(c, "Failed to redeliver webhook")
+		return
+	}
+
+	if err := h.jobs.EnqueueWebhookDelivery(jobs.WebhookNotificationPayload{
+		BaseJobPayload: jobs.BaseJobPayload{TenantID: webhook.TenantID},
+		WebhookID:      webhook.ID,
+		DeliveryID:     delivery.ID,
+		Event:          delivery.EventType,
+		MaxRetries:     h.maxRetries,
+	}); err != nil {
+		h.logger.WithError(err).WithField("delivery_id", delivery.ID).Error("Failed to enqueue webhook delivery")
+		h.db.Model(delivery).Updates(map[string]interface{}{
+			"status": models.WebhookDeliveryFailed,
+			"error":  "the delivery could not be queued",
+		})
+		response.InternalServerError(c, "Failed to redeliver webhook")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, middleware.SuccessResponse(delivery, "Redelivery queued"))
+}
+
+// loadWebhook fetches the webhook named in the path within the current tenant
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	tenantID, err := middleware.GetCurrentTenantID(c)
+	if err != nil {
+		response.Unauthorized(c, "Tenant not found")
+		return nil, false
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return nil, false
+	}
+
+	var webhook models.Webhook
+	if err := h.db.Where("id = ? AND tenant_id = ?", webhookID, tenantID).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Webhook not found")
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch webhook")
+		response.InternalServerError(c, "Failed to fetch webhook")
+		return nil, false
+	}
+
+	return &webhook, true
+}
+
+// loadDelivery fetches the delivery named in the path for a webhook
+func (h *WebhookHandler) loadDelivery(c *gin.Context, webhook *models.Webhook) (*models.WebhookDelivery, bool) {
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid delivery ID")
+		return nil, false
+	}
+
+	var delivery models.WebhookDelivery
+	if err := h.db.Where("id = ? AND webhook_id = ? AND tenant_id = ?", deliveryID, webhook.ID, webhook.TenantID).
+		First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			response.NotFound(c, "Delivery not found")
+			return nil, false
+		}
+		h.logger.WithError(err).Error("Failed to fetch webhook delivery")
+		response.InternalServerError(c, "Failed to fetch webhook delivery")
+		return nil, false
+	}
+
+	return &delivery, true
+}
+
+// validateWebhook checks that a webhook posts to an HTTP(S) URL outside the
+// internal network and only subscribes to known event types. Notifications
+// belong to single users and are never sent to webhooks.
+func (h *WebhookHandler) validateWebhook(target string, subscribed []string) []string {
+	var errors []string
+	if err := h.guard.CheckURL(target); err == webhooks.ErrBlockedAddress {
+		errors = append(errors, "url must not point to a private or internal address")
+	} else if err != nil {
+		errors = append(errors, err.Error())
+	}
+	for _, name := range subscribed {
+		if name == models.WebhookAllEvents {
+			continue
+		}
+		if t := events.Type(name); !t.IsValid() || t == events.TypeNotificationCreated {
+			errors = append(errors, fmt.Sprintf("unknown event type %q", name))
+		}
+	}
+	return errors
+}
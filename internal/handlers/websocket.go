@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -9,35 +11,95 @@ import (
 	"github.com/drazan344/taskflow-go/internal/models"
 	"github.com/drazan344/taskflow-go/internal/websocket"
 	"github.com/drazan344/taskflow-go/pkg/logger"
+	"gorm.io/gorm"
+)
+
+var (
+	errTopicNotFound  = errors.New("topic not found")
+	errTopicForbidden = errors.New("access denied")
+	errTopicCheck     = errors.New("failed to check access")
 )
 
 // WebSocketHandler handles WebSocket-related operations
 type WebSocketHandler struct {
+	db     *gorm.DB
 	hub    *websocket.Hub
 	logger *logger.Logger
+
+	// closed ends the event streams on shutdown
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 // NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(hub *websocket.Hub, logger *logger.Logger) *WebSocketHandler {
+func NewWebSocketHandler(db *gorm.DB, hub *websocket.Hub, logger *logger.Logger) *WebSocketHandler {
 	return &WebSocketHandler{
+		db:     db,
 		hub:    hub,
 		logger: logger,
+		closed: make(chan struct{}),
 	}
 }
 
 // HandleConnection handles WebSocket connection upgrades
 // @Summary WebSocket connection
-// @Description Establish WebSocket connection for real-time updates
+// @Description Establish WebSocket connection for real-time updates. Connections receive their own notifications and subscribe to other topics by sending {"type":"subscribe","data":{"topics":[...]}}, or leave them with "unsubscribe". Topics are "tenant" for tenant-wide events and presence, "project:<id>", "task:<id>" and "user:<id>"; each is checked against the user's access when subscribing and again when access is revoked.
 // @Tags websocket
 // @Security BearerAuth
 // @Router /ws [get]
 func (h *WebSocketHandler) HandleConnection() gin.HandlerFunc {
-	return websocket.HandleWebSocket(h.hub, h.logger)
+	return websocket.HandleWebSocket(h.hub, h.logger, h.authorizeTopic)
+}
+
+// authorizeTopic checks that a user may receive the messages of a topic.
+// Project and task topics need read access; user topics are private to
+// their user.
+func (h *WebSocketHandler) authorizeTopic(user *models.User, topic string) error {
+	kind, id, err := websocket.ParseTopic(topic)
+	if err != nil {
+		return err
+	}
+
+	var role models.ProjectRole
+	switch kind {
+	case websocket.TopicKindTenant:
+		return nil
+	case websocket.TopicKindUser:
+		if id != user.ID {
+			return errTopicForbidden
+		}
+		return nil
+	case websocket.TopicKindProject:
+		var project *models.Project
+		project, role, err = projectAccess(h.db, user, id)
+		if err == nil && project == nil {
+			return errTopicNotFound
+		}
+	case websocket.TopicKindTask:
+		var task models.Task
+		if err := h.db.Where("id = ? AND tenant_id = ?", id, user.TenantID).First(&task).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errTopicNotFound
+			}
+			h.logger.WithError(err).WithField("topic", topic).Error("Failed to load task for subscription")
+			return errTopicCheck
+		}
+		_, role, err = taskAccess(h.db, user, &task)
+	}
+	if err != nil {
+		h.logger.WithError(err).WithField("topic", topic).Error("Failed to check subscription access")
+		return errTopicCheck
+	}
+	if !role.CanRead() {
+		// Not disclosed, like private projects over HTTP
+		return errTopicNotFound
+	}
+	return nil
 }
 
 // GetOnlineUsers returns online users in the current tenant
 // @Summary Get online users
-// @Description Get list of users currently online in the tenant
+// @Description Get list of users currently online in the tenant, with each user's do-not-disturb status in presence
 // @Tags websocket
 // @Produce json
 // @Security BearerAuth
@@ -56,24 +118,25 @@ func (h *WebSocketHandler) GetOnlineUsers(c *gin.Context) {
 
 	c.JSON(http.StatusOK, middleware.SuccessResponse(gin.H{
 		"online_users":  onlineUsers,
+		"presence":      h.hub.GetPresence(tenantID),
 		"client_count":  clientCount,
 		"user_count":    len(onlineUsers),
 	}))
 }
 
-// BroadcastTaskUpdate broadcasts a task update to all connected clients
-func (h *WebSocketHandler) BroadcastTaskUpdate(task *models.Task, action string) {
-	data := map[string]interface{}{
+// BroadcastTaskUpdate broadcasts a task update to the clients subscribed to
+// any of the topics
+func (h *WebSocketHandler) BroadcastTaskUpdate(task *models.Task, action string, topics []string) {
+	h.hub.Broadcast(task.TenantID, topics, websocket.MessageTypeTaskUpdate, map[string]interface{}{
 		"action": action, // "created", "updated", "deleted"
 		"task":   task,
-	}
+	})
 
-	h.hub.BroadcastToTenant(task.TenantID, websocket.MessageTypeTaskUpdate, data)
-	
 	h.logger.WithFields(map[string]interface{}{
 		"task_id":   task.ID,
 		"tenant_id": task.TenantID,
 		"action":    action,
+		"topics":    topics,
 	}).Debug("Broadcasted task update")
 }
 
@@ -98,7 +161,21 @@ func (h *WebSocketHandler) BroadcastNotification(notification *models.Notificati
 	}).Debug("Broadcasted notification")
 }
 
-// BroadcastToTenant broadcasts a message to all users in a tenant
+// Broadcast broadcasts a message to the clients subscribed to any of the
+// topics
+func (h *WebSocketHandler) Broadcast(tenantID uuid.UUID, topics []string, messageType websocket.MessageType, data interface{}) {
+	h.hub.Broadcast(tenantID, topics, messageType, data)
+}
+
+// Reauthorize drops the subscriptions a user, or every user of the tenant
+// when userID is uuid.Nil, may no longer see. topic limits the check to one
+// topic.
+func (h *WebSocketHandler) Reauthorize(tenantID, userID uuid.UUID, topic string) {
+	h.hub.Reauthorize(tenantID, userID, topic)
+}
+
+// BroadcastToTenant broadcasts a message to the clients subscribed to the
+// tenant topic
 func (h *WebSocketHandler) BroadcastToTenant(tenantID uuid.UUID, messageType websocket.MessageType, data interface{}) {
 	h.hub.BroadcastToTenant(tenantID, messageType, data)
 }
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// headerAliases suggests the task field for common CSV column names
+var headerAliases = map[string]string{
+	"id":              FieldKey,
+	"key":             FieldKey,
+	"issue key":       FieldKey,
+	"parent":          FieldParentKey,
+	"parent id":       FieldParentKey,
+	"parent key":      FieldParentKey,
+	"title":           FieldTitle,
+	"name":            FieldTitle,
+	"summary":         FieldTitle,
+	"task":            FieldTitle,
+	"description":     FieldDescription,
+	"notes":           FieldDescription,
+	"status":          FieldStatus,
+	"state":           FieldStatus,
+	"priority":        FieldPriority,
+	"project":         FieldProject,
+	"project name":    FieldProject,
+	"tags":            FieldTags,
+	"labels":          FieldTags,
+	"assignee":        FieldAssignee,
+	"assignee email":  FieldAssignee,
+	"assigned to":     FieldAssignee,
+	"owner":           FieldAssignee,
+	"creator":         FieldCreator,
+	"reporter":        FieldCreator,
+	"created by":      FieldCreator,
+	"start":           FieldStartDate,
+	"start date":      FieldStartDate,
+	"due":             FieldDueDate,
+	"due date":        FieldDueDate,
+	"deadline":        FieldDueDate,
+	"estimate":        FieldEstimatedHours,
+	"estimated hours": FieldEstimatedHours,
+	"comment":         FieldComment,
+	"comments":        FieldComment,
+}
+
+// readCSV reads every row of a CSV file, trimming a UTF-8 byte order mark
+func readCSV(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file is empty")
+	}
+	return rows, nil
+}
+
+// ReadHeaders returns the header row of a CSV file
+func ReadHeaders(data []byte) ([]string, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	headers := make([]string, len(rows[0]))
+	for i, header := range rows[0] {
+		headers[i] = strings.TrimSpace(header)
+	}
+	return headers, nil
+}
+
+// SuggestMapping proposes a field mapping from well-known column names
+func SuggestMapping(headers []string) map[string]string {
+	mapping := map[string]string{}
+	for _, header := range headers {
+		field, ok := headerAliases[strings.ToLower(header)]
+		if !ok {
+			continue
+		}
+		if _, taken := mapping[field]; !taken {
+			mapping[field] = header
+		}
+	}
+	return mapping
+}
+
+// ValidateMapping checks a mapping names known fields and existing columns
+func ValidateMapping(headers []string, mapping map[string]string) error {
+	columns := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		columns[header] = true
+	}
+	known := make(map[string]bool, len(Fields))
+	for _, field := range Fields {
+		known[field] = true
+	}
+
+	for field, column := range mapping {
+		if !known[field] {
+			return fmt.Errorf("unknown field %q", field)
+		}
+		if !columns[column] {
+			return fmt.Errorf("column %q mapped to %s does not exist", column, field)
+		}
+	}
+	if mapping[FieldTitle] == "" {
+		return fmt.Errorf("a column must be mapped to title")
+	}
+	return nil
+}
+
+// ParseCSV reads the records of a generic CSV file using a field to column mapping
+func ParseCSV(data []byte, mapping map[string]string) ([]Record, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(rows[0]))
+	for i, header := range rows[0] {
+		index[strings.TrimSpace(header)] = i
+	}
+	columns := make(map[string]int, len(mapping))
+	for field, column := range mapping {
+		i, ok := index[column]
+		if !ok {
+			return nil, fmt.Errorf("column %q mapped to %s does not exist", column, field)
+		}
+		columns[field] = i
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		if isBlank(row) {
+			continue
+		}
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		record := Record{
+			Row:            n + 2, // 1-based and after the header row
+			Key:            value(FieldKey),
+			ParentKey:      value(FieldParentKey),
+			Title:          value(FieldTitle),
+			Description:    value(FieldDescription),
+			Status:         value(FieldStatus),
+			Priority:       value(FieldPriority),
+			Project:        value(FieldProject),
+			Tags:           splitList(value(FieldTags)),
+			Assignee:       value(FieldAssignee),
+			Creator:        value(FieldCreator),
+			StartDate:      value(FieldStartDate),
+			DueDate:        value(FieldDueDate),
+			EstimatedHours: value(FieldEstimatedHours),
+		}
+		if comment := value(FieldComment); comment != "" {
+			record.Comments = append(record.Comments, Comment{Body: comment})
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// isBlank checks if every cell of a row is empty
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
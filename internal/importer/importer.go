@@ -0,0 +1,788 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// progressInterval is the number of rows imported between progress updates
+const progressInterval = 20
+
+// Importer runs imports in the background and undoes them
+type Importer struct {
+	db     *gorm.DB
+	logger *logrus.Logger
+}
+
+// New creates a new importer
+func New(db *gorm.DB, logger *logrus.Logger) *Importer {
+	return &Importer{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// row is a record that passed validation, with its values parsed
+type row struct {
+	Record
+	status     models.TaskStatus
+	priority   models.TaskPriority
+	startDate  *time.Time
+	dueDate    *time.Time
+	hours      *float64
+	tags       []string
+	project    *projectRef
+	assigneeID *uuid.UUID
+	creatorID  uuid.UUID
+	parentKey  string
+}
+
+// projectRef is a project rows are imported into, created on first use
+type projectRef struct {
+	id   *uuid.UUID
+	name string
+}
+
+// run holds the state of one import while it is processed
+type run struct {
+	db       *gorm.DB
+	imp      *models.Import
+	user     *models.User
+	users    map[string]*uuid.UUID
+	projects map[string]*projectRef
+	tags     map[string]*uuid.UUID
+	tasks    map[string]uuid.UUID
+	summary  models.ImportSummary
+	errors   []models.ImportRowError
+}
+
+// Run processes a queued import. Dry runs only validate the rows; real runs
+// create the projects, tags, tasks, comments and checklist items of every
+// valid row and remember them so the import can be undone.
+func (im *Importer) Run(ctx context.Context, importID uuid.UUID) error {
+	db := im.db.WithContext(ctx)
+
+	var imp models.Import
+	if err := db.Where("id = ?", importID).First(&imp).Error; err != nil {
+		return fmt.Errorf("failed to load import: %w", err)
+	}
+	if imp.Status != models.ImportStatusQueued {
+		im.logger.WithFields(logrus.Fields{
+			"import_id": imp.ID,
+			"status":    imp.Status,
+		}).Warn("Skipping import that is not queued")
+		return nil
+	}
+
+	now := time.Now()
+	imp.Status = models.ImportStatusRunning
+	imp.StartedAt = &now
+	imp.FinishedAt = nil
+	imp.TotalRows = 0
+	imp.Processed = 0
+	imp.Summary = models.ImportSummary{}
+	imp.Errors = nil
+	imp.Failure = ""
+	if err := im.save(db, &imp); err != nil {
+		return fmt.Errorf("failed to start import: %w", err)
+	}
+
+	if err := im.process(db, &imp); err != nil {
+		finished := time.Now()
+		imp.Status = models.ImportStatusFailed
+		imp.Failure = err.Error()
+		imp.FinishedAt = &finished
+		if saveErr := im.save(db, &imp); saveErr != nil {
+			im.logger.WithError(saveErr).WithField("import_id", imp.ID).Error("Failed to record import failure")
+		}
+		return err
+	}
+
+	im.logger.WithFields(logrus.Fields{
+		"import_id": imp.ID,
+		"status":    imp.Status,
+		"tasks":     imp.Summary.Tasks,
+		"skipped":   imp.Summary.SkippedRows,
+	}).Info("Import finished")
+	return nil
+}
+
+// process parses, validates and imports the rows of an import
+func (im *Importer) process(db *gorm.DB, imp *models.Import) error {
+	records, err := Parse(imp)
+	if err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := db.Where("id = ? AND tenant_id = ?", imp.CreatedBy, imp.TenantID).First(&user).Error; err != nil {
+		return fmt.Errorf("importing user not found: %w", err)
+	}
+
+	r := &run{
+		db:       db,
+		imp:      imp,
+		user:     &user,
+		users:    map[string]*uuid.UUID{},
+		projects: map[string]*projectRef{},
+		tags:     map[string]*uuid.UUID{},
+		tasks:    map[string]uuid.UUID{},
+	}
+	if imp.ProjectID != nil {
+		if err := r.checkDefaultProject(); err != nil {
+			return err
+		}
+	}
+
+	imp.TotalRows = len(records)
+	rows, err := r.validate(records)
+	if err != nil {
+		return err
+	}
+	imp.Processed = len(records) - len(rows)
+
+	if imp.DryRun {
+		if err := r.preview(rows); err != nil {
+			return err
+		}
+		imp.Processed = len(records)
+		return im.finish(db, imp, r, models.ImportStatusValidated)
+	}
+
+	imp.Errors = r.errors
+	if err := im.save(db, imp); err != nil {
+		return err
+	}
+
+	for i, row := range rows {
+		if err := r.importRow(row); err != nil {
+			r.rowError(row.Row, "", "failed to import row: "+err.Error(), models.ImportSeverityError)
+			r.summary.SkippedRows++
+		}
+		imp.Processed++
+		if (i+1)%progressInterval == 0 {
+			imp.Summary = r.summary
+			imp.Errors = r.errors
+			if err := im.save(db, imp); err != nil {
+				im.logger.WithError(err).WithField("import_id", imp.ID).Warn("Failed to update import progress")
+			}
+		}
+	}
+
+	return im.finish(db, imp, r, models.ImportStatusCompleted)
+}
+
+// finish stores the outcome of an import
+func (im *Importer) finish(db *gorm.DB, imp *models.Import, r *run, status models.ImportStatus) error {
+	now := time.Now()
+	imp.Status = status
+	imp.Summary = r.summary
+	imp.Errors = r.errors
+	imp.FinishedAt = &now
+	return im.save(db, imp)
+}
+
+// save writes the progress columns of an import
+func (im *Importer) save(db *gorm.DB, imp *models.Import) error {
+	return db.Model(imp).
+		Select("status", "started_at", "finished_at", "total_rows", "processed", "summary", "errors", "failure").
+		Updates(imp).Error
+}
+
+// rowError reports a problem with a row
+func (r *run) rowError(rowNumber int, field, message string, severity models.ImportSeverity) {
+	r.errors = append(r.errors, models.ImportRowError{
+		Row:      rowNumber,
+		Field:    field,
+		Message:  message,
+		Severity: severity,
+	})
+}
+
+// checkDefaultProject makes sure the importing user may add tasks to the
+// project chosen for rows without one
+func (r *run) checkDefaultProject() error {
+	var project models.Project
+	if err := r.db.Where("id = ? AND tenant_id = ?", *r.imp.ProjectID, r.imp.TenantID).First(&project).Error; err != nil {
+		return fmt.Errorf("default project not found: %w", err)
+	}
+	if msg := r.checkProject(&project); msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	r.projects[""] = &projectRef{id: &project.ID, name: project.Name}
+	return nil
+}
+
+// checkProject returns why the importing user may not add tasks to a project,
+// or an empty string when they may
+func (r *run) checkProject(project *models.Project) string {
+	role, err := models.ProjectRoleOf(r.db, r.user, project)
+	if err != nil || !role.CanRead() {
+		return fmt.Sprintf("project %q not found", project.Name)
+	}
+	if project.IsArchived() {
+		return fmt.Sprintf("project %q is archived", project.Name)
+	}
+	if !role.CanEdit() {
+		return fmt.Sprintf("insufficient permissions for project %q", project.Name)
+	}
+	return ""
+}
+
+// validate parses and checks every record. Rows with errors are skipped and
+// the remaining rows are returned with parents ahead of their subtasks.
+func (r *run) validate(records []Record) ([]*row, error) {
+	keys := map[string]int{}
+	for _, record := range records {
+		if record.Key != "" {
+			keys[record.Key]++
+		}
+	}
+
+	var rows []*row
+	for _, record := range records {
+		if record.Archived {
+			r.rowError(record.Row, "", "archived card skipped", models.ImportSeverityWarning)
+			r.summary.SkippedRows++
+			continue
+		}
+
+		valid := true
+		fail := func(field, message string) {
+			r.rowError(record.Row, field, message, models.ImportSeverityError)
+			valid = false
+		}
+		warn := func(field, message string) {
+			r.rowError(record.Row, field, message, models.ImportSeverityWarning)
+		}
+
+		rw := &row{Record: record, creatorID: r.user.ID, parentKey: record.ParentKey}
+
+		if record.Title == "" {
+			fail(FieldTitle, "title is required")
+		} else if utf8.RuneCountInString(record.Title) > 255 {
+			fail(FieldTitle, "title is longer than 255 characters")
+		}
+		if record.Key != "" && keys[record.Key] > 1 {
+			fail(FieldKey, fmt.Sprintf("key %q is used by more than one row", record.Key))
+		}
+
+		var ok bool
+		if rw.status, ok = ParseStatus(record.Status); !ok {
+			fail(FieldStatus, fmt.Sprintf("unknown status %q", record.Status))
+		}
+		if rw.priority, ok = ParsePriority(record.Priority); !ok {
+			fail(FieldPriority, fmt.Sprintf("unknown priority %q", record.Priority))
+		}
+
+		var err error
+		if rw.startDate, err = ParseDate(record.StartDate); err != nil {
+			fail(FieldStartDate, err.Error())
+		}
+		if rw.dueDate, err = ParseDate(record.DueDate); err != nil {
+			fail(FieldDueDate, err.Error())
+		}
+		if rw.startDate != nil && rw.dueDate != nil && rw.dueDate.Before(*rw.startDate) {
+			fail(FieldDueDate, "due date cannot be before start date")
+		}
+		if rw.hours, err = ParseHours(record.EstimatedHours); err != nil {
+			fail(FieldEstimatedHours, err.Error())
+		}
+
+		for _, tag := range record.Tags {
+			name, err := models.NormalizeTagName(tag)
+			if err != nil || utf8.RuneCountInString(name) > 100 {
+				warn(FieldTags, fmt.Sprintf("invalid tag %q ignored", tag))
+				continue
+			}
+			rw.tags = append(rw.tags, name)
+		}
+
+		project, msg, err := r.project(record.Project)
+		if err != nil {
+			return nil, err
+		}
+		if msg != "" {
+			fail(FieldProject, msg)
+		}
+		rw.project = project
+
+		if record.Assignee != "" {
+			if rw.assigneeID, err = r.resolveUser(record.Assignee); err != nil {
+				return nil, err
+			}
+			if rw.assigneeID == nil {
+				warn(FieldAssignee, fmt.Sprintf("unknown user %q, task left unassigned", record.Assignee))
+			}
+		}
+		if record.Creator != "" {
+			creatorID, err := r.resolveUser(record.Creator)
+			if err != nil {
+				return nil, err
+			}
+			if creatorID != nil {
+				rw.creatorID = *creatorID
+			} else {
+				warn(FieldCreator, fmt.Sprintf("unknown user %q, the importing user is recorded as creator", record.Creator))
+			}
+		}
+
+		if !valid {
+			r.summary.SkippedRows++
+			continue
+		}
+		rows = append(rows, rw)
+	}
+
+	return r.order(rows), nil
+}
+
+// order drops parent references that cannot be resolved and sorts rows so
+// parents are imported before their subtasks
+func (r *run) order(rows []*row) []*row {
+	byKey := map[string]*row{}
+	for _, rw := range rows {
+		if rw.Key != "" {
+			byKey[rw.Key] = rw
+		}
+	}
+	for _, rw := range rows {
+		if rw.parentKey != "" && byKey[rw.parentKey] == nil {
+			r.rowError(rw.Row, FieldParentKey, fmt.Sprintf("parent %q not imported, task added at the top level", rw.parentKey), models.ImportSeverityWarning)
+			rw.parentKey = ""
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[*row]int{}
+	ordered := make([]*row, 0, len(rows))
+	var visit func(rw *row)
+	visit = func(rw *row) {
+		state[rw] = visiting
+		if rw.parentKey != "" {
+			parent := byKey[rw.parentKey]
+			switch state[parent] {
+			case visiting:
+				r.rowError(rw.Row, FieldParentKey, "circular parent reference, task added at the top level", models.ImportSeverityWarning)
+				rw.parentKey = ""
+			case 0:
+				visit(parent)
+			}
+		}
+		state[rw] = visited
+		ordered = append(ordered, rw)
+	}
+	for _, rw := range rows {
+		if state[rw] == 0 {
+			visit(rw)
+		}
+	}
+	return ordered
+}
+
+// project finds the project named in a row. Unknown projects are created when
+// the first row using them is imported. The message explains why the row may
+// not use the project.
+func (r *run) project(name string) (*projectRef, string, error) {
+	name = strings.TrimSpace(name)
+	key := strings.ToLower(name)
+	if ref, ok := r.projects[key]; ok {
+		return ref, "", nil
+	}
+	if name == "" {
+		return nil, "", nil
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return nil, "project name is longer than 255 characters", nil
+	}
+
+	var projects []models.Project
+	if err := r.db.
+		Where("tenant_id = ? AND LOWER(name) = LOWER(?)", r.imp.TenantID, name).
+		Order("is_active DESC, created_at ASC").
+		Limit(1).
+		Find(&projects).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to look up project: %w", err)
+	}
+
+	ref := &projectRef{name: name}
+	if len(projects) > 0 {
+		if msg := r.checkProject(&projects[0]); msg != "" {
+			return nil, msg, nil
+		}
+		ref.id = &projects[0].ID
+	}
+	r.projects[key] = ref
+	return ref, "", nil
+}
+
+// resolveUser finds a tenant user by email, after translating external user
+// names through the user map of the import
+func (r *run) resolveUser(ref string) (*uuid.UUID, error) {
+	ref = strings.TrimSpace(ref)
+	key := strings.ToLower(ref)
+	if id, ok := r.users[key]; ok {
+		return id, nil
+	}
+
+	email := ref
+	for external, mapped := range r.imp.UserMap {
+		if strings.EqualFold(external, ref) {
+			email = mapped
+			break
+		}
+	}
+
+	var id *uuid.UUID
+	if strings.Contains(email, "@") {
+		var users []models.User
+		if err := r.db.
+			Where("tenant_id = ? AND LOWER(email) = LOWER(?)", r.imp.TenantID, strings.TrimSpace(email)).
+			Limit(1).
+			Find(&users).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up user: %w", err)
+		}
+		if len(users) > 0 {
+			id = &users[0].ID
+		}
+	}
+
+	r.users[key] = id
+	return id, nil
+}
+
+// preview counts what a real run would create without writing anything
+func (r *run) preview(rows []*row) error {
+	projects := map[*projectRef]bool{}
+	tags := map[string]bool{}
+	for _, rw := range rows {
+		r.summary.Tasks++
+		r.summary.Comments += len(rw.Comments)
+		r.summary.ChecklistItems += len(rw.Checklist)
+		if rw.project != nil && rw.project.id == nil {
+			projects[rw.project] = true
+		}
+		for _, name := range rw.tags {
+			for ; name != ""; name = models.TagParentName(name) {
+				tags[strings.ToLower(name)] = true
+			}
+		}
+	}
+
+	r.summary.Projects = len(projects)
+	for name := range tags {
+		tag, err := models.FindTagByName(r.db, r.imp.TenantID, name)
+		if err != nil {
+			return fmt.Errorf("failed to look up tag: %w", err)
+		}
+		if tag == nil {
+			r.summary.Tags++
+		}
+	}
+	return nil
+}
+
+// importRow creates the task of a row with its comments and checklist
+func (r *run) importRow(rw *row) error {
+	var projectID *uuid.UUID
+	if rw.project != nil {
+		id, err := r.ensureProject(rw.project)
+		if err != nil {
+			return err
+		}
+		projectID = &id
+	}
+
+	var tagIDs []uuid.UUID
+	for _, name := range rw.tags {
+		id, err := r.ensureTag(name)
+		if err != nil {
+			return err
+		}
+		tagIDs = append(tagIDs, id)
+	}
+
+	task := &models.Task{
+		TenantModel:    models.TenantModel{TenantID: r.imp.TenantID},
+		Title:          rw.Title,
+		Description:    rw.Description,
+		Status:         rw.status,
+		Priority:       rw.priority,
+		StartDate:      rw.startDate,
+		DueDate:        rw.dueDate,
+		EstimatedHours: rw.hours,
+		CreatorID:      rw.creatorID,
+		AssigneeID:     rw.assigneeID,
+		ProjectID:      projectID,
+	}
+	if rw.status == models.TaskStatusCompleted {
+		task.MarkAsCompleted()
+	}
+	if rw.parentKey != "" {
+		parentID, ok := r.tasks[rw.parentKey]
+		if ok {
+			task.ParentID = &parentID
+		} else {
+			r.rowError(rw.Row, FieldParentKey, fmt.Sprintf("parent %q was not imported, task added at the top level", rw.parentKey), models.ImportSeverityWarning)
+		}
+	}
+
+	var comments, checklist int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		records := []models.ImportRecord{{ImportID: r.imp.ID, EntityType: models.ImportEntityTask, EntityID: task.ID}}
+
+		for _, tagID := range tagIDs {
+			if err := tx.Create(&models.TaskTag{TaskID: task.ID, TagID: tagID}).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, c := range rw.Comments {
+			if strings.TrimSpace(c.Body) == "" {
+				continue
+			}
+			comment := &models.TaskComment{
+				TenantModel: models.TenantModel{TenantID: r.imp.TenantID},
+				TaskID:      task.ID,
+				UserID:      r.user.ID,
+				Content:     c.Body,
+			}
+			if c.CreatedAt != nil {
+				comment.CreatedAt = *c.CreatedAt
+			}
+			if c.Author != "" {
+				authorID, err := r.resolveUser(c.Author)
+				if err != nil {
+					return err
+				}
+				if authorID != nil {
+					comment.UserID = *authorID
+				} else {
+					comment.Content = c.Author + ": " + c.Body
+				}
+			}
+			if err := tx.Create(comment).Error; err != nil {
+				return err
+			}
+			records = append(records, models.ImportRecord{ImportID: r.imp.ID, EntityType: models.ImportEntityComment, EntityID: comment.ID})
+			comments++
+		}
+
+		for i, entry := range rw.Checklist {
+			text := strings.TrimSpace(entry.Text)
+			if text == "" {
+				continue
+			}
+			if utf8.RuneCountInString(text) > 500 {
+				text = string([]rune(text)[:500])
+			}
+			item := &models.ChecklistItem{
+				TenantModel: models.TenantModel{TenantID: r.imp.TenantID},
+				TaskID:      task.ID,
+				Text:        text,
+				Position:    i,
+				CreatedBy:   r.user.ID,
+			}
+			item.SetDone(entry.IsDone, r.user.ID)
+			if err := tx.Create(item).Error; err != nil {
+				return err
+			}
+			records = append(records, models.ImportRecord{ImportID: r.imp.ID, EntityType: models.ImportEntityChecklistItem, EntityID: item.ID})
+			checklist++
+		}
+
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rw.Key != "" {
+		r.tasks[rw.Key] = task.ID
+	}
+	r.summary.Tasks++
+	r.summary.Comments += comments
+	r.summary.ChecklistItems += checklist
+	return nil
+}
+
+// ensureProject creates a project the first time a row uses it, with the
+// importing user as owner
+func (r *run) ensureProject(ref *projectRef) (uuid.UUID, error) {
+	if ref.id != nil {
+		return *ref.id, nil
+	}
+
+	project := &models.Project{
+		TenantModel: models.TenantModel{TenantID: r.imp.TenantID},
+		Name:        ref.name,
+		IsActive:    true,
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(project).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.ProjectMember{
+			ProjectID: project.ID,
+			UserID:    r.user.ID,
+			TenantID:  r.imp.TenantID,
+			Role:      models.ProjectRoleOwner,
+			AddedBy:   &r.user.ID,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ImportRecord{ImportID: r.imp.ID, EntityType: models.ImportEntityProject, EntityID: project.ID}).Error
+	})
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create project %q: %w", ref.name, err)
+	}
+
+	ref.id = &project.ID
+	r.summary.Projects++
+	return project.ID, nil
+}
+
+// ensureTag finds a tag by name, creating it and its missing parents
+func (r *run) ensureTag(name string) (uuid.UUID, error) {
+	key := strings.ToLower(name)
+	if id := r.tags[key]; id != nil {
+		return *id, nil
+	}
+
+	tag, err := models.FindTagByName(r.db, r.imp.TenantID, name)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to look up tag: %w", err)
+	}
+	if tag == nil {
+		tag = &models.Tag{
+			TenantModel: models.TenantModel{TenantID: r.imp.TenantID},
+			Name:        name,
+		}
+		if parentName := models.TagParentName(name); parentName != "" {
+			parentID, err := r.ensureTag(parentName)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			tag.ParentID = &parentID
+		}
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(tag).Error; err != nil {
+				return err
+			}
+			return tx.Create(&models.ImportRecord{ImportID: r.imp.ID, EntityType: models.ImportEntityTag, EntityID: tag.ID}).Error
+		})
+		if models.IsTagNameConflict(err) {
+			// Created concurrently, by another import or a user
+			if tag, err = models.FindTagByName(r.db, r.imp.TenantID, name); err == nil && tag == nil {
+				err = fmt.Errorf("tag %q disappeared after a conflict", name)
+			}
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("failed to look up tag: %w", err)
+			}
+		} else if err != nil {
+			return uuid.Nil, fmt.Errorf("failed to create tag %q: %w", name, err)
+		} else {
+			r.summary.Tags++
+		}
+	}
+
+	r.tags[key] = &tag.ID
+	return tag.ID, nil
+}
+
+// Undo removes everything an import created. Tags and projects that other
+// tasks have started using since the import are kept and counted in the result.
+func (im *Importer) Undo(ctx context.Context, imp *models.Import) (kept int, err error) {
+	err = im.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		created := func(entityType string) *gorm.DB {
+			return tx.Model(&models.ImportRecord{}).
+				Select("entity_id").
+				Where("import_id = ? AND entity_type = ?", imp.ID, entityType)
+		}
+
+		if err := tx.Where("id IN (?)", created(models.ImportEntityComment)).Delete(&models.TaskComment{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN (?)", created(models.ImportEntityChecklistItem)).Delete(&models.ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id IN (?)", created(models.ImportEntityTask)).Delete(&models.TaskTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN (?)", created(models.ImportEntityTask)).Delete(&models.Task{}).Error; err != nil {
+			return err
+		}
+
+		// Nested tags are removed before their parents
+		var tags []models.Tag
+		if err := tx.Where("id IN (?)", created(models.ImportEntityTag)).Order("LENGTH(name) DESC").Find(&tags).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			var used int64
+			if err := tx.Model(&models.TaskTag{}).
+				Joins("JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+				Where("task_tags.tag_id = ?", tag.ID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			var children int64
+			if err := tx.Model(&models.Tag{}).Where("parent_id = ?", tag.ID).Count(&children).Error; err != nil {
+				return err
+			}
+			if used > 0 || children > 0 {
+				kept++
+				continue
+			}
+			if err := tx.Delete(&tag).Error; err != nil {
+				return err
+			}
+		}
+
+		var projects []models.Project
+		if err := tx.Where("id IN (?)", created(models.ImportEntityProject)).Find(&projects).Error; err != nil {
+			return err
+		}
+		for _, project := range projects {
+			var tasks int64
+			if err := tx.Model(&models.Task{}).Where("project_id = ?", project.ID).Count(&tasks).Error; err != nil {
+				return err
+			}
+			if tasks > 0 {
+				kept++
+				continue
+			}
+			if err := tx.Where("project_id = ?", project.ID).Delete(&models.ProjectMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&project).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("import_id = ?", imp.ID).Delete(&models.ImportRecord{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		imp.Status = models.ImportStatusUndone
+		imp.UndoneAt = &now
+		return tx.Model(imp).Select("status", "undone_at").Updates(imp).Error
+	})
+	return kept, err
+}
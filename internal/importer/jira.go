@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseJira reads a Jira issue CSV export. Jira repeats columns such as Labels
+// and Comment once per value, and stores comments as "date;author;body".
+func ParseJira(data []byte) ([]Record, error) {
+	rows, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string][]int{}
+	for i, header := range rows[0] {
+		name := strings.ToLower(strings.TrimSpace(header))
+		columns[name] = append(columns[name], i)
+	}
+	if len(columns["summary"]) == 0 {
+		return nil, fmt.Errorf("not a Jira export: the Summary column is missing")
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		if isBlank(row) {
+			continue
+		}
+		first := func(names ...string) string {
+			for _, name := range names {
+				for _, i := range columns[name] {
+					if i < len(row) && strings.TrimSpace(row[i]) != "" {
+						return strings.TrimSpace(row[i])
+					}
+				}
+			}
+			return ""
+		}
+		all := func(name string) []string {
+			var values []string
+			for _, i := range columns[name] {
+				if i < len(row) && strings.TrimSpace(row[i]) != "" {
+					values = append(values, strings.TrimSpace(row[i]))
+				}
+			}
+			return values
+		}
+
+		record := Record{
+			Row:         n + 2,
+			Key:         first("issue id"),
+			ParentKey:   first("parent id", "parent"),
+			Title:       first("summary"),
+			Description: first("description"),
+			Status:      first("status"),
+			Priority:    first("priority"),
+			Project:     first("project name"),
+			Assignee:    first("assignee email", "assignee"),
+			Creator:     first("reporter email", "reporter", "creator"),
+			StartDate:   first("start date", "custom field (start date)"),
+			DueDate:     first("due date", "due"),
+		}
+		if record.Key == "" {
+			record.Key = first("issue key")
+		}
+		for _, label := range all("labels") {
+			record.Tags = append(record.Tags, strings.Fields(label)...)
+		}
+		if component := first("component/s", "components"); component != "" {
+			record.Tags = append(record.Tags, "component/"+component)
+		}
+		// Jira exports estimates in seconds
+		if seconds := first("original estimate", "original estimate (seconds)"); seconds != "" {
+			if value, err := strconv.ParseFloat(seconds, 64); err == nil {
+				record.EstimatedHours = strconv.FormatFloat(value/3600, 'f', -1, 64)
+			} else {
+				record.EstimatedHours = seconds
+			}
+		}
+		for _, comment := range all("comment") {
+			record.Comments = append(record.Comments, parseJiraComment(comment))
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseJiraComment splits a Jira "date;author;body" comment, keeping the raw
+// text as the body when it does not follow that layout
+func parseJiraComment(value string) Comment {
+	parts := strings.SplitN(value, ";", 3)
+	if len(parts) == 3 {
+		if createdAt, err := ParseDate(parts[0]); err == nil && createdAt != nil {
+			return Comment{Author: strings.TrimSpace(parts[1]), Body: strings.TrimSpace(parts[2]), CreatedAt: createdAt}
+		}
+	}
+	return Comment{Body: value}
+}
@@ -0,0 +1,198 @@
+// Package importer turns CSV, Jira and Trello exports into TaskFlow projects,
+// tags, tasks and comments.
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Task fields a CSV column can be mapped to
+const (
+	FieldKey            = "key"
+	FieldParentKey      = "parent_key"
+	FieldTitle          = "title"
+	FieldDescription    = "description"
+	FieldStatus         = "status"
+	FieldPriority       = "priority"
+	FieldProject        = "project"
+	FieldTags           = "tags"
+	FieldAssignee       = "assignee"
+	FieldCreator        = "creator"
+	FieldStartDate      = "start_date"
+	FieldDueDate        = "due_date"
+	FieldEstimatedHours = "estimated_hours"
+	FieldComment        = "comment"
+)
+
+// Fields lists every mappable task field
+var Fields = []string{
+	FieldKey, FieldParentKey, FieldTitle, FieldDescription, FieldStatus, FieldPriority, FieldProject,
+	FieldTags, FieldAssignee, FieldCreator, FieldStartDate, FieldDueDate, FieldEstimatedHours, FieldComment,
+}
+
+// Comment is a comment attached to an imported task
+type Comment struct {
+	Author    string
+	Body      string
+	CreatedAt *time.Time
+}
+
+// ChecklistItem is a checklist entry attached to an imported task
+type ChecklistItem struct {
+	Text   string
+	IsDone bool
+}
+
+// Record is one task read from an import file, before it is validated
+type Record struct {
+	Row            int
+	Key            string
+	ParentKey      string
+	Title          string
+	Description    string
+	Status         string
+	Priority       string
+	Project        string
+	Tags           []string
+	Assignee       string
+	Creator        string
+	StartDate      string
+	DueDate        string
+	EstimatedHours string
+	Comments       []Comment
+	Checklist      []ChecklistItem
+	Archived       bool
+}
+
+// Parse reads the records of an import file for its source
+func Parse(imp *models.Import) ([]Record, error) {
+	switch imp.Source {
+	case models.ImportSourceCSV:
+		return ParseCSV(imp.Data, imp.Mapping)
+	case models.ImportSourceJira:
+		return ParseJira(imp.Data)
+	case models.ImportSourceTrello:
+		return ParseTrello(imp.Data)
+	}
+	return nil, fmt.Errorf("unsupported import source %q", imp.Source)
+}
+
+// statusAliases maps common workflow names onto task statuses
+var statusAliases = map[string]models.TaskStatus{
+	"todo":                     models.TaskStatusTodo,
+	"to do":                    models.TaskStatusTodo,
+	"open":                     models.TaskStatusTodo,
+	"backlog":                  models.TaskStatusTodo,
+	"new":                      models.TaskStatusTodo,
+	"selected for development": models.TaskStatusTodo,
+	"in_progress":              models.TaskStatusInProgress,
+	"in progress":              models.TaskStatusInProgress,
+	"doing":                    models.TaskStatusInProgress,
+	"started":                  models.TaskStatusInProgress,
+	"in_review":                models.TaskStatusInReview,
+	"in review":                models.TaskStatusInReview,
+	"review":                   models.TaskStatusInReview,
+	"code review":              models.TaskStatusInReview,
+	"testing":                  models.TaskStatusInReview,
+	"completed":                models.TaskStatusCompleted,
+	"complete":                 models.TaskStatusCompleted,
+	"done":                     models.TaskStatusCompleted,
+	"closed":                   models.TaskStatusCompleted,
+	"resolved":                 models.TaskStatusCompleted,
+	"canceled":                 models.TaskStatusCanceled,
+	"cancelled":                models.TaskStatusCanceled,
+	"won't do":                 models.TaskStatusCanceled,
+	"won't fix":                models.TaskStatusCanceled,
+}
+
+// priorityAliases maps common priority names onto task priorities
+var priorityAliases = map[string]models.TaskPriority{
+	"low":      models.TaskPriorityLow,
+	"lowest":   models.TaskPriorityLow,
+	"minor":    models.TaskPriorityLow,
+	"trivial":  models.TaskPriorityLow,
+	"medium":   models.TaskPriorityMedium,
+	"normal":   models.TaskPriorityMedium,
+	"major":    models.TaskPriorityHigh,
+	"high":     models.TaskPriorityHigh,
+	"highest":  models.TaskPriorityUrgent,
+	"urgent":   models.TaskPriorityUrgent,
+	"critical": models.TaskPriorityUrgent,
+	"blocker":  models.TaskPriorityUrgent,
+}
+
+// ParseStatus maps a status name onto a task status. Empty values default to todo.
+func ParseStatus(value string) (models.TaskStatus, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return models.TaskStatusTodo, true
+	}
+	status, ok := statusAliases[value]
+	return status, ok
+}
+
+// ParsePriority maps a priority name onto a task priority. Empty values default to medium.
+func ParsePriority(value string) (models.TaskPriority, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return models.TaskPriorityMedium, true
+	}
+	priority, ok := priorityAliases[value]
+	return priority, ok
+}
+
+// dateLayouts are the date formats accepted in import files
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06",
+	"01/02/2006",
+	"1/2/2006",
+}
+
+// ParseDate parses a date in one of the accepted formats. Empty values return nil.
+func ParseDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized date %q", value)
+}
+
+// ParseHours parses an estimate in hours. Empty values return nil.
+func ParseHours(value string) (*float64, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "h"))
+	if value == "" {
+		return nil, nil
+	}
+	hours, err := strconv.ParseFloat(value, 64)
+	if err != nil || hours < 0 {
+		return nil, fmt.Errorf("invalid number of hours %q", value)
+	}
+	return &hours, nil
+}
+
+// splitList splits a list of tags separated by commas, semicolons or pipes
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// trelloBoard is the subset of a Trello board JSON export read by the importer
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Labels []struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Color string `json:"color"`
+	} `json:"labels"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		FullName string `json:"fullName"`
+	} `json:"members"`
+	Cards []struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		IDList      string     `json:"idList"`
+		IDLabels    []string   `json:"idLabels"`
+		IDMembers   []string   `json:"idMembers"`
+		Start       *time.Time `json:"start"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		Closed      bool       `json:"closed"`
+		Pos         float64    `json:"pos"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+	Actions []struct {
+		Type string    `json:"type"`
+		Date time.Time `json:"date"`
+		Data struct {
+			Text string `json:"text"`
+			Card struct {
+				ID string `json:"id"`
+			} `json:"card"`
+		} `json:"data"`
+		MemberCreator struct {
+			Username string `json:"username"`
+		} `json:"memberCreator"`
+	} `json:"actions"`
+}
+
+// ParseTrello reads a Trello board JSON export. Each card becomes a task in a
+// project named after the board, and its list decides the task status.
+func ParseTrello(data []byte) ([]Record, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %w", err)
+	}
+	if board.Name == "" && len(board.Cards) == 0 {
+		return nil, fmt.Errorf("not a Trello board export")
+	}
+
+	lists := map[string]string{}
+	closedLists := map[string]bool{}
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+		closedLists[list.ID] = list.Closed
+	}
+	labels := map[string]string{}
+	for _, label := range board.Labels {
+		name := label.Name
+		if name == "" {
+			name = label.Color
+		}
+		labels[label.ID] = name
+	}
+	members := map[string]string{}
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+
+	// Trello keeps checklists and comments apart from the cards they belong to
+	checklists := map[string][]ChecklistItem{}
+	sort.SliceStable(board.Checklists, func(i, j int) bool { return board.Checklists[i].Pos < board.Checklists[j].Pos })
+	for _, checklist := range board.Checklists {
+		items := checklist.CheckItems
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Pos < items[j].Pos })
+		for _, item := range items {
+			checklists[checklist.IDCard] = append(checklists[checklist.IDCard], ChecklistItem{
+				Text:   item.Name,
+				IsDone: item.State == "complete",
+			})
+		}
+	}
+	comments := map[string][]Comment{}
+	for i := len(board.Actions) - 1; i >= 0; i-- { // actions are exported newest first
+		action := board.Actions[i]
+		if action.Type != "commentCard" || action.Data.Text == "" {
+			continue
+		}
+		createdAt := action.Date
+		comments[action.Data.Card.ID] = append(comments[action.Data.Card.ID], Comment{
+			Author:    action.MemberCreator.Username,
+			Body:      action.Data.Text,
+			CreatedAt: &createdAt,
+		})
+	}
+
+	records := make([]Record, 0, len(board.Cards))
+	for n, card := range board.Cards {
+		record := Record{
+			Row:         n + 1,
+			Key:         card.ID,
+			Title:       strings.TrimSpace(card.Name),
+			Description: card.Desc,
+			Status:      trelloStatus(lists[card.IDList], card.DueComplete),
+			Project:     board.Name,
+			Comments:    comments[card.ID],
+			Checklist:   checklists[card.ID],
+			Archived:    card.Closed || closedLists[card.IDList],
+		}
+		for _, id := range card.IDLabels {
+			if name := labels[id]; name != "" {
+				record.Tags = append(record.Tags, name)
+			}
+		}
+		if len(card.IDMembers) > 0 {
+			record.Assignee = members[card.IDMembers[0]]
+		}
+		if card.Start != nil {
+			record.StartDate = card.Start.Format(time.RFC3339)
+		}
+		if card.Due != nil {
+			record.DueDate = card.Due.Format(time.RFC3339)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// trelloStatus guesses a task status from the name of a Trello list
+func trelloStatus(list string, dueComplete bool) string {
+	if dueComplete {
+		return string(models.TaskStatusCompleted)
+	}
+	if status, ok := ParseStatus(list); ok {
+		return string(status)
+	}
+
+	name := strings.ToLower(list)
+	switch {
+	case strings.Contains(name, "done"), strings.Contains(name, "complete"), strings.Contains(name, "shipped"):
+		return string(models.TaskStatusCompleted)
+	case strings.Contains(name, "review"), strings.Contains(name, "qa"), strings.Contains(name, "test"):
+		return string(models.TaskStatusInReview)
+	case strings.Contains(name, "progress"), strings.Contains(name, "doing"), strings.Contains(name, "working"):
+		return string(models.TaskStatusInProgress)
+	}
+	return string(models.TaskStatusTodo)
+}
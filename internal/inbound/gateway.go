@@ -0,0 +1,470 @@
+// Package inbound turns email sent to project+<token>@ addresses into tasks,
+// and replies to those emails into comments on the task they started.
+package inbound
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/pkg/storage"
+)
+
+// addressPrefix is the local part prefix of project addresses
+const addressPrefix = "project+"
+
+// maxTitleLength is the longest task title taken from a subject
+const maxTitleLength = 255
+
+var (
+	// ErrUnknownAddress is returned when no recipient is an active project address
+	ErrUnknownAddress = errors.New("no recipient is an active project address")
+
+	// ErrProjectArchived is returned for mail sent to an archived project
+	ErrProjectArchived = errors.New("the project is archived")
+
+	// ErrOwnerNoAccess is returned when the user who created the address may
+	// no longer add to the project
+	ErrOwnerNoAccess = errors.New("the address owner can no longer act on the project")
+)
+
+var (
+	// subjectPrefix matches the reply and forward markers clients add to subjects
+	subjectPrefix = regexp.MustCompile(`(?i)^\s*((re|fw|fwd|aw|wg|sv|vs)\s*(\[\d+\])?\s*:\s*)+`)
+
+	// quoteHeader matches the line clients put above the quoted original
+	quoteHeader = regexp.MustCompile(`(?im)^\s*(>\s*)?(on\s.+wrote:|-+\s*original message\s*-+|_{10,})\s*$`)
+)
+
+// Gateway files inbound email as tasks and comments
+type Gateway struct {
+	db        *gorm.DB
+	store     *storage.Local
+	publisher events.Publisher
+	logger    *logrus.Logger
+}
+
+// New creates a new gateway. Attachments are saved to store.
+func New(db *gorm.DB, store *storage.Local, publisher events.Publisher, logger *logrus.Logger) *Gateway {
+	return &Gateway{
+		db:        db,
+		store:     store,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Result describes what an email was filed as
+type Result struct {
+	Task      *models.Task        `json:"task"`
+	Comment   *models.TaskComment `json:"comment,omitempty"`
+	Duplicate bool                `json:"duplicate"`
+}
+
+// AddressToken returns the token of the first project address among the
+// recipients
+func AddressToken(recipients []string) string {
+	for _, recipient := range recipients {
+		local := strings.ToLower(recipient)
+		if at := strings.LastIndexByte(local, '@'); at >= 0 {
+			local = local[:at]
+		}
+		if strings.HasPrefix(local, addressPrefix) && len(local) > len(addressPrefix) {
+			return local[len(addressPrefix):]
+		}
+	}
+	return ""
+}
+
+// Receive files an email. A reply to an email that created a task becomes a
+// comment on that task; any other email creates a task. An email that was
+// already received is not filed again.
+func (g *Gateway) Receive(ctx context.Context, msg *Message) (*Result, error) {
+	db := g.db.WithContext(ctx)
+
+	address, err := g.address(db, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var project models.Project
+	if err := db.Where("id = ? AND tenant_id = ?", address.ProjectID, address.TenantID).First(&project).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUnknownAddress
+		}
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+
+	if msg.MessageID == "" {
+		msg.MessageID = fmt.Sprintf("%s@taskflow.invalid", uuid.New())
+	} else if result, err := g.duplicate(db, address, msg.MessageID); err != nil || result != nil {
+		return result, err
+	}
+
+	thread, err := g.thread(db, address, msg)
+	if err != nil {
+		return nil, err
+	}
+	if thread != nil {
+		return g.comment(ctx, db, address, thread, msg)
+	}
+	if project.IsArchived() {
+		return nil, ErrProjectArchived
+	}
+	return g.task(ctx, db, address, &project, msg)
+}
+
+// address finds the active project address the email was sent to
+func (g *Gateway) address(db *gorm.DB, msg *Message) (*models.InboundAddress, error) {
+	token := AddressToken(msg.Recipients)
+	if token == "" {
+		return nil, ErrUnknownAddress
+	}
+
+	var address models.InboundAddress
+	if err := db.Where("token = ? AND revoked_at IS NULL", token).First(&address).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrUnknownAddress
+		}
+		return nil, fmt.Errorf("failed to load inbound address: %w", err)
+	}
+	return &address, nil
+}
+
+// duplicate returns the result of an email that was already filed
+func (g *Gateway) duplicate(db *gorm.DB, address *models.InboundAddress, messageID string) (*Result, error) {
+	var received []models.InboundMessage
+	if err := db.Where("tenant_id = ? AND message_id = ?", address.TenantID, messageID).Limit(1).Find(&received).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up message: %w", err)
+	}
+	if len(received) == 0 {
+		return nil, nil
+	}
+
+	result := &Result{Task: &models.Task{}, Duplicate: true}
+	if err := db.Unscoped().Where("id = ?", received[0].TaskID).First(result.Task).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	if received[0].CommentID != nil {
+		result.Comment = &models.TaskComment{}
+		if err := db.Unscoped().Where("id = ?", *received[0].CommentID).First(result.Comment).Error; err != nil {
+			return nil, fmt.Errorf("failed to load comment: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// thread finds the task started by an email this one replies to, if that
+// task still exists. Only emails received for the address's project, about
+// tasks still in it, are followed, so a reply cannot reach another project by
+// quoting one of its Message-IDs.
+func (g *Gateway) thread(db *gorm.DB, address *models.InboundAddress, msg *Message) (*models.Task, error) {
+	ids := append([]string{}, msg.InReplyTo...)
+	for i := len(msg.References) - 1; i >= 0; i-- {
+		ids = append(ids, msg.References[i])
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var received []models.InboundMessage
+	if err := db.Where("tenant_id = ? AND message_id IN ? AND address_id IN (?)", address.TenantID, ids,
+		db.Model(&models.InboundAddress{}).Select("id").Where("project_id = ?", address.ProjectID)).
+		Order("created_at DESC").Limit(1).Find(&received).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up thread: %w", err)
+	}
+	if len(received) == 0 {
+		return nil, nil
+	}
+
+	var tasks []models.Task
+	if err := db.Where("id = ? AND tenant_id = ? AND project_id = ?", received[0].TaskID, address.TenantID, address.ProjectID).
+		Limit(1).Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	return &tasks[0], nil
+}
+
+// task creates a task from an email
+func (g *Gateway) task(ctx context.Context, db *gorm.DB, address *models.InboundAddress, project *models.Project, msg *Message) (*Result, error) {
+	author, err := g.author(db, address, project, models.ProjectRole.CanEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &models.Task{
+		TenantModel: models.TenantModel{TenantID: address.TenantID},
+		Title:       Title(msg.Subject),
+		Description: senderNote(msg) + Body(msg),
+		Status:      models.TaskStatusTodo,
+		Priority:    models.TaskPriorityMedium,
+		CreatorID:   author,
+		ProjectID:   &project.ID,
+	}
+	task.ID = uuid.New()
+
+	attachments, err := g.saveAttachments(task, author, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			if err := tx.Create(&attachments).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(g.received(address, msg, task.ID, nil)).Error
+	})
+	if err != nil {
+		g.removeAttachments(attachments)
+		return nil, fmt.Errorf("failed to create task from email: %w", err)
+	}
+
+	events.Publish(ctx, g.publisher, g.logger, task.TenantID, &author, events.TaskCreated{Task: events.NewTaskSnapshot(task)})
+
+	g.logger.WithFields(logrus.Fields{
+		"task_id":     task.ID,
+		"project_id":  project.ID,
+		"message_id":  msg.MessageID,
+		"attachments": len(attachments),
+	}).Info("Task created from email")
+
+	task.Attachments = attachments
+	return &Result{Task: task}, nil
+}
+
+// comment adds a reply to the task its thread started
+func (g *Gateway) comment(ctx context.Context, db *gorm.DB, address *models.InboundAddress, task *models.Task, msg *Message) (*Result, error) {
+	var project models.Project
+	if task.ProjectID != nil {
+		if err := db.Unscoped().Where("id = ?", *task.ProjectID).First(&project).Error; err != nil {
+			return nil, fmt.Errorf("failed to load project: %w", err)
+		}
+		if project.IsArchived() {
+			return nil, ErrProjectArchived
+		}
+	}
+
+	author, err := g.author(db, address, &project, models.ProjectRole.CanComment)
+	if err != nil {
+		return nil, err
+	}
+
+	content := StripQuoted(Body(msg))
+	if content == "" && len(msg.Attachments) == 0 {
+		content = "(empty reply)"
+	}
+
+	comment := &models.TaskComment{
+		TenantModel: models.TenantModel{TenantID: task.TenantID},
+		TaskID:      task.ID,
+		UserID:      author,
+		Content:     senderNote(msg) + content,
+	}
+	comment.ID = uuid.New()
+
+	attachments, err := g.saveAttachments(task, author, msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(attachments) > 0 && content == "" {
+		comment.Content = senderNote(msg) + fmt.Sprintf("Attached %d file(s)", len(attachments))
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if len(attachments) > 0 {
+			if err := tx.Create(&attachments).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Create(g.received(address, msg, task.ID, &comment.ID)).Error
+	})
+	if err != nil {
+		g.removeAttachments(attachments)
+		return nil, fmt.Errorf("failed to add comment from email: %w", err)
+	}
+
+	events.Publish(ctx, g.publisher, g.logger, task.TenantID, &author, events.CommentAdded{
+		Task:    events.NewTaskSnapshot(task),
+		Comment: events.NewCommentSnapshot(comment),
+	})
+
+	g.logger.WithFields(logrus.Fields{
+		"task_id":     task.ID,
+		"comment_id":  comment.ID,
+		"message_id":  msg.MessageID,
+		"attachments": len(attachments),
+	}).Info("Comment added from email")
+
+	return &Result{Task: task, Comment: comment}, nil
+}
+
+// author returns the owner of the address, who every email to it is filed
+// under. The From header can be forged, so it is only recorded as text.
+// Mail is refused once the owner may no longer act on the project.
+func (g *Gateway) author(db *gorm.DB, address *models.InboundAddress, project *models.Project,
+	allowed func(models.ProjectRole) bool) (uuid.UUID, error) {
+	var owner models.User
+	if err := db.Where("id = ? AND tenant_id = ?", address.CreatedBy, address.TenantID).First(&owner).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return uuid.Nil, ErrOwnerNoAccess
+		}
+		return uuid.Nil, fmt.Errorf("failed to load address owner: %w", err)
+	}
+	if !owner.IsActive() {
+		return uuid.Nil, ErrOwnerNoAccess
+	}
+
+	role, err := models.ProjectRoleOf(db, &owner, project)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to check address owner permissions: %w", err)
+	}
+	if !allowed(role) {
+		return uuid.Nil, ErrOwnerNoAccess
+	}
+	return owner.ID, nil
+}
+
+// received records the email so replies can be threaded onto its task
+func (g *Gateway) received(address *models.InboundAddress, msg *Message, taskID uuid.UUID, commentID *uuid.UUID) *models.InboundMessage {
+	return &models.InboundMessage{
+		TenantModel: models.TenantModel{TenantID: address.TenantID},
+		AddressID:   address.ID,
+		MessageID:   msg.MessageID,
+		TaskID:      taskID,
+		CommentID:   commentID,
+		From:        msg.From.Address,
+		Subject:     truncate(msg.Subject, 998),
+	}
+}
+
+// saveAttachments stores the attachments of an email as files of a task
+func (g *Gateway) saveAttachments(task *models.Task, userID uuid.UUID, msg *Message) ([]models.TaskAttachment, error) {
+	attachments := make([]models.TaskAttachment, 0, len(msg.Attachments))
+	for _, file := range msg.Attachments {
+		attachment := models.TaskAttachment{
+			TenantModel:  models.TenantModel{TenantID: task.TenantID},
+			TaskID:       task.ID,
+			UserID:       userID,
+			OriginalName: truncate(file.FileName, 255),
+			FileSize:     int64(len(file.Data)),
+			MimeType:     truncate(file.ContentType, 100),
+		}
+		attachment.ID = uuid.New()
+		attachment.FileName = attachment.ID.String() + extension(file)
+		attachment.FilePath = fmt.Sprintf("attachments/%s/%s/%s", task.TenantID, task.ID, attachment.FileName)
+
+		if err := g.write(attachment.FilePath, file.Data); err != nil {
+			g.removeAttachments(attachments)
+			return nil, fmt.Errorf("failed to store attachment %q: %w", file.FileName, err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// write stores a file
+func (g *Gateway) write(key string, data []byte) error {
+	file, err := g.store.Create(key)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// removeAttachments deletes stored files of attachments that were not saved
+func (g *Gateway) removeAttachments(attachments []models.TaskAttachment) {
+	for _, attachment := range attachments {
+		if err := g.store.Remove(attachment.FilePath); err != nil {
+			g.logger.WithError(err).WithField("path", attachment.FilePath).Warn("Failed to remove attachment file")
+		}
+	}
+}
+
+// Title turns an email subject into a task title
+func Title(subject string) string {
+	title := strings.TrimSpace(subjectPrefix.ReplaceAllString(subject, ""))
+	if title == "" {
+		return "(no subject)"
+	}
+	return truncate(title, maxTitleLength)
+}
+
+// Body returns the content of an email as Markdown, preferring the HTML body
+func Body(msg *Message) string {
+	if strings.TrimSpace(msg.HTML) != "" {
+		return HTMLToMarkdown(msg.HTML)
+	}
+	return strings.TrimSpace(strings.ReplaceAll(msg.Text, "\r\n", "\n"))
+}
+
+// StripQuoted removes the quoted original from a reply, keeping only what the
+// sender wrote above it
+func StripQuoted(body string) string {
+	if loc := quoteHeader.FindStringIndex(body); loc != nil {
+		body = body[:loc[0]]
+	}
+
+	// Drop the trailing block of quoted lines that some clients add without
+	// a header line
+	lines := strings.Split(strings.TrimRight(body, "\n "), "\n")
+	end := len(lines)
+	for end > 0 {
+		line := strings.TrimSpace(lines[end-1])
+		if line != "" && !strings.HasPrefix(line, ">") {
+			break
+		}
+		end--
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
+}
+
+// senderNote names the sender of an email, as given by its From header
+func senderNote(msg *Message) string {
+	return fmt.Sprintf("_Sent by %s_\n\n", msg.From.String())
+}
+
+// extension returns the file extension of an attachment, from its name or
+// else its content type
+func extension(file Attachment) string {
+	if ext := filepath.Ext(file.FileName); ext != "" && len(ext) <= 10 {
+		return strings.ToLower(ext)
+	}
+	if exts, err := mime.ExtensionsByType(file.ContentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
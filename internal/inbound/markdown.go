@@ -0,0 +1,280 @@
+package inbound
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blankLines collapses runs of empty lines left by nested block elements
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// HTMLToMarkdown converts an HTML email body to Markdown. Formatting that has
+// no Markdown equivalent is dropped and only its text is kept.
+func HTMLToMarkdown(source string) string {
+	doc, err := html.Parse(strings.NewReader(source))
+	if err != nil {
+		return strings.TrimSpace(source)
+	}
+
+	w := &markdownWriter{}
+	w.children(doc)
+
+	lines := strings.Split(w.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// markdownWriter renders an HTML tree as Markdown
+type markdownWriter struct {
+	bytes.Buffer
+	lists []listState
+	pre   bool
+	quote int
+}
+
+// listState tracks the kind and item count of an open list
+type listState struct {
+	ordered bool
+	items   int
+}
+
+func (w *markdownWriter) children(n *html.Node) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		w.node(child)
+	}
+}
+
+func (w *markdownWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.ElementNode:
+	default:
+		w.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Head, atom.Script, atom.Style, atom.Title:
+		return
+	case atom.Br:
+		w.newline()
+	case atom.Hr:
+		w.block()
+		w.WriteString("---")
+		w.block()
+	case atom.P, atom.Div, atom.Table, atom.Section, atom.Article:
+		w.block()
+		w.children(n)
+		w.block()
+	case atom.Tr:
+		w.newline()
+		w.children(n)
+	case atom.Td, atom.Th:
+		w.children(n)
+		w.WriteString(" ")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		w.block()
+		w.WriteString(strings.Repeat("#", int(n.Data[1]-'0')) + " ")
+		w.children(n)
+		w.block()
+	case atom.Strong, atom.B:
+		w.wrap(n, "**")
+	case atom.Em, atom.I:
+		w.wrap(n, "_")
+	case atom.S, atom.Del, atom.Strike:
+		w.wrap(n, "~~")
+	case atom.Code:
+		if w.pre {
+			w.children(n)
+		} else {
+			w.wrap(n, "`")
+		}
+	case atom.Pre:
+		w.block()
+		w.WriteString("```\n")
+		w.pre = true
+		w.children(n)
+		w.pre = false
+		w.newline()
+		w.WriteString("```")
+		w.block()
+	case atom.Blockquote:
+		w.quote++
+		w.block()
+		if prefix := strings.Repeat("> ", w.quote); !strings.HasSuffix(w.String(), prefix) {
+			w.WriteString(prefix)
+		}
+		w.children(n)
+		// Drop the empty quoted lines left by the last block in the quote
+		w.Truncate(len(strings.TrimRight(w.String(), "> \n")))
+		w.quote--
+		w.block()
+	case atom.Ul, atom.Ol:
+		w.newline()
+		w.lists = append(w.lists, listState{ordered: n.DataAtom == atom.Ol})
+		w.children(n)
+		w.lists = w.lists[:len(w.lists)-1]
+		w.block()
+	case atom.Li:
+		w.newline()
+		w.listItem()
+		w.children(n)
+	case atom.A:
+		href := attr(n, "href")
+		var label markdownWriter
+		label.children(n)
+		text := strings.TrimSpace(label.String())
+		switch {
+		case href == "" || strings.HasPrefix(href, "#"):
+			w.WriteString(text)
+		case text == "" || text == href:
+			w.WriteString("<" + href + ">")
+		default:
+			w.WriteString("[" + text + "](" + href + ")")
+		}
+	case atom.Img:
+		if alt := attr(n, "alt"); alt != "" {
+			w.WriteString("[" + alt + "]")
+		}
+	default:
+		w.children(n)
+	}
+}
+
+// text writes a text node, collapsing whitespace outside preformatted blocks
+func (w *markdownWriter) text(data string) {
+	if w.pre {
+		if w.quote > 0 {
+			data = strings.ReplaceAll(data, "\n", "\n"+strings.Repeat("> ", w.quote))
+		}
+		w.WriteString(data)
+		return
+	}
+	collapsed := strings.Join(strings.Fields(data), " ")
+	needsSpace := !w.atLineStart() && !endsWithSpace(w.String())
+	if collapsed == "" {
+		if data != "" && needsSpace {
+			w.WriteString(" ")
+		}
+		return
+	}
+	if startsWithSpace(data) && needsSpace {
+		w.WriteString(" ")
+	}
+	w.WriteString(collapsed)
+	if endsWithSpace(data) {
+		w.WriteString(" ")
+	}
+}
+
+// wrap surrounds the text of an inline element with a marker
+func (w *markdownWriter) wrap(n *html.Node, marker string) {
+	var inner markdownWriter
+	inner.pre = w.pre
+	inner.children(n)
+	text := inner.String()
+	if strings.TrimSpace(text) == "" {
+		w.WriteString(text)
+		return
+	}
+	w.WriteString(marker + strings.TrimSpace(text) + marker)
+	if endsWithSpace(text) {
+		w.WriteString(" ")
+	}
+}
+
+// listItem writes the bullet or number of a list item
+func (w *markdownWriter) listItem() {
+	if len(w.lists) == 0 {
+		w.WriteString("- ")
+		return
+	}
+	list := &w.lists[len(w.lists)-1]
+	list.items++
+	w.WriteString(strings.Repeat("  ", len(w.lists)-1))
+	if list.ordered {
+		w.WriteString(fmt.Sprintf("%d. ", list.items))
+	} else {
+		w.WriteString("- ")
+	}
+}
+
+// newline starts a new line unless the output already ends with one
+func (w *markdownWriter) newline() {
+	if w.Len() == 0 || w.atLineStart() {
+		return
+	}
+	w.WriteString("\n")
+	w.quotePrefix()
+}
+
+// block separates block elements with an empty line
+func (w *markdownWriter) block() {
+	// A quote prefix alone does not count as content on its line
+	base := strings.TrimRight(w.String(), "> ")
+	w.Truncate(len(base))
+
+	marker := strings.TrimSpace(strings.Repeat("> ", w.quote))
+	switch {
+	case separated(base):
+	case strings.HasSuffix(base, "\n"):
+		w.WriteString(marker + "\n")
+	default:
+		w.WriteString("\n" + marker + "\n")
+	}
+	w.quotePrefix()
+}
+
+// separated reports whether output ends with an empty line, ignoring quote
+// markers, or is still empty
+func separated(s string) bool {
+	if s == "" {
+		return true
+	}
+	if !strings.HasSuffix(s, "\n") {
+		return false
+	}
+	rest := s[:len(s)-1]
+	line := rest[strings.LastIndex(rest, "\n")+1:]
+	return strings.TrimSpace(strings.ReplaceAll(line, ">", "")) == ""
+}
+
+// quotePrefix continues a blockquote on a new line
+func (w *markdownWriter) quotePrefix() {
+	if w.quote > 0 {
+		w.WriteString(strings.Repeat("> ", w.quote))
+	}
+}
+
+// atLineStart reports whether the output ends at the start of a line
+func (w *markdownWriter) atLineStart() bool {
+	s := strings.TrimRight(w.String(), "> ")
+	return s == "" || strings.HasSuffix(s, "\n")
+}
+
+// attr returns the value of an attribute of an element
+func attr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\r\n", rune(s[0]))
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.ContainsRune(" \t\r\n", rune(s[len(s)-1]))
+}
@@ -0,0 +1,269 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"path"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// maxPartDepth limits how deeply nested multipart bodies are read
+const maxPartDepth = 10
+
+// Message is a parsed email
+type Message struct {
+	MessageID   string
+	InReplyTo   []string
+	References  []string
+	From        *mail.Address
+	Recipients  []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+// headerDecoder decodes RFC 2047 encoded words in any supported charset
+var headerDecoder = &mime.WordDecoder{CharsetReader: charsetReader}
+
+// Parse reads a raw RFC 822 message
+func Parse(r io.Reader) (*Message, error) {
+	raw, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email: %w", err)
+	}
+
+	msg := &Message{
+		MessageID:  firstMessageID(raw.Header.Get("Message-Id")),
+		InReplyTo:  messageIDs(raw.Header.Get("In-Reply-To")),
+		References: messageIDs(raw.Header.Get("References")),
+		Subject:    decodeHeader(raw.Header.Get("Subject")),
+	}
+
+	if from, err := headerAddresses(raw.Header, "From"); err == nil && len(from) > 0 {
+		msg.From = from[0]
+	}
+	if msg.From == nil {
+		return nil, fmt.Errorf("invalid email: missing From address")
+	}
+
+	// Forwarding services put the original recipient in their own headers
+	for _, name := range []string{"To", "Cc", "Delivered-To", "X-Original-To", "Envelope-To"} {
+		addresses, err := headerAddresses(raw.Header, name)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			msg.Recipients = append(msg.Recipients, address.Address)
+		}
+	}
+
+	header := partHeader{
+		contentType: raw.Header.Get("Content-Type"),
+		encoding:    raw.Header.Get("Content-Transfer-Encoding"),
+		disposition: raw.Header.Get("Content-Disposition"),
+	}
+	if err := msg.readPart(header, raw.Body, 0); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// partHeader holds the MIME headers of a body part
+type partHeader struct {
+	contentType string
+	encoding    string
+	disposition string
+}
+
+// readPart collects the text, HTML and attachments of a body part and the
+// parts nested in it
+func (m *Message) readPart(header partHeader, body io.Reader, depth int) error {
+	if depth > maxPartDepth {
+		return fmt.Errorf("invalid email: parts are nested too deeply")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.contentType)
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid email: %w", err)
+			}
+			child := partHeader{
+				contentType: part.Header.Get("Content-Type"),
+				encoding:    part.Header.Get("Content-Transfer-Encoding"),
+				disposition: part.Header.Get("Content-Disposition"),
+			}
+			if err := m.readPart(child, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.encoding, body))
+	if err != nil {
+		return fmt.Errorf("invalid email: %w", err)
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.disposition)
+	fileName := decodeHeader(dispositionParams["filename"])
+	if fileName == "" {
+		fileName = decodeHeader(params["name"])
+	}
+
+	isText := mediaType == "text/plain" || mediaType == "text/html"
+	if disposition == "attachment" || fileName != "" || !isText {
+		if fileName == "" {
+			fileName = "attachment"
+		}
+		m.Attachments = append(m.Attachments, Attachment{
+			FileName:    path.Base(strings.ReplaceAll(fileName, "\\", "/")),
+			ContentType: mediaType,
+			Data:        data,
+		})
+		return nil
+	}
+
+	text := decodeCharset(params["charset"], data)
+	// The first text and HTML bodies win; later ones are usually quoted
+	// copies attached by the sender's client
+	if mediaType == "text/html" {
+		if m.HTML == "" {
+			m.HTML = text
+		}
+	} else if m.Text == "" {
+		m.Text = text
+	}
+	return nil
+}
+
+// decodeTransfer undoes the content transfer encoding of a part
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &lineStripper{r: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+// lineStripper drops the line breaks that split base64 bodies
+type lineStripper struct {
+	r io.Reader
+}
+
+func (l *lineStripper) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	kept := 0
+	for _, b := range p[:n] {
+		if b != '\r' && b != '\n' && b != ' ' && b != '\t' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+// decodeCharset converts text in the given charset to UTF-8
+func decodeCharset(charset string, data []byte) string {
+	if charset == "" || strings.EqualFold(charset, "utf-8") || strings.EqualFold(charset, "us-ascii") {
+		return string(data)
+	}
+	reader, err := charsetReader(charset, bytes.NewReader(data))
+	if err != nil {
+		return string(data)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// charsetReader returns a reader converting from the charset to UTF-8
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	encoding, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+	return encoding.NewDecoder().Reader(input), nil
+}
+
+// decodeHeader decodes RFC 2047 encoded words, keeping the raw value when it
+// cannot be decoded
+func decodeHeader(value string) string {
+	decoded, err := headerDecoder.DecodeHeader(value)
+	if err != nil {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(decoded)
+}
+
+// headerAddresses parses an address list header
+func headerAddresses(header mail.Header, name string) ([]*mail.Address, error) {
+	if header.Get(name) == "" {
+		return nil, nil
+	}
+	parser := mail.AddressParser{WordDecoder: headerDecoder}
+	return parser.ParseList(header.Get(name))
+}
+
+// messageIDs extracts the <id> tokens of a Message-ID style header, without
+// their angle brackets
+func messageIDs(value string) []string {
+	var ids []string
+	for {
+		start := strings.IndexByte(value, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(value[start:], '>')
+		if end < 0 {
+			break
+		}
+		if id := strings.TrimSpace(value[start+1 : start+end]); id != "" {
+			ids = append(ids, id)
+		}
+		value = value[start+end+1:]
+	}
+	if len(ids) == 0 {
+		// Some clients leave out the angle brackets
+		for _, field := range strings.Fields(value) {
+			ids = append(ids, strings.Trim(field, "<>"))
+		}
+	}
+	return ids
+}
+
+// firstMessageID returns the first ID of a Message-ID header
+func firstMessageID(value string) string {
+	if ids := messageIDs(value); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
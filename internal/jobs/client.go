@@ -2,9 +2,12 @@ package jobs
 
 import (
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/hibiken/asynq"
+
+	"github.com/drazan344/taskflow-go/internal/notify"
 )
 
 // Client wraps the Asynq client for background job processing
@@ -27,11 +30,18 @@ func (c *Client) Close() error {
 
 // Job types
 const (
-	TypeWelcomeEmail     = "email:welcome"
-	TypePasswordReset    = "email:password_reset"
-	TypeTaskNotification = "notification:task"
-	TypeEmailDigest      = "email:digest"
-	TypeDataExport       = "data:export"
+	TypeWelcomeEmail        = "email:welcome"
+	TypePasswordReset       = "email:password_reset"
+	TypeTaskNotification    = "notification:task"
+	TypeEmailDigest         = "email:digest"
+	TypeDataExport          = "data:export"
+	TypeSprintSnapshot      = "sprint:snapshot"
+	TypeImportRun           = "import:run"
+	TypeExportCleanup       = "export:cleanup"
+	TypeWebhookDelivery     = "notification:webhook"
+	TypeNotificationDigest  = "notification:digest"
+	TypeNotificationRelease = "notification:release"
+	TypeNotificationSend    = "notification:send"
 )
 
 
@@ -100,4 +110,65 @@ func (c *Client) EnqueueDataExport(payload GenerateReportPayload) error {
 		asynq.Timeout(10*time.Minute),
 	)
 	return err
-}
\ No newline at end of file
+}
+
+// EnqueueImport enqueues a task import job. Imports are not retried because a
+// partially applied import must be undone before it runs again.
+func (c *Client) EnqueueImport(payload ImportRunPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask(TypeImportRun, data)
+	_, err = c.client.Enqueue(task,
+		asynq.Queue("imports"),
+		asynq.MaxRetry(0),
+		asynq.Timeout(30*time.Minute),
+	)
+	return err
+}
+
+// EnqueueWebhookDelivery enqueues a webhook delivery. Failed attempts are
+// retried with exponential backoff up to payload.MaxRetries times.
+func (c *Client) EnqueueWebhookDelivery(payload WebhookNotificationPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	task := asynq.NewTask(TypeWebhookDelivery, data)
+	_, err = c.client.Enqueue(task,
+		asynq.Queue("webhooks"),
+		asynq.MaxRetry(payload.MaxRetries),
+		asynq.Timeout(time.Minute),
+	)
+	return err
+}
+
+// EnqueueNotification enqueues the delivery of a notification. The job is
+// identified by the notification key, so a notification that is already
+// queued is not queued again. The job is retried until each failed channel
+// has been attempted notify.MaxAttempts times.
+func (c *Client) EnqueueNotification(payload NotificationPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	options := []asynq.Option{
+		asynq.Queue("notifications"),
+		asynq.MaxRetry(notify.MaxAttempts - 1),
+		asynq.Timeout(5 * time.Minute),
+	}
+	if payload.Notification.Key != "" {
+		options = append(options, asynq.TaskID(payload.Notification.Key))
+	}
+
+	task := asynq.NewTask(TypeNotificationSend, data)
+	_, err = c.client.Enqueue(task, options...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	return err
+}
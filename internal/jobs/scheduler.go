@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/sirupsen/logrus"
+
+	"github.com/drazan344/taskflow-go/internal/config"
+)
+
+// Scheduler enqueues periodic background jobs
+type Scheduler struct {
+	scheduler *asynq.Scheduler
+	logger    *logrus.Logger
+}
+
+// NewScheduler creates a new periodic job scheduler
+func NewScheduler(cfg *config.Config, logger *logrus.Logger) (*Scheduler, error) {
+	scheduler := asynq.NewScheduler(
+		asynq.RedisClientOpt{Addr: cfg.GetRedisAddr()},
+		&asynq.SchedulerOpts{Location: time.UTC},
+	)
+
+	s := &Scheduler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+
+	if err := s.registerJobs(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// registerJobs registers all periodic jobs
+func (s *Scheduler) registerJobs() error {
+	// Snapshot sprints just before the UTC day ends so each day is recorded
+	// with its final state
+	if _, err := s.scheduler.Register("55 23 * * *",
+		asynq.NewTask(TypeSprintSnapshot, nil),
+		asynq.Queue("maintenance"),
+		asynq.Unique(time.Hour),
+	); err != nil {
+		return err
+	}
+
+	// Remove export files once their download links have expired
+	if _, err := s.scheduler.Register("@hourly",
+		asynq.NewTask(TypeExportCleanup, nil),
+		asynq.Queue("maintenance"),
+		asynq.Unique(30*time.Minute),
+	); err != nil {
+		return err
+	}
+
+	// Send notification digests. Users are in many timezones, so digests
+	// fall due throughout the day.
+	if _, err := s.scheduler.Register("*/15 * * * *",
+		asynq.NewTask(TypeNotificationDigest, nil),
+		asynq.Queue("notifications"),
+		asynq.Unique(10*time.Minute),
+	); err != nil {
+		return err
+	}
+
+	// Deliver the email and push notifications held during quiet hours.
+	// Quiet hours end on the minute.
+	_, err := s.scheduler.Register("* * * * *",
+		asynq.NewTask(TypeNotificationRelease, nil),
+		asynq.Queue("notifications"),
+		asynq.Unique(50*time.Second),
+	)
+	return err
+}
+
+// Start starts the scheduler in the background
+func (s *Scheduler) Start() error {
+	s.logger.Info("Starting job scheduler...")
+	return s.scheduler.Start()
+}
+
+// Shutdown stops the scheduler
+func (s *Scheduler) Shutdown() {
+	s.logger.Info("Shutting down job scheduler...")
+	s.scheduler.Shutdown()
+}
@@ -3,28 +3,47 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/mail"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 
 	"github.com/drazan344/taskflow-go/internal/config"
+	"github.com/drazan344/taskflow-go/internal/digest"
+	"github.com/drazan344/taskflow-go/internal/exporter"
+	"github.com/drazan344/taskflow-go/internal/importer"
+	"github.com/drazan344/taskflow-go/internal/mailer"
 	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/notify"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+	"github.com/drazan344/taskflow-go/pkg/storage"
 )
 
 // Server represents the background job server
 type Server struct {
-	server *asynq.Server
-	mux    *asynq.ServeMux
-	db     *gorm.DB
-	logger *logrus.Logger
-	config *config.Config
+	server     *asynq.Server
+	mux        *asynq.ServeMux
+	db         *gorm.DB
+	logger     *logrus.Logger
+	config     *config.Config
+	exporter   *exporter.Exporter
+	webhooks   *webhooks.Deliverer
+	mailer     mailer.Mailer
+	templates  *templates.Engine
+	digests    *digest.Sender
+	dispatcher *notify.Dispatcher
 }
 
-// NewServer creates a new job server
-func NewServer(cfg *config.Config, db *gorm.DB, logger *logrus.Logger) *Server {
+// NewServer creates a new job server. Email jobs are sent with mail,
+// notifications are delivered by the dispatcher and webhook deliveries only
+// reach the addresses guard allows.
+func NewServer(cfg *config.Config, db *gorm.DB, logger *logrus.Logger, mail mailer.Mailer, dispatcher *notify.Dispatcher, guard *webhooks.Guard) *Server {
 	srv := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: cfg.GetRedisAddr()},
 		asynq.Config{
@@ -32,9 +51,18 @@ func NewServer(cfg *config.Config, db *gorm.DB, logger *logrus.Logger) *Server {
 			Queues: map[string]int{
 				"emails":        6, // high priority for emails
 				"notifications": 3, // medium priority for notifications
+				"webhooks":      2, // medium priority for webhook deliveries
 				"exports":       1, // low priority for exports
+				"imports":       1, // low priority for imports
+				"maintenance":   1, // low priority for scheduled housekeeping
 			},
 			StrictPriority: true,
+			RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+				if task.Type() == TypeWebhookDelivery {
+					return webhooks.RetryDelay(n)
+				}
+				return asynq.DefaultRetryDelayFunc(n, err, task)
+			},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 				logger.WithFields(logrus.Fields{
 					"task_type":    task.Type(),
@@ -48,12 +76,19 @@ func NewServer(cfg *config.Config, db *gorm.DB, logger *logrus.Logger) *Server {
 
 	mux := asynq.NewServeMux()
 	
+	engine := templates.New(db, cfg.Server.PublicURL)
 	jobServer := &Server{
-		server: srv,
-		mux:    mux,
-		db:     db,
-		logger: logger,
-		config: cfg,
+		server:     srv,
+		mux:        mux,
+		db:         db,
+		logger:     logger,
+		config:     cfg,
+		exporter:   exporter.New(db, storage.NewLocal(cfg.Storage.UploadPath), logger, cfg.Storage.LinkExpiry),
+		webhooks:   webhooks.NewDeliverer(db, logger, guard, cfg.Webhooks.Timeout, cfg.Webhooks.DisableAfter),
+		mailer:     mail,
+		templates:  engine,
+		digests:    digest.New(db, engine, mail, logger),
+		dispatcher: dispatcher,
 	}
 
 	// Register handlers
@@ -69,6 +104,13 @@ func (s *Server) registerHandlers() {
 	s.mux.HandleFunc(TypeTaskNotification, s.handleTaskNotification)
 	s.mux.HandleFunc(TypeEmailDigest, s.handleEmailDigest)
 	s.mux.HandleFunc(TypeDataExport, s.handleDataExport)
+	s.mux.HandleFunc(TypeSprintSnapshot, s.handleSprintSnapshot)
+	s.mux.HandleFunc(TypeImportRun, s.handleImportRun)
+	s.mux.HandleFunc(TypeExportCleanup, s.handleExportCleanup)
+	s.mux.HandleFunc(TypeWebhookDelivery, s.handleWebhookDelivery)
+	s.mux.HandleFunc(TypeNotificationDigest, s.handleNotificationDigest)
+	s.mux.HandleFunc(TypeNotificationRelease, s.handleNotificationRelease)
+	s.mux.HandleFunc(TypeNotificationSend, s.handleNotificationSend)
 }
 
 // Start starts the job server
@@ -81,6 +123,9 @@ func (s *Server) Start() error {
 func (s *Server) Shutdown() {
 	s.logger.Info("Shutting down background job server...")
 	s.server.Shutdown()
+	if err := s.mailer.Close(); err != nil {
+		s.logger.WithError(err).Warn("Failed to close mailer")
+	}
 }
 
 // handleWelcomeEmail handles welcome email jobs
@@ -97,40 +142,28 @@ func (s *Server) handleWelcomeEmail(ctx context.Context, t *asynq.Task) error {
 		"first_name": payload.FirstName,
 	}).Info("Processing welcome email job")
 
-	// TODO: Integrate with actual email service (SendGrid, SES, etc.)
-	// For now, we'll just simulate sending the email
-	if err := s.sendWelcomeEmail(payload); err != nil {
-		return fmt.Errorf("failed to send welcome email: %w", err)
-	}
-
-	// Create notification record
-	notification := &models.Notification{
-		TenantModel: models.TenantModel{TenantID: payload.TenantID},
-		UserID:      payload.UserID,
-		Type:        models.NotificationTypeWelcome,
-		Status:      models.NotificationStatusUnread,
-		Title:       "Welcome to TaskFlow!",
-		Message:     fmt.Sprintf("Hello %s! Welcome to TaskFlow. We're excited to have you on board.", payload.FirstName),
-		Data: models.NotificationData{
+	if err := s.dispatcher.Dispatch(ctx, &notify.Notification{
+		TenantID:   payload.TenantID,
+		Type:       models.NotificationTypeWelcome,
+		Recipients: []uuid.UUID{payload.UserID},
+		Key:        jobKey(ctx),
+		Data: templates.Data{
+			"recipient_name": payload.FirstName,
+			"login_url":      s.loginURL(payload.LoginURL),
+		},
+		Meta: models.NotificationData{
 			ActorName:  payload.FirstName,
 			EntityType: "welcome",
-			ExtraData: map[string]interface{}{
-				"email_sent": true,
-				"sent_at":    time.Now(),
-			},
 		},
-	}
-
-	if err := s.db.Create(notification).Error; err != nil {
-		s.logger.WithError(err).Error("Failed to create welcome notification")
-		// Don't fail the job for this
+	}); err != nil {
+		return fmt.Errorf("failed to deliver welcome notification: %w", err)
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"user_id":   payload.UserID,
 		"tenant_id": payload.TenantID,
 		"email":     payload.Email,
-	}).Info("Welcome email sent successfully")
+	}).Info("Welcome notification delivered")
 
 	return nil
 }
@@ -148,9 +181,8 @@ func (s *Server) handlePasswordReset(ctx context.Context, t *asynq.Task) error {
 		"email":      payload.Email,
 	}).Info("Processing password reset email job")
 
-	// TODO: Send actual password reset email
-	if err := s.sendPasswordResetEmail(payload); err != nil {
-		return fmt.Errorf("failed to send password reset email: %w", err)
+	if err := s.sendPasswordResetEmail(ctx, payload); err != nil {
+		return sendError("failed to send password reset email", err)
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -176,22 +208,23 @@ func (s *Server) handleTaskNotification(ctx context.Context, t *asynq.Task) erro
 		"task_title":   payload.TaskTitle,
 	}).Info("Processing task notification job")
 
-	// Create in-app notification
-	var message string
-	var notificationType models.NotificationType
-
-	// This is a task assignment notification
-	message = fmt.Sprintf("Task assigned to you: %s", payload.TaskTitle)
-	notificationType = models.NotificationTypeTaskAssigned
-
-	notification := &models.Notification{
-		TenantModel: models.TenantModel{TenantID: payload.TenantID},
-		UserID:      payload.AssigneeID,
-		Type:        notificationType,
-		Status:      models.NotificationStatusUnread,
-		Title:       "Task Assigned",
-		Message:     message,
-		Data: models.NotificationData{
+	taskURL := payload.TaskURL
+	if taskURL == "" {
+		taskURL = templates.TaskURL(s.templates.AppURL(), payload.TaskID)
+	}
+	notification := &notify.Notification{
+		TenantID:   payload.TenantID,
+		Type:       models.NotificationTypeTaskAssigned,
+		Recipients: []uuid.UUID{payload.AssigneeID},
+		Key:        jobKey(ctx),
+		ActionURL:  taskURL,
+		TaskID:     &payload.TaskID,
+		Data: templates.Data{
+			"actor_name": payload.AssignerName,
+			"task_title": payload.TaskTitle,
+			"task_url":   taskURL,
+		},
+		Meta: models.NotificationData{
 			EntityType: "task",
 			EntityID:   payload.TaskID.String(),
 			EntityName: payload.TaskTitle,
@@ -203,20 +236,18 @@ func (s *Server) handleTaskNotification(ctx context.Context, t *asynq.Task) erro
 			},
 		},
 	}
-
-	if err := s.db.Create(notification).Error; err != nil {
-		return fmt.Errorf("failed to create task notification: %w", err)
+	if payload.DueDate != nil {
+		notification.Data["due_date"] = payload.DueDate.Format("2006-01-02")
+	}
+	if err := s.dispatcher.Dispatch(ctx, notification); err != nil {
+		return fmt.Errorf("failed to deliver task notification: %w", err)
 	}
-
-	// TODO: Send push notification if enabled
-	// TODO: Send email notification if enabled
 
 	s.logger.WithFields(logrus.Fields{
-		"task_id":         payload.TaskID,
-		"tenant_id":       payload.TenantID,
-		"assignee_id":     payload.AssigneeID,
-		"notification_id": notification.ID,
-	}).Info("Task notification created successfully")
+		"task_id":     payload.TaskID,
+		"tenant_id":   payload.TenantID,
+		"assignee_id": payload.AssigneeID,
+	}).Info("Task notification delivered")
 
 	return nil
 }
@@ -235,9 +266,8 @@ func (s *Server) handleEmailDigest(ctx context.Context, t *asynq.Task) error {
 		"user_name":  payload.UserName,
 	}).Info("Processing email digest job")
 
-	// TODO: Generate and send email digest
-	if err := s.sendEmailDigest(payload); err != nil {
-		return fmt.Errorf("failed to send email digest: %w", err)
+	if err := s.sendEmailDigest(ctx, payload); err != nil {
+		return sendError("failed to send email digest", err)
 	}
 
 	s.logger.WithFields(logrus.Fields{
@@ -249,7 +279,35 @@ func (s *Server) handleEmailDigest(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-// handleDataExport handles data export jobs
+// handleNotificationDigest sends the daily and weekly notification digests
+// that are due. Digests that fail are retried by the next run.
+func (s *Server) handleNotificationDigest(ctx context.Context, t *asynq.Task) error {
+	sent, err := s.digests.Send(ctx)
+	if err != nil {
+		return err
+	}
+
+	if sent > 0 {
+		s.logger.WithField("digests", sent).Info("Notification digests sent")
+	}
+	return nil
+}
+
+// handleNotificationRelease delivers the notifications held during quiet
+// hours that have ended
+func (s *Server) handleNotificationRelease(ctx context.Context, t *asynq.Task) error {
+	released, err := s.dispatcher.Release(ctx)
+	if err != nil {
+		return err
+	}
+
+	if released > 0 {
+		s.logger.WithField("notifications", released).Info("Held notifications delivered")
+	}
+	return nil
+}
+
+// handleDataExport generates an export file and notifies the requesting user
 func (s *Server) handleDataExport(ctx context.Context, t *asynq.Task) error {
 	var payload GenerateReportPayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
@@ -259,18 +317,20 @@ func (s *Server) handleDataExport(ctx context.Context, t *asynq.Task) error {
 	s.logger.WithFields(logrus.Fields{
 		"user_id":     payload.UserID,
 		"tenant_id":   payload.TenantID,
+		"export_id":   payload.ExportID,
 		"report_type": payload.ReportType,
 		"format":      payload.Format,
 	}).Info("Processing data export job")
 
-	// TODO: Generate export file and send download link via email
-	if err := s.generateAndSendExport(payload); err != nil {
-		return fmt.Errorf("failed to generate data export: %w", err)
+	// A failed export is recorded on the export itself, so it is not retried
+	if err := s.generateAndSendExport(ctx, payload); err != nil {
+		return fmt.Errorf("failed to generate data export: %v: %w", err, asynq.SkipRetry)
 	}
 
 	s.logger.WithFields(logrus.Fields{
 		"user_id":     payload.UserID,
 		"tenant_id":   payload.TenantID,
+		"export_id":   payload.ExportID,
 		"report_type": payload.ReportType,
 		"format":      payload.Format,
 	}).Info("Data export generated and sent successfully")
@@ -278,58 +338,222 @@ func (s *Server) handleDataExport(ctx context.Context, t *asynq.Task) error {
 	return nil
 }
 
-// Email service methods (stubs for now)
-func (s *Server) sendWelcomeEmail(payload WelcomeEmailPayload) error {
-	// TODO: Implement actual email sending
-	s.logger.WithFields(logrus.Fields{
-		"to":         payload.Email,
-		"first_name": payload.FirstName,
-		"template":   "welcome",
-	}).Info("Simulating welcome email send")
-	
-	// Simulate some processing time
-	time.Sleep(100 * time.Millisecond)
-	
+// handleExportCleanup removes export files whose download links have expired
+func (s *Server) handleExportCleanup(ctx context.Context, t *asynq.Task) error {
+	expired, err := s.exporter.Expire(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithField("exports", expired).Info("Expired export files removed")
 	return nil
 }
 
-func (s *Server) sendPasswordResetEmail(payload PasswordResetEmailPayload) error {
-	// TODO: Implement actual email sending with reset link
-	s.logger.WithFields(logrus.Fields{
-		"to":         payload.Email,
-		"first_name": payload.FirstName,
-		"template":   "password_reset",
-		"token":      payload.ResetToken[:8] + "...", // Log only first 8 chars for security
-	}).Info("Simulating password reset email send")
-	
-	time.Sleep(100 * time.Millisecond)
-	
+// sendPasswordResetEmail sends a password reset link
+func (s *Server) sendPasswordResetEmail(ctx context.Context, payload PasswordResetEmailPayload) error {
+	return s.sendEmail(ctx, payload.TenantID, payload.UserID, models.NotificationTypePasswordReset,
+		mail.Address{Name: payload.FirstName, Address: payload.Email},
+		templates.Data{
+			"recipient_name": payload.FirstName,
+			"reset_url":      payload.ResetURL,
+			"expires_at":     payload.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"),
+		})
+}
+
+// sendEmailDigest sends the weekly summary of a user's tasks
+func (s *Server) sendEmailDigest(ctx context.Context, payload WeeklyDigestEmailPayload) error {
+	return s.sendEmail(ctx, payload.TenantID, payload.UserID, models.NotificationTypeWeeklyDigest,
+		mail.Address{Name: payload.UserName, Address: payload.Email},
+		templates.Data{
+			"recipient_name":  payload.UserName,
+			"week":            payload.WeekStartDate.Format("January 2"),
+			"completed_tasks": payload.CompletedTasks,
+			"pending_tasks":   payload.PendingTasks,
+			"overdue_tasks":   payload.OverdueTasks,
+		})
+}
+
+// sendEmail renders the email template of a notification type in the
+// recipient's language and sends it
+func (s *Server) sendEmail(ctx context.Context, tenantID, userID uuid.UUID, notificationType models.NotificationType,
+	to mail.Address, data templates.Data) error {
+	content, err := s.render(ctx, tenantID, userID, notificationType, models.NotificationChannelEmail, data)
+	if err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      []mail.Address{to},
+		Subject: content.Subject,
+		Text:    content.Body,
+		HTML:    content.HTML,
+	})
+}
+
+// render renders a notification type for a user, in the user's language.
+// The user's full name is the recipient name unless data sets one.
+func (s *Server) render(ctx context.Context, tenantID, userID uuid.UUID, notificationType models.NotificationType,
+	channel models.NotificationChannel, data templates.Data) (*templates.Rendered, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "first_name", "last_name", "language").
+		Where("id = ? AND tenant_id = ?", userID, tenantID).First(&user).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to load recipient: %w", err)
+	}
+	if _, ok := data["recipient_name"]; !ok && user.ID != uuid.Nil {
+		data["recipient_name"] = user.GetFullName()
+	}
+	return s.templates.Render(ctx, tenantID, notificationType, channel, user.Language, data)
+}
+
+// loginURL returns the sign in link of a welcome email
+func (s *Server) loginURL(loginURL string) string {
+	if loginURL == "" {
+		return s.templates.AppURL()
+	}
+	return loginURL
+}
+
+// jobKey identifies the running job, so the notifications it dispatches are
+// not repeated when it is retried
+func jobKey(ctx context.Context) string {
+	if id, ok := asynq.GetTaskID(ctx); ok {
+		return "job:" + id
+	}
+	return ""
+}
+
+// sendError wraps a failed send, stopping retries of email that can never be
+// delivered
+func sendError(action string, err error) error {
+	if errors.Is(err, mailer.ErrPermanent) {
+		return fmt.Errorf("%s: %v: %w", action, err, asynq.SkipRetry)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// generateAndSendExport writes the export file and sends its signed download
+// link to the requesting user as a notification
+func (s *Server) generateAndSendExport(ctx context.Context, payload GenerateReportPayload) error {
+	export, err := s.exporter.Run(ctx, payload.ExportID)
+	if err != nil || export == nil {
+		return err
+	}
+
+	downloadURL := exporter.DownloadURL(s.config.Server.PublicURL, s.config.GetSigningSecret(), export)
+	err = s.dispatcher.Dispatch(ctx, &notify.Notification{
+		TenantID:   export.TenantID,
+		Type:       models.NotificationTypeExportReady,
+		Recipients: []uuid.UUID{export.RequestedBy},
+		Key:        fmt.Sprintf("export:%s", export.ID),
+		ActionURL:  downloadURL,
+		Data: templates.Data{
+			"resource":     export.Resource,
+			"rows":         export.Rows,
+			"download_url": downloadURL,
+			"expires_at":   export.ExpiresAt.UTC().Format("2006-01-02 15:04 MST"),
+		},
+		Meta: models.NotificationData{
+			EntityType: "export",
+			EntityID:   export.ID.String(),
+			EntityName: export.FileName,
+			ExtraData: map[string]interface{}{
+				"format":     export.Format,
+				"rows":       export.Rows,
+				"expires_at": export.ExpiresAt,
+			},
+		},
+	})
+	if err != nil {
+		s.logger.WithError(err).WithField("export_id", export.ID).Error("Failed to deliver export notification")
+		// The export itself succeeded and can still be fetched through the API
+	}
+
 	return nil
 }
 
-func (s *Server) sendEmailDigest(payload WeeklyDigestEmailPayload) error {
-	// TODO: Generate digest content and send email
+// handleSprintSnapshot records the daily snapshot of every active sprint
+func (s *Server) handleSprintSnapshot(ctx context.Context, t *asynq.Task) error {
+	var sprints []models.Sprint
+	if err := s.db.WithContext(ctx).Where("status = ?", models.SprintStatusActive).Find(&sprints).Error; err != nil {
+		return fmt.Errorf("failed to fetch active sprints: %w", err)
+	}
+
+	now := time.Now()
+	failed := 0
+	for i := range sprints {
+		if _, err := sprints[i].TakeSnapshot(s.db.WithContext(ctx), now); err != nil {
+			failed++
+			s.logger.WithError(err).WithField("sprint_id", sprints[i].ID).Error("Failed to take sprint snapshot")
+		}
+	}
+
 	s.logger.WithFields(logrus.Fields{
-		"to":        payload.Email,
-		"user_name": payload.UserName,
-		"template":  "digest",
-	}).Info("Simulating email digest send")
-	
-	time.Sleep(200 * time.Millisecond)
-	
+		"sprints": len(sprints),
+		"failed":  failed,
+	}).Info("Sprint snapshots recorded")
+
+	if failed > 0 {
+		return fmt.Errorf("failed to snapshot %d of %d sprints", failed, len(sprints))
+	}
 	return nil
 }
 
-func (s *Server) generateAndSendExport(payload GenerateReportPayload) error {
-	// TODO: Generate export file and send download link
+// handleImportRun processes an uploaded task import
+func (s *Server) handleImportRun(ctx context.Context, t *asynq.Task) error {
+	var payload ImportRunPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
 	s.logger.WithFields(logrus.Fields{
-		"recipients":   payload.Recipients,
-		"report_type":  payload.ReportType,
-		"format":       payload.Format,
-		"template":     "export_ready",
-	}).Info("Simulating data export generation and email send")
-	
-	time.Sleep(500 * time.Millisecond) // Simulate longer processing time
-	
+		"import_id": payload.ImportID,
+		"tenant_id": payload.TenantID,
+	}).Info("Processing import job")
+
+	if err := importer.New(s.db, s.logger).Run(ctx, payload.ImportID); err != nil {
+		return fmt.Errorf("import failed: %v: %w", err, asynq.SkipRetry)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// handleWebhookDelivery makes one attempt to send a webhook delivery. Failed
+// attempts are retried by the queue until the last one is used up.
+func (s *Server) handleWebhookDelivery(ctx context.Context, t *asynq.Task) error {
+	var payload WebhookNotificationPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	final := retried >= maxRetry
+
+	s.logger.WithFields(logrus.Fields{
+		"delivery_id": payload.DeliveryID,
+		"webhook_id":  payload.WebhookID,
+		"tenant_id":   payload.TenantID,
+		"event":       payload.Event,
+		"attempt":     retried + 1,
+	}).Debug("Processing webhook delivery job")
+
+	return s.webhooks.Deliver(ctx, payload.DeliveryID, final)
+}
+
+// handleNotificationSend dispatches a notification queued by the notification
+// subscriber. The dispatcher skips channels that were already delivered, so a
+// retry only repeats the ones that failed.
+func (s *Server) handleNotificationSend(ctx context.Context, t *asynq.Task) error {
+	var payload NotificationPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("json.Unmarshal failed: %v: %w", err, asynq.SkipRetry)
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	s.logger.WithFields(logrus.Fields{
+		"tenant_id": payload.Notification.TenantID,
+		"type":      payload.Notification.Type,
+		"key":       payload.Notification.Key,
+		"attempt":   retried + 1,
+	}).Debug("Processing notification job")
+
+	return s.dispatcher.Dispatch(ctx, &payload.Notification)
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/notify"
 )
 
 // JobType represents different types of background jobs
@@ -160,14 +162,21 @@ type TenantUsageUpdatePayload struct {
 // GenerateReportPayload for report generation
 type GenerateReportPayload struct {
 	BaseJobPayload
+	ExportID     uuid.UUID `json:"export_id"`
 	ReportType   string    `json:"report_type"`
 	StartDate    time.Time `json:"start_date"`
 	EndDate      time.Time `json:"end_date"`
-	Format       string    `json:"format"` // "pdf", "csv", "excel"
+	Format       string    `json:"format"` // "csv", "xlsx", "ndjson"
 	DeliveryMethod string  `json:"delivery_method"` // "email", "download"
 	Recipients   []string  `json:"recipients,omitempty"`
 }
 
+// ImportRunPayload for running an uploaded task import
+type ImportRunPayload struct {
+	BaseJobPayload
+	ImportID uuid.UUID `json:"import_id"`
+}
+
 // Notification Job Payloads
 
 // PushNotificationPayload for push notifications
@@ -192,14 +201,21 @@ type InAppNotificationPayload struct {
 	ActionURL      string                 `json:"action_url,omitempty"`
 }
 
-// WebhookNotificationPayload for webhook notifications
+// WebhookNotificationPayload for webhook deliveries. The request itself is
+// stored on the delivery so redeliveries send the same body.
 type WebhookNotificationPayload struct {
 	BaseJobPayload
-	WebhookURL  string                 `json:"webhook_url"`
-	Event       string                 `json:"event"`
-	Data        map[string]interface{} `json:"data"`
-	RetryCount  int                    `json:"retry_count"`
-	MaxRetries  int                    `json:"max_retries"`
+	WebhookID  uuid.UUID `json:"webhook_id"`
+	DeliveryID uuid.UUID `json:"delivery_id"`
+	Event      string    `json:"event"`
+	MaxRetries int       `json:"max_retries"`
+}
+
+// NotificationPayload for notifications caused by events. The worker
+// dispatches them so failed channels are retried whichever event bus is used.
+type NotificationPayload struct {
+	BaseJobPayload
+	Notification notify.Notification `json:"notification"`
 }
 
 // Maintenance Job Payloads
@@ -0,0 +1,47 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// File writes each message to an .eml file in a directory instead of sending
+// it. Mail clients open the files as received email.
+type File struct {
+	dir  string
+	from mail.Address
+}
+
+// NewFile creates a mailer writing to dir
+func NewFile(dir string, from mail.Address) *File {
+	return &File{dir: dir, from: from}
+}
+
+// Send writes the message to a new file
+func (f *File) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	env, err := encode(msg, f.from, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), uuid.New())
+	if err := os.WriteFile(filepath.Join(f.dir, name), env.Data, 0o644); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// Close does nothing
+func (f *File) Close() error {
+	return nil
+}
@@ -0,0 +1,69 @@
+// Package mailer sends email through an SMTP server, or keeps it locally for
+// development and tests.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/drazan344/taskflow-go/internal/config"
+)
+
+// ErrPermanent marks failures that will not succeed when retried, such as a
+// rejected recipient or an invalid message
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// Mailer sends email
+type Mailer interface {
+	// Send delivers a message. Errors wrapping ErrPermanent should not be
+	// retried.
+	Send(ctx context.Context, msg *Message) error
+
+	// Close releases open connections
+	Close() error
+}
+
+// Message is an email with a plain text body, an HTML body, or both
+type Message struct {
+	From    *mail.Address // defaults to the configured sender
+	To      []mail.Address
+	ReplyTo *mail.Address
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// New creates the mailer selected in the configuration
+func New(cfg *config.Config, logger *logrus.Logger) (Mailer, error) {
+	from := mail.Address{Name: cfg.Email.FromName, Address: cfg.Email.From}
+	if _, err := mail.ParseAddress(from.Address); err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", cfg.Email.From, err)
+	}
+
+	switch cfg.Email.Driver {
+	case "", "smtp":
+		return NewSMTP(SMTPOptions{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.SMTPUser,
+			Password: cfg.Email.SMTPPassword,
+			TLS:      cfg.Email.SMTPTLS,
+			Timeout:  cfg.Email.Timeout,
+		}, from, logger)
+	case "file":
+		return NewFile(cfg.Email.FileDir, from), nil
+	case "memory":
+		return NewMemory(from), nil
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", cfg.Email.Driver)
+}
+
+// permanent wraps err as a permanent failure
+func permanent(err error) error {
+	return fmt.Errorf("%w: %v", ErrPermanent, err)
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"net/mail"
+	"sync"
+	"time"
+)
+
+// Sent is a message kept by the in-memory mailer
+type Sent struct {
+	Message
+	Recipients []string
+	Raw        []byte
+}
+
+// Memory keeps messages in memory instead of sending them, so tests can
+// inspect what would have been sent
+type Memory struct {
+	from mail.Address
+
+	mu   sync.Mutex
+	sent []Sent
+}
+
+// NewMemory creates an in-memory mailer
+func NewMemory(from mail.Address) *Memory {
+	return &Memory{from: from}
+}
+
+// Send keeps the message
+func (m *Memory) Send(ctx context.Context, msg *Message) error {
+	env, err := encode(msg, m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Sent{Message: *msg, Recipients: env.Recipients, Raw: env.Data})
+	return nil
+}
+
+// Messages returns the messages sent so far
+func (m *Memory) Messages() []Sent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Sent(nil), m.sent...)
+}
+
+// Reset forgets the messages sent so far
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
+
+// Close does nothing
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,145 @@
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// envelope is a message encoded for sending
+type envelope struct {
+	From       string
+	Recipients []string
+	Data       []byte
+}
+
+// encode builds the RFC 5322 form of a message. The body is multipart/
+// alternative when the message has both a text and an HTML body.
+func encode(msg *Message, sender mail.Address, now time.Time) (*envelope, error) {
+	if len(msg.To) == 0 {
+		return nil, permanent(fmt.Errorf("message has no recipients"))
+	}
+	if msg.Text == "" && msg.HTML == "" {
+		return nil, permanent(fmt.Errorf("message has no body"))
+	}
+
+	from := sender
+	if msg.From != nil {
+		from = *msg.From
+	}
+
+	env := &envelope{From: from.Address}
+	to := make([]string, 0, len(msg.To))
+	for _, address := range msg.To {
+		if _, err := mail.ParseAddress(address.Address); err != nil {
+			return nil, permanent(fmt.Errorf("invalid recipient %q: %w", address.Address, err))
+		}
+		env.Recipients = append(env.Recipients, address.Address)
+		to = append(to, address.String())
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(to, ", "))
+	if msg.ReplyTo != nil {
+		header.Set("Reply-To", msg.ReplyTo.String())
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%s@%s>", uuid.New(), domain(from.Address)))
+	header.Set("MIME-Version", "1.0")
+	for name, value := range msg.Headers {
+		header.Set(name, value)
+	}
+	for name, values := range header {
+		for _, value := range values {
+			if strings.ContainsAny(name+value, "\r\n") {
+				return nil, permanent(fmt.Errorf("header %s contains a line break", name))
+			}
+		}
+	}
+
+	var body bytes.Buffer
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		parts := multipart.NewWriter(&body)
+		header.Set("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+		for _, part := range []struct{ mediaType, content string }{
+			{"text/plain", msg.Text},
+			{"text/html", msg.HTML},
+		} {
+			w, err := parts.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.mediaType + "; charset=utf-8"},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQuotedPrintable(w, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := parts.Close(); err != nil {
+			return nil, err
+		}
+	case msg.HTML != "":
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&body, msg.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&body, msg.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	var data bytes.Buffer
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(&data, "%s: %s\r\n", name, value)
+		}
+	}
+	data.WriteString("\r\n")
+	data.Write(body.Bytes())
+
+	env.Data = data.Bytes()
+	return env, nil
+}
+
+// writeQuotedPrintable writes content with CRLF line endings in quoted-
+// printable encoding
+func writeQuotedPrintable(w io.Writer, content string) error {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\n", "\r\n")
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// domain returns the domain of an address, used to make Message-IDs unique
+func domain(address string) string {
+	if at := strings.LastIndexByte(address, '@'); at >= 0 && at < len(address)-1 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,236 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TLS modes of an SMTP connection
+const (
+	TLSStartTLS = "starttls" // upgrade a plain connection, failing when the server cannot
+	TLSImplicit = "tls"      // connect over TLS, usually on port 465
+	TLSNone     = "none"     // plain text, for local servers such as MailHog
+)
+
+// defaultTimeout bounds connecting and each message when none is configured
+const defaultTimeout = 30 * time.Second
+
+// SMTPOptions configure the connection to an SMTP server
+type SMTPOptions struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	TLS      string
+	Timeout  time.Duration
+}
+
+// SMTP sends email through an SMTP server. The connection is kept open and
+// reused by later messages until the server closes it.
+type SMTP struct {
+	options SMTPOptions
+	from    mail.Address
+	logger  *logrus.Logger
+
+	mu     sync.Mutex
+	conn   net.Conn
+	client *smtp.Client
+}
+
+// NewSMTP creates an SMTP mailer
+func NewSMTP(options SMTPOptions, from mail.Address, logger *logrus.Logger) (*SMTP, error) {
+	if options.Host == "" {
+		return nil, fmt.Errorf("SMTP host is not configured")
+	}
+	switch options.TLS {
+	case "":
+		options.TLS = TLSStartTLS
+		if options.Port == 465 {
+			options.TLS = TLSImplicit
+		}
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", options.TLS)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+
+	return &SMTP{
+		options: options,
+		from:    from,
+		logger:  logger,
+	}, nil
+}
+
+// Send delivers a message. Replies in the 5xx range to the message are
+// permanent failures; connection and authentication problems and 4xx replies
+// can be retried.
+func (s *SMTP) Send(ctx context.Context, msg *Message) error {
+	env, err := encode(msg, s.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reused := s.client != nil
+	if err := s.connect(ctx); err != nil {
+		return err
+	}
+
+	err = s.transact(ctx, env)
+	if err != nil && reused && !isReply(err) {
+		// The server may have closed the idle connection since the last
+		// message, so try once more on a fresh one
+		s.logger.WithError(err).Debug("Reconnecting to SMTP server")
+		s.disconnect()
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+		err = s.transact(ctx, env)
+	}
+	if err != nil {
+		// A rejected message leaves the connection usable for the next one
+		if !isReply(err) || s.client.Reset() != nil {
+			s.disconnect()
+		}
+		return classify(err)
+	}
+	return nil
+}
+
+// Close ends the open connection, if any
+func (s *SMTP) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+	err := s.client.Quit()
+	s.disconnect()
+	return err
+}
+
+// connect opens a connection unless one is already open
+func (s *SMTP) connect(ctx context.Context) error {
+	if s.client != nil {
+		return nil
+	}
+
+	addr := net.JoinHostPort(s.options.Host, strconv.Itoa(s.options.Port))
+	tlsConfig := &tls.Config{ServerName: s.options.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: s.options.Timeout}
+
+	var conn net.Conn
+	var err error
+	if s.options.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	s.setDeadline(ctx, conn)
+
+	client, err := smtp.NewClient(conn, s.options.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to greet %s: %w", addr, err)
+	}
+
+	if s.options.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return fmt.Errorf("failed to start TLS with %s: %w", addr, err)
+		}
+	}
+
+	if s.options.Username != "" {
+		auth := smtp.PlainAuth("", s.options.Username, s.options.Password, s.options.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return fmt.Errorf("failed to authenticate with %s: %w", addr, err)
+		}
+	}
+
+	s.conn = conn
+	s.client = client
+	return nil
+}
+
+// transact sends one message over the open connection
+func (s *SMTP) transact(ctx context.Context, env *envelope) error {
+	s.setDeadline(ctx, s.conn)
+
+	if err := s.client.Mail(env.From); err != nil {
+		return err
+	}
+	for _, recipient := range env.Recipients {
+		if err := s.client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+	w, err := s.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(env.Data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// disconnect drops the open connection without saying goodbye
+func (s *SMTP) disconnect() {
+	if s.client != nil {
+		s.client.Close()
+	}
+	s.client = nil
+	s.conn = nil
+}
+
+// setDeadline bounds the next exchange by the timeout or the context deadline,
+// whichever comes first
+func (s *SMTP) setDeadline(ctx context.Context, conn net.Conn) {
+	deadline := time.Now().Add(s.options.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+}
+
+// isReply reports whether err is a reply from the server rather than a
+// connection failure
+func isReply(err error) bool {
+	var reply *textproto.Error
+	return errors.As(err, &reply)
+}
+
+// classify marks 5xx replies as permanent failures
+func classify(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog is an immutable record of a change made in a tenant. Entries are
+// written from domain events and share the event's ID, so an event delivered
+// twice is only recorded once.
+type AuditLog struct {
+	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primary_key"`
+	TenantID   uuid.UUID     `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	Action     string        `json:"action" gorm:"size:50;not null;index"` // event type, e.g. "task.updated"
+	ActorID    *uuid.UUID    `json:"actor_id,omitempty" gorm:"type:uuid;index"`
+	EntityType string        `json:"entity_type" gorm:"size:20;not null"`
+	EntityID   uuid.UUID     `json:"entity_id" gorm:"type:uuid;not null"`
+	EntityName string        `json:"entity_name" gorm:"size:255"`
+	ProjectID  *uuid.UUID    `json:"project_id,omitempty" gorm:"type:uuid;index"`
+	Changes    []AuditChange `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	Details    string        `json:"details,omitempty" gorm:"type:text"`
+	OccurredAt time.Time     `json:"occurred_at" gorm:"not null;index"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Actor *User `json:"actor,omitempty" gorm:"foreignKey:ActorID"`
+}
+
+// AuditChange is a field changed by an audited action
+type AuditChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
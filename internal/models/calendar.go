@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarFeed represents a secret-token iCalendar subscription URL.
+// A feed without a ProjectID lists the tasks assigned to its owner; a feed
+// with a ProjectID lists every task of that project.
+type CalendarFeed struct {
+	TenantModel
+	UserID         uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	ProjectID      *uuid.UUID `json:"project_id,omitempty" gorm:"type:uuid;index"`
+	Name           string     `json:"name" gorm:"size:255"`
+	Token          string     `json:"-" gorm:"unique;not null;size:100"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+
+	// Relationships
+	User    User     `json:"-" gorm:"foreignKey:UserID"`
+	Project *Project `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
+}
+
+// TableName specifies the table name for CalendarFeed
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
+
+// IsRevoked checks if the feed token has been revoked
+func (cf *CalendarFeed) IsRevoked() bool {
+	return cf.RevokedAt != nil
+}
+
+// Revoke marks the feed token as revoked
+func (cf *CalendarFeed) Revoke() {
+	now := time.Now()
+	cf.RevokedAt = &now
+}
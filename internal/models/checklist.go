@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChecklistItem is a lightweight step inside a task, ordered by position
+type ChecklistItem struct {
+	TenantModel
+	TaskID     uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	Text       string     `json:"text" gorm:"not null;size:500"`
+	Position   int        `json:"position" gorm:"not null;default:0"`
+	IsDone     bool       `json:"is_done" gorm:"default:false"`
+	DoneAt     *time.Time `json:"done_at,omitempty"`
+	DoneBy     *uuid.UUID `json:"done_by,omitempty" gorm:"type:uuid"`
+	AssigneeID *uuid.UUID `json:"assignee_id,omitempty" gorm:"type:uuid"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+	CreatedBy  uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Relationships
+	Task     Task  `json:"-" gorm:"foreignKey:TaskID"`
+	Assignee *User `json:"assignee,omitempty" gorm:"foreignKey:AssigneeID"`
+}
+
+// TableName specifies the table name for ChecklistItem
+func (ChecklistItem) TableName() string {
+	return "checklist_items"
+}
+
+// SetDone marks the item as done or not done by the given user
+func (i *ChecklistItem) SetDone(done bool, userID uuid.UUID) {
+	i.IsDone = done
+	if done {
+		now := time.Now()
+		i.DoneAt = &now
+		i.DoneBy = &userID
+		return
+	}
+	i.DoneAt = nil
+	i.DoneBy = nil
+}
+
+// ChecklistProgress returns the percentage of done items, or false when the
+// checklist is empty
+func ChecklistProgress(items []ChecklistItem) (float64, bool) {
+	if len(items) == 0 {
+		return 0, false
+	}
+
+	done := 0
+	for _, item := range items {
+		if item.IsDone {
+			done++
+		}
+	}
+
+	return float64(done) / float64(len(items)) * 100.0, true
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportResource is the kind of data an export contains
+type ExportResource string
+
+const (
+	ExportResourceTasks    ExportResource = "tasks"
+	ExportResourceProjects ExportResource = "projects"
+)
+
+// ExportFormat is the file format of an export
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatXLSX   ExportFormat = "xlsx"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ExportStatus represents the lifecycle of an export
+type ExportStatus string
+
+const (
+	ExportStatusQueued    ExportStatus = "queued"
+	ExportStatusRunning   ExportStatus = "running"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+	ExportStatusExpired   ExportStatus = "expired"
+)
+
+// exportContentTypes maps export formats to the MIME type of their files
+var exportContentTypes = map[ExportFormat]string{
+	ExportFormatCSV:    "text/csv",
+	ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// Export is a file of tasks or projects generated in the background
+type Export struct {
+	TenantModel
+	RequestedBy     uuid.UUID      `json:"requested_by" gorm:"type:uuid;not null;index"`
+	Resource        ExportResource `json:"resource" gorm:"size:20;not null"`
+	Format          ExportFormat   `json:"format" gorm:"size:10;not null"`
+	Status          ExportStatus   `json:"status" gorm:"size:20;not null;index"`
+	Filter          TaskFilter     `json:"filter" gorm:"type:jsonb;serializer:json"`
+	IncludeComments bool           `json:"include_comments"`
+	IncludeActivity bool           `json:"include_activity"`
+	Rows            int            `json:"rows"`
+	FileName        string         `json:"file_name,omitempty" gorm:"size:255"`
+	StorageKey      string         `json:"-" gorm:"size:500"`
+	FileSize        int64          `json:"file_size"`
+	Failure         string         `json:"failure,omitempty" gorm:"type:text"`
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	FinishedAt      *time.Time     `json:"finished_at,omitempty"`
+	ExpiresAt       *time.Time     `json:"expires_at,omitempty" gorm:"index"`
+	DownloadURL     string         `json:"download_url,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for Export
+func (Export) TableName() string {
+	return "exports"
+}
+
+// IsValid checks if the format is a supported export format
+func (f ExportFormat) IsValid() bool {
+	_, ok := exportContentTypes[f]
+	return ok
+}
+
+// ContentType returns the MIME type of files in the format
+func (f ExportFormat) ContentType() string {
+	return exportContentTypes[f]
+}
+
+// IsValid checks if the resource can be exported
+func (r ExportResource) IsValid() bool {
+	return r == ExportResourceTasks || r == ExportResourceProjects
+}
+
+// IsDownloadable checks if the export file is ready and its link has not expired
+func (e *Export) IsDownloadable() bool {
+	return e.Status == ExportStatusCompleted && e.StorageKey != "" &&
+		(e.ExpiresAt == nil || time.Now().Before(*e.ExpiresAt))
+}
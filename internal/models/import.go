@@ -0,0 +1,142 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ImportSource identifies the format of an import file
+type ImportSource string
+
+const (
+	ImportSourceCSV    ImportSource = "csv"
+	ImportSourceJira   ImportSource = "jira"
+	ImportSourceTrello ImportSource = "trello"
+)
+
+// ImportStatus represents the lifecycle of an import
+type ImportStatus string
+
+const (
+	ImportStatusUploaded  ImportStatus = "uploaded"
+	ImportStatusQueued    ImportStatus = "queued"
+	ImportStatusRunning   ImportStatus = "running"
+	ImportStatusValidated ImportStatus = "validated" // dry run finished
+	ImportStatusCompleted ImportStatus = "completed"
+	ImportStatusFailed    ImportStatus = "failed"
+	ImportStatusUndone    ImportStatus = "undone"
+)
+
+// ImportSeverity tells whether a row problem skips the row or is only reported
+type ImportSeverity string
+
+const (
+	ImportSeverityError   ImportSeverity = "error"
+	ImportSeverityWarning ImportSeverity = "warning"
+)
+
+// ImportRowError describes a problem found in one row of an import file
+type ImportRowError struct {
+	Row      int            `json:"row"`
+	Field    string         `json:"field,omitempty"`
+	Message  string         `json:"message"`
+	Severity ImportSeverity `json:"severity"`
+}
+
+// ImportSummary counts what an import created
+type ImportSummary struct {
+	Projects       int `json:"projects"`
+	Tags           int `json:"tags"`
+	Tasks          int `json:"tasks"`
+	Comments       int `json:"comments"`
+	ChecklistItems int `json:"checklist_items"`
+	SkippedRows    int `json:"skipped_rows"`
+}
+
+// Import is an uploaded file of tasks and the state of importing it
+type Import struct {
+	TenantModel
+	CreatedBy  uuid.UUID         `json:"created_by" gorm:"type:uuid;not null;index"`
+	Source     ImportSource      `json:"source" gorm:"size:20;not null"`
+	Status     ImportStatus      `json:"status" gorm:"size:20;not null;index"`
+	FileName   string            `json:"file_name" gorm:"size:255"`
+	Data       []byte            `json:"-" gorm:"type:bytea"`
+	Headers    []string          `json:"headers,omitempty" gorm:"type:jsonb;serializer:json"`
+	Mapping    map[string]string `json:"mapping,omitempty" gorm:"type:jsonb;serializer:json"`  // task field -> CSV column
+	UserMap    map[string]string `json:"user_map,omitempty" gorm:"type:jsonb;serializer:json"` // external user -> email
+	ProjectID  *uuid.UUID        `json:"project_id,omitempty" gorm:"type:uuid"`                // default project for rows without one
+	DryRun     bool              `json:"dry_run"`
+	TotalRows  int               `json:"total_rows"`
+	Processed  int               `json:"processed"`
+	Summary    ImportSummary     `json:"summary" gorm:"type:jsonb;serializer:json"`
+	Errors     []ImportRowError  `json:"errors" gorm:"type:jsonb;serializer:json"`
+	Failure    string            `json:"failure,omitempty" gorm:"type:text"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	UndoneAt   *time.Time        `json:"undone_at,omitempty"`
+	Percent    float64           `json:"progress" gorm:"-"`
+}
+
+// ImportRecord remembers an entity created by an import so it can be undone
+type ImportRecord struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ImportID   uuid.UUID `json:"import_id" gorm:"type:uuid;not null;index"`
+	EntityType string    `json:"entity_type" gorm:"size:30;not null"`
+	EntityID   uuid.UUID `json:"entity_id" gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Entity types recorded by imports
+const (
+	ImportEntityProject       = "project"
+	ImportEntityTag           = "tag"
+	ImportEntityTask          = "task"
+	ImportEntityComment       = "comment"
+	ImportEntityChecklistItem = "checklist_item"
+)
+
+// TableName specifies the table name for Import
+func (Import) TableName() string {
+	return "imports"
+}
+
+// TableName specifies the table name for ImportRecord
+func (ImportRecord) TableName() string {
+	return "import_records"
+}
+
+// Progress returns the share of rows processed as a percentage
+func (i *Import) Progress() float64 {
+	if i.TotalRows == 0 {
+		if i.Status == ImportStatusCompleted || i.Status == ImportStatusValidated {
+			return 100
+		}
+		return 0
+	}
+	return float64(i.Processed) / float64(i.TotalRows) * 100
+}
+
+// AfterFind fills the progress percentage of loaded imports
+func (i *Import) AfterFind(tx *gorm.DB) error {
+	i.Percent = i.Progress()
+	return nil
+}
+
+// CanRun checks if the import may be queued. Failed real runs must be undone
+// first so their partial data is not duplicated.
+func (i *Import) CanRun() bool {
+	switch i.Status {
+	case ImportStatusUploaded, ImportStatusValidated, ImportStatusUndone:
+		return true
+	case ImportStatusFailed:
+		return i.DryRun
+	}
+	return false
+}
+
+// CanUndo checks if the import created data that can be removed
+func (i *Import) CanUndo() bool {
+	return i.Status == ImportStatusCompleted || (i.Status == ImportStatusFailed && !i.DryRun)
+}
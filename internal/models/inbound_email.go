@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboundAddress routes email sent to project+<token>@ addresses into a
+// project. Mail from senders who are not users of the tenant is filed under
+// the user who created the address.
+type InboundAddress struct {
+	TenantModel
+	ProjectID uuid.UUID  `json:"project_id" gorm:"type:uuid;not null;index"`
+	Token     string     `json:"token" gorm:"size:64;not null;uniqueIndex"`
+	CreatedBy uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Address   string     `json:"address,omitempty" gorm:"-"`
+}
+
+// TableName specifies the table name for InboundAddress
+func (InboundAddress) TableName() string {
+	return "inbound_addresses"
+}
+
+// IsActive reports whether the address still accepts mail
+func (a *InboundAddress) IsActive() bool {
+	return a.RevokedAt == nil
+}
+
+// Revoke stops the address from accepting mail
+func (a *InboundAddress) Revoke() {
+	now := time.Now()
+	a.RevokedAt = &now
+}
+
+// InboundMessage records an email turned into a task or a comment. Replies are
+// threaded by looking up the Message-ID they answer.
+type InboundMessage struct {
+	TenantModel
+	AddressID uuid.UUID  `json:"address_id" gorm:"type:uuid;not null;index"`
+	MessageID string     `json:"message_id" gorm:"size:998;not null;index"`
+	TaskID    uuid.UUID  `json:"task_id" gorm:"type:uuid;not null;index"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty" gorm:"type:uuid"`
+	From      string     `json:"from" gorm:"size:320"`
+	Subject   string     `json:"subject" gorm:"size:998"`
+}
+
+// TableName specifies the table name for InboundMessage
+func (InboundMessage) TableName() string {
+	return "inbound_messages"
+}
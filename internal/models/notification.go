@@ -22,6 +22,10 @@ const (
 	NotificationTypeUserJoined     NotificationType = "user_joined"
 	NotificationTypeProjectCreated NotificationType = "project_created"
 	NotificationTypeSystemUpdate   NotificationType = "system_update"
+	NotificationTypeExportReady    NotificationType = "export_ready"
+	NotificationTypePasswordReset  NotificationType = "password_reset"
+	NotificationTypeWeeklyDigest   NotificationType = "weekly_digest"
+	NotificationTypeDigest         NotificationType = "digest"
 )
 
 // NotificationStatus represents the status of a notification
@@ -52,9 +56,19 @@ type Notification struct {
 	Title       string              `json:"title" gorm:"not null;size:255"`
 	Message     string              `json:"message" gorm:"not null;type:text"`
 	ActionURL   string              `json:"action_url,omitempty" gorm:"size:500"`
-	Data        NotificationData    `json:"data" gorm:"type:jsonb"`
+	Data        NotificationData    `json:"data" gorm:"type:jsonb;serializer:json"`
 	ReadAt      *time.Time          `json:"read_at,omitempty"`
 	ArchivedAt  *time.Time          `json:"archived_at,omitempty"`
+
+	// Notifications with the same collapse key that arrive while one is
+	// unread are grouped into it. EventCount is how many it stands for.
+	CollapseKey string `json:"collapse_key,omitempty" gorm:"size:255;index"`
+	EventCount  int    `json:"event_count" gorm:"not null;default:1"`
+
+	// Hidden notifications were only sent on other channels, because the user
+	// turned in-app notifications off. They are kept so email, push and
+	// WebSocket can refer to them, but left out of the notification list.
+	Hidden bool `json:"hidden,omitempty" gorm:"not null;default:false;index"`
 	
 	// Related entity references
 	TaskID      *uuid.UUID `json:"task_id,omitempty" gorm:"type:uuid"`
@@ -80,6 +94,18 @@ type NotificationData struct {
 	ExtraData     map[string]interface{} `json:"extra_data,omitempty"`
 }
 
+// NotificationEvent is one of the events grouped into a notification
+type NotificationEvent struct {
+	BaseModel
+	TenantID       uuid.UUID        `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	NotificationID uuid.UUID        `json:"notification_id" gorm:"type:uuid;not null;index"`
+	ActorID        *uuid.UUID       `json:"actor_id,omitempty" gorm:"type:uuid"`
+	ActorName      string           `json:"actor_name,omitempty" gorm:"size:200"`
+	Title          string           `json:"title" gorm:"not null;size:255"`
+	Message        string           `json:"message" gorm:"not null;type:text"`
+	Data           NotificationData `json:"data" gorm:"type:jsonb;serializer:json"`
+}
+
 // NotificationPreference represents user notification preferences
 type NotificationPreference struct {
 	TenantModel
@@ -105,34 +131,45 @@ const (
 	NotificationFrequencyNever     NotificationFrequency = "never"
 )
 
-// NotificationTemplate represents a template for generating notifications
+// NotificationTemplate overrides the built-in content of a notification type
+// on one channel. Subject, Body and HTMLBody use Go template syntax and may
+// only reference the declared Variables. Templates of a tenant take precedence
+// over global ones (no tenant), and templates in the recipient's language over
+// other languages.
 type NotificationTemplate struct {
 	BaseModel
-	Type         NotificationType    `json:"type" gorm:"unique;not null;size:50"`
-	Name         string              `json:"name" gorm:"not null;size:100"`
-	Subject      string              `json:"subject" gorm:"not null;size:255"`
-	Body         string              `json:"body" gorm:"not null;type:text"`
-	HTMLBody     string              `json:"html_body" gorm:"type:text"`
-	Variables    []string            `json:"variables" gorm:"type:jsonb"`
-	IsActive     bool                `json:"is_active" gorm:"default:true"`
+	TenantID  *uuid.UUID          `json:"tenant_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_notification_templates_key"`
+	Type      NotificationType    `json:"type" gorm:"not null;size:50;uniqueIndex:idx_notification_templates_key"`
+	Channel   NotificationChannel `json:"channel" gorm:"not null;size:20;uniqueIndex:idx_notification_templates_key"`
+	Language  string              `json:"language" gorm:"not null;size:10;uniqueIndex:idx_notification_templates_key"`
+	Name      string              `json:"name" gorm:"not null;size:100"`
+	Subject   string              `json:"subject" gorm:"not null;size:255"`
+	Body      string              `json:"body" gorm:"not null;type:text"`
+	HTMLBody  string              `json:"html_body" gorm:"type:text"`
+	Variables []string            `json:"variables" gorm:"type:jsonb;serializer:json"`
+	IsActive  bool                `json:"is_active" gorm:"default:true"`
 }
 
 // NotificationQueue represents queued notifications for batch processing
 type NotificationQueue struct {
 	BaseModel
-	TenantID    uuid.UUID           `json:"tenant_id" gorm:"type:uuid;not null;index"`
-	UserID      uuid.UUID           `json:"user_id" gorm:"type:uuid;not null;index"`
-	Type        NotificationType    `json:"type" gorm:"not null;size:50"`
-	Channel     NotificationChannel `json:"channel" gorm:"not null;size:20"`
-	Priority    int                 `json:"priority" gorm:"default:1"`
-	Payload     NotificationPayload `json:"payload" gorm:"type:jsonb"`
-	Status      string              `json:"status" gorm:"default:'pending';size:20"`
-	ScheduledAt time.Time           `json:"scheduled_at" gorm:"not null"`
-	SentAt      *time.Time          `json:"sent_at,omitempty"`
-	FailedAt    *time.Time          `json:"failed_at,omitempty"`
-	RetryCount  int                 `json:"retry_count" gorm:"default:0"`
-	LastError   string              `json:"last_error,omitempty" gorm:"type:text"`
-	
+	TenantID    uuid.UUID             `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID             `json:"user_id" gorm:"type:uuid;not null;index"`
+	Type        NotificationType      `json:"type" gorm:"not null;size:50"`
+	Channel     NotificationChannel   `json:"channel" gorm:"not null;size:20"`
+	Frequency   NotificationFrequency `json:"frequency,omitempty" gorm:"size:20"`
+	Priority    int                   `json:"priority" gorm:"default:1"`
+	Payload     NotificationPayload   `json:"payload" gorm:"type:jsonb;serializer:json"`
+	Status      string                `json:"status" gorm:"default:'pending';size:20;index"`
+	ScheduledAt time.Time             `json:"scheduled_at" gorm:"not null;index"`
+	SentAt      *time.Time            `json:"sent_at,omitempty"`
+	FailedAt    *time.Time            `json:"failed_at,omitempty"`
+	RetryCount  int                   `json:"retry_count" gorm:"default:0"`
+	LastError   string                `json:"last_error,omitempty" gorm:"type:text"`
+
+	// NotificationID is the in-app notification a digest item repeats
+	NotificationID *uuid.UUID `json:"notification_id,omitempty" gorm:"type:uuid;index"`
+
 	// Relationships
 	Tenant Tenant `json:"tenant" gorm:"foreignKey:TenantID"`
 	User   User   `json:"user" gorm:"foreignKey:UserID"`
@@ -153,6 +190,11 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
+// TableName specifies the table name for NotificationEvent
+func (NotificationEvent) TableName() string {
+	return "notification_events"
+}
+
 // TableName specifies the table name for NotificationPreference
 func (NotificationPreference) TableName() string {
 	return "notification_preferences"
@@ -173,6 +215,11 @@ func (n *Notification) IsRead() bool {
 	return n.Status == NotificationStatusRead
 }
 
+// IsGrouped reports whether the notification stands for several events
+func (n *Notification) IsGrouped() bool {
+	return n.EventCount > 1
+}
+
 // IsArchived checks if the notification has been archived
 func (n *Notification) IsArchived() bool {
 	return n.Status == NotificationStatusArchived
@@ -230,6 +277,19 @@ func (nq *NotificationQueue) MarkAsSent() {
 	nq.SentAt = &now
 }
 
+// IsSkipped checks if the queued notification was dropped without sending
+func (nq *NotificationQueue) IsSkipped() bool {
+	return nq.Status == "skipped"
+}
+
+// MarkAsSkipped marks the queued notification as not needing to be sent,
+// such as a digest item the user already read in-app
+func (nq *NotificationQueue) MarkAsSkipped() {
+	nq.Status = "skipped"
+	now := time.Now()
+	nq.SentAt = &now
+}
+
 // MarkAsFailed marks the queued notification as failed
 func (nq *NotificationQueue) MarkAsFailed(err error) {
 	nq.Status = "failed"
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationDeliveryStatus represents the outcome of delivering a
+// notification on one channel
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending NotificationDeliveryStatus = "pending"
+	NotificationDeliverySent    NotificationDeliveryStatus = "sent"
+	NotificationDeliveryQueued  NotificationDeliveryStatus = "queued" // added to the user's email digest
+	NotificationDeliveryFailed  NotificationDeliveryStatus = "failed"
+	NotificationDeliverySkipped NotificationDeliveryStatus = "skipped"
+)
+
+// NotificationDelivery records a notification delivered to a user on one
+// channel. Key identifies what caused the notification, such as an event or a
+// job, so dispatching the same cause again delivers nothing twice.
+type NotificationDelivery struct {
+	TenantModel
+	Key            string                     `json:"key" gorm:"size:200;not null;uniqueIndex:idx_notification_deliveries_key"`
+	UserID         uuid.UUID                  `json:"user_id" gorm:"type:uuid;not null;uniqueIndex:idx_notification_deliveries_key"`
+	Channel        NotificationChannel        `json:"channel" gorm:"size:20;not null;uniqueIndex:idx_notification_deliveries_key"`
+	Type           NotificationType           `json:"type" gorm:"size:50;not null"`
+	NotificationID *uuid.UUID                 `json:"notification_id,omitempty" gorm:"type:uuid;index"`
+	Status         NotificationDeliveryStatus `json:"status" gorm:"size:20;not null;index"`
+	Attempts       int                        `json:"attempts" gorm:"not null;default:0"`
+	Error          string                     `json:"error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time                 `json:"delivered_at,omitempty"`
+}
+
+// TableName specifies the table name for NotificationDelivery
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
+
+// IsDone reports whether nothing is left to deliver
+func (d *NotificationDelivery) IsDone() bool {
+	switch d.Status {
+	case NotificationDeliverySent, NotificationDeliveryQueued, NotificationDeliverySkipped:
+		return true
+	}
+	return false
+}
+
+// MarkAsDelivered records a successful attempt
+func (d *NotificationDelivery) MarkAsDelivered(status NotificationDeliveryStatus) {
+	now := time.Now()
+	d.Status = status
+	d.Attempts++
+	d.Error = ""
+	d.DeliveredAt = &now
+}
+
+// MarkAsFailed records a failed attempt
+func (d *NotificationDelivery) MarkAsFailed(err error) {
+	d.Status = NotificationDeliveryFailed
+	d.Attempts++
+	if err != nil {
+		d.Error = err.Error()
+	}
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProjectRole represents what a user may do inside a project
+type ProjectRole string
+
+const (
+	ProjectRoleNone      ProjectRole = ""
+	ProjectRoleViewer    ProjectRole = "viewer"
+	ProjectRoleCommenter ProjectRole = "commenter"
+	ProjectRoleEditor    ProjectRole = "editor"
+	ProjectRoleOwner     ProjectRole = "owner"
+)
+
+// projectRoleRanks orders project roles from least to most privileged
+var projectRoleRanks = map[ProjectRole]int{
+	ProjectRoleViewer:    1,
+	ProjectRoleCommenter: 2,
+	ProjectRoleEditor:    3,
+	ProjectRoleOwner:     4,
+}
+
+// ProjectMember grants a user a role in a project
+type ProjectMember struct {
+	ProjectID uuid.UUID   `json:"project_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID   `json:"user_id" gorm:"type:uuid;primaryKey;index"`
+	TenantID  uuid.UUID   `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	Role      ProjectRole `json:"role" gorm:"size:20;not null"`
+	AddedBy   *uuid.UUID  `json:"added_by,omitempty" gorm:"type:uuid"`
+	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationships
+	Project Project `json:"-" gorm:"foreignKey:ProjectID"`
+	User    User    `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for ProjectMember
+func (ProjectMember) TableName() string {
+	return "project_members"
+}
+
+// IsValid checks if the role is a known project role
+func (r ProjectRole) IsValid() bool {
+	_, ok := projectRoleRanks[r]
+	return ok
+}
+
+// AtLeast checks if the role grants at least the permissions of min
+func (r ProjectRole) AtLeast(min ProjectRole) bool {
+	rank, ok := projectRoleRanks[r]
+	return ok && rank >= projectRoleRanks[min]
+}
+
+// CanRead checks if the role may see the project and its tasks
+func (r ProjectRole) CanRead() bool {
+	return r.AtLeast(ProjectRoleViewer)
+}
+
+// CanComment checks if the role may comment on tasks
+func (r ProjectRole) CanComment() bool {
+	return r.AtLeast(ProjectRoleCommenter)
+}
+
+// CanEdit checks if the role may create and change tasks
+func (r ProjectRole) CanEdit() bool {
+	return r.AtLeast(ProjectRoleEditor)
+}
+
+// CanManage checks if the role may change the project and its members
+func (r ProjectRole) CanManage() bool {
+	return r.AtLeast(ProjectRoleOwner)
+}
+
+// RoleFor returns the effective role of a user in the project. Tenant admins
+// own every project. Members get their explicit role. Other users can only
+// access public projects: managers own them and everyone else gets the
+// project's default role.
+func (p *Project) RoleFor(user *User, member *ProjectMember) ProjectRole {
+	if user == nil || user.TenantID != p.TenantID {
+		return ProjectRoleNone
+	}
+	if user.IsAdmin() {
+		return ProjectRoleOwner
+	}
+	if member != nil && member.UserID == user.ID && member.ProjectID == p.ID {
+		return member.Role
+	}
+	if p.IsPrivate {
+		return ProjectRoleNone
+	}
+	if user.IsManager() {
+		return ProjectRoleOwner
+	}
+	if p.DefaultRole.IsValid() {
+		return p.DefaultRole
+	}
+	return ProjectRoleEditor
+}
+
+// ProjectRoleOf looks up the membership of a user and returns their effective
+// role in the project
+func ProjectRoleOf(db *gorm.DB, user *User, project *Project) (ProjectRole, error) {
+	if user == nil {
+		return ProjectRoleNone, nil
+	}
+	if user.IsAdmin() {
+		return project.RoleFor(user, nil), nil
+	}
+
+	var members []ProjectMember
+	if err := db.Where("project_id = ? AND user_id = ?", project.ID, user.ID).Limit(1).Find(&members).Error; err != nil {
+		return ProjectRoleNone, err
+	}
+	if len(members) == 0 {
+		return project.RoleFor(user, nil), nil
+	}
+	return project.RoleFor(user, &members[0]), nil
+}
+
+// VisibleProjectIDs returns a subquery selecting the projects a user can read
+func VisibleProjectIDs(db *gorm.DB, user *User) *gorm.DB {
+	query := db.Model(&Project{}).Select("id").Where("tenant_id = ?", user.TenantID)
+	if user.IsAdmin() {
+		return query
+	}
+	return query.Where("is_private = ? OR id IN (?)", false,
+		db.Model(&ProjectMember{}).Select("project_id").Where("user_id = ?", user.ID))
+}
+
+// ArchivedProjectIDs returns a subquery selecting the archived projects of a tenant
+func ArchivedProjectIDs(db *gorm.DB, tenantID uuid.UUID) *gorm.DB {
+	return db.Model(&Project{}).Select("id").Where("tenant_id = ? AND is_active = ?", tenantID, false)
+}
+
+// ScopeVisibleTasks restricts a task query to tasks without a project or in
+// projects the user can read
+func ScopeVisibleTasks(db *gorm.DB, query *gorm.DB, user *User) *gorm.DB {
+	if user.IsAdmin() {
+		return query
+	}
+	return query.Where("tasks.project_id IS NULL OR tasks.project_id IN (?)", VisibleProjectIDs(db, user))
+}
+
+// ProjectAudience returns the users who may see the contents of a private
+// project. Public projects, or content without a project, are visible to the
+// whole tenant and report restricted as false. Deleted projects are still
+// resolved so their removal can be announced to the right users.
+func ProjectAudience(db *gorm.DB, tenantID uuid.UUID, projectID *uuid.UUID) (userIDs []uuid.UUID, restricted bool, err error) {
+	public, err := ProjectIsPublic(db, tenantID, projectID)
+	if err != nil || public {
+		return nil, false, err
+	}
+
+	err = db.Model(&User{}).
+		Where("tenant_id = ? AND (role = ? OR id IN (?))", tenantID, UserRoleAdmin,
+			db.Model(&ProjectMember{}).Select("user_id").Where("project_id = ?", *projectID)).
+		Pluck("id", &userIDs).Error
+	return userIDs, true, err
+}
+
+// ProjectIsPublic reports whether the contents of a project are visible to
+// the whole tenant. Content without a project is public. Deleted projects
+// are still resolved.
+func ProjectIsPublic(db *gorm.DB, tenantID uuid.UUID, projectID *uuid.UUID) (bool, error) {
+	if projectID == nil {
+		return true, nil
+	}
+
+	var project Project
+	if err := db.Unscoped().Select("id", "is_private").Where("id = ? AND tenant_id = ?", *projectID, tenantID).First(&project).Error; err != nil {
+		return false, err
+	}
+	return !project.IsPrivate, nil
+}
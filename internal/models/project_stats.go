@@ -0,0 +1,145 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProjectHealthStatus summarizes a project health score
+type ProjectHealthStatus string
+
+const (
+	ProjectHealthHealthy  ProjectHealthStatus = "healthy"
+	ProjectHealthAtRisk   ProjectHealthStatus = "at_risk"
+	ProjectHealthCritical ProjectHealthStatus = "critical"
+)
+
+// Weights of the health score components; together they can remove the whole score
+const (
+	healthOverdueWeight = 60.0
+	healthSlipWeight    = 40.0
+)
+
+// WeeklyThroughput is the number of tasks completed in a week starting on Monday
+type WeeklyThroughput struct {
+	WeekStart time.Time `json:"week_start"`
+	Completed int64     `json:"completed"`
+}
+
+// ProjectHours compares estimated and logged hours
+type ProjectHours struct {
+	Estimated          float64  `json:"estimated"`
+	Actual             float64  `json:"actual"`
+	CompletedEstimated float64  `json:"completed_estimated"`
+	CompletedActual    float64  `json:"completed_actual"`
+	VariancePercent    *float64 `json:"variance_percent,omitempty"` // actual vs estimate of completed tasks
+}
+
+// ProjectSlip describes how far a project runs behind its planned end
+type ProjectSlip struct {
+	PlannedEnd      *time.Time `json:"planned_end,omitempty"`
+	ProjectedEnd    *time.Time `json:"projected_end,omitempty"`
+	SlipDays        float64    `json:"slip_days"`
+	LateCompletions int64      `json:"late_completions"`
+	AvgLateDays     float64    `json:"avg_late_days"`
+}
+
+// ProjectHealth is a 0-100 score of how well a project is tracking
+type ProjectHealth struct {
+	Score        float64             `json:"score"`
+	Status       ProjectHealthStatus `json:"status"`
+	OverdueRatio float64             `json:"overdue_ratio"`
+	SlipRatio    float64             `json:"slip_ratio"`
+}
+
+// ProjectStats aggregates the tasks of a project
+type ProjectStats struct {
+	ProjectID         uuid.UUID              `json:"project_id"`
+	Total             int64                  `json:"total"`
+	Open              int64                  `json:"open"`
+	ByStatus          map[TaskStatus]int64   `json:"by_status"`
+	ByPriority        map[TaskPriority]int64 `json:"by_priority"`
+	Overdue           int64                  `json:"overdue"`
+	OverdueByPriority map[TaskPriority]int64 `json:"overdue_by_priority"`
+	CompletionPercent float64                `json:"completion_percent"`
+	Hours             ProjectHours           `json:"hours"`
+	Throughput        []WeeklyThroughput     `json:"throughput"`
+	AvgCycleTimeDays  *float64               `json:"avg_cycle_time_days,omitempty"`
+	Slip              ProjectSlip            `json:"slip"`
+	Health            ProjectHealth          `json:"health"`
+	GeneratedAt       time.Time              `json:"generated_at"`
+}
+
+// WeekStart returns the Monday starting the UTC week of t
+func WeekStart(t time.Time) time.Time {
+	day := SprintDay(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// FillThroughput returns one entry per week from the week of since up to the
+// week of now, using zero for weeks without completions
+func FillThroughput(counts map[time.Time]int64, since, now time.Time) []WeeklyThroughput {
+	weeks := []WeeklyThroughput{}
+	for week := WeekStart(since); !week.After(now); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, WeeklyThroughput{WeekStart: week, Completed: counts[week]})
+	}
+	return weeks
+}
+
+// ComputeSlip fills the slip in days between the planned project end and the
+// latest due date of its open tasks. Open overdue tasks cannot finish before now.
+func (s *ProjectStats) ComputeSlip(project *Project, latestOpenDue *time.Time, now time.Time) {
+	s.Slip.PlannedEnd = project.EndDate
+
+	projected := latestOpenDue
+	if s.Overdue > 0 && (projected == nil || projected.Before(now)) {
+		projected = &now
+	}
+	s.Slip.ProjectedEnd = projected
+
+	if project.EndDate != nil && projected != nil && projected.After(*project.EndDate) {
+		s.Slip.SlipDays = math.Round(projected.Sub(*project.EndDate).Hours()/24*10) / 10
+	}
+}
+
+// ComputeHealth scores the project from its overdue ratio and timeline slip.
+// Slip is measured against the planned duration of the project, or as the
+// share of late completions when the project has no planned end.
+func (s *ProjectStats) ComputeHealth(project *Project) {
+	health := ProjectHealth{Score: 100}
+
+	if s.Open > 0 {
+		health.OverdueRatio = float64(s.Overdue) / float64(s.Open)
+	}
+
+	plannedStart := project.CreatedAt
+	if project.StartDate != nil {
+		plannedStart = *project.StartDate
+	}
+	if project.EndDate != nil && project.EndDate.After(plannedStart) {
+		duration := project.EndDate.Sub(plannedStart).Hours() / 24
+		health.SlipRatio = s.Slip.SlipDays / duration
+	} else if completed := s.ByStatus[TaskStatusCompleted]; completed > 0 {
+		health.SlipRatio = float64(s.Slip.LateCompletions) / float64(completed)
+	}
+	health.SlipRatio = math.Min(health.SlipRatio, 1)
+
+	health.Score -= health.OverdueRatio*healthOverdueWeight + health.SlipRatio*healthSlipWeight
+	health.Score = math.Round(math.Max(health.Score, 0)*10) / 10
+	health.OverdueRatio = math.Round(health.OverdueRatio*1000) / 1000
+	health.SlipRatio = math.Round(health.SlipRatio*1000) / 1000
+
+	switch {
+	case health.Score >= 75:
+		health.Status = ProjectHealthHealthy
+	case health.Score >= 50:
+		health.Status = ProjectHealthAtRisk
+	default:
+		health.Status = ProjectHealthCritical
+	}
+
+	s.Health = health
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushSubscription is a browser's Web Push subscription. It belongs to the
+// session that registered it and is removed when that session logs out.
+type PushSubscription struct {
+	TenantModel
+	UserID     uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	SessionID  *uuid.UUID `json:"session_id,omitempty" gorm:"type:uuid;index"`
+	Endpoint   string     `json:"endpoint" gorm:"size:2000;not null;uniqueIndex"`
+	P256dh     string     `json:"-" gorm:"size:200;not null"`
+	Auth       string     `json:"-" gorm:"size:100;not null"`
+	UserAgent  string     `json:"user_agent,omitempty" gorm:"size:500"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for PushSubscription
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
+
+// IsExpired reports whether the browser said the subscription has expired
+func (s *PushSubscription) IsExpired() bool {
+	return s.ExpiresAt != nil && time.Now().After(*s.ExpiresAt)
+}
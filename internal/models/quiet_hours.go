@@ -0,0 +1,100 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxQuietWindows bounds how many back-to-back quiet windows QuietUntil
+// joins, such as do-not-disturb running into a muted weekend and then into
+// Monday's quiet hours
+const maxQuietWindows = 8
+
+// ParseClock parses a time of day written as HH:MM and returns the minutes
+// since midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Location returns the user's timezone, or UTC when it is unset or unknown
+func (u *User) Location() *time.Location {
+	if u.Timezone != "" {
+		if location, err := time.LoadLocation(u.Timezone); err == nil {
+			return location
+		}
+	}
+	return time.UTC
+}
+
+// DoNotDisturb reports whether the user's do-not-disturb is on at now
+func (u *User) DoNotDisturb(now time.Time) bool {
+	return u.DoNotDisturbUntil != nil && now.Before(*u.DoNotDisturbUntil)
+}
+
+// QuietUntil returns when the quiet time now falls in ends, joining
+// do-not-disturb, muted weekends and quiet hours that follow one another.
+// It reports false when the user is not quiet at now.
+func (u *User) QuietUntil(now time.Time) (time.Time, bool) {
+	until := now
+	for i := 0; i < maxQuietWindows; i++ {
+		end := u.quietEnd(until)
+		if !end.After(until) {
+			break
+		}
+		until = end
+	}
+	return until, until.After(now)
+}
+
+// AllowsThroughQuiet reports whether a notification reaches the user while
+// they are quiet. Only urgent notifications do, when the user allows it.
+func (u *User) AllowsThroughQuiet(urgent bool) bool {
+	return urgent && u.QuietAllowUrgent
+}
+
+// quietEnd returns when the quiet window at t ends, or t when t is not in
+// one
+func (u *User) quietEnd(t time.Time) time.Time {
+	if u.DoNotDisturb(t) {
+		return *u.DoNotDisturbUntil
+	}
+
+	location := u.Location()
+	local := t.In(location)
+	if u.QuietWeekends {
+		switch local.Weekday() {
+		case time.Saturday:
+			return time.Date(local.Year(), local.Month(), local.Day()+2, 0, 0, 0, 0, location)
+		case time.Sunday:
+			return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, location)
+		}
+	}
+
+	if !u.QuietHoursEnabled {
+		return t
+	}
+	start, err := ParseClock(u.QuietHoursStart)
+	if err != nil {
+		return t
+	}
+	end, err := ParseClock(u.QuietHoursEnd)
+	if err != nil || start == end {
+		return t
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Day()
+	switch {
+	case start < end && minute >= start && minute < end:
+	case start > end && minute < end:
+	case start > end && minute >= start:
+		day++ // the window ends tomorrow
+	default:
+		return t
+	}
+	return time.Date(local.Year(), local.Month(), day, end/60, end%60, 0, 0, location)
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ViewVisibility controls who can see a saved view
+type ViewVisibility string
+
+const (
+	ViewVisibilityPrivate ViewVisibility = "private"
+	ViewVisibilityTenant  ViewVisibility = "tenant"
+	ViewVisibilityProject ViewVisibility = "project"
+)
+
+// ViewMode represents how a saved view is rendered, matching User.TaskViewMode
+type ViewMode string
+
+const (
+	ViewModeList     ViewMode = "list"
+	ViewModeBoard    ViewMode = "board"
+	ViewModeCalendar ViewMode = "calendar"
+)
+
+// TaskFilter is the set of task listing filters shared by ListTasks and saved views
+type TaskFilter struct {
+	Statuses         []TaskStatus   `json:"statuses,omitempty"`
+	Priorities       []TaskPriority `json:"priorities,omitempty"`
+	AssigneeID       *uuid.UUID     `json:"assignee_id,omitempty"`
+	AssignedToMe     bool           `json:"assigned_to_me,omitempty"`
+	Unassigned       bool           `json:"unassigned,omitempty"`
+	ProjectID        *uuid.UUID     `json:"project_id,omitempty"`
+	CreatorID        *uuid.UUID     `json:"creator_id,omitempty"`
+	TagIDs           []uuid.UUID    `json:"tag_ids,omitempty"`
+	DueFrom          *time.Time     `json:"due_from,omitempty"`
+	DueTo            *time.Time     `json:"due_to,omitempty"`
+	Overdue          bool           `json:"overdue,omitempty"`
+	Search           string         `json:"search,omitempty"`
+	IncludeCompleted *bool          `json:"include_completed,omitempty"`
+	IncludeArchived  bool           `json:"include_archived,omitempty"`
+}
+
+// Apply narrows a task query with the filter for the given user
+func (filter *TaskFilter) Apply(db *gorm.DB, query *gorm.DB, user *User) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	} else if filter.IncludeCompleted != nil && !*filter.IncludeCompleted {
+		query = query.Where("status NOT IN ?", []TaskStatus{TaskStatusCompleted, TaskStatusCanceled})
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("priority IN ?", filter.Priorities)
+	}
+	switch {
+	case filter.AssignedToMe:
+		query = query.Where("assignee_id = ?", user.ID)
+	case filter.Unassigned:
+		query = query.Where("assignee_id IS NULL")
+	case filter.AssigneeID != nil:
+		query = query.Where("assignee_id = ?", *filter.AssigneeID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", *filter.ProjectID)
+	} else if !filter.IncludeArchived {
+		// Tasks of archived projects only show up when asked for or when
+		// their project is listed explicitly
+		query = query.Where("project_id IS NULL OR project_id NOT IN (?)", ArchivedProjectIDs(db, user.TenantID))
+	}
+	if filter.CreatorID != nil {
+		query = query.Where("creator_id = ?", *filter.CreatorID)
+	}
+	if len(filter.TagIDs) > 0 {
+		// Filtering by a tag also matches the tags nested below it
+		query = query.Where("id IN (?)", db.Model(&TaskTag{}).Select("task_id").
+			Where("tag_id IN (?)", TagSubtreeIDs(db, user.TenantID, filter.TagIDs)))
+	}
+	if filter.DueFrom != nil {
+		query = query.Where("due_date >= ?", *filter.DueFrom)
+	}
+	if filter.DueTo != nil {
+		query = query.Where("due_date <= ?", *filter.DueTo)
+	}
+	if filter.Overdue {
+		query = query.Where("due_date < ? AND status NOT IN ?", time.Now(),
+			[]TaskStatus{TaskStatusCompleted, TaskStatusCanceled})
+	}
+	if filter.Search != "" {
+		searchTerm := "%" + strings.ToLower(filter.Search) + "%"
+		query = query.Where("LOWER(title) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm)
+	}
+
+	return query
+}
+
+// SavedView represents a named, reusable task listing
+type SavedView struct {
+	TenantModel
+	OwnerID    uuid.UUID      `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Name       string         `json:"name" gorm:"not null;size:100"`
+	Filter     TaskFilter     `json:"filter" gorm:"type:jsonb;serializer:json"`
+	Sort       string         `json:"sort" gorm:"size:50;default:'created_at'"`
+	Order      string         `json:"order" gorm:"size:4;default:'desc'"`
+	Columns    []string       `json:"columns" gorm:"type:jsonb;serializer:json"`
+	ViewMode   ViewMode       `json:"view_mode" gorm:"size:10;default:'list'"`
+	Visibility ViewVisibility `json:"visibility" gorm:"size:10;default:'private'"`
+	ProjectID  *uuid.UUID     `json:"project_id,omitempty" gorm:"type:uuid;index"`
+	IsDefault  bool           `json:"is_default" gorm:"-"`
+
+	// Relationships
+	Owner   User     `json:"owner" gorm:"foreignKey:OwnerID"`
+	Project *Project `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
+}
+
+// TableName specifies the table name for SavedView
+func (SavedView) TableName() string {
+	return "saved_views"
+}
+
+// IsShared checks if the view is visible to users other than its owner
+func (sv *SavedView) IsShared() bool {
+	return sv.Visibility != ViewVisibilityPrivate
+}
+
+// CanView checks if a user may see and run the view
+func (sv *SavedView) CanView(user *User) bool {
+	if user == nil || user.TenantID != sv.TenantID {
+		return false
+	}
+	return sv.OwnerID == user.ID || sv.IsShared()
+}
+
+// CanEdit checks if a user may change or delete the view
+func (sv *SavedView) CanEdit(user *User) bool {
+	if user == nil || user.TenantID != sv.TenantID {
+		return false
+	}
+	if sv.OwnerID == user.ID {
+		return true
+	}
+	return sv.IsShared() && user.CanManage()
+}
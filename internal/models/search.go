@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchEntityType is the kind of entity a search document describes
+type SearchEntityType string
+
+const (
+	SearchEntityTask    SearchEntityType = "task"
+	SearchEntityProject SearchEntityType = "project"
+	SearchEntityComment SearchEntityType = "comment"
+)
+
+// SearchDocument is the searchable text of a task, project or comment. The
+// index is kept up to date from domain events.
+type SearchDocument struct {
+	EntityType SearchEntityType `json:"entity_type" gorm:"size:20;primaryKey"`
+	EntityID   uuid.UUID        `json:"entity_id" gorm:"type:uuid;primaryKey"`
+	TenantID   uuid.UUID        `json:"tenant_id" gorm:"type:uuid;not null;index"`
+	ProjectID  *uuid.UUID       `json:"project_id,omitempty" gorm:"type:uuid;index"`
+	TaskID     *uuid.UUID       `json:"task_id,omitempty" gorm:"type:uuid;index"` // the task a comment belongs to
+	Title      string           `json:"title" gorm:"size:255"`
+	Content    string           `json:"content" gorm:"type:text"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	Rank       float64          `json:"rank,omitempty" gorm:"->;-:migration"`
+}
+
+// TableName specifies the table name for SearchDocument
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
+
+// IsValid checks if the entity type is searchable
+func (t SearchEntityType) IsValid() bool {
+	return t == SearchEntityTask || t == SearchEntityProject || t == SearchEntityComment
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SprintStatus represents the lifecycle state of a sprint
+type SprintStatus string
+
+const (
+	SprintStatusPlanned SprintStatus = "planned"
+	SprintStatusActive  SprintStatus = "active"
+	SprintStatusClosed  SprintStatus = "closed"
+)
+
+// EstimateUnit selects which task estimate a sprint measures its scope in
+type EstimateUnit string
+
+const (
+	EstimateUnitHours  EstimateUnit = "hours"
+	EstimateUnitPoints EstimateUnit = "points"
+)
+
+// Sprint represents a time-boxed iteration of a project
+type Sprint struct {
+	TenantModel
+	ProjectID    uuid.UUID     `json:"project_id" gorm:"type:uuid;not null;index"`
+	Name         string        `json:"name" gorm:"not null;size:100"`
+	Goal         string        `json:"goal" gorm:"type:text"`
+	StartDate    time.Time     `json:"start_date" gorm:"not null"`
+	EndDate      time.Time     `json:"end_date" gorm:"not null"`
+	Status       SprintStatus  `json:"status" gorm:"size:20;default:'planned';index"`
+	EstimateUnit EstimateUnit  `json:"estimate_unit" gorm:"size:10;default:'hours'"`
+	StartedAt    *time.Time    `json:"started_at,omitempty"`
+	ClosedAt     *time.Time    `json:"closed_at,omitempty"`
+	ClosedBy     *uuid.UUID    `json:"closed_by,omitempty" gorm:"type:uuid"`
+	Report       *SprintReport `json:"report,omitempty" gorm:"type:jsonb;serializer:json"`
+
+	// Relationships
+	Project *Project     `json:"project,omitempty" gorm:"foreignKey:ProjectID"`
+	Tasks   []SprintTask `json:"tasks,omitempty" gorm:"foreignKey:SprintID"`
+}
+
+// SprintTask records a task's membership of a sprint
+type SprintTask struct {
+	SprintID        uuid.UUID  `json:"sprint_id" gorm:"type:uuid;primaryKey"`
+	TaskID          uuid.UUID  `json:"task_id" gorm:"type:uuid;primaryKey;index"`
+	AddedAt         time.Time  `json:"added_at" gorm:"autoCreateTime"`
+	CarriedOverFrom *uuid.UUID `json:"carried_over_from,omitempty" gorm:"type:uuid"`
+
+	// Relationships
+	Task Task `json:"task" gorm:"foreignKey:TaskID"`
+}
+
+// SprintSnapshot captures the scope and progress of a sprint at the end of a day
+type SprintSnapshot struct {
+	ID             uuid.UUID `json:"-" gorm:"type:uuid;primary_key"`
+	SprintID       uuid.UUID `json:"sprint_id" gorm:"type:uuid;not null;uniqueIndex:idx_sprint_snapshot_day"`
+	Day            time.Time `json:"day" gorm:"type:date;not null;uniqueIndex:idx_sprint_snapshot_day"`
+	Scope          float64   `json:"scope"`
+	Completed      float64   `json:"completed"`
+	Remaining      float64   `json:"remaining"`
+	TaskCount      int       `json:"task_count"`
+	CompletedCount int       `json:"completed_count"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// SprintReport summarises a sprint when it is closed
+type SprintReport struct {
+	Unit            EstimateUnit `json:"unit"`
+	CommittedScope  float64      `json:"committed_scope"`
+	FinalScope      float64      `json:"final_scope"`
+	Completed       float64      `json:"completed"`
+	CompletionRate  float64      `json:"completion_rate"`
+	CompletedTasks  []uuid.UUID  `json:"completed_tasks"`
+	IncompleteTasks []uuid.UUID  `json:"incomplete_tasks"`
+	AddedTasks      int          `json:"added_tasks"`
+	CarriedOverTo   *uuid.UUID   `json:"carried_over_to,omitempty"`
+	DurationDays    int          `json:"duration_days"`
+}
+
+// BurndownPoint is one day of a sprint's burndown and burnup series
+type BurndownPoint struct {
+	Day       time.Time `json:"day"`
+	Scope     float64   `json:"scope"`
+	Completed float64   `json:"completed"`
+	Remaining float64   `json:"remaining"`
+	Ideal     float64   `json:"ideal"`
+}
+
+// TableName specifies the table name for Sprint
+func (Sprint) TableName() string {
+	return "sprints"
+}
+
+// TableName specifies the table name for SprintTask
+func (SprintTask) TableName() string {
+	return "sprint_tasks"
+}
+
+// TableName specifies the table name for SprintSnapshot
+func (SprintSnapshot) TableName() string {
+	return "sprint_snapshots"
+}
+
+// BeforeCreate generates the snapshot ID
+func (ss *SprintSnapshot) BeforeCreate(tx *gorm.DB) error {
+	if ss.ID == uuid.Nil {
+		ss.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsClosed checks if the sprint has been closed
+func (s *Sprint) IsClosed() bool {
+	return s.Status == SprintStatusClosed
+}
+
+// Estimate returns the size of a task in the sprint's estimate unit
+func (s *Sprint) Estimate(task *Task) float64 {
+	switch s.EstimateUnit {
+	case EstimateUnitPoints:
+		if task.StoryPoints != nil {
+			return *task.StoryPoints
+		}
+	default:
+		if task.EstimatedHours != nil {
+			return *task.EstimatedHours
+		}
+	}
+	return 0
+}
+
+// Measure computes the current snapshot of the sprint from its member tasks.
+// Canceled tasks no longer count towards the scope.
+func (s *Sprint) Measure(tasks []Task, day time.Time) *SprintSnapshot {
+	snapshot := &SprintSnapshot{
+		SprintID: s.ID,
+		Day:      SprintDay(day),
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if task.Status == TaskStatusCanceled {
+			continue
+		}
+		estimate := s.Estimate(task)
+		snapshot.Scope += estimate
+		snapshot.TaskCount++
+		if task.IsCompleted() {
+			snapshot.Completed += estimate
+			snapshot.CompletedCount++
+		}
+	}
+	snapshot.Remaining = snapshot.Scope - snapshot.Completed
+
+	return snapshot
+}
+
+// MemberTasks loads the tasks of the sprint
+func (s *Sprint) MemberTasks(db *gorm.DB) ([]Task, error) {
+	var tasks []Task
+	err := db.
+		Where("id IN (?)", db.Model(&SprintTask{}).Select("task_id").Where("sprint_id = ?", s.ID)).
+		Find(&tasks).Error
+	return tasks, err
+}
+
+// TakeSnapshot measures the sprint and stores the result as the snapshot of
+// the given day, replacing any earlier snapshot of that day
+func (s *Sprint) TakeSnapshot(db *gorm.DB, day time.Time) (*SprintSnapshot, error) {
+	tasks, err := s.MemberTasks(db)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := s.Measure(tasks, day)
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sprint_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"scope", "completed", "remaining", "task_count", "completed_count"}),
+	}).Create(snapshot).Error
+
+	return snapshot, err
+}
+
+// Burndown builds the daily burndown and burnup series of the sprint from its
+// snapshots. Days without a snapshot repeat the previous day, and the ideal
+// line burns the committed scope down linearly to the end date.
+func (s *Sprint) Burndown(snapshots []SprintSnapshot, now time.Time) []BurndownPoint {
+	byDay := make(map[time.Time]SprintSnapshot, len(snapshots))
+	for _, snapshot := range snapshots {
+		byDay[SprintDay(snapshot.Day)] = snapshot
+	}
+
+	start, end := SprintDay(s.StartDate), SprintDay(s.EndDate)
+	last := end
+	if s.ClosedAt != nil && SprintDay(*s.ClosedAt).Before(last) {
+		last = SprintDay(*s.ClosedAt)
+	}
+	if today := SprintDay(now); today.Before(last) {
+		last = today
+	}
+
+	var committed float64
+	if first, ok := byDay[start]; ok {
+		committed = first.Scope
+	} else if len(snapshots) > 0 {
+		committed = snapshots[0].Scope
+	}
+
+	totalDays := end.Sub(start).Hours() / 24
+	points := []BurndownPoint{}
+	var previous SprintSnapshot
+	for day := start; !day.After(last); day = day.AddDate(0, 0, 1) {
+		if snapshot, ok := byDay[day]; ok {
+			previous = snapshot
+		}
+
+		ideal := 0.0
+		if totalDays > 0 {
+			ideal = committed * (1 - day.Sub(start).Hours()/24/totalDays)
+		}
+
+		points = append(points, BurndownPoint{
+			Day:       day,
+			Scope:     previous.Scope,
+			Completed: previous.Completed,
+			Remaining: previous.Remaining,
+			Ideal:     ideal,
+		})
+	}
+
+	return points
+}
+
+// SprintDay truncates a time to the UTC day used for sprint snapshots
+func SprintDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
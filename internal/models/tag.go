@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// TagPathSeparator separates the levels of a nested tag name such as "area/backend"
+const TagPathSeparator = "/"
+
+// tagNameIndex keeps tag names unique per tenant ignoring case
+const tagNameIndex = "idx_tags_tenant_lower_name"
+
+// ErrInvalidTagName is returned for tag names with empty path segments
+var ErrInvalidTagName = errors.New("tag name segments cannot be empty")
+
+// IsTagNameConflict reports whether err is a write that would have given two
+// tags of a tenant the same name
+func IsTagNameConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == tagNameIndex
+}
+
+// MigrateTagNames merges tags whose names differ only in case into the
+// oldest of them, then adds the index that keeps names unique
+func MigrateTagNames(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var duplicates []struct {
+			ID     uuid.UUID
+			KeepID uuid.UUID
+		}
+		if err := tx.Raw(`SELECT id, keep_id FROM (
+				SELECT id, FIRST_VALUE(id) OVER (PARTITION BY tenant_id, LOWER(name) ORDER BY created_at, id) AS keep_id
+				FROM tags WHERE deleted_at IS NULL
+			) AS ranked WHERE id <> keep_id`).
+			Scan(&duplicates).Error; err != nil {
+			return fmt.Errorf("failed to find duplicate tags: %w", err)
+		}
+
+		for _, duplicate := range duplicates {
+			steps := []struct {
+				sql  string
+				args []interface{}
+			}{
+				{"INSERT INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ? ON CONFLICT DO NOTHING", []interface{}{duplicate.KeepID, duplicate.ID}},
+				{"DELETE FROM task_tags WHERE tag_id = ?", []interface{}{duplicate.ID}},
+				{"UPDATE tags SET parent_id = ? WHERE parent_id = ?", []interface{}{duplicate.KeepID, duplicate.ID}},
+				{"UPDATE tags SET deleted_at = NOW() WHERE id = ?", []interface{}{duplicate.ID}},
+			}
+			for _, step := range steps {
+				if err := tx.Exec(step.sql, step.args...).Error; err != nil {
+					return fmt.Errorf("failed to merge duplicate tag %s: %w", duplicate.ID, err)
+				}
+			}
+		}
+
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS " + tagNameIndex +
+			" ON tags (tenant_id, LOWER(name)) WHERE deleted_at IS NULL").Error
+	})
+}
+
+// NormalizeTagName trims the tag name and each of its path segments
+func NormalizeTagName(name string) (string, error) {
+	segments := strings.Split(strings.TrimSpace(name), TagPathSeparator)
+	for i, segment := range segments {
+		segments[i] = strings.TrimSpace(segment)
+		if segments[i] == "" {
+			return "", ErrInvalidTagName
+		}
+	}
+	return strings.Join(segments, TagPathSeparator), nil
+}
+
+// TagParentName returns the name of the parent of a nested tag, or an empty
+// string for top-level tags
+func TagParentName(name string) string {
+	i := strings.LastIndex(name, TagPathSeparator)
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+// TagLeafName returns the last path segment of a tag name
+func TagLeafName(name string) string {
+	return name[strings.LastIndex(name, TagPathSeparator)+1:]
+}
+
+// IsTagDescendant checks if name is nested below ancestor, ignoring case
+func IsTagDescendant(name, ancestor string) bool {
+	prefix := strings.ToLower(ancestor) + TagPathSeparator
+	return strings.HasPrefix(strings.ToLower(name), prefix)
+}
+
+// FindTagByName looks up a tag of a tenant by name, ignoring case
+func FindTagByName(db *gorm.DB, tenantID uuid.UUID, name string) (*Tag, error) {
+	var tags []Tag
+	if err := db.Where("tenant_id = ? AND LOWER(name) = LOWER(?)", tenantID, name).Limit(1).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	return &tags[0], nil
+}
+
+// TagDescendants returns the tags nested below a tag, shallowest first
+func TagDescendants(db *gorm.DB, tag *Tag) ([]Tag, error) {
+	var tags []Tag
+	err := db.
+		Where("tenant_id = ? AND LEFT(LOWER(name), ?) = LOWER(?)",
+			tag.TenantID, len([]rune(tag.Name))+1, tag.Name+TagPathSeparator).
+		Order("LENGTH(name) ASC").
+		Find(&tags).Error
+	return tags, err
+}
+
+// TagSubtreeIDs returns a subquery selecting the given tags and every tag
+// nested below them
+func TagSubtreeIDs(db *gorm.DB, tenantID uuid.UUID, tagIDs []uuid.UUID) *gorm.DB {
+	return db.Table("tags AS d").
+		Select("d.id").
+		Joins("JOIN tags AS p ON p.tenant_id = d.tenant_id AND (d.id = p.id OR LEFT(LOWER(d.name), LENGTH(p.name) + 1) = LOWER(p.name) || ?)", TagPathSeparator).
+		Where("p.tenant_id = ? AND p.id IN ? AND d.deleted_at IS NULL AND p.deleted_at IS NULL", tenantID, tagIDs)
+}
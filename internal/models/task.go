@@ -35,10 +35,13 @@ type Task struct {
 	Description   string        `json:"description" gorm:"type:text"`
 	Status        TaskStatus    `json:"status" gorm:"default:'todo'"`
 	Priority      TaskPriority  `json:"priority" gorm:"default:'medium'"`
+	StartDate     *time.Time    `json:"start_date,omitempty"`
 	DueDate       *time.Time    `json:"due_date,omitempty"`
 	CompletedAt   *time.Time    `json:"completed_at,omitempty"`
 	EstimatedHours *float64     `json:"estimated_hours,omitempty"`
 	ActualHours   *float64      `json:"actual_hours,omitempty"`
+	StoryPoints   *float64      `json:"story_points,omitempty"`
+	IsMilestone   bool          `json:"is_milestone" gorm:"default:false"`
 	
 	// Relationships
 	CreatorID   uuid.UUID  `json:"creator_id" gorm:"type:uuid;not null"`
@@ -55,6 +58,9 @@ type Task struct {
 	Attachments []TaskAttachment `json:"attachments,omitempty" gorm:"foreignKey:TaskID"`
 	Tags       []Tag      `json:"tags,omitempty" gorm:"many2many:task_tags;"`
 	Activities []TaskActivity  `json:"activities,omitempty" gorm:"foreignKey:TaskID"`
+	Watchers   []TaskWatcher   `json:"watchers,omitempty" gorm:"foreignKey:TaskID"`
+	Checklist  []ChecklistItem `json:"checklist,omitempty" gorm:"foreignKey:TaskID"`
+	Relations  []TaskRelationView `json:"relations,omitempty" gorm:"-"`
 }
 
 // TaskComment represents a comment on a task
@@ -110,19 +116,26 @@ type Project struct {
 	Name        string    `json:"name" gorm:"not null;size:255"`
 	Description string    `json:"description" gorm:"type:text"`
 	Color       string    `json:"color" gorm:"size:7"` // Hex color code
-	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	IsActive    bool      `json:"is_active" gorm:"default:true;index"` // false once the project is archived
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	ArchivedBy  *uuid.UUID `json:"archived_by,omitempty" gorm:"type:uuid"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
 	EndDate     *time.Time `json:"end_date,omitempty"`
+	IsPrivate   bool        `json:"is_private" gorm:"default:false;index"`
+	DefaultRole ProjectRole `json:"default_role" gorm:"size:20;default:'editor'"`
+	Role        ProjectRole `json:"role,omitempty" gorm:"-"`
 	
 	// Relationships
-	Tasks []Task `json:"tasks,omitempty" gorm:"foreignKey:ProjectID"`
+	Tasks   []Task          `json:"tasks,omitempty" gorm:"foreignKey:ProjectID"`
+	Members []ProjectMember `json:"members,omitempty" gorm:"foreignKey:ProjectID"`
 }
 
 // Tag represents a tag that can be applied to tasks
 type Tag struct {
 	TenantModel
-	Name  string `json:"name" gorm:"not null;size:100"`
-	Color string `json:"color" gorm:"size:7"` // Hex color code
+	Name     string     `json:"name" gorm:"not null;size:100"` // Full path for nested tags, e.g. "area/backend"
+	Color    string     `json:"color" gorm:"size:7"` // Hex color code
+	ParentID *uuid.UUID `json:"parent_id,omitempty" gorm:"type:uuid;index"`
 	
 	// Relationships
 	Tasks []Task `json:"tasks,omitempty" gorm:"many2many:task_tags;"`
@@ -199,9 +212,13 @@ func (t *Task) MarkAsIncomplete() {
 	t.CompletedAt = nil
 }
 
-// GetProgress returns the progress percentage of the task based on subtasks
+// GetProgress returns the progress percentage of the task based on subtasks,
+// or on its checklist when it has no subtasks
 func (t *Task) GetProgress() float64 {
 	if len(t.Subtasks) == 0 {
+		if progress, ok := ChecklistProgress(t.Checklist); ok {
+			return progress
+		}
 		if t.IsCompleted() {
 			return 100.0
 		}
@@ -228,6 +245,26 @@ func (t *Task) CanBeAssignedTo(userID uuid.UUID, user *User) bool {
 	return user.TenantID == t.TenantID && user.IsActive()
 }
 
+// IsArchived checks if the project is archived and therefore read-only
+func (p *Project) IsArchived() bool {
+	return !p.IsActive
+}
+
+// Archive marks the project as archived by the given user
+func (p *Project) Archive(userID uuid.UUID) {
+	now := time.Now()
+	p.IsActive = false
+	p.ArchivedAt = &now
+	p.ArchivedBy = &userID
+}
+
+// Unarchive restores the project to active use
+func (p *Project) Unarchive() {
+	p.IsActive = true
+	p.ArchivedAt = nil
+	p.ArchivedBy = nil
+}
+
 // GetFileSizeFormatted returns the file size in a human-readable format
 func (ta *TaskAttachment) GetFileSizeFormatted() string {
 	const unit = 1024
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskRelationType represents the kind of link between two tasks
+type TaskRelationType string
+
+const (
+	TaskRelationRelatesTo    TaskRelationType = "relates_to"
+	TaskRelationDuplicates   TaskRelationType = "duplicates"
+	TaskRelationDuplicatedBy TaskRelationType = "duplicated_by"
+	TaskRelationCausedBy     TaskRelationType = "caused_by"
+	TaskRelationCauses       TaskRelationType = "causes"
+	TaskRelationClones       TaskRelationType = "clones"
+	TaskRelationClonedBy     TaskRelationType = "cloned_by"
+	TaskRelationBlocks       TaskRelationType = "blocks"
+	TaskRelationBlockedBy    TaskRelationType = "blocked_by"
+)
+
+// taskRelationInverses maps each relation type to the label seen from the other task
+var taskRelationInverses = map[TaskRelationType]TaskRelationType{
+	TaskRelationRelatesTo:    TaskRelationRelatesTo,
+	TaskRelationDuplicates:   TaskRelationDuplicatedBy,
+	TaskRelationDuplicatedBy: TaskRelationDuplicates,
+	TaskRelationCausedBy:     TaskRelationCauses,
+	TaskRelationCauses:       TaskRelationCausedBy,
+	TaskRelationClones:       TaskRelationClonedBy,
+	TaskRelationClonedBy:     TaskRelationClones,
+	TaskRelationBlocks:       TaskRelationBlockedBy,
+	TaskRelationBlockedBy:    TaskRelationBlocks,
+}
+
+// TaskRelation represents a typed, bidirectional link between two tasks.
+// Only one row is stored per link; the inverse label is derived when the
+// relation is viewed from the target task.
+type TaskRelation struct {
+	TenantModel
+	SourceTaskID uuid.UUID        `json:"source_task_id" gorm:"type:uuid;not null;index"`
+	TargetTaskID uuid.UUID        `json:"target_task_id" gorm:"type:uuid;not null;index"`
+	Type         TaskRelationType `json:"type" gorm:"not null;size:30"`
+	CreatedBy    uuid.UUID        `json:"created_by" gorm:"type:uuid;not null"`
+
+	// Relationships
+	SourceTask Task `json:"-" gorm:"foreignKey:SourceTaskID"`
+	TargetTask Task `json:"-" gorm:"foreignKey:TargetTaskID"`
+}
+
+// TaskRelationView is a relation as seen from one of its tasks
+type TaskRelationView struct {
+	ID         uuid.UUID        `json:"id"`
+	Type       TaskRelationType `json:"type"`
+	TaskID     uuid.UUID        `json:"task_id"`
+	TaskTitle  string           `json:"task_title"`
+	TaskStatus TaskStatus       `json:"task_status"`
+	IsOutgoing bool             `json:"is_outgoing"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// TaskWatcher represents a user following the changes of a task
+type TaskWatcher struct {
+	TaskID    uuid.UUID `json:"task_id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+
+	// Relationships
+	Task Task `json:"-" gorm:"foreignKey:TaskID"`
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for TaskRelation
+func (TaskRelation) TableName() string {
+	return "task_relations"
+}
+
+// TableName specifies the table name for TaskWatcher
+func (TaskWatcher) TableName() string {
+	return "task_watchers"
+}
+
+// IsValid checks if the relation type is known
+func (t TaskRelationType) IsValid() bool {
+	_, ok := taskRelationInverses[t]
+	return ok
+}
+
+// Inverse returns the label of the relation as seen from the other task
+func (t TaskRelationType) Inverse() TaskRelationType {
+	if inverse, ok := taskRelationInverses[t]; ok {
+		return inverse
+	}
+	return t
+}
+
+// IsDependency checks if the relation orders its tasks, i.e. the target cannot
+// start before the source is finished
+func (t TaskRelationType) IsDependency() bool {
+	return t == TaskRelationBlocks || t == TaskRelationBlockedBy
+}
+
+// Normalize returns the canonical direction of the relation so that each link
+// is always stored the same way, and reports whether the ends were swapped
+func (t TaskRelationType) Normalize() (TaskRelationType, bool) {
+	switch t {
+	case TaskRelationDuplicatedBy, TaskRelationCauses, TaskRelationClonedBy, TaskRelationBlockedBy:
+		return t.Inverse(), true
+	default:
+		return t, false
+	}
+}
+
+// ViewFrom returns the relation as seen from the given task
+func (tr *TaskRelation) ViewFrom(taskID uuid.UUID) TaskRelationView {
+	view := TaskRelationView{
+		ID:        tr.ID,
+		CreatedAt: tr.CreatedAt,
+	}
+
+	if tr.SourceTaskID == taskID {
+		view.Type = tr.Type
+		view.TaskID = tr.TargetTaskID
+		view.TaskTitle = tr.TargetTask.Title
+		view.TaskStatus = tr.TargetTask.Status
+		view.IsOutgoing = true
+	} else {
+		view.Type = tr.Type.Inverse()
+		view.TaskID = tr.SourceTaskID
+		view.TaskTitle = tr.SourceTask.Title
+		view.TaskStatus = tr.SourceTask.Status
+	}
+
+	return view
+}
@@ -42,6 +42,7 @@ type Tenant struct {
 	RequireEmailVerification bool `json:"require_email_verification"`
 	DefaultUserRole       string `json:"default_user_role" gorm:"size:20"`
 	TaskAutoAssignment    bool   `json:"task_auto_assignment"`
+	RequireArchiveBeforeDelete bool `json:"require_archive_before_delete"`
 	
 	// Notification settings (flattened)
 	EmailNotifications    bool `json:"email_notifications"`
@@ -67,6 +68,7 @@ type TenantSettings struct {
 	RequireEmailVerification bool `json:"require_email_verification"`
 	DefaultUserRole       string `json:"default_user_role"`
 	TaskAutoAssignment    bool   `json:"task_auto_assignment"`
+	RequireArchiveBeforeDelete bool `json:"require_archive_before_delete"`
 	NotificationSettings  NotificationSettings `json:"notification_settings" gorm:"embedded;embeddedPrefix:notif_"`
 	BrandingSettings      BrandingSettings     `json:"branding_settings" gorm:"embedded;embeddedPrefix:brand_"`
 }
@@ -0,0 +1,240 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoursPerWorkday converts estimated hours into timeline days
+const HoursPerWorkday = 8
+
+// TimelineTask is a task placed on a project timeline
+type TimelineTask struct {
+	TaskID       uuid.UUID    `json:"task_id"`
+	Title        string       `json:"title"`
+	Status       TaskStatus   `json:"status"`
+	Priority     TaskPriority `json:"priority"`
+	AssigneeID   *uuid.UUID   `json:"assignee_id,omitempty"`
+	ParentID     *uuid.UUID   `json:"parent_id,omitempty"`
+	IsMilestone  bool         `json:"is_milestone"`
+	PlannedStart time.Time    `json:"planned_start"`
+	PlannedEnd   time.Time    `json:"planned_end"`
+	Start        time.Time    `json:"start"`
+	End          time.Time    `json:"end"`
+	SlackDays    float64      `json:"slack_days"`
+	IsCritical   bool         `json:"is_critical"`
+	IsDelayed    bool         `json:"is_delayed"`
+	Progress     float64      `json:"progress"`
+}
+
+// TimelineDependency is a finish-to-start edge between two timeline tasks
+type TimelineDependency struct {
+	ID         uuid.UUID `json:"id"`
+	FromTaskID uuid.UUID `json:"from_task_id"`
+	ToTaskID   uuid.UUID `json:"to_task_id"`
+}
+
+// Timeline is the Gantt representation of a project
+type Timeline struct {
+	ProjectID    uuid.UUID            `json:"project_id"`
+	Start        *time.Time           `json:"start,omitempty"`
+	End          *time.Time           `json:"end,omitempty"`
+	Tasks        []TimelineTask       `json:"tasks"`
+	Dependencies []TimelineDependency `json:"dependencies"`
+	Milestones   []TimelineTask       `json:"milestones"`
+	Unscheduled  []uuid.UUID          `json:"unscheduled"`
+}
+
+// BuildTimeline schedules the tasks of a project. Tasks are placed from their
+// start and due dates, falling back to the estimate when only one is known,
+// then pushed back so they never start before their blockers finish. Slack is
+// the time a task can slip before it delays a dependent task or the project end.
+func BuildTimeline(project *Project, tasks []Task, relations []TaskRelation) *Timeline {
+	timeline := &Timeline{
+		ProjectID:    project.ID,
+		Tasks:        []TimelineTask{},
+		Dependencies: []TimelineDependency{},
+		Milestones:   []TimelineTask{},
+		Unscheduled:  []uuid.UUID{},
+	}
+
+	items := make(map[uuid.UUID]*TimelineTask, len(tasks))
+	var order []uuid.UUID
+	for i := range tasks {
+		item, ok := newTimelineTask(&tasks[i])
+		if !ok {
+			timeline.Unscheduled = append(timeline.Unscheduled, tasks[i].ID)
+			continue
+		}
+		items[item.TaskID] = item
+		order = append(order, item.TaskID)
+	}
+
+	successors := make(map[uuid.UUID][]uuid.UUID)
+	predecessors := make(map[uuid.UUID][]uuid.UUID)
+	for _, rel := range relations {
+		if rel.Type != TaskRelationBlocks {
+			continue
+		}
+		if items[rel.SourceTaskID] == nil || items[rel.TargetTaskID] == nil {
+			continue
+		}
+		timeline.Dependencies = append(timeline.Dependencies, TimelineDependency{
+			ID:         rel.ID,
+			FromTaskID: rel.SourceTaskID,
+			ToTaskID:   rel.TargetTaskID,
+		})
+		successors[rel.SourceTaskID] = append(successors[rel.SourceTaskID], rel.TargetTaskID)
+		predecessors[rel.TargetTaskID] = append(predecessors[rel.TargetTaskID], rel.SourceTaskID)
+	}
+
+	sorted := topologicalOrder(order, successors, predecessors)
+
+	// Forward pass: a task cannot start before all of its blockers have ended
+	for _, id := range sorted {
+		item := items[id]
+		for _, pred := range predecessors[id] {
+			if end := items[pred].End; end.After(item.Start) {
+				shift := end.Sub(item.Start)
+				item.Start = item.Start.Add(shift)
+				item.End = item.End.Add(shift)
+				item.IsDelayed = true
+			}
+		}
+	}
+
+	// The project ends at its end date, or when its last task ends
+	var finish time.Time
+	for _, id := range sorted {
+		item := items[id]
+		if timeline.Start == nil || item.Start.Before(*timeline.Start) {
+			start := item.Start
+			timeline.Start = &start
+		}
+		if item.End.After(finish) {
+			finish = item.End
+		}
+	}
+	if project.StartDate != nil {
+		timeline.Start = project.StartDate
+	}
+	if project.EndDate != nil {
+		finish = *project.EndDate
+	}
+	if !finish.IsZero() {
+		timeline.End = &finish
+	}
+
+	// Backward pass: the latest a task may end without delaying anything
+	latestEnd := make(map[uuid.UUID]time.Time, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		id := sorted[i]
+		item := items[id]
+		latest := finish
+		for _, succ := range successors[id] {
+			next := items[succ]
+			if lateStart := latestEnd[succ].Add(-next.End.Sub(next.Start)); lateStart.Before(latest) {
+				latest = lateStart
+			}
+		}
+		latestEnd[id] = latest
+
+		item.SlackDays = math.Round(latest.Sub(item.End).Hours()/24*10) / 10
+		item.IsCritical = item.SlackDays <= 0 && item.Status != TaskStatusCompleted && item.Status != TaskStatusCanceled
+	}
+
+	for _, id := range order {
+		item := *items[id]
+		timeline.Tasks = append(timeline.Tasks, item)
+		if item.IsMilestone {
+			timeline.Milestones = append(timeline.Milestones, item)
+		}
+	}
+
+	sort.SliceStable(timeline.Tasks, func(i, j int) bool {
+		return timeline.Tasks[i].Start.Before(timeline.Tasks[j].Start)
+	})
+	sort.SliceStable(timeline.Milestones, func(i, j int) bool {
+		return timeline.Milestones[i].End.Before(timeline.Milestones[j].End)
+	})
+
+	return timeline
+}
+
+// newTimelineTask places a task using its dates and estimate, reporting false
+// when there is not enough information to schedule it
+func newTimelineTask(task *Task) (*TimelineTask, bool) {
+	item := &TimelineTask{
+		TaskID:      task.ID,
+		Title:       task.Title,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		AssigneeID:  task.AssigneeID,
+		ParentID:    task.ParentID,
+		IsMilestone: task.IsMilestone,
+		Progress:    task.GetProgress(),
+	}
+
+	var duration time.Duration
+	if task.EstimatedHours != nil && *task.EstimatedHours > 0 {
+		days := math.Ceil(*task.EstimatedHours / HoursPerWorkday)
+		duration = time.Duration(days) * 24 * time.Hour
+	}
+
+	switch {
+	case task.IsMilestone && task.DueDate != nil:
+		item.Start, item.End = *task.DueDate, *task.DueDate
+	case task.StartDate != nil && task.DueDate != nil:
+		item.Start, item.End = *task.StartDate, *task.DueDate
+	case task.DueDate != nil:
+		item.Start, item.End = task.DueDate.Add(-duration), *task.DueDate
+	case task.StartDate != nil:
+		item.Start, item.End = *task.StartDate, task.StartDate.Add(duration)
+	default:
+		return nil, false
+	}
+
+	item.PlannedStart, item.PlannedEnd = item.Start, item.End
+	return item, true
+}
+
+// topologicalOrder sorts tasks so that blockers come before the tasks they
+// block. Tasks caught in a cycle are appended in their original order.
+func topologicalOrder(ids []uuid.UUID, successors, predecessors map[uuid.UUID][]uuid.UUID) []uuid.UUID {
+	indegree := make(map[uuid.UUID]int, len(ids))
+	for _, id := range ids {
+		indegree[id] = len(predecessors[id])
+	}
+
+	var queue, sorted []uuid.UUID
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		sorted = append(sorted, id)
+		seen[id] = true
+		for _, succ := range successors[id] {
+			indegree[succ]--
+			if indegree[succ] == 0 {
+				queue = append(queue, succ)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if !seen[id] {
+			sorted = append(sorted, id)
+		}
+	}
+
+	return sorted
+}
@@ -52,6 +52,17 @@ type User struct {
 	TaskViewMode            string `json:"task_view_mode" gorm:"size:10"` // list, board, calendar
 	ShowCompletedTasks      bool   `json:"show_completed_tasks"`
 	TasksPerPage            int    `json:"tasks_per_page"`
+	DefaultViewID           *uuid.UUID `json:"default_view_id,omitempty" gorm:"type:uuid"`
+
+	// Quiet hours hold email and push notifications. Start and end are
+	// local times (HH:MM) in Timezone; an end before the start runs past
+	// midnight.
+	QuietHoursEnabled bool       `json:"quiet_hours_enabled"`
+	QuietHoursStart   string     `json:"quiet_hours_start" gorm:"size:5"`
+	QuietHoursEnd     string     `json:"quiet_hours_end" gorm:"size:5"`
+	QuietWeekends     bool       `json:"quiet_weekends"`
+	QuietAllowUrgent  bool       `json:"quiet_allow_urgent"` // urgent task notifications break through
+	DoNotDisturbUntil *time.Time `json:"do_not_disturb_until,omitempty"`
 	
 	// Relationships
 	Tenant          Tenant            `json:"tenant" gorm:"foreignKey:TenantID"`
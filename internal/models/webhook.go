@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookAllEvents subscribes a webhook to every event type
+const WebhookAllEvents = "*"
+
+// Webhook is a tenant endpoint that receives domain events over HTTP
+type Webhook struct {
+	TenantModel
+	Name                string     `json:"name" gorm:"size:100;not null"`
+	URL                 string     `json:"url" gorm:"size:2000;not null"`
+	Secret              string     `json:"-" gorm:"size:100;not null"`
+	Events              []string   `json:"events" gorm:"type:jsonb;serializer:json"`
+	Active              bool       `json:"active" gorm:"not null;default:true"`
+	CreatedBy           uuid.UUID  `json:"created_by" gorm:"type:uuid;not null"`
+	ConsecutiveFailures int        `json:"consecutive_failures" gorm:"not null;default:0"`
+	LastDeliveryAt      *time.Time `json:"last_delivery_at,omitempty"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty" gorm:"size:255"`
+}
+
+// TableName specifies the table name for Webhook
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes reports whether the webhook wants events of the type
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == WebhookAllEvents || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus represents the outcome of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records an event sent to a webhook, with the request and the
+// response of its latest attempt
+type WebhookDelivery struct {
+	TenantModel
+	WebhookID      uuid.UUID             `json:"webhook_id" gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID             `json:"event_id" gorm:"type:uuid;not null;index"`
+	EventType      string                `json:"event_type" gorm:"size:50;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"size:20;not null;index"`
+	Attempts       int                   `json:"attempts" gorm:"not null;default:0"`
+	RequestHeaders map[string]string     `json:"request_headers,omitempty" gorm:"type:jsonb;serializer:json"`
+	RequestBody    string                `json:"request_body" gorm:"type:text"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	ResponseBody   string                `json:"response_body,omitempty" gorm:"type:text"`
+	Error          string                `json:"error,omitempty" gorm:"type:text"`
+	DurationMs     int64                 `json:"duration_ms"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	RedeliveryOf   *uuid.UUID            `json:"redelivery_of,omitempty" gorm:"type:uuid"`
+}
+
+// TableName specifies the table name for WebhookDelivery
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
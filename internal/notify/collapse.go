@@ -0,0 +1,117 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/templates"
+)
+
+// collapse groups the notification into the user's unread notification with
+// the same collapse key, if that was updated within the window. The grouped
+// notification is rendered again with the actors of all its events, e.g.
+// "Alice and 3 others updated ...", and replaces notification. It reports
+// false when there is nothing to group into.
+func (d *Dispatcher) collapse(ctx context.Context, tx *gorm.DB, n *Notification, user *models.User,
+	notification *models.Notification, data templates.Data) (bool, error) {
+	if n.CollapseKey == "" || d.window <= 0 {
+		return false, nil
+	}
+
+	var existing models.Notification
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND collapse_key = ? AND hidden = ? AND status = ? AND read_at IS NULL AND updated_at > ?",
+			user.ID, n.CollapseKey, notification.Hidden, models.NotificationStatusUnread, time.Now().Add(-d.window)).
+		Order("updated_at DESC").
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load notification to group into: %w", err)
+	}
+
+	event := newEvent(n, notification)
+	event.NotificationID = existing.ID
+	if err := tx.Create(event).Error; err != nil {
+		return false, fmt.Errorf("failed to record notification event: %w", err)
+	}
+
+	var names []string
+	if err := tx.Model(&models.NotificationEvent{}).Where("notification_id = ?", existing.ID).
+		Order("created_at DESC").Pluck("actor_name", &names).Error; err != nil {
+		return false, fmt.Errorf("failed to load notification events: %w", err)
+	}
+	grouped := templates.Data{}
+	for name, value := range data {
+		grouped[name] = value
+	}
+	actorName := actorList(names)
+	if actorName != "" {
+		grouped["actor_name"] = actorName
+	}
+	content, err := d.templates.Render(ctx, n.TenantID, n.Type, models.NotificationChannelInApp, user.Language, grouped)
+	if err != nil {
+		return false, fmt.Errorf("failed to render grouped notification: %w", err)
+	}
+
+	existing.Title = content.Subject
+	existing.Message = content.Body
+	existing.ActionURL = notification.ActionURL
+	existing.Data = notification.Data
+	if actorName != "" {
+		existing.Data.ActorName = actorName
+	}
+	existing.CommentID = notification.CommentID
+	existing.EventCount++
+	if err := tx.Save(&existing).Error; err != nil {
+		return false, fmt.Errorf("failed to update grouped notification: %w", err)
+	}
+
+	*notification = existing
+	return true, nil
+}
+
+// newEvent records what a notification says, so a grouped notification can
+// be expanded into its events
+func newEvent(n *Notification, notification *models.Notification) *models.NotificationEvent {
+	return &models.NotificationEvent{
+		TenantID:       n.TenantID,
+		NotificationID: notification.ID,
+		ActorID:        n.ActorID,
+		ActorName:      notification.Data.ActorName,
+		Title:          notification.Title,
+		Message:        notification.Message,
+		Data:           notification.Data,
+	}
+}
+
+// actorList names the distinct actors, most recent first: "Alice",
+// "Alice and Bob" or "Alice and 3 others"
+func actorList(names []string) string {
+	seen := map[string]bool{}
+	var distinct []string
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			distinct = append(distinct, name)
+		}
+	}
+
+	switch len(distinct) {
+	case 0:
+		return ""
+	case 1:
+		return distinct[0]
+	case 2:
+		return distinct[0] + " and " + distinct[1]
+	default:
+		return fmt.Sprintf("%s and %d others", distinct[0], len(distinct)-1)
+	}
+}
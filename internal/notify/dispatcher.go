@@ -0,0 +1,434 @@
+// Package notify delivers notifications. For each recipient the dispatcher
+// works out the channels a notification goes out on, from the tenant's
+// switches, the user's settings and the user's preference for the type, and
+// delivers it once on each. Email and push wait for the end of the user's
+// quiet hours or do-not-disturb.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/drazan344/taskflow-go/internal/digest"
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/mailer"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/internal/webpush"
+	"github.com/drazan344/taskflow-go/internal/websocket"
+)
+
+// MaxAttempts is how many times delivery on a channel is attempted before
+// it is given up
+const MaxAttempts = 5
+
+// Broadcaster sends messages to a user's connected WebSocket clients
+type Broadcaster interface {
+	BroadcastToUser(tenantID, userID uuid.UUID, messageType websocket.MessageType, data interface{})
+}
+
+// Pusher sends push notifications to a user's devices
+type Pusher interface {
+	Push(ctx context.Context, user *models.User, notification *models.Notification, content *templates.Rendered) error
+}
+
+// Notification is a notification to deliver to a set of users
+type Notification struct {
+	TenantID   uuid.UUID
+	Type       models.NotificationType
+	Recipients []uuid.UUID
+
+	// Key identifies what caused the notification, such as an event or a
+	// job. Dispatching the same key again only retries the channels that
+	// failed. An empty key is never deduplicated.
+	Key string
+
+	ActorID   *uuid.UUID
+	ActionURL string
+	TaskID    *uuid.UUID
+	ProjectID *uuid.UUID
+	CommentID *uuid.UUID
+
+	// Data holds the template variables. recipient_name is set to each
+	// recipient's full name unless Data has one.
+	Data templates.Data
+	// Meta is stored with the in-app notification
+	Meta models.NotificationData
+
+	// Urgent notifications reach users in quiet hours who allow it
+	Urgent bool
+
+	// CollapseKey groups notifications about the same entity and type into
+	// one unread in-app notification, such as "task:<id>:task_updated".
+	// An empty key is never grouped.
+	CollapseKey string
+}
+
+// Dispatcher delivers notifications on every channel
+type Dispatcher struct {
+	db          *gorm.DB
+	templates   *templates.Engine
+	mailer      mailer.Mailer
+	broadcaster Broadcaster
+	pusher      Pusher
+	publisher   events.Publisher
+	schedule    digest.Schedule
+	window      time.Duration
+	logger      *logrus.Logger
+}
+
+// New creates a dispatcher. Email of users on daily or weekly frequency is
+// queued for their digest on the schedule. Notifications with a collapse key
+// are grouped into an unread one updated within the collapse window; a zero
+// window turns grouping off. A nil pusher turns push off.
+func New(db *gorm.DB, engine *templates.Engine, mail mailer.Mailer, broadcaster Broadcaster, pusher Pusher,
+	publisher events.Publisher, schedule digest.Schedule, collapseWindow time.Duration, logger *logrus.Logger) *Dispatcher {
+	return &Dispatcher{
+		db:          db,
+		templates:   engine,
+		mailer:      mail,
+		broadcaster: broadcaster,
+		pusher:      pusher,
+		publisher:   publisher,
+		schedule:    schedule,
+		window:      collapseWindow,
+		logger:      logger,
+	}
+}
+
+// Dispatch delivers a notification to each active recipient on the channels
+// they receive it on. Failed channels are recorded and returned as an error
+// so the caller can retry; channels that were delivered are not repeated.
+func (d *Dispatcher) Dispatch(ctx context.Context, n *Notification) error {
+	if len(n.Recipients) == 0 {
+		return nil
+	}
+	if n.Key == "" {
+		n.Key = uuid.NewString()
+	}
+
+	var tenant models.Tenant
+	if err := d.db.WithContext(ctx).Where("id = ?", n.TenantID).First(&tenant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to load tenant: %w", err)
+	}
+	if !enabled(&tenant, n.Type) {
+		return nil
+	}
+
+	var users []models.User
+	if err := d.db.WithContext(ctx).Where("id IN ? AND tenant_id = ?", n.Recipients, n.TenantID).Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to load recipients: %w", err)
+	}
+	preferences, err := d.preferences(ctx, n, users)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range users {
+		user := &users[i]
+		preference := preferences[user.ID]
+		if !user.IsActive() || preference.Frequency == models.NotificationFrequencyNever {
+			continue
+		}
+		if err := d.deliver(ctx, n, &tenant, user, preference); err != nil {
+			d.logger.WithError(err).WithFields(logrus.Fields{
+				"tenant_id": n.TenantID,
+				"user_id":   user.ID,
+				"type":      n.Type,
+				"key":       n.Key,
+			}).Warn("Failed to deliver notification")
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// preferences returns the preference of each user for the type. Users
+// without one receive the type on every channel as it happens.
+func (d *Dispatcher) preferences(ctx context.Context, n *Notification, users []models.User) (map[uuid.UUID]*models.NotificationPreference, error) {
+	ids := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+
+	var stored []models.NotificationPreference
+	if len(ids) > 0 {
+		if err := d.db.WithContext(ctx).Where("tenant_id = ? AND type = ? AND user_id IN ?", n.TenantID, n.Type, ids).
+			Find(&stored).Error; err != nil {
+			return nil, fmt.Errorf("failed to load notification preferences: %w", err)
+		}
+	}
+
+	preferences := make(map[uuid.UUID]*models.NotificationPreference, len(ids))
+	for _, id := range ids {
+		preferences[id] = &models.NotificationPreference{
+			UserID:    id,
+			Type:      n.Type,
+			InApp:     true,
+			Email:     true,
+			Push:      true,
+			WebSocket: true,
+			Frequency: models.NotificationFrequencyImmediate,
+		}
+	}
+	for i := range stored {
+		preferences[stored[i].UserID] = &stored[i]
+	}
+	return preferences, nil
+}
+
+// deliver sends the notification to one user. The notification is stored
+// first so the other channels can refer to it, hidden from the in-app list
+// when the user turned in-app notifications off.
+func (d *Dispatcher) deliver(ctx context.Context, n *Notification, tenant *models.Tenant, user *models.User, preference *models.NotificationPreference) error {
+	data := templates.Data{}
+	for name, value := range n.Data {
+		data[name] = value
+	}
+	if _, ok := data["recipient_name"]; !ok {
+		data["recipient_name"] = user.GetFullName()
+	}
+
+	content, err := d.templates.Render(ctx, n.TenantID, n.Type, models.NotificationChannelInApp, user.Language, data)
+	if err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+	notification := &models.Notification{
+		TenantModel: models.TenantModel{TenantID: n.TenantID},
+		UserID:      user.ID,
+		Type:        n.Type,
+		Status:      models.NotificationStatusUnread,
+		Title:       content.Subject,
+		Message:     content.Body,
+		ActionURL:   n.ActionURL,
+		Data:        n.Meta,
+		TaskID:      n.TaskID,
+		ProjectID:   n.ProjectID,
+		CommentID:   n.CommentID,
+		CollapseKey: n.CollapseKey,
+		EventCount:  1,
+		Hidden:      !preference.ShouldSend(models.NotificationChannelInApp),
+	}
+
+	action := "created"
+	grouped, err := d.inApp(ctx, n, user, notification, data)
+	if err != nil {
+		return err
+	}
+	if grouped {
+		action = "updated"
+		// Email and push name the actors of the group too
+		if notification.Data.ActorName != "" {
+			data["actor_name"] = notification.Data.ActorName
+		}
+	}
+
+	// In-app and WebSocket notifications are silent, so only email and push
+	// are held while the user is quiet
+	var holdUntil *time.Time
+	if until, quiet := user.QuietUntil(time.Now()); quiet && !user.AllowsThroughQuiet(n.Urgent) {
+		holdUntil = &until
+	}
+
+	var errs []error
+	if d.broadcaster != nil && preference.ShouldSend(models.NotificationChannelWebSocket) {
+		errs = append(errs, d.attempt(ctx, n, user.ID, models.NotificationChannelWebSocket, notification,
+			func() (models.NotificationDeliveryStatus, error) {
+				d.broadcaster.BroadcastToUser(n.TenantID, user.ID, websocket.MessageTypeNotification,
+					map[string]interface{}{"notification": notification, "action": action})
+				return models.NotificationDeliverySent, nil
+			}))
+	}
+	if tenant.EmailNotifications && user.EnableEmailNotifications && preference.ShouldSend(models.NotificationChannelEmail) {
+		errs = append(errs, d.attempt(ctx, n, user.ID, models.NotificationChannelEmail, notification,
+			func() (models.NotificationDeliveryStatus, error) {
+				return d.email(ctx, n, user, preference, notification, data, holdUntil)
+			}))
+	}
+	if d.pusher != nil && user.EnablePushNotifications && preference.ShouldSend(models.NotificationChannelPush) {
+		errs = append(errs, d.attempt(ctx, n, user.ID, models.NotificationChannelPush, notification,
+			func() (models.NotificationDeliveryStatus, error) {
+				content, err := d.templates.Render(ctx, n.TenantID, n.Type, models.NotificationChannelPush, user.Language, data)
+				if err != nil {
+					return "", err
+				}
+				if holdUntil != nil {
+					return d.hold(ctx, user, notification, models.NotificationChannelPush, content, *holdUntil)
+				}
+				return models.NotificationDeliverySent, d.pusher.Push(ctx, user, notification, content)
+			}))
+	}
+	return errors.Join(errs...)
+}
+
+// inApp stores the in-app notification, or groups it into an unread one
+// with the same collapse key, and reports whether it was grouped. When it was
+// stored by an earlier dispatch, notification is replaced by the stored one.
+// Hidden notifications are stored the same way, but their in-app delivery is
+// recorded as skipped and nothing is published.
+func (d *Dispatcher) inApp(ctx context.Context, n *Notification, user *models.User, notification *models.Notification,
+	data templates.Data) (bool, error) {
+	delivery, err := d.claim(ctx, n, notification.UserID, models.NotificationChannelInApp)
+	if err != nil {
+		return false, err
+	}
+	if delivery.IsDone() {
+		if delivery.NotificationID != nil {
+			var stored models.Notification
+			if err := d.db.WithContext(ctx).Where("id = ?", *delivery.NotificationID).First(&stored).Error; err == nil {
+				*notification = stored
+			}
+		}
+		return notification.IsGrouped(), nil
+	}
+
+	grouped := false
+	err = d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if grouped, err = d.collapse(ctx, tx, n, user, notification, data); err != nil {
+			return err
+		}
+		if !grouped {
+			if err := tx.Create(notification).Error; err != nil {
+				return fmt.Errorf("failed to create notification: %w", err)
+			}
+			if notification.CollapseKey != "" {
+				if err := tx.Create(newEvent(n, notification)).Error; err != nil {
+					return fmt.Errorf("failed to record notification event: %w", err)
+				}
+			}
+		}
+		delivery.NotificationID = &notification.ID
+		if notification.Hidden {
+			delivery.MarkAsDelivered(models.NotificationDeliverySkipped)
+		} else {
+			delivery.MarkAsDelivered(models.NotificationDeliverySent)
+		}
+		if err := tx.Save(delivery).Error; err != nil {
+			return fmt.Errorf("failed to record notification delivery: %w", err)
+		}
+		return nil
+	})
+	if err != nil || grouped || notification.Hidden {
+		return grouped, err
+	}
+
+	events.Publish(ctx, d.publisher, d.logger, n.TenantID, n.ActorID, events.NotificationCreated{
+		NotificationID: notification.ID,
+		UserID:         notification.UserID,
+		Type:           notification.Type,
+	})
+	return false, nil
+}
+
+// email sends the notification by email, queues it for the user's digest or
+// holds it until holdUntil when that is set
+func (d *Dispatcher) email(ctx context.Context, n *Notification, user *models.User, preference *models.NotificationPreference,
+	notification *models.Notification, data templates.Data, holdUntil *time.Time) (models.NotificationDeliveryStatus, error) {
+	if preference.Frequency == models.NotificationFrequencyDaily || preference.Frequency == models.NotificationFrequencyWeekly {
+		projectName, _ := data["project_name"].(string)
+		scheduledAt := d.schedule.Next(time.Now(), user.Timezone, preference.Frequency)
+		if err := digest.Queue(d.db.WithContext(ctx), notification, preference.Frequency, scheduledAt, projectName); err != nil {
+			return "", err
+		}
+		return models.NotificationDeliveryQueued, nil
+	}
+
+	content, err := d.templates.Render(ctx, n.TenantID, n.Type, models.NotificationChannelEmail, user.Language, data)
+	if err != nil {
+		return "", err
+	}
+	if holdUntil != nil {
+		return d.hold(ctx, user, notification, models.NotificationChannelEmail, content, *holdUntil)
+	}
+	return models.NotificationDeliverySent, d.mailer.Send(ctx, &mailer.Message{
+		To:      []mail.Address{{Name: user.GetFullName(), Address: user.Email}},
+		Subject: content.Subject,
+		Text:    content.Body,
+		HTML:    content.HTML,
+	})
+}
+
+// attempt delivers on a channel unless an earlier dispatch did, and records
+// the outcome. Failures that retrying cannot fix are recorded but not
+// returned.
+func (d *Dispatcher) attempt(ctx context.Context, n *Notification, userID uuid.UUID, channel models.NotificationChannel,
+	notification *models.Notification, send func() (models.NotificationDeliveryStatus, error)) error {
+	delivery, err := d.claim(ctx, n, userID, channel)
+	if err != nil || delivery.IsDone() || delivery.Attempts >= MaxAttempts {
+		return err
+	}
+
+	delivery.NotificationID = &notification.ID
+	status, sendErr := send()
+	if sendErr != nil {
+		delivery.MarkAsFailed(sendErr)
+		if errors.Is(sendErr, mailer.ErrPermanent) || errors.Is(sendErr, webpush.ErrPermanent) {
+			delivery.Attempts = MaxAttempts
+		}
+	} else {
+		delivery.MarkAsDelivered(status)
+	}
+	if err := d.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to record notification delivery: %w", err)
+	}
+
+	if sendErr != nil && delivery.Attempts < MaxAttempts {
+		return fmt.Errorf("%s delivery failed: %w", channel, sendErr)
+	}
+	return nil
+}
+
+// claim returns the delivery of the notification to a user on a channel,
+// recording it on the first dispatch
+func (d *Dispatcher) claim(ctx context.Context, n *Notification, userID uuid.UUID, channel models.NotificationChannel) (*models.NotificationDelivery, error) {
+	delivery := &models.NotificationDelivery{
+		TenantModel: models.TenantModel{TenantID: n.TenantID},
+		Key:         n.Key,
+		UserID:      userID,
+		Channel:     channel,
+		Type:        n.Type,
+		Status:      models.NotificationDeliveryPending,
+	}
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to record notification delivery: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return delivery, nil
+	}
+
+	existing := &models.NotificationDelivery{}
+	if err := d.db.WithContext(ctx).Where("key = ? AND user_id = ? AND channel = ?", n.Key, userID, channel).
+		First(existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to load notification delivery: %w", err)
+	}
+	return existing, nil
+}
+
+// enabled reports whether the tenant sends notifications of the type
+func enabled(tenant *models.Tenant, notificationType models.NotificationType) bool {
+	switch notificationType {
+	case models.NotificationTypeTaskAssigned:
+		return tenant.TaskAssignments
+	case models.NotificationTypeTaskDue, models.NotificationTypeTaskOverdue:
+		return tenant.TaskDueDates
+	case models.NotificationTypeTaskCompleted:
+		return tenant.TaskCompletions
+	case models.NotificationTypeWeeklyDigest:
+		return tenant.WeeklyDigest
+	}
+	return true
+}
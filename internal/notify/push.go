@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/internal/webpush"
+)
+
+// maxPushBody is the number of bytes of notification text sent in a push
+// message. Messages are shortened further when they would not fit in
+// webpush.MaxPayload.
+const maxPushBody = 1000
+
+// WebPush delivers push notifications to the browsers a user subscribed.
+// Subscriptions the push service no longer knows are deleted.
+type WebPush struct {
+	db     *gorm.DB
+	client *webpush.Client
+	logger *logrus.Logger
+}
+
+// NewWebPush creates a pusher sending through the Web Push client
+func NewWebPush(db *gorm.DB, client *webpush.Client, logger *logrus.Logger) *WebPush {
+	return &WebPush{db: db, client: client, logger: logger}
+}
+
+// pushPayload is the message the service worker receives
+type pushPayload struct {
+	ID        *uuid.UUID              `json:"id,omitempty"`
+	Type      models.NotificationType `json:"type"`
+	Title     string                  `json:"title"`
+	Body      string                  `json:"body"`
+	URL       string                  `json:"url,omitempty"`
+	Timestamp int64                   `json:"timestamp"`
+}
+
+// Push sends a notification to each of the user's subscriptions. It only
+// fails when no subscription received it, preferring an error worth
+// retrying.
+func (w *WebPush) Push(ctx context.Context, user *models.User, notification *models.Notification, content *templates.Rendered) error {
+	var subscriptions []models.PushSubscription
+	if err := w.db.WithContext(ctx).Where("user_id = ? AND tenant_id = ?", user.ID, user.TenantID).
+		Find(&subscriptions).Error; err != nil {
+		return fmt.Errorf("failed to load push subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload := pushPayload{
+		Type:      notification.Type,
+		Title:     content.Subject,
+		URL:       notification.ActionURL,
+		Timestamp: time.Now().UnixMilli(),
+	}
+	if notification.ID != uuid.Nil {
+		payload.ID = &notification.ID
+	}
+	body, err := encodePayload(payload, content.Body)
+	if err != nil {
+		return err
+	}
+	message := &webpush.Message{Payload: body, Topic: topic(notification.CollapseKey), Urgency: webpush.UrgencyNormal}
+
+	delivered := 0
+	var failure error
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if subscription.IsExpired() {
+			w.remove(ctx, subscription)
+			continue
+		}
+
+		err := w.client.Send(ctx, &webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, message)
+		switch {
+		case err == nil:
+			delivered++
+			now := time.Now()
+			w.db.WithContext(ctx).Model(subscription).UpdateColumn("last_used_at", &now)
+		case errors.Is(err, webpush.ErrGone):
+			w.remove(ctx, subscription)
+		default:
+			w.logger.WithError(err).WithFields(logrus.Fields{
+				"user_id":         user.ID,
+				"subscription_id": subscription.ID,
+			}).Warn("Failed to send push notification")
+			if failure == nil || errors.Is(failure, webpush.ErrPermanent) {
+				failure = err
+			}
+		}
+	}
+
+	if delivered > 0 {
+		return nil
+	}
+	return failure
+}
+
+// remove deletes a subscription that can no longer receive messages
+func (w *WebPush) remove(ctx context.Context, subscription *models.PushSubscription) {
+	if err := w.db.WithContext(ctx).Unscoped().Delete(subscription).Error; err != nil {
+		w.logger.WithError(err).WithField("subscription_id", subscription.ID).Warn("Failed to delete expired push subscription")
+		return
+	}
+	w.logger.WithFields(logrus.Fields{
+		"user_id":         subscription.UserID,
+		"subscription_id": subscription.ID,
+	}).Info("Deleted expired push subscription")
+}
+
+// topic turns a collapse key into a push topic, so a grouped notification
+// replaces its earlier message on devices that have not received it yet.
+// Topics are at most 32 base64url characters.
+func topic(collapseKey string) string {
+	if collapseKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(collapseKey))
+	return base64.RawURLEncoding.EncodeToString(sum[:])[:32]
+}
+
+// encodePayload marshals the payload with as much of text as its body as
+// fits in webpush.MaxPayload. JSON escaping can make text up to six times
+// longer, so the body is shortened in proportion to how much it grew.
+func encodePayload(payload pushPayload, text string) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	budget := webpush.MaxPayload - len(data)
+	if budget < 0 {
+		return nil, fmt.Errorf("%w: push message of %d bytes exceeds %d", webpush.ErrPermanent, len(data), webpush.MaxPayload)
+	}
+
+	limit := maxPushBody
+	for {
+		payload.Body = truncate(text, limit)
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		encoded := len(body) - len(data)
+		if encoded <= budget {
+			return body, nil
+		}
+		limit = min(len(payload.Body)*budget/encoded, len(payload.Body)-1)
+	}
+}
+
+// truncate shortens text to at most max bytes, cutting on a rune boundary
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	const ellipsis = "…"
+	if max < len(ellipsis) {
+		return ""
+	}
+	cut := max - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + ellipsis
+}
@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/drazan344/taskflow-go/internal/webpush"
+)
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", truncate("short", 10))
+	assert.Equal(t, "abcdefg…", truncate("abcdefghijkl", 10))
+	// "é" is two bytes and is not cut in half
+	assert.Equal(t, "ééé…", truncate("éééééé", 10))
+	assert.Equal(t, "", truncate("abcdef", 2))
+
+	for max := 0; max < 20; max++ {
+		got := truncate(strings.Repeat("日本", 10), max)
+		assert.LessOrEqual(t, len(got), max)
+		assert.True(t, utf8.ValidString(got))
+	}
+}
+
+func TestEncodePayloadFitsMaxPayload(t *testing.T) {
+	payload := pushPayload{Type: "task_assigned", Title: "Task assigned", URL: "https://app.example.com/tasks/1"}
+
+	tests := map[string]string{
+		"ascii":      strings.Repeat("a", 10000),
+		"multi-byte": strings.Repeat("日本語", 2000),
+		// Escaped characters take six bytes in JSON
+		"escaped": strings.Repeat("<>&\x01", 2000),
+	}
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := encodePayload(payload, text)
+			require.NoError(t, err)
+			assert.LessOrEqual(t, len(data), webpush.MaxPayload)
+
+			var decoded pushPayload
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.NotEmpty(t, decoded.Body)
+			assert.LessOrEqual(t, len(decoded.Body), maxPushBody)
+			assert.True(t, utf8.ValidString(decoded.Body))
+		})
+	}
+}
+
+func TestEncodePayloadRejectsOversizedTitle(t *testing.T) {
+	payload := pushPayload{Type: "task_assigned", Title: strings.Repeat("a", webpush.MaxPayload)}
+
+	_, err := encodePayload(payload, "body")
+	assert.ErrorIs(t, err, webpush.ErrPermanent)
+}
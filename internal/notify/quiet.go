@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/drazan344/taskflow-go/internal/mailer"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/templates"
+	"github.com/drazan344/taskflow-go/internal/webpush"
+)
+
+// releaseBatchSize limits the held notifications loaded by one release
+const releaseBatchSize = 1000
+
+// hold keeps rendered email or push content in the notification queue until
+// the user's quiet time ends, replacing content already held for the
+// notification
+func (d *Dispatcher) hold(ctx context.Context, user *models.User, notification *models.Notification,
+	channel models.NotificationChannel, content *templates.Rendered, until time.Time) (models.NotificationDeliveryStatus, error) {
+	item := &models.NotificationQueue{
+		TenantID:    notification.TenantID,
+		UserID:      user.ID,
+		Type:        notification.Type,
+		Channel:     channel,
+		Frequency:   models.NotificationFrequencyImmediate,
+		Priority:    1,
+		Status:      "pending",
+		ScheduledAt: until,
+		Payload: models.NotificationPayload{
+			To:        user.Email,
+			Subject:   content.Subject,
+			Body:      content.Body,
+			HTMLBody:  content.HTML,
+			ActionURL: notification.ActionURL,
+		},
+	}
+	if notification.ID != uuid.Nil {
+		item.NotificationID = &notification.ID
+
+		// A grouped notification replaces the content its first event held,
+		// so the group is sent once when quiet time ends
+		result := d.db.WithContext(ctx).Model(&models.NotificationQueue{}).Select("payload").
+			Where("notification_id = ? AND channel = ? AND status = ?", notification.ID, channel, "pending").
+			Updates(&models.NotificationQueue{Payload: item.Payload})
+		if result.Error != nil {
+			return "", fmt.Errorf("failed to update held notification: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return models.NotificationDeliveryQueued, nil
+		}
+	}
+	if err := d.db.WithContext(ctx).Create(item).Error; err != nil {
+		return "", fmt.Errorf("failed to hold notification: %w", err)
+	}
+	return models.NotificationDeliveryQueued, nil
+}
+
+// Release delivers the email and push notifications held for quiet time that
+// has ended and returns how many were delivered. Notifications read in-app
+// in the meantime are skipped, and users who are quiet again keep theirs
+// until the new end. Failed deliveries are retried by later runs.
+func (d *Dispatcher) Release(ctx context.Context) (int, error) {
+	now := time.Now()
+	var items []*models.NotificationQueue
+	if err := d.db.WithContext(ctx).
+		Where("channel IN ? AND frequency = ? AND scheduled_at <= ?",
+			[]models.NotificationChannel{models.NotificationChannelEmail, models.NotificationChannelPush},
+			models.NotificationFrequencyImmediate, now).
+		Where("status = ? OR (status = ? AND retry_count < ?)", "pending", "failed", MaxAttempts).
+		Order("scheduled_at, created_at").
+		Limit(releaseBatchSize).
+		Find(&items).Error; err != nil {
+		return 0, fmt.Errorf("failed to load held notifications: %w", err)
+	}
+	if len(items) == 0 {
+		return 0, nil
+	}
+
+	var userIDs, notificationIDs []uuid.UUID
+	for _, item := range items {
+		userIDs = append(userIDs, item.UserID)
+		if item.NotificationID != nil {
+			notificationIDs = append(notificationIDs, *item.NotificationID)
+		}
+	}
+	var users []models.User
+	if err := d.db.WithContext(ctx).Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return 0, fmt.Errorf("failed to load users: %w", err)
+	}
+	usersByID := make(map[uuid.UUID]*models.User, len(users))
+	for i := range users {
+		usersByID[users[i].ID] = &users[i]
+	}
+	var notifications []models.Notification
+	if len(notificationIDs) > 0 {
+		if err := d.db.WithContext(ctx).Where("id IN ?", notificationIDs).Find(&notifications).Error; err != nil {
+			return 0, fmt.Errorf("failed to load notifications: %w", err)
+		}
+	}
+	notificationsByID := make(map[uuid.UUID]*models.Notification, len(notifications))
+	for i := range notifications {
+		notificationsByID[notifications[i].ID] = &notifications[i]
+	}
+
+	released := 0
+	for _, item := range items {
+		user := usersByID[item.UserID]
+		var notification *models.Notification
+		if item.NotificationID != nil {
+			notification = notificationsByID[*item.NotificationID]
+		}
+
+		switch {
+		case user == nil || !user.IsActive(),
+			item.NotificationID != nil && (notification == nil || notification.ReadAt != nil ||
+				notification.Status != models.NotificationStatusUnread):
+			item.MarkAsSkipped()
+		default:
+			if until, quiet := user.QuietUntil(now); quiet {
+				item.ScheduledAt = until // quiet again, so wait for the new end
+				break
+			}
+			sent, err := d.release(ctx, user, item, notification)
+			switch {
+			case err != nil:
+				item.MarkAsFailed(err)
+				if errors.Is(err, mailer.ErrPermanent) || errors.Is(err, webpush.ErrPermanent) {
+					item.RetryCount = MaxAttempts
+				}
+				d.logger.WithError(err).WithFields(logrus.Fields{
+					"user_id": item.UserID,
+					"channel": item.Channel,
+					"item_id": item.ID,
+				}).Warn("Failed to deliver held notification")
+			case sent:
+				item.MarkAsSent()
+				released++
+			default:
+				item.MarkAsSkipped()
+			}
+		}
+
+		if err := d.db.WithContext(ctx).Save(item).Error; err != nil {
+			return released, fmt.Errorf("failed to update held notification: %w", err)
+		}
+	}
+	return released, nil
+}
+
+// release delivers one held notification. It reports false when the user
+// turned the channel off in the meantime.
+func (d *Dispatcher) release(ctx context.Context, user *models.User, item *models.NotificationQueue,
+	notification *models.Notification) (bool, error) {
+	switch item.Channel {
+	case models.NotificationChannelEmail:
+		if !user.EnableEmailNotifications {
+			return false, nil
+		}
+		return true, d.mailer.Send(ctx, &mailer.Message{
+			To:      []mail.Address{{Name: user.GetFullName(), Address: user.Email}},
+			Subject: item.Payload.Subject,
+			Text:    item.Payload.Body,
+			HTML:    item.Payload.HTMLBody,
+		})
+	case models.NotificationChannelPush:
+		if d.pusher == nil || !user.EnablePushNotifications {
+			return false, nil
+		}
+		if notification == nil {
+			notification = &models.Notification{
+				TenantModel: models.TenantModel{TenantID: item.TenantID},
+				UserID:      item.UserID,
+				Type:        item.Type,
+				ActionURL:   item.Payload.ActionURL,
+			}
+		}
+		return true, d.pusher.Push(ctx, user, notification, &templates.Rendered{
+			Subject: item.Payload.Subject,
+			Body:    item.Payload.Body,
+		})
+	}
+	return false, nil
+}
@@ -12,11 +12,14 @@ type CreateTaskRequest struct {
 	Title          string               `json:"title" validate:"required,min=1,max=200"`
 	Description    string               `json:"description" validate:"max=2000"`
 	Priority       models.TaskPriority  `json:"priority" validate:"required,priority"`
+	StartDate      *time.Time           `json:"start_date,omitempty"`
 	DueDate        *time.Time           `json:"due_date,omitempty"`
 	AssigneeID     *uuid.UUID           `json:"assignee_id,omitempty" validate:"omitempty,uuid"`
 	ProjectID      *uuid.UUID           `json:"project_id,omitempty" validate:"omitempty,uuid"`
 	ParentID       *uuid.UUID           `json:"parent_id,omitempty" validate:"omitempty,uuid"`
 	EstimatedHours *float64             `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=9999"`
+	StoryPoints    *float64             `json:"story_points,omitempty" validate:"omitempty,min=0,max=999"`
+	IsMilestone    bool                 `json:"is_milestone,omitempty"`
 	Tags           []uuid.UUID          `json:"tags,omitempty"`
 }
 
@@ -26,11 +29,14 @@ type UpdateTaskRequest struct {
 	Description    *string              `json:"description,omitempty" validate:"omitempty,max=2000"`
 	Priority       *models.TaskPriority `json:"priority,omitempty" validate:"omitempty,priority"`
 	Status         *models.TaskStatus   `json:"status,omitempty" validate:"omitempty,task_status"`
+	StartDate      *time.Time           `json:"start_date,omitempty"`
 	DueDate        *time.Time           `json:"due_date,omitempty"`
 	AssigneeID     *uuid.UUID           `json:"assignee_id,omitempty" validate:"omitempty,uuid"`
 	ProjectID      *uuid.UUID           `json:"project_id,omitempty" validate:"omitempty,uuid"`
 	ParentID       *uuid.UUID           `json:"parent_id,omitempty" validate:"omitempty,uuid"`
 	EstimatedHours *float64             `json:"estimated_hours,omitempty" validate:"omitempty,min=0,max=9999"`
+	StoryPoints    *float64             `json:"story_points,omitempty" validate:"omitempty,min=0,max=999"`
+	IsMilestone    *bool                `json:"is_milestone,omitempty"`
 	ActualHours    *float64             `json:"actual_hours,omitempty" validate:"omitempty,min=0,max=9999"`
 	Tags           []uuid.UUID          `json:"tags,omitempty"`
 }
@@ -43,6 +49,8 @@ type CreateProjectRequest struct {
 	IsActive    *bool      `json:"is_active,omitempty"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
 	EndDate     *time.Time `json:"end_date,omitempty"`
+	IsPrivate   bool       `json:"is_private,omitempty"`
+	DefaultRole string     `json:"default_role,omitempty" validate:"omitempty,oneof=viewer commenter editor"`
 }
 
 // UpdateProjectRequest represents a project update request with validation
@@ -53,25 +61,158 @@ type UpdateProjectRequest struct {
 	IsActive    *bool      `json:"is_active,omitempty"`
 	StartDate   *time.Time `json:"start_date,omitempty"`
 	EndDate     *time.Time `json:"end_date,omitempty"`
+	IsPrivate   *bool      `json:"is_private,omitempty"`
+	DefaultRole *string    `json:"default_role,omitempty" validate:"omitempty,oneof=viewer commenter editor"`
+}
+
+// AddProjectMemberRequest represents a request to add a user to a project
+type AddProjectMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Role   string    `json:"role" validate:"required,oneof=owner editor commenter viewer"`
+}
+
+// UpdateProjectMemberRequest represents a request to change a member's project role
+type UpdateProjectMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner editor commenter viewer"`
 }
 
 // CreateTagRequest represents a tag creation request with validation
 type CreateTagRequest struct {
-	Name  string `json:"name" validate:"required,min=1,max=50"`
+	Name  string `json:"name" validate:"required,min=1,max=100"`
 	Color string `json:"color" validate:"omitempty,hexcolor"`
 }
 
 // UpdateTagRequest represents a tag update request with validation
 type UpdateTagRequest struct {
-	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=50"`
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
 	Color *string `json:"color,omitempty" validate:"omitempty,hexcolor"`
 }
 
+// MergeTagRequest represents a request to merge a tag into another tag
+type MergeTagRequest struct {
+	TargetTagID uuid.UUID `json:"target_tag_id" validate:"required"`
+}
+
+// CreateChecklistItemRequest represents a checklist item creation request with validation
+type CreateChecklistItemRequest struct {
+	Text       string     `json:"text" validate:"required,min=1,max=500"`
+	IsDone     bool       `json:"is_done,omitempty"`
+	AssigneeID *uuid.UUID `json:"assignee_id,omitempty"`
+	DueDate    *time.Time `json:"due_date,omitempty"`
+}
+
+// UpdateChecklistItemRequest represents a checklist item update request with validation
+type UpdateChecklistItemRequest struct {
+	Text          *string    `json:"text,omitempty" validate:"omitempty,min=1,max=500"`
+	IsDone        *bool      `json:"is_done,omitempty"`
+	AssigneeID    *uuid.UUID `json:"assignee_id,omitempty"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	ClearAssignee bool       `json:"clear_assignee,omitempty"`
+	ClearDueDate  bool       `json:"clear_due_date,omitempty"`
+}
+
+// ReorderChecklistRequest lists every checklist item of a task in its new order
+type ReorderChecklistRequest struct {
+	ItemIDs []uuid.UUID `json:"item_ids" validate:"required,min=1,max=500"`
+}
+
+// RunImportRequest starts an uploaded import. CSV imports need a mapping of
+// task fields to columns; user_map translates external user names to emails.
+type RunImportRequest struct {
+	Mapping map[string]string `json:"mapping,omitempty"`
+	UserMap map[string]string `json:"user_map,omitempty"`
+	DryRun  bool              `json:"dry_run"`
+}
+
+// CreateExportRequest asks for a background export of tasks or projects. Tasks
+// are narrowed by filter, or by the filter of a saved view when view_id is set.
+type CreateExportRequest struct {
+	Resource        models.ExportResource `json:"resource" validate:"required,oneof=tasks projects"`
+	Format          models.ExportFormat   `json:"format" validate:"required,oneof=csv xlsx ndjson"`
+	Filter          models.TaskFilter     `json:"filter"`
+	ViewID          *uuid.UUID            `json:"view_id,omitempty"`
+	IncludeComments bool                  `json:"include_comments"`
+	IncludeActivity bool                  `json:"include_activity"`
+}
+
 // CreateCommentRequest represents a comment creation request with validation
 type CreateCommentRequest struct {
 	Content string `json:"content" validate:"required,min=1,max=2000"`
 }
 
+// CreateTaskRelationRequest represents a task relation creation request with validation
+type CreateTaskRelationRequest struct {
+	Type           models.TaskRelationType `json:"type" validate:"required,oneof=relates_to duplicates duplicated_by caused_by causes clones cloned_by blocks blocked_by"`
+	TargetTaskID   uuid.UUID               `json:"target_task_id" validate:"required"`
+	CloseDuplicate bool                    `json:"close_duplicate,omitempty"`
+}
+
+// ShiftTaskRequest represents a request to move a task on the project timeline
+type ShiftTaskRequest struct {
+	TaskID         uuid.UUID `json:"task_id" validate:"required"`
+	Days           int       `json:"days" validate:"required,min=-3650,max=3650"`
+	PushDependents bool      `json:"push_dependents,omitempty"`
+}
+
+// CreateSprintRequest represents a sprint creation request with validation
+type CreateSprintRequest struct {
+	Name         string              `json:"name" validate:"required,min=1,max=100"`
+	Goal         string              `json:"goal" validate:"max=2000"`
+	StartDate    time.Time           `json:"start_date" validate:"required"`
+	EndDate      time.Time           `json:"end_date" validate:"required"`
+	EstimateUnit models.EstimateUnit `json:"estimate_unit" validate:"omitempty,oneof=hours points"`
+}
+
+// UpdateSprintRequest represents a sprint update request with validation
+type UpdateSprintRequest struct {
+	Name         *string              `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Goal         *string              `json:"goal,omitempty" validate:"omitempty,max=2000"`
+	StartDate    *time.Time           `json:"start_date,omitempty"`
+	EndDate      *time.Time           `json:"end_date,omitempty"`
+	EstimateUnit *models.EstimateUnit `json:"estimate_unit,omitempty" validate:"omitempty,oneof=hours points"`
+}
+
+// SprintTasksRequest represents a request to add tasks to a sprint
+type SprintTasksRequest struct {
+	TaskIDs []uuid.UUID `json:"task_ids" validate:"required,min=1,max=500"`
+}
+
+// CloseSprintRequest represents a sprint close request
+type CloseSprintRequest struct {
+	CarryOverTo *uuid.UUID `json:"carry_over_to,omitempty"`
+}
+
+// CreateCalendarFeedRequest represents a calendar feed creation request with validation
+type CreateCalendarFeedRequest struct {
+	Name      string     `json:"name" validate:"max=255"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// CreateSavedViewRequest represents a saved view creation request with validation
+type CreateSavedViewRequest struct {
+	Name       string                `json:"name" validate:"required,min=1,max=100"`
+	Filter     models.TaskFilter     `json:"filter"`
+	Sort       string                `json:"sort" validate:"omitempty,oneof=created_at updated_at due_date title status priority"`
+	Order      string                `json:"order" validate:"omitempty,oneof=asc desc"`
+	Columns    []string              `json:"columns" validate:"omitempty,dive,oneof=title status priority assignee project due_date tags creator created_at updated_at estimated_hours actual_hours"`
+	ViewMode   models.ViewMode       `json:"view_mode" validate:"omitempty,oneof=list board calendar"`
+	Visibility models.ViewVisibility `json:"visibility" validate:"omitempty,oneof=private tenant project"`
+	ProjectID  *uuid.UUID            `json:"project_id,omitempty"`
+	IsDefault  bool                  `json:"is_default,omitempty"`
+}
+
+// UpdateSavedViewRequest represents a saved view update request with validation
+type UpdateSavedViewRequest struct {
+	Name       *string                `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Filter     *models.TaskFilter     `json:"filter,omitempty"`
+	Sort       *string                `json:"sort,omitempty" validate:"omitempty,oneof=created_at updated_at due_date title status priority"`
+	Order      *string                `json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	Columns    []string               `json:"columns,omitempty" validate:"omitempty,dive,oneof=title status priority assignee project due_date tags creator created_at updated_at estimated_hours actual_hours"`
+	ViewMode   *models.ViewMode       `json:"view_mode,omitempty" validate:"omitempty,oneof=list board calendar"`
+	Visibility *models.ViewVisibility `json:"visibility,omitempty" validate:"omitempty,oneof=private tenant project"`
+	ProjectID  *uuid.UUID             `json:"project_id,omitempty"`
+}
+
 // TaskFiltersRequest represents task filtering parameters
 type TaskFiltersRequest struct {
 	Status     *models.TaskStatus   `form:"status" validate:"omitempty,task_status"`
@@ -104,4 +245,92 @@ func (p *PaginationRequest) DefaultPagination() {
 // GetOffset calculates the offset for database queries
 func (p *PaginationRequest) GetOffset() int {
 	return (p.Page - 1) * p.PerPage
-}
\ No newline at end of file
+}
+// CreateWebhookRequest registers a webhook endpoint. A signing secret is
+// generated when none is given. Events lists event types, or "*" for all.
+type CreateWebhookRequest struct {
+	Name   string   `json:"name" validate:"required,min=1,max=100"`
+	URL    string   `json:"url" validate:"required,url,max=2000"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=100"`
+}
+
+// UpdateWebhookRequest changes a webhook. Setting active re-enables a webhook
+// that was disabled after failed deliveries.
+type UpdateWebhookRequest struct {
+	Name   *string  `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	URL    *string  `json:"url,omitempty" validate:"omitempty,url,max=2000"`
+	Events []string `json:"events,omitempty" validate:"omitempty,min=1,dive,required"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+// WebhookDeliveryFiltersRequest narrows the delivery log of a webhook
+type WebhookDeliveryFiltersRequest struct {
+	Status    *models.WebhookDeliveryStatus `form:"status" validate:"omitempty,oneof=pending succeeded failed"`
+	EventType string                        `form:"event_type" validate:"max=50"`
+}
+
+// CreateNotificationTemplateRequest overrides the content of a notification
+// type on one channel for the tenant. Language defaults to "en"; variables
+// lists every variable the templates use.
+type CreateNotificationTemplateRequest struct {
+	Type      models.NotificationType    `json:"type" validate:"required,max=50"`
+	Channel   models.NotificationChannel `json:"channel" validate:"required,oneof=in_app email push"`
+	Language  string                     `json:"language,omitempty" validate:"max=10"`
+	Name      string                     `json:"name" validate:"required,min=1,max=100"`
+	Subject   string                     `json:"subject" validate:"required,max=255"`
+	Body      string                     `json:"body" validate:"required,max=20000"`
+	HTMLBody  string                     `json:"html_body,omitempty" validate:"max=100000"`
+	Variables []string                   `json:"variables" validate:"dive,required"`
+}
+
+// UpdateNotificationTemplateRequest changes a notification template
+type UpdateNotificationTemplateRequest struct {
+	Name      *string  `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Subject   *string  `json:"subject,omitempty" validate:"omitempty,min=1,max=255"`
+	Body      *string  `json:"body,omitempty" validate:"omitempty,min=1,max=20000"`
+	HTMLBody  *string  `json:"html_body,omitempty" validate:"omitempty,max=100000"`
+	Variables []string `json:"variables,omitempty" validate:"omitempty,dive,required"`
+	IsActive  *bool    `json:"is_active,omitempty"`
+}
+
+// PreviewNotificationTemplateRequest renders a notification with example
+// values. Without a subject and body, the template the tenant currently uses
+// for the language is previewed. Data replaces example values.
+type PreviewNotificationTemplateRequest struct {
+	Type     models.NotificationType    `json:"type" validate:"required,max=50"`
+	Channel  models.NotificationChannel `json:"channel" validate:"required,oneof=in_app email push"`
+	Language string                     `json:"language,omitempty" validate:"max=10"`
+	Subject  string                     `json:"subject,omitempty" validate:"max=255"`
+	Body     string                     `json:"body,omitempty" validate:"max=20000"`
+	HTMLBody string                     `json:"html_body,omitempty" validate:"max=100000"`
+	Data     map[string]interface{}     `json:"data,omitempty"`
+}
+
+// CreatePushSubscriptionRequest registers a browser for push notifications.
+// It has the shape of PushSubscription.toJSON() in the browser.
+type CreatePushSubscriptionRequest struct {
+	Endpoint       string `json:"endpoint" validate:"required,url,max=2000"`
+	ExpirationTime *int64 `json:"expirationTime,omitempty"` // milliseconds since the epoch
+	Keys           struct {
+		P256dh string `json:"p256dh" validate:"required,max=200"`
+		Auth   string `json:"auth" validate:"required,max=100"`
+	} `json:"keys"`
+}
+
+// UpdateQuietHoursRequest changes the current user's quiet hours. Start and
+// end are HH:MM in the user's timezone.
+type UpdateQuietHoursRequest struct {
+	Enabled     *bool   `json:"enabled,omitempty"`
+	Start       *string `json:"start,omitempty" validate:"omitempty,datetime=15:04"`
+	End         *string `json:"end,omitempty" validate:"omitempty,datetime=15:04"`
+	Weekends    *bool   `json:"weekends,omitempty"`
+	AllowUrgent *bool   `json:"allow_urgent,omitempty"`
+}
+
+// SetDoNotDisturbRequest turns on do-not-disturb for a number of minutes or
+// until a time. At most one of them is given.
+type SetDoNotDisturbRequest struct {
+	Minutes *int       `json:"minutes,omitempty" validate:"omitempty,min=1,max=43200"`
+	Until   *time.Time `json:"until,omitempty"`
+}
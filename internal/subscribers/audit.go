@@ -0,0 +1,204 @@
+package subscribers
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Audit records every domain change in the audit log
+type Audit struct {
+	db *gorm.DB
+}
+
+// NewAudit creates the audit log subscriber
+func NewAudit(db *gorm.DB) *Audit {
+	return &Audit{db: db}
+}
+
+// Types returns the events the subscriber handles. Notifications are not
+// changes made by users and are left out.
+func (a *Audit) Types() []events.Type {
+	types := make([]events.Type, 0, len(events.Types))
+	for _, t := range events.Types {
+		if t != events.TypeNotificationCreated {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Handle writes the audit log entry for an event
+func (a *Audit) Handle(ctx context.Context, event *events.Event) error {
+	entry, err := auditEntry(event)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	// The entry shares the event ID, so a redelivered event is ignored
+	if err := a.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// auditEntry describes an event as an audit log entry
+func auditEntry(event *events.Event) (*models.AuditLog, error) {
+	entry := &models.AuditLog{
+		ID:         event.ID,
+		TenantID:   event.TenantID,
+		Action:     string(event.Type),
+		ActorID:    event.ActorID,
+		OccurredAt: event.OccurredAt,
+	}
+
+	switch event.Type {
+	case events.TypeTaskCreated:
+		var payload events.TaskCreated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditTask(entry, &payload.Task)
+	case events.TypeTaskUpdated:
+		var payload events.TaskUpdated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditTask(entry, &payload.Task)
+		entry.Changes = auditChanges(payload.Changes)
+	case events.TypeTaskDeleted:
+		var payload events.TaskDeleted
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditTask(entry, &payload.Task)
+	case events.TypeCommentAdded:
+		var payload events.CommentAdded
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditTask(entry, &payload.Task)
+		entry.Details = fmt.Sprintf("comment %s", payload.Comment.ID)
+	case events.TypeProjectCreated:
+		var payload events.ProjectCreated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+	case events.TypeProjectUpdated:
+		var payload events.ProjectUpdated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+		entry.Changes = auditChanges(payload.Changes)
+	case events.TypeProjectArchived:
+		var payload events.ProjectArchived
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+	case events.TypeProjectUnarchived:
+		var payload events.ProjectUnarchived
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+	case events.TypeProjectDeleted:
+		var payload events.ProjectDeleted
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+	case events.TypeProjectMemberAdded:
+		var payload events.ProjectMemberAdded
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+		entry.Details = fmt.Sprintf("user %s added as %s", payload.UserID, payload.Role)
+	case events.TypeProjectMemberUpdated:
+		var payload events.ProjectMemberUpdated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+		entry.Changes = []models.AuditChange{{Field: "role", Old: payload.PreviousRole, New: payload.Role}}
+		entry.Details = fmt.Sprintf("user %s", payload.UserID)
+	case events.TypeProjectMemberRemoved:
+		var payload events.ProjectMemberRemoved
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditProject(entry, &payload.Project)
+		entry.Details = fmt.Sprintf("user %s removed, was %s", payload.UserID, payload.Role)
+	case events.TypeUserRegistered:
+		var payload events.UserRegistered
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditUser(entry, &payload.User)
+	case events.TypeUserInvited:
+		var payload events.UserInvited
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		entry.EntityType = "invitation"
+		entry.EntityID = payload.InvitationID
+		entry.EntityName = payload.Email
+		entry.Details = fmt.Sprintf("invited as %s", payload.Role)
+	case events.TypeUserUpdated:
+		var payload events.UserUpdated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditUser(entry, &payload.User)
+		entry.Changes = auditChanges(payload.Changes)
+	case events.TypeUserDeleted:
+		var payload events.UserDeleted
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		auditUser(entry, &payload.User)
+	default:
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// auditChanges converts event changes to audit log changes
+func auditChanges(changes []events.Change) []models.AuditChange {
+	result := make([]models.AuditChange, len(changes))
+	for i, change := range changes {
+		result[i] = models.AuditChange{Field: change.Field, Old: change.Old, New: change.New}
+	}
+	return result
+}
+
+// auditTask points an audit log entry at a task
+func auditTask(entry *models.AuditLog, task *events.TaskSnapshot) {
+	entry.EntityType = "task"
+	entry.EntityID = task.ID
+	entry.EntityName = task.Title
+	entry.ProjectID = task.ProjectID
+}
+
+// auditProject points an audit log entry at a project
+func auditProject(entry *models.AuditLog, project *events.ProjectSnapshot) {
+	entry.EntityType = "project"
+	entry.EntityID = project.ID
+	entry.EntityName = project.Name
+	entry.ProjectID = &project.ID
+}
+
+// auditUser points an audit log entry at a user account
+func auditUser(entry *models.AuditLog, user *events.UserSnapshot) {
+	entry.EntityType = "user"
+	entry.EntityID = user.ID
+	entry.EntityName = user.Email
+}
@@ -0,0 +1,265 @@
+package subscribers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/notify"
+	"github.com/drazan344/taskflow-go/internal/templates"
+)
+
+// Notifications notifies the people a change concerns: assignees, task
+// creators and watchers. They hear about assignments, completions, comments
+// and other edits of a task. The user who made the change is never notified
+// about it.
+type Notifications struct {
+	db     *gorm.DB
+	jobs   *jobs.Client
+	appURL string
+}
+
+// NewNotifications creates the notification subscriber. Notifications are
+// queued and delivered by a background job on the channels each recipient
+// receives them on, once per event even when the event is handled again.
+// Links in notifications start with appURL.
+func NewNotifications(db *gorm.DB, jobClient *jobs.Client, appURL string) *Notifications {
+	return &Notifications{db: db, jobs: jobClient, appURL: appURL}
+}
+
+// Types returns the events the subscriber handles
+func (n *Notifications) Types() []events.Type {
+	return []events.Type{events.TypeTaskCreated, events.TypeTaskUpdated, events.TypeCommentAdded}
+}
+
+// Handle creates the notifications for an event
+func (n *Notifications) Handle(ctx context.Context, event *events.Event) error {
+	switch event.Type {
+	case events.TypeTaskCreated:
+		var payload events.TaskCreated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Task.AssigneeID == nil {
+			return nil
+		}
+		return n.notifyTask(ctx, event, &payload.Task, models.NotificationTypeTaskAssigned, []uuid.UUID{*payload.Task.AssigneeID})
+
+	case events.TypeTaskUpdated:
+		var payload events.TaskUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		assigned := events.HasChange(payload.Changes, "assignee_id") && payload.Task.AssigneeID != nil
+		if assigned {
+			if err := n.notifyTask(ctx, event, &payload.Task, models.NotificationTypeTaskAssigned, []uuid.UUID{*payload.Task.AssigneeID}); err != nil {
+				return err
+			}
+		}
+		if events.HasChange(payload.Changes, "status") && payload.Task.Status == models.TaskStatusCompleted {
+			recipients, err := n.followers(&payload.Task)
+			if err != nil {
+				return err
+			}
+			return n.notifyTask(ctx, event, &payload.Task, models.NotificationTypeTaskCompleted, recipients)
+		}
+		if !changedBesides(payload.Changes, "assignee_id") {
+			return nil
+		}
+		recipients, err := n.followers(&payload.Task)
+		if err != nil {
+			return err
+		}
+		if assigned {
+			// The new assignee was told about the task already
+			recipients = without(recipients, *payload.Task.AssigneeID)
+		}
+		return n.notifyTask(ctx, event, &payload.Task, models.NotificationTypeTaskUpdated, recipients)
+
+	case events.TypeCommentAdded:
+		var payload events.CommentAdded
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		recipients, err := n.followers(&payload.Task)
+		if err != nil {
+			return err
+		}
+		return n.notifyTask(ctx, event, &payload.Task, models.NotificationTypeCommentAdded, recipients,
+			withComment(payload.Comment.ID), withVariable("comment", excerpt(payload.Comment.Content)))
+	}
+	return nil
+}
+
+// followers returns the creator, assignee and watchers of a task
+func (n *Notifications) followers(task *events.TaskSnapshot) ([]uuid.UUID, error) {
+	var watchers []uuid.UUID
+	if err := n.db.Model(&models.TaskWatcher{}).Where("task_id = ?", task.ID).Pluck("user_id", &watchers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task watchers: %w", err)
+	}
+	recipients := append([]uuid.UUID{task.CreatorID}, watchers...)
+	if task.AssigneeID != nil {
+		recipients = append(recipients, *task.AssigneeID)
+	}
+	return recipients, nil
+}
+
+// changedBesides reports whether a field other than the given ones changed
+func changedBesides(changes []events.Change, fields ...string) bool {
+	for _, change := range changes {
+		if !slices.Contains(fields, change.Field) {
+			return true
+		}
+	}
+	return false
+}
+
+// without returns the users other than userID
+func without(users []uuid.UUID, userID uuid.UUID) []uuid.UUID {
+	others := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		if user != userID {
+			others = append(others, user)
+		}
+	}
+	return others
+}
+
+// maxExcerpt is the length of comment text quoted in notifications
+const maxExcerpt = 140
+
+// notificationOption adjusts a notification before it is dispatched, such as
+// the variables its templates receive
+type notificationOption func(*notify.Notification)
+
+// withComment links a notification to a comment
+func withComment(commentID uuid.UUID) notificationOption {
+	return func(notification *notify.Notification) {
+		notification.CommentID = &commentID
+	}
+}
+
+// withVariable passes a variable to the notification templates
+func withVariable(name string, value interface{}) notificationOption {
+	return func(notification *notify.Notification) {
+		notification.Data[name] = value
+	}
+}
+
+// notifyTask notifies each recipient who can see the task about it
+func (n *Notifications) notifyTask(ctx context.Context, event *events.Event, task *events.TaskSnapshot, notificationType models.NotificationType,
+	recipients []uuid.UUID, options ...notificationOption) error {
+	recipients, err := n.recipients(event, task, recipients)
+	if err != nil || len(recipients) == 0 {
+		return err
+	}
+
+	actorName := "Someone"
+	if event.ActorID != nil {
+		var actor models.User
+		if err := n.db.WithContext(ctx).Select("id", "first_name", "last_name").Where("id = ?", *event.ActorID).First(&actor).Error; err == nil {
+			actorName = actor.GetFullName()
+		}
+	}
+
+	taskURL := templates.TaskURL(n.appURL, task.ID)
+	notification := &notify.Notification{
+		TenantID:    event.TenantID,
+		Type:        notificationType,
+		Recipients:  recipients,
+		Key:         fmt.Sprintf("event:%s:%s", event.ID, notificationType),
+		ActorID:     event.ActorID,
+		ActionURL:   taskURL,
+		TaskID:      &task.ID,
+		ProjectID:   task.ProjectID,
+		Urgent:      task.Priority == models.TaskPriorityUrgent,
+		CollapseKey: fmt.Sprintf("task:%s:%s", task.ID, notificationType),
+		Data: templates.Data{
+			"actor_name": actorName,
+			"task_title": task.Title,
+			"task_url":   taskURL,
+		},
+		Meta: models.NotificationData{
+			ActorID:    event.ActorID,
+			ActorName:  actorName,
+			EntityType: "task",
+			EntityID:   task.ID.String(),
+			EntityName: task.Title,
+			ExtraData: map[string]interface{}{
+				"event_id": event.ID,
+			},
+		},
+	}
+	if task.DueDate != nil {
+		notification.Data["due_date"] = task.DueDate.Format("2006-01-02")
+	}
+	if task.ProjectID != nil {
+		var project models.Project
+		if err := n.db.WithContext(ctx).Select("id", "name").Where("id = ?", *task.ProjectID).First(&project).Error; err == nil {
+			notification.Data["project_name"] = project.Name
+		}
+	}
+	for _, option := range options {
+		option(notification)
+	}
+	// Delivery runs in a job so slow channels do not hold up the event bus
+	// and failed channels are retried with any bus driver
+	if err := n.jobs.EnqueueNotification(jobs.NotificationPayload{
+		BaseJobPayload: jobs.BaseJobPayload{TenantID: event.TenantID},
+		Notification:   *notification,
+	}); err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
+
+// recipients removes the actor, duplicates and users who cannot see the task
+func (n *Notifications) recipients(event *events.Event, task *events.TaskSnapshot, candidates []uuid.UUID) ([]uuid.UUID, error) {
+	seen := map[uuid.UUID]bool{}
+	unique := make([]uuid.UUID, 0, len(candidates))
+	for _, userID := range candidates {
+		if seen[userID] || event.IsActor(userID) {
+			continue
+		}
+		seen[userID] = true
+		unique = append(unique, userID)
+	}
+	if len(unique) == 0 {
+		return nil, nil
+	}
+
+	audience, restricted, err := models.ProjectAudience(n.db, event.TenantID, task.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve task audience: %w", err)
+	}
+	if !restricted {
+		return unique, nil
+	}
+	allowed := make(map[uuid.UUID]bool, len(audience))
+	for _, userID := range audience {
+		allowed[userID] = true
+	}
+	visible := unique[:0]
+	for _, userID := range unique {
+		if allowed[userID] {
+			visible = append(visible, userID)
+		}
+	}
+	return visible, nil
+}
+
+// excerpt shortens text to the start quoted in notifications
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxExcerpt {
+		return string(runes[:maxExcerpt-1]) + "…"
+	}
+	return text
+}
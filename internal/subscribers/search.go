@@ -0,0 +1,157 @@
+package subscribers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Search keeps the search index in step with tasks, projects and comments
+type Search struct {
+	db *gorm.DB
+}
+
+// NewSearch creates the search indexing subscriber
+func NewSearch(db *gorm.DB) *Search {
+	return &Search{db: db}
+}
+
+// Types returns the events the subscriber handles
+func (s *Search) Types() []events.Type {
+	return []events.Type{
+		events.TypeTaskCreated, events.TypeTaskUpdated, events.TypeTaskDeleted, events.TypeCommentAdded,
+		events.TypeProjectCreated, events.TypeProjectUpdated, events.TypeProjectDeleted,
+	}
+}
+
+// Handle updates the index for an event
+func (s *Search) Handle(ctx context.Context, event *events.Event) error {
+	db := s.db.WithContext(ctx)
+
+	switch event.Type {
+	case events.TypeTaskCreated:
+		var payload events.TaskCreated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.index(db, taskDocument(event.TenantID, &payload.Task))
+
+	case events.TypeTaskUpdated:
+		var payload events.TaskUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		if err := s.index(db, taskDocument(event.TenantID, &payload.Task)); err != nil {
+			return err
+		}
+		// Comments follow their task when it moves to another project
+		if events.HasChange(payload.Changes, "project_id") {
+			if err := db.Model(&models.SearchDocument{}).
+				Where("entity_type = ? AND task_id = ?", models.SearchEntityComment, payload.Task.ID).
+				Update("project_id", payload.Task.ProjectID).Error; err != nil {
+				return fmt.Errorf("failed to move comment documents: %w", err)
+			}
+		}
+		return nil
+
+	case events.TypeTaskDeleted:
+		var payload events.TaskDeleted
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.remove(db, "(entity_type = ? AND entity_id = ?) OR (entity_type = ? AND task_id = ?)",
+			models.SearchEntityTask, payload.Task.ID, models.SearchEntityComment, payload.Task.ID)
+
+	case events.TypeCommentAdded:
+		var payload events.CommentAdded
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.index(db, &models.SearchDocument{
+			EntityType: models.SearchEntityComment,
+			EntityID:   payload.Comment.ID,
+			TenantID:   event.TenantID,
+			ProjectID:  payload.Task.ProjectID,
+			TaskID:     &payload.Task.ID,
+			Title:      payload.Task.Title,
+			Content:    payload.Comment.Content,
+			UpdatedAt:  payload.Comment.CreatedAt,
+		})
+
+	case events.TypeProjectCreated:
+		var payload events.ProjectCreated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.index(db, projectDocument(event.TenantID, &payload.Project))
+
+	case events.TypeProjectUpdated:
+		var payload events.ProjectUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.index(db, projectDocument(event.TenantID, &payload.Project))
+
+	case events.TypeProjectDeleted:
+		var payload events.ProjectDeleted
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return s.remove(db, "entity_type = ? AND entity_id = ?", models.SearchEntityProject, payload.Project.ID)
+	}
+	return nil
+}
+
+// index adds a document or replaces an older version of it. Versions are
+// compared by update time so a redelivered event cannot undo a later one.
+func (s *Search) index(db *gorm.DB, document *models.SearchDocument) error {
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}},
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "search_documents.updated_at <= excluded.updated_at"}}},
+		DoUpdates: clause.AssignmentColumns([]string{"project_id", "task_id", "title", "content", "updated_at"}),
+	}).Create(document).Error
+	if err != nil {
+		return fmt.Errorf("failed to index %s %s: %w", document.EntityType, document.EntityID, err)
+	}
+	return nil
+}
+
+// remove deletes the documents matching the condition
+func (s *Search) remove(db *gorm.DB, query string, args ...interface{}) error {
+	if err := db.Where(query, args...).Delete(&models.SearchDocument{}).Error; err != nil {
+		return fmt.Errorf("failed to remove search documents: %w", err)
+	}
+	return nil
+}
+
+// taskDocument builds the search document of a task
+func taskDocument(tenantID uuid.UUID, task *events.TaskSnapshot) *models.SearchDocument {
+	return &models.SearchDocument{
+		EntityType: models.SearchEntityTask,
+		EntityID:   task.ID,
+		TenantID:   tenantID,
+		ProjectID:  task.ProjectID,
+		Title:      task.Title,
+		Content:    task.Description,
+		UpdatedAt:  task.UpdatedAt,
+	}
+}
+
+// projectDocument builds the search document of a project
+func projectDocument(tenantID uuid.UUID, project *events.ProjectSnapshot) *models.SearchDocument {
+	return &models.SearchDocument{
+		EntityType: models.SearchEntityProject,
+		EntityID:   project.ID,
+		TenantID:   tenantID,
+		ProjectID:  &project.ID,
+		Title:      project.Name,
+		Content:    project.Description,
+		UpdatedAt:  project.UpdatedAt,
+	}
+}
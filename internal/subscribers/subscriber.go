@@ -0,0 +1,22 @@
+// Package subscribers holds the components that react to domain events:
+// WebSocket broadcasting, notifications, search indexing and the audit log.
+// Each one is registered on the event bus separately and keeps working when
+// another fails.
+package subscribers
+
+import (
+	"context"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+)
+
+// Subscriber handles a set of event types
+type Subscriber interface {
+	Types() []events.Type
+	Handle(ctx context.Context, event *events.Event) error
+}
+
+// Subscribe registers a subscriber on the bus under the given name
+func Subscribe(bus events.Bus, name string, subscriber Subscriber) {
+	bus.Subscribe(name, subscriber.Handle, subscriber.Types()...)
+}
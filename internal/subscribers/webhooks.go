@@ -0,0 +1,83 @@
+package subscribers
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/jobs"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+)
+
+// Webhooks queues a delivery of each event to the tenant's active webhooks
+// that subscribe to it
+type Webhooks struct {
+	db         *gorm.DB
+	jobs       *jobs.Client
+	maxRetries int
+}
+
+// NewWebhooks creates the webhook subscriber. Each delivery is retried up to
+// maxRetries times.
+func NewWebhooks(db *gorm.DB, jobClient *jobs.Client, maxRetries int) *Webhooks {
+	return &Webhooks{db: db, jobs: jobClient, maxRetries: maxRetries}
+}
+
+// Types returns the events the subscriber handles. Notifications are sent to
+// a single user and are not shared with webhooks.
+func (w *Webhooks) Types() []events.Type {
+	types := make([]events.Type, 0, len(events.Types))
+	for _, t := range events.Types {
+		if t != events.TypeNotificationCreated {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Handle queues the deliveries of an event
+func (w *Webhooks) Handle(ctx context.Context, event *events.Event) error {
+	db := w.db.WithContext(ctx)
+
+	var hooks []models.Webhook
+	if err := db.Where("tenant_id = ? AND active = ?", event.TenantID, true).Find(&hooks).Error; err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+
+	for i := range hooks {
+		if !hooks[i].Subscribes(string(event.Type)) {
+			continue
+		}
+
+		delivery, err := webhooks.NewDelivery(&hooks[i], event)
+		if err != nil {
+			return err
+		}
+		// The delivery ID is derived from the event, so a redelivered event
+		// finds its delivery already queued
+		result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+		if result.Error != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := w.jobs.EnqueueWebhookDelivery(jobs.WebhookNotificationPayload{
+			BaseJobPayload: jobs.BaseJobPayload{TenantID: event.TenantID},
+			WebhookID:      hooks[i].ID,
+			DeliveryID:     delivery.ID,
+			Event:          string(event.Type),
+			MaxRetries:     w.maxRetries,
+		}); err != nil {
+			// Drop the delivery so the event bus can retry it from scratch
+			db.Unscoped().Delete(delivery)
+			return fmt.Errorf("failed to queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package subscribers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+	"github.com/drazan344/taskflow-go/internal/websocket"
+)
+
+// Broadcaster sends messages to the WebSocket clients subscribed to topics
+type Broadcaster interface {
+	BroadcastTaskUpdate(task *models.Task, action string, topics []string)
+	Broadcast(tenantID uuid.UUID, topics []string, messageType websocket.MessageType, data interface{})
+	Reauthorize(tenantID, userID uuid.UUID, topic string)
+}
+
+// WebSocket pushes task, comment and project changes to the topics of the
+// task and project, and to the tenant topic when the whole tenant may see
+// them. Subscriptions are authorised when made, so access that is taken
+// away is checked again before the change goes out. Notifications are
+// pushed by the notification dispatcher.
+type WebSocket struct {
+	db          *gorm.DB
+	broadcaster Broadcaster
+	logger      *logrus.Logger
+}
+
+// NewWebSocket creates the WebSocket subscriber
+func NewWebSocket(db *gorm.DB, broadcaster Broadcaster, logger *logrus.Logger) *WebSocket {
+	return &WebSocket{db: db, broadcaster: broadcaster, logger: logger}
+}
+
+// Types returns the events the subscriber handles
+func (w *WebSocket) Types() []events.Type {
+	return []events.Type{
+		events.TypeTaskCreated, events.TypeTaskUpdated, events.TypeTaskDeleted, events.TypeCommentAdded,
+		events.TypeProjectCreated, events.TypeProjectUpdated, events.TypeProjectArchived, events.TypeProjectUnarchived,
+		events.TypeProjectDeleted, events.TypeProjectMemberAdded, events.TypeProjectMemberUpdated, events.TypeProjectMemberRemoved,
+	}
+}
+
+// Handle broadcasts an event
+func (w *WebSocket) Handle(ctx context.Context, event *events.Event) error {
+	switch event.Type {
+	case events.TypeTaskCreated:
+		var payload events.TaskCreated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return w.task(event, payload.Task.ID, "created")
+
+	case events.TypeTaskUpdated:
+		var payload events.TaskUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		if events.HasChange(payload.Changes, "project_id") {
+			// Subscribers may not see the project the task moved to
+			w.broadcaster.Reauthorize(event.TenantID, uuid.Nil, websocket.TaskTopic(payload.Task.ID))
+		}
+		return w.task(event, payload.Task.ID, "updated")
+
+	case events.TypeTaskDeleted:
+		var payload events.TaskDeleted
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return w.task(event, payload.Task.ID, "deleted")
+
+	case events.TypeCommentAdded:
+		var payload events.CommentAdded
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return w.send(event, payload.Task.ProjectID, &payload.Task.ID, websocket.MessageTypeComment, map[string]interface{}{
+			"task_id": payload.Task.ID,
+			"comment": payload.Comment,
+		})
+
+	default:
+		return w.project(event)
+	}
+}
+
+// task broadcasts the current state of a task. Deleted tasks are still
+// loaded so clients receive the task they should remove.
+func (w *WebSocket) task(event *events.Event, taskID uuid.UUID, action string) error {
+	var task models.Task
+	if err := w.db.Unscoped().
+		Preload("Creator").
+		Preload("Assignee").
+		Preload("Project").
+		Preload("Tags").
+		Where("id = ? AND tenant_id = ?", taskID, event.TenantID).
+		First(&task).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+
+	public, err := models.ProjectIsPublic(w.db, event.TenantID, task.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve task visibility: %w", err)
+	}
+	w.broadcaster.BroadcastTaskUpdate(&task, action, websocket.ContentTopics(task.ProjectID, &task.ID, public))
+	return nil
+}
+
+// project broadcasts changes to a project and its members
+func (w *WebSocket) project(event *events.Event) error {
+	var project events.ProjectSnapshot
+	var member *uuid.UUID
+	data := map[string]interface{}{"action": event.Type}
+
+	switch event.Type {
+	case events.TypeProjectCreated:
+		var payload events.ProjectCreated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+	case events.TypeProjectUpdated:
+		var payload events.ProjectUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+		data["changes"] = payload.Changes
+		if project.IsPrivate && events.HasChange(payload.Changes, "is_private") {
+			w.broadcaster.Reauthorize(event.TenantID, uuid.Nil, "")
+		}
+	case events.TypeProjectArchived:
+		var payload events.ProjectArchived
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+	case events.TypeProjectUnarchived:
+		var payload events.ProjectUnarchived
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+	case events.TypeProjectDeleted:
+		var payload events.ProjectDeleted
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+	case events.TypeProjectMemberAdded:
+		var payload events.ProjectMemberAdded
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+		data["user_id"], data["role"] = payload.UserID, payload.Role
+		member = &payload.UserID
+	case events.TypeProjectMemberUpdated:
+		var payload events.ProjectMemberUpdated
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+		data["user_id"], data["role"] = payload.UserID, payload.Role
+		member = &payload.UserID
+	case events.TypeProjectMemberRemoved:
+		var payload events.ProjectMemberRemoved
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		project = payload.Project
+		data["user_id"], data["role"] = payload.UserID, payload.Role
+		member = &payload.UserID
+		w.broadcaster.Reauthorize(event.TenantID, payload.UserID, "")
+	default:
+		return nil
+	}
+
+	data["project"] = project
+	topics := websocket.ContentTopics(&project.ID, nil, !project.IsPrivate)
+	if member != nil {
+		// The member learns of the change, including that they lost access
+		topics = append(topics, websocket.UserTopic(*member))
+	}
+	w.broadcaster.Broadcast(event.TenantID, topics, websocket.MessageTypeProjectUpdate, data)
+	return nil
+}
+
+// send delivers a message to the subscribers of a task and its project, and
+// to the tenant when the project is public
+func (w *WebSocket) send(event *events.Event, projectID, taskID *uuid.UUID, messageType websocket.MessageType, data interface{}) error {
+	public, err := models.ProjectIsPublic(w.db, event.TenantID, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve visibility: %w", err)
+	}
+	w.broadcaster.Broadcast(event.TenantID, websocket.ContentTopics(projectID, taskID, public), messageType, data)
+	return nil
+}
@@ -0,0 +1,305 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Channels that have templates. WebSocket clients receive in-app
+// notifications, so they share the in-app template.
+var Channels = []models.NotificationChannel{
+	models.NotificationChannelInApp,
+	models.NotificationChannelEmail,
+	models.NotificationChannelPush,
+}
+
+// Variable is a value a notification type provides to its templates
+type Variable struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Example     interface{} `json:"example"`
+}
+
+// Content is the subject and bodies of a template. HTML is only used by email.
+type Content struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Definition describes a notification type: the variables it provides and its
+// built-in English content
+type Definition struct {
+	Type      models.NotificationType `json:"type"`
+	Variables []Variable              `json:"variables"`
+
+	// Title and Message are the in-app and push content
+	Title   string `json:"-"`
+	Message string `json:"-"`
+
+	// Action labels the link to URL in emails
+	Action string `json:"-"`
+	URL    string `json:"-"`
+
+	// Email replaces the email derived from Title and Message
+	Email *Content `json:"-"`
+}
+
+// Builtin variables are available to every template
+var Builtin = []Variable{
+	{Name: "recipient_name", Description: "Full name of the recipient", Example: "Ada Lovelace"},
+	{Name: "app_url", Description: "Base URL of TaskFlow", Example: "https://taskflow.example.com"},
+	{Name: "brand_name", Description: "Name of the tenant", Example: "Acme Inc"},
+	{Name: "brand_color", Description: "Primary color of the tenant", Example: "#4F46E5"},
+	{Name: "brand_logo_url", Description: "Logo of the tenant", Example: "https://example.com/logo.png"},
+}
+
+var (
+	actorName   = Variable{Name: "actor_name", Description: "Name of the user who made the change", Example: "Grace Hopper"}
+	taskTitle   = Variable{Name: "task_title", Description: "Title of the task", Example: "Prepare the quarterly report"}
+	taskURL     = Variable{Name: "task_url", Description: "Link to the task", Example: "https://taskflow.example.com/tasks/123e4567-e89b-12d3-a456-426614174000"}
+	projectName = Variable{Name: "project_name", Description: "Name of the task's project", Example: "Finance"}
+	dueDate     = Variable{Name: "due_date", Description: "Due date of the task", Example: "2024-03-31"}
+	expiresAt   = Variable{Name: "expires_at", Description: "When the link expires", Example: "2024-03-01 12:00 UTC"}
+)
+
+var definitions = []Definition{
+	{
+		Type:      models.NotificationTypeWelcome,
+		Variables: []Variable{{Name: "login_url", Description: "Sign in link", Example: "https://taskflow.example.com/login"}},
+		Title:     "Welcome to {{.brand_name}}!",
+		Message:   "Hello {{.recipient_name}}! Welcome to {{.brand_name}}. We're excited to have you on board.",
+		Action:    "Sign in",
+		URL:       "login_url",
+		Email: &Content{
+			Subject: "Welcome to {{.brand_name}}",
+			Body:    "Hi {{.recipient_name}},\n\nYour account is ready. Sign in at {{.login_url}} to start organising your work.\n\nThe {{.brand_name}} team\n",
+			HTML:    `<p>Hi {{.recipient_name}},</p><p>Your account is ready. <a href="{{.login_url}}">Sign in</a> to start organising your work.</p><p>The {{.brand_name}} team</p>`,
+		},
+	},
+	{
+		Type: models.NotificationTypePasswordReset,
+		Variables: []Variable{
+			{Name: "reset_url", Description: "Password reset link", Example: "https://taskflow.example.com/reset?token=abc"},
+			expiresAt,
+		},
+		Title:   "Password reset requested",
+		Message: "A password reset link was sent to your email address.",
+		Action:  "Choose a new password",
+		URL:     "reset_url",
+		Email: &Content{
+			Subject: "Reset your {{.brand_name}} password",
+			Body:    "Hi {{.recipient_name}},\n\nOpen the link below to choose a new password. It expires at {{.expires_at}}.\n\n{{.reset_url}}\n\nIf you did not ask to reset your password, you can ignore this email.\n",
+			HTML:    `<p>Hi {{.recipient_name}},</p><p><a href="{{.reset_url}}">Choose a new password</a>. The link expires at {{.expires_at}}.</p><p>If you did not ask to reset your password, you can ignore this email.</p>`,
+		},
+	},
+	{
+		Type: models.NotificationTypeWeeklyDigest,
+		Variables: []Variable{
+			{Name: "week", Description: "First day of the week", Example: "March 4"},
+			{Name: "completed_tasks", Description: "Tasks completed during the week", Example: "12"},
+			{Name: "pending_tasks", Description: "Tasks still open", Example: "5"},
+			{Name: "overdue_tasks", Description: "Tasks past their due date", Example: "1"},
+		},
+		Title:   "Your week of {{.week}}",
+		Message: "{{.completed_tasks}} tasks completed, {{.pending_tasks}} pending and {{.overdue_tasks}} overdue.",
+		Action:  "Open TaskFlow",
+		URL:     "app_url",
+		Email: &Content{
+			Subject: "Your {{.brand_name}} week of {{.week}}",
+			Body:    "Hi {{.recipient_name}},\n\nHere is your week of {{.week}}:\n\n- {{.completed_tasks}} tasks completed\n- {{.pending_tasks}} tasks pending\n- {{.overdue_tasks}} tasks overdue\n\n{{.app_url}}\n",
+			HTML:    `<p>Hi {{.recipient_name}},</p><p>Here is your week of {{.week}}:</p><ul><li>{{.completed_tasks}} tasks completed</li><li>{{.pending_tasks}} tasks pending</li><li>{{.overdue_tasks}} tasks overdue</li></ul><p><a href="{{.app_url}}">Open TaskFlow</a></p>`,
+		},
+	},
+	{
+		Type: models.NotificationTypeDigest,
+		Variables: []Variable{
+			{Name: "period", Description: "What the digest covers: day or week", Example: "day"},
+			{Name: "count", Description: "Number of notifications in the digest", Example: 3},
+			{Name: "groups", Description: "Notifications by project and type. Each group has project, label and items; each item has title, message and url.", Example: []map[string]interface{}{
+				{"project": "Finance", "label": "Task assigned", "items": []map[string]interface{}{
+					{"title": "Task assigned", "message": `Grace Hopper assigned you to "Prepare the quarterly report"`, "url": "https://taskflow.example.com/tasks/123e4567-e89b-12d3-a456-426614174000"},
+				}},
+				{"project": "Finance", "label": "Comment added", "items": []map[string]interface{}{
+					{"title": "New comment", "message": `Grace Hopper commented on "Prepare the quarterly report"`, "url": "https://taskflow.example.com/tasks/123e4567-e89b-12d3-a456-426614174000"},
+					{"title": "New comment", "message": `Alan Turing commented on "Prepare the quarterly report"`, "url": "https://taskflow.example.com/tasks/123e4567-e89b-12d3-a456-426614174000"},
+				}},
+			}},
+		},
+		Title:   "Your {{.period}} in {{.brand_name}}",
+		Message: "You have {{.count}} notifications from the last {{.period}}.",
+		Action:  "Open TaskFlow",
+		URL:     "app_url",
+		Email: &Content{
+			Subject: "Your {{.brand_name}} digest: {{.count}} notifications",
+			Body:    "Hi {{.recipient_name}},\n\nHere is what happened in the last {{.period}}:\n{{range .groups}}\n{{.project}} - {{.label}}\n{{range .items}}- {{.message}}\n  {{.url}}\n{{end}}{{end}}\n{{.app_url}}\n",
+			HTML:    `<p>Hi {{.recipient_name}},</p><p>Here is what happened in the last {{.period}}:</p>{{range .groups}}<h3 style="font-size:15px;margin:16px 0 4px;">{{.project}} &middot; {{.label}}</h3><ul style="margin:0;padding-left:20px;">{{range .items}}<li><a href="{{.url}}">{{.message}}</a></li>{{end}}</ul>{{end}}<p><a href="{{.app_url}}">Open TaskFlow</a></p>`,
+		},
+	},
+	{
+		Type:      models.NotificationTypeTaskAssigned,
+		Variables: []Variable{actorName, taskTitle, taskURL, projectName, dueDate},
+		Title:     "Task assigned",
+		Message:   `{{.actor_name}} assigned you to "{{.task_title}}"`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type:      models.NotificationTypeTaskCreated,
+		Variables: []Variable{actorName, taskTitle, taskURL, projectName},
+		Title:     "Task created",
+		Message:   `{{.actor_name}} created "{{.task_title}}"`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type:      models.NotificationTypeTaskCompleted,
+		Variables: []Variable{actorName, taskTitle, taskURL, projectName},
+		Title:     "Task completed",
+		Message:   `{{.actor_name}} completed "{{.task_title}}"`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type:      models.NotificationTypeTaskUpdated,
+		Variables: []Variable{actorName, taskTitle, taskURL, projectName},
+		Title:     "Task updated",
+		Message:   `{{.actor_name}} updated "{{.task_title}}"`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type:      models.NotificationTypeTaskDue,
+		Variables: []Variable{taskTitle, taskURL, projectName, dueDate},
+		Title:     "Task due soon",
+		Message:   `"{{.task_title}}" is due on {{.due_date}}`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type:      models.NotificationTypeTaskOverdue,
+		Variables: []Variable{taskTitle, taskURL, projectName, dueDate},
+		Title:     "Task overdue",
+		Message:   `"{{.task_title}}" was due on {{.due_date}}`,
+		Action:    "View task",
+		URL:       "task_url",
+	},
+	{
+		Type: models.NotificationTypeCommentAdded,
+		Variables: []Variable{actorName, taskTitle, taskURL, projectName,
+			{Name: "comment", Description: "Start of the comment", Example: "Looks good to me"}},
+		Title:   "New comment",
+		Message: `{{.actor_name}} commented on "{{.task_title}}"`,
+		Action:  "View comment",
+		URL:     "task_url",
+	},
+	{
+		Type: models.NotificationTypeUserInvited,
+		Variables: []Variable{actorName,
+			{Name: "role", Description: "Role offered to the invitee", Example: "member"},
+			{Name: "invite_url", Description: "Link accepting the invitation", Example: "https://taskflow.example.com/invitations/abc"},
+			expiresAt},
+		Title:   "You're invited to {{.brand_name}}",
+		Message: "{{.actor_name}} invited you to join {{.brand_name}} as {{.role}}.",
+		Action:  "Accept invitation",
+		URL:     "invite_url",
+	},
+	{
+		Type:      models.NotificationTypeUserJoined,
+		Variables: []Variable{actorName},
+		Title:     "New team member",
+		Message:   "{{.actor_name}} joined {{.brand_name}}",
+		Action:    "Open TaskFlow",
+		URL:       "app_url",
+	},
+	{
+		Type: models.NotificationTypeProjectCreated,
+		Variables: []Variable{actorName, projectName,
+			{Name: "project_url", Description: "Link to the project", Example: "https://taskflow.example.com/projects/123e4567-e89b-12d3-a456-426614174000"}},
+		Title:   "Project created",
+		Message: `{{.actor_name}} created the project "{{.project_name}}"`,
+		Action:  "View project",
+		URL:     "project_url",
+	},
+	{
+		Type: models.NotificationTypeSystemUpdate,
+		Variables: []Variable{
+			{Name: "title", Description: "Title of the announcement", Example: "Scheduled maintenance"},
+			{Name: "message", Description: "Text of the announcement", Example: "TaskFlow will be unavailable on Sunday from 02:00 to 03:00 UTC."},
+		},
+		Title:   "{{.title}}",
+		Message: "{{.message}}",
+		Action:  "Open TaskFlow",
+		URL:     "app_url",
+	},
+	{
+		Type: models.NotificationTypeExportReady,
+		Variables: []Variable{
+			{Name: "resource", Description: "What was exported", Example: "tasks"},
+			{Name: "rows", Description: "Number of exported rows", Example: "240"},
+			{Name: "download_url", Description: "Signed download link", Example: "https://taskflow.example.com/api/v1/exports/abc/download"},
+			expiresAt,
+		},
+		Title:   "Your export is ready",
+		Message: "Your {{.resource}} export ({{.rows}} rows) is ready to download until {{.expires_at}}.",
+		Action:  "Download",
+		URL:     "download_url",
+	},
+}
+
+// Definitions returns every notification type with templates
+func Definitions() []Definition {
+	return definitions
+}
+
+// Lookup returns the definition of a notification type
+func Lookup(notificationType models.NotificationType) (*Definition, bool) {
+	for i := range definitions {
+		if definitions[i].Type == notificationType {
+			return &definitions[i], true
+		}
+	}
+	return nil, false
+}
+
+// Content returns the built-in content of the type on a channel
+func (d *Definition) Content(channel models.NotificationChannel) Content {
+	if channel != models.NotificationChannelEmail {
+		return Content{Subject: d.Title, Body: d.Message}
+	}
+	if d.Email != nil {
+		return *d.Email
+	}
+	return Content{
+		Subject: d.Title,
+		Body:    fmt.Sprintf("%s\n\n%s: {{.%s}}\n", d.Message, d.Action, d.URL),
+		HTML:    fmt.Sprintf(`<p>%s</p><p><a href="{{.%s}}">%s</a></p>`, d.Message, d.URL, d.Action),
+	}
+}
+
+// Examples returns example values of every variable the type provides
+func (d *Definition) Examples() Data {
+	data := Data{}
+	for _, variable := range append(append([]Variable{}, Builtin...), d.Variables...) {
+		data[variable.Name] = variable.Example
+	}
+	return data
+}
+
+// provides reports whether the type provides a variable
+func (d *Definition) provides(name string) bool {
+	for _, variable := range append(append([]Variable{}, Builtin...), d.Variables...) {
+		if variable.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TaskURL returns the link to a task in the application
+func TaskURL(appURL string, taskID uuid.UUID) string {
+	return fmt.Sprintf("%s/tasks/%s", appURL, taskID)
+}
@@ -0,0 +1,267 @@
+// Package templates renders the content of notifications on every channel.
+// Each notification type has built-in English content, which tenants can
+// override per channel and language.
+package templates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"reflect"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// DefaultLanguage is the language of the built-in templates
+const DefaultLanguage = "en"
+
+// defaultColor is used in emails of tenants without a valid primary color
+const defaultColor = "#4F46E5"
+
+// hexColor matches the colors safe to put in an email stylesheet
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Data holds the variables of a template
+type Data map[string]interface{}
+
+// Rendered is the content of a notification for one recipient and channel
+type Rendered struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Brand is the tenant branding shown in HTML emails
+type Brand struct {
+	Name         string
+	PrimaryColor string
+	LogoURL      string
+}
+
+// Engine renders notification templates
+type Engine struct {
+	db     *gorm.DB
+	appURL string
+}
+
+// New creates a template engine. appURL is the base of links in
+// notifications.
+func New(db *gorm.DB, appURL string) *Engine {
+	return &Engine{db: db, appURL: strings.TrimRight(appURL, "/")}
+}
+
+// AppURL returns the base of links in notifications
+func (e *Engine) AppURL() string {
+	return e.appURL
+}
+
+// Render renders a notification type for a recipient. The tenant's template
+// in the recipient's language is preferred, then a global one, then the
+// same in the default language, then the built-in content.
+func (e *Engine) Render(ctx context.Context, tenantID uuid.UUID, notificationType models.NotificationType,
+	channel models.NotificationChannel, language string, data Data) (*Rendered, error) {
+	definition, ok := Lookup(notificationType)
+	if !ok {
+		return nil, fmt.Errorf("no template for notification type %q", notificationType)
+	}
+	if channel == models.NotificationChannelWebSocket {
+		channel = models.NotificationChannelInApp
+	}
+
+	content, err := e.resolve(ctx, tenantID, definition, channel, language)
+	if err != nil {
+		return nil, err
+	}
+	brand, err := e.brand(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return e.execute(definition, channel, content, brand, data)
+}
+
+// Preview renders content as it would be sent to a recipient of the tenant,
+// using the example values of any variable missing from data
+func (e *Engine) Preview(ctx context.Context, tenantID uuid.UUID, notificationType models.NotificationType,
+	channel models.NotificationChannel, content Content, data Data) (*Rendered, error) {
+	definition, ok := Lookup(notificationType)
+	if !ok {
+		return nil, fmt.Errorf("no template for notification type %q", notificationType)
+	}
+
+	brand, err := e.brand(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	values := definition.Examples()
+	for name, value := range data {
+		values[name] = value
+	}
+	return e.execute(definition, channel, content, brand, values)
+}
+
+// Effective returns the content a recipient of the tenant would receive in
+// the language
+func (e *Engine) Effective(ctx context.Context, tenantID uuid.UUID, notificationType models.NotificationType,
+	channel models.NotificationChannel, language string) (Content, error) {
+	definition, ok := Lookup(notificationType)
+	if !ok {
+		return Content{}, fmt.Errorf("no template for notification type %q", notificationType)
+	}
+	return e.resolve(ctx, tenantID, definition, channel, language)
+}
+
+// resolve picks the template of a type, channel and language
+func (e *Engine) resolve(ctx context.Context, tenantID uuid.UUID, definition *Definition,
+	channel models.NotificationChannel, language string) (Content, error) {
+	languages := Languages(language)
+
+	var candidates []models.NotificationTemplate
+	if err := e.db.WithContext(ctx).
+		Where("type = ? AND channel = ? AND is_active = ? AND language IN ?", definition.Type, channel, true, languages).
+		Where("tenant_id = ? OR tenant_id IS NULL", tenantID).
+		Find(&candidates).Error; err != nil {
+		return Content{}, fmt.Errorf("failed to load notification templates: %w", err)
+	}
+
+	for _, lang := range languages {
+		var global *models.NotificationTemplate
+		for i := range candidates {
+			candidate := &candidates[i]
+			if candidate.Language != lang {
+				continue
+			}
+			if candidate.TenantID != nil {
+				return Content{Subject: candidate.Subject, Body: candidate.Body, HTML: candidate.HTMLBody}, nil
+			}
+			global = candidate
+		}
+		if global != nil {
+			return Content{Subject: global.Subject, Body: global.Body, HTML: global.HTMLBody}, nil
+		}
+	}
+	return definition.Content(channel), nil
+}
+
+// brand loads the branding of a tenant
+func (e *Engine) brand(ctx context.Context, tenantID uuid.UUID) (Brand, error) {
+	var tenant models.Tenant
+	if err := e.db.WithContext(ctx).Select("id", "name", "primary_color", "logo_url").
+		Where("id = ?", tenantID).First(&tenant).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return Brand{}, fmt.Errorf("failed to load tenant branding: %w", err)
+	}
+
+	brand := Brand{Name: tenant.Name, PrimaryColor: tenant.PrimaryColor, LogoURL: tenant.LogoURL}
+	if brand.Name == "" {
+		brand.Name = "TaskFlow"
+	}
+	if !hexColor.MatchString(brand.PrimaryColor) {
+		brand.PrimaryColor = defaultColor
+	}
+	return brand, nil
+}
+
+// execute renders content with the variables of the type. Variables missing
+// from data render empty.
+func (e *Engine) execute(definition *Definition, channel models.NotificationChannel, content Content, brand Brand, data Data) (*Rendered, error) {
+	values := Data{
+		"app_url":        e.appURL,
+		"brand_name":     brand.Name,
+		"brand_color":    brand.PrimaryColor,
+		"brand_logo_url": brand.LogoURL,
+		"recipient_name": "",
+	}
+	for _, variable := range definition.Variables {
+		// Lists stay nil so ranging over a missing one renders nothing
+		if reflect.ValueOf(variable.Example).Kind() == reflect.Slice {
+			values[variable.Name] = nil
+		} else {
+			values[variable.Name] = ""
+		}
+	}
+	for name, value := range data {
+		values[name] = value
+	}
+
+	rendered := &Rendered{}
+	var err error
+	if rendered.Subject, err = executeText("subject", content.Subject, values); err != nil {
+		return nil, err
+	}
+	// Subjects are single lines, whatever the variables contain
+	rendered.Subject = strings.Join(strings.Fields(rendered.Subject), " ")
+	if rendered.Body, err = executeText("body", content.Body, values); err != nil {
+		return nil, err
+	}
+
+	if channel == models.NotificationChannelEmail && content.HTML != "" {
+		body, err := executeHTML("html_body", content.HTML, values)
+		if err != nil {
+			return nil, err
+		}
+		var page bytes.Buffer
+		if err := layout.Execute(&page, layoutData{
+			Subject: rendered.Subject,
+			Brand:   brand,
+			AppURL:  e.appURL,
+			Content: htmltemplate.HTML(body),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to render email layout: %w", err)
+		}
+		rendered.HTML = page.String()
+	}
+	return rendered, nil
+}
+
+// Languages returns the languages to look for, most specific first: the
+// language itself, its base language and the default language
+func Languages(language string) []string {
+	language = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(language, "_", "-")))
+	var languages []string
+	add := func(lang string) {
+		for _, existing := range languages {
+			if existing == lang {
+				return
+			}
+		}
+		languages = append(languages, lang)
+	}
+	if language != "" {
+		add(language)
+		if dash := strings.IndexByte(language, '-'); dash > 0 {
+			add(language[:dash])
+		}
+	}
+	add(DefaultLanguage)
+	return languages
+}
+
+func executeText(name, source string, data Data) (string, error) {
+	tmpl, err := texttemplate.New(name).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+func executeHTML(name, source string, data Data) (string, error) {
+	tmpl, err := htmltemplate.New(name).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return out.String(), nil
+}
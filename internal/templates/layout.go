@@ -0,0 +1,42 @@
+package templates
+
+import (
+	htmltemplate "html/template"
+)
+
+// layoutData is the page an HTML email body is placed in
+type layoutData struct {
+	Subject string
+	Brand   Brand
+	AppURL  string
+	Content htmltemplate.HTML
+}
+
+// layout wraps HTML email bodies with the tenant's logo and color. Email
+// clients ignore stylesheets, so styles are inline.
+var layout = htmltemplate.Must(htmltemplate.New("layout").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Subject}}</title>
+</head>
+<body style="margin:0;padding:0;background:#f4f4f5;font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;color:#18181b;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="background:#f4f4f5;padding:24px 0;">
+<tr><td align="center">
+<table role="presentation" width="600" cellpadding="0" cellspacing="0" style="max-width:600px;width:100%;background:#ffffff;border-radius:8px;overflow:hidden;">
+<tr><td style="background:{{.Brand.PrimaryColor}};padding:16px 24px;">
+{{if .Brand.LogoURL}}<img src="{{.Brand.LogoURL}}" alt="{{.Brand.Name}}" height="32" style="height:32px;border:0;">{{else}}<span style="color:#ffffff;font-size:18px;font-weight:bold;">{{.Brand.Name}}</span>{{end}}
+</td></tr>
+<tr><td style="padding:24px;font-size:15px;line-height:1.5;">
+{{.Content}}
+</td></tr>
+<tr><td style="padding:16px 24px;border-top:1px solid #e4e4e7;font-size:12px;color:#71717a;">
+Sent by {{.Brand.Name}}{{if .AppURL}} &middot; <a href="{{.AppURL}}" style="color:#71717a;">{{.AppURL}}</a>{{end}}
+</td></tr>
+</table>
+</td></tr>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,161 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// languageTag matches language codes such as "en" or "pt-br"
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// Validate checks a template before it is saved: the type and channel must
+// have templates, the templates must parse and render with example values,
+// they may only use declared variables, and only variables the type provides
+// may be declared. Language is normalised in place.
+func Validate(t *models.NotificationTemplate) []string {
+	var errs []string
+
+	definition, ok := Lookup(t.Type)
+	if !ok {
+		return []string{fmt.Sprintf("type %q has no templates", t.Type)}
+	}
+	if !isChannel(t.Channel) {
+		errs = append(errs, fmt.Sprintf("channel must be one of %v", Channels))
+	}
+	t.Language = Languages(t.Language)[0]
+	if !languageTag.MatchString(t.Language) {
+		errs = append(errs, fmt.Sprintf("language %q is not a valid language code", t.Language))
+	}
+	if t.HTMLBody != "" && t.Channel != models.NotificationChannelEmail {
+		errs = append(errs, "html_body is only used by email templates")
+	}
+
+	declared := map[string]bool{}
+	for _, name := range t.Variables {
+		if !definition.provides(name) {
+			errs = append(errs, fmt.Sprintf("variable %q is not provided by %s notifications", name, t.Type))
+		}
+		declared[name] = true
+	}
+
+	sources := []struct{ name, source string }{
+		{"subject", t.Subject},
+		{"body", t.Body},
+		{"html_body", t.HTMLBody},
+	}
+	for _, s := range sources {
+		if s.source == "" {
+			continue
+		}
+		used, err := variables(s.name, s.source)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		for _, name := range used {
+			if !declared[name] {
+				errs = append(errs, fmt.Sprintf("%s uses undeclared variable %q", s.name, name))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	// Catch templates that parse but fail on real values, such as ranging
+	// over a string
+	engine := &Engine{appURL: "https://taskflow.example.com"}
+	content := Content{Subject: t.Subject, Body: t.Body, HTML: t.HTMLBody}
+	if _, err := engine.execute(definition, t.Channel, content, Brand{Name: "Example", PrimaryColor: defaultColor}, definition.Examples()); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// variables returns the top-level variables a template references. HTML
+// templates share the syntax of text templates, so both parse the same way.
+func variables(name, source string) ([]string, error) {
+	tmpl, err := texttemplate.New(name).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %v", name, err)
+	}
+
+	found := map[string]bool{}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && t.Tree.Root != nil {
+			collect(t.Tree.Root, found, false)
+		}
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// collect adds the variables referenced under node. Inside range and with
+// blocks dot is something else, so only $.name references count there.
+func collect(node parse.Node, found map[string]bool, nested bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collect(child, found, nested)
+		}
+	case *parse.ActionNode:
+		collect(n.Pipe, found, nested)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collect(cmd, found, nested)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collect(arg, found, nested)
+		}
+	case *parse.FieldNode:
+		if !nested {
+			found[n.Ident[0]] = true
+		}
+	case *parse.ChainNode:
+		collect(n.Node, found, nested)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			found[n.Ident[1]] = true
+		}
+	case *parse.IfNode:
+		collect(n.Pipe, found, nested)
+		collect(n.List, found, nested)
+		collect(n.ElseList, found, nested)
+	case *parse.RangeNode:
+		collect(n.Pipe, found, nested)
+		collect(n.List, found, true)
+		collect(n.ElseList, found, nested)
+	case *parse.WithNode:
+		collect(n.Pipe, found, nested)
+		collect(n.List, found, true)
+		collect(n.ElseList, found, nested)
+	case *parse.TemplateNode:
+		collect(n.Pipe, found, nested)
+	}
+}
+
+// isChannel reports whether a channel has templates
+func isChannel(channel models.NotificationChannel) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
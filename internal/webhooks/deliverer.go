@@ -0,0 +1,261 @@
+// Package webhooks sends domain events to tenant webhook endpoints. Requests
+// are signed with HMAC-SHA256 and every attempt is recorded in the delivery
+// log.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+const (
+	// maxResponseExcerpt is the number of response bytes kept in the
+	// delivery log, enough to tell why a receiver refused a delivery
+	maxResponseExcerpt = 512
+
+	// userAgent identifies deliveries to receivers
+	userAgent = "TaskFlow-Webhooks/1.0"
+
+	// Retries start after baseRetryDelay and double up to maxRetryDelay
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+)
+
+// RetryDelay returns how long to wait before the next attempt of a delivery
+// that has already been retried n times
+func RetryDelay(n int) time.Duration {
+	if n < 0 {
+		n = 0
+	}
+	delay := float64(baseRetryDelay) * math.Pow(2, float64(n))
+	if delay > float64(maxRetryDelay) {
+		return maxRetryDelay
+	}
+	return time.Duration(delay)
+}
+
+// NewDelivery prepares the delivery of an event to a webhook. The delivery ID
+// is derived from the event and the webhook, so an event delivered twice by
+// the event bus creates a single delivery.
+func NewDelivery(webhook *models.Webhook, event *events.Event) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		TenantModel: models.TenantModel{TenantID: webhook.TenantID},
+		WebhookID:   webhook.ID,
+		EventID:     event.ID,
+		EventType:   string(event.Type),
+		Status:      models.WebhookDeliveryPending,
+		RequestBody: string(body),
+	}
+	delivery.ID = uuid.NewSHA1(event.ID, webhook.ID[:])
+	return delivery, nil
+}
+
+// NewRedelivery prepares a new delivery of the event sent by an earlier one
+func NewRedelivery(original *models.WebhookDelivery) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		TenantModel:  models.TenantModel{TenantID: original.TenantID},
+		WebhookID:    original.WebhookID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Status:       models.WebhookDeliveryPending,
+		RequestBody:  original.RequestBody,
+		RedeliveryOf: &original.ID,
+	}
+}
+
+// Deliverer sends pending deliveries to their webhooks
+type Deliverer struct {
+	db           *gorm.DB
+	client       *http.Client
+	logger       *logrus.Logger
+	disableAfter int
+}
+
+// NewDeliverer creates a deliverer. Requests only reach the addresses guard
+// allows and time out after timeout, and a webhook is disabled once
+// disableAfter deliveries in a row have failed for good. A disableAfter of
+// zero never disables webhooks.
+func NewDeliverer(db *gorm.DB, logger *logrus.Logger, guard *Guard, timeout time.Duration, disableAfter int) *Deliverer {
+	return &Deliverer{
+		db:           db,
+		client:       guard.Client(timeout),
+		logger:       logger,
+		disableAfter: disableAfter,
+	}
+}
+
+// Deliver makes one attempt to send a delivery. It returns an error when the
+// attempt failed and should be retried. final marks the last attempt, after
+// which a failed delivery counts against its webhook.
+func (d *Deliverer) Deliver(ctx context.Context, deliveryID uuid.UUID, final bool) error {
+	db := d.db.WithContext(ctx)
+
+	var delivery models.WebhookDelivery
+	if err := db.Where("id = ?", deliveryID).First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to load webhook delivery: %w", err)
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+
+	var webhook models.Webhook
+	if err := db.Where("id = ? AND tenant_id = ?", delivery.WebhookID, delivery.TenantID).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return d.abandon(db, &delivery, "the webhook was deleted")
+		}
+		return fmt.Errorf("failed to load webhook: %w", err)
+	}
+	if !webhook.Active {
+		return d.abandon(db, &delivery, "the webhook is disabled")
+	}
+
+	sendErr := d.send(ctx, &webhook, &delivery)
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+	} else if final {
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	if err := db.Save(&delivery).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	if err := d.track(db, &webhook, &delivery); err != nil {
+		d.logger.WithError(err).WithField("webhook_id", webhook.ID).Error("Failed to update webhook health")
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("webhook delivery %s failed: %w", delivery.ID, sendErr)
+	}
+	return nil
+}
+
+// send posts the delivery to the webhook and records the request and the
+// response on the delivery
+func (d *Deliverer) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) error {
+	body := []byte(delivery.RequestBody)
+	timestamp := time.Now().Unix()
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		"User-Agent":    userAgent,
+		HeaderEvent:     delivery.EventType,
+		HeaderDelivery:  delivery.ID.String(),
+		HeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		HeaderSignature: Sign(webhook.Secret, timestamp, body),
+	}
+
+	delivery.Attempts++
+	delivery.RequestHeaders = headers
+	delivery.ResponseStatus = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	delivery.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Only an excerpt of the body is kept, and no headers, so the log cannot
+	// be used to read whatever a receiver returns
+	excerpt, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseExcerpt))
+	if err != nil {
+		d.logger.WithError(err).WithField("delivery_id", delivery.ID).Warn("Failed to read webhook response")
+	}
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = strings.ToValidUTF8(string(excerpt), "")
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+		delivery.Error = err.Error()
+		return err
+	}
+
+	now := time.Now()
+	delivery.DeliveredAt = &now
+	return nil
+}
+
+// track updates the failure count of a webhook after a delivery succeeded or
+// failed for good, and disables the webhook when too many failed in a row
+func (d *Deliverer) track(db *gorm.DB, webhook *models.Webhook, delivery *models.WebhookDelivery) error {
+	now := time.Now()
+	switch delivery.Status {
+	case models.WebhookDeliverySucceeded:
+		return db.Model(webhook).Updates(map[string]interface{}{
+			"consecutive_failures": 0,
+			"last_delivery_at":     now,
+		}).Error
+	case models.WebhookDeliveryFailed:
+		if err := db.Model(webhook).Updates(map[string]interface{}{
+			"consecutive_failures": gorm.Expr("consecutive_failures + 1"),
+			"last_delivery_at":     now,
+		}).Error; err != nil {
+			return err
+		}
+		if d.disableAfter <= 0 {
+			return nil
+		}
+		result := db.Model(&models.Webhook{}).
+			Where("id = ? AND active = ? AND consecutive_failures >= ?", webhook.ID, true, d.disableAfter).
+			Updates(map[string]interface{}{
+				"active":          false,
+				"disabled_at":     now,
+				"disabled_reason": fmt.Sprintf("disabled after %d failed deliveries in a row", d.disableAfter),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			d.logger.WithFields(logrus.Fields{
+				"webhook_id": webhook.ID,
+				"tenant_id":  webhook.TenantID,
+			}).Warn("Webhook disabled after repeated delivery failures")
+		}
+	}
+	return nil
+}
+
+// abandon marks a delivery as failed without sending it
+func (d *Deliverer) abandon(db *gorm.DB, delivery *models.WebhookDelivery, reason string) error {
+	delivery.Status = models.WebhookDeliveryFailed
+	delivery.Error = reason
+	if err := db.Save(delivery).Error; err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/drazan344/taskflow-go/internal/events"
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// newTestDeliverer returns a deliverer on an in-memory database that may
+// reach the loopback addresses httptest servers listen on
+func newTestDeliverer(t *testing.T, disableAfter int) (*Deliverer, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file:"+uuid.NewString()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{}))
+
+	guard, err := NewGuard([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return NewDeliverer(db, logger, guard, 5*time.Second, disableAfter), db
+}
+
+// createDelivery stores a webhook posting to url and a pending delivery to it
+func createDelivery(t *testing.T, db *gorm.DB, url string) (*models.Webhook, *models.WebhookDelivery) {
+	t.Helper()
+
+	webhook := &models.Webhook{
+		TenantModel: models.TenantModel{TenantID: uuid.New()},
+		Name:        "Receiver",
+		URL:         url,
+		Secret:      "whsec_test",
+		Events:      []string{models.WebhookAllEvents},
+		Active:      true,
+		CreatedBy:   uuid.New(),
+	}
+	require.NoError(t, db.Create(webhook).Error)
+
+	delivery, err := NewDelivery(webhook, &events.Event{ID: uuid.New(), Type: "task.created", TenantID: webhook.TenantID})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(delivery).Error)
+	return webhook, delivery
+}
+
+func TestDeliverSignsRequest(t *testing.T) {
+	deliverer, db := newTestDeliverer(t, 0)
+
+	var received atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received.Store(Verify("whsec_test", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Minute))
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	webhook, delivery := createDelivery(t, db, server.URL)
+	require.NoError(t, deliverer.Deliver(context.Background(), delivery.ID, false))
+	assert.True(t, received.Load())
+
+	var stored models.WebhookDelivery
+	require.NoError(t, db.First(&stored, "id = ?", delivery.ID).Error)
+	assert.Equal(t, models.WebhookDeliverySucceeded, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, http.StatusOK, stored.ResponseStatus)
+	assert.Equal(t, "ok", stored.ResponseBody)
+	assert.NotNil(t, stored.DeliveredAt)
+
+	require.NoError(t, db.First(webhook, "id = ?", webhook.ID).Error)
+	assert.Equal(t, 0, webhook.ConsecutiveFailures)
+	assert.NotNil(t, webhook.LastDeliveryAt)
+}
+
+func TestDeliverKeepsResponseExcerpt(t *testing.T) {
+	deliverer, db := newTestDeliverer(t, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(strings.Repeat("x", 4*maxResponseExcerpt)))
+	}))
+	defer server.Close()
+
+	_, delivery := createDelivery(t, db, server.URL)
+	require.Error(t, deliverer.Deliver(context.Background(), delivery.ID, false))
+
+	var stored models.WebhookDelivery
+	require.NoError(t, db.First(&stored, "id = ?", delivery.ID).Error)
+	assert.Len(t, stored.ResponseBody, maxResponseExcerpt)
+}
+
+func TestDeliverRetriesUntilFinalAttempt(t *testing.T) {
+	deliverer, db := newTestDeliverer(t, 0)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook, delivery := createDelivery(t, db, server.URL)
+	for attempt := 1; attempt <= 2; attempt++ {
+		err := deliverer.Deliver(context.Background(), delivery.ID, false)
+		require.Error(t, err)
+
+		var stored models.WebhookDelivery
+		require.NoError(t, db.First(&stored, "id = ?", delivery.ID).Error)
+		assert.Equal(t, models.WebhookDeliveryPending, stored.Status)
+		assert.Equal(t, attempt, stored.Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, stored.ResponseStatus)
+	}
+	require.NoError(t, deliverer.Deliver(context.Background(), delivery.ID, false))
+
+	var stored models.WebhookDelivery
+	require.NoError(t, db.First(&stored, "id = ?", delivery.ID).Error)
+	assert.Equal(t, models.WebhookDeliverySucceeded, stored.Status)
+	assert.Equal(t, 3, stored.Attempts)
+
+	// A delivery that is no longer pending is not sent again
+	require.NoError(t, deliverer.Deliver(context.Background(), delivery.ID, false))
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Retries that are not final do not count against the webhook
+	require.NoError(t, db.First(webhook, "id = ?", webhook.ID).Error)
+	assert.Equal(t, 0, webhook.ConsecutiveFailures)
+}
+
+func TestDeliverDisablesWebhookAfterRepeatedFailures(t *testing.T) {
+	deliverer, db := newTestDeliverer(t, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook, first := createDelivery(t, db, server.URL)
+	require.Error(t, deliverer.Deliver(context.Background(), first.ID, true))
+
+	require.NoError(t, db.First(webhook, "id = ?", webhook.ID).Error)
+	assert.Equal(t, 1, webhook.ConsecutiveFailures)
+	assert.True(t, webhook.Active)
+
+	second, err := NewDelivery(webhook, &events.Event{ID: uuid.New(), Type: "task.updated", TenantID: webhook.TenantID})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(second).Error)
+	require.Error(t, deliverer.Deliver(context.Background(), second.ID, true))
+
+	var stored models.WebhookDelivery
+	require.NoError(t, db.First(&stored, "id = ?", second.ID).Error)
+	assert.Equal(t, models.WebhookDeliveryFailed, stored.Status)
+
+	require.NoError(t, db.First(webhook, "id = ?", webhook.ID).Error)
+	assert.Equal(t, 2, webhook.ConsecutiveFailures)
+	assert.False(t, webhook.Active)
+	assert.NotNil(t, webhook.DisabledAt)
+	assert.NotEmpty(t, webhook.DisabledReason)
+
+	// Deliveries to a disabled webhook are abandoned without being sent
+	third, err := NewDelivery(webhook, &events.Event{ID: uuid.New(), Type: "task.deleted", TenantID: webhook.TenantID})
+	require.NoError(t, err)
+	require.NoError(t, db.Create(third).Error)
+	require.NoError(t, deliverer.Deliver(context.Background(), third.ID, false))
+
+	var abandoned models.WebhookDelivery
+	require.NoError(t, db.First(&abandoned, "id = ?", third.ID).Error)
+	assert.Equal(t, models.WebhookDeliveryFailed, abandoned.Status)
+	assert.Equal(t, 0, abandoned.Attempts)
+}
+
+func TestDeliverRefusesBlockedAddress(t *testing.T) {
+	deliverer, db := newTestDeliverer(t, 0)
+	deliverer.client = mustGuard(t).Client(5 * time.Second)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	_, delivery := createDelivery(t, db, server.URL)
+	err := deliverer.Deliver(context.Background(), delivery.ID, false)
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+	assert.Equal(t, int32(0), requests.Load())
+}
+
+// mustGuard returns a guard without allowed networks
+func mustGuard(t *testing.T) *Guard {
+	t.Helper()
+	guard, err := NewGuard(nil)
+	require.NoError(t, err)
+	return guard
+}
@@ -0,0 +1,157 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is how many redirects a delivery follows
+const maxRedirects = 5
+
+// ErrBlockedAddress is returned for webhook targets outside the public
+// internet, such as loopback, private or cloud metadata addresses
+var ErrBlockedAddress = errors.New("webhook target address is not allowed")
+
+// blockedPrefixes are special-purpose ranges that net/netip still reports as
+// global unicast
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this" network
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach IPv4 private ranges
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2002::/16"),      // 6to4, which embeds IPv4 addresses
+	netip.MustParsePrefix("2001::/32"),      // Teredo
+	netip.MustParsePrefix("100::/64"),       // discard-only
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// Guard keeps webhook deliveries on the public internet, so tenants cannot
+// use webhooks to reach the network the API runs in. Addresses are checked
+// when connecting, after DNS resolution, so names that resolve to internal
+// addresses are refused too.
+type Guard struct {
+	allowed []netip.Prefix
+}
+
+// NewGuard creates a guard that also lets deliveries reach the given
+// networks, in CIDR notation, for receivers on internal networks
+func NewGuard(allowedNetworks []string) (*Guard, error) {
+	g := &Guard{}
+	for _, network := range allowedNetworks {
+		network = strings.TrimSpace(network)
+		if network == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook network %q: %w", network, err)
+		}
+		g.allowed = append(g.allowed, prefix.Masked())
+	}
+	return g, nil
+}
+
+// Allows reports whether deliveries may connect to an address
+func (g *Guard) Allows(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range g.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckURL checks that a URL is an http or https URL whose host is not a
+// blocked address. Host names are only resolved when connecting.
+func (g *Guard) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("url must be an http or https URL")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !g.Allows(addr) {
+			return ErrBlockedAddress
+		}
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		if !g.Allows(netip.MustParseAddr("127.0.0.1")) {
+			return ErrBlockedAddress
+		}
+	}
+	return nil
+}
+
+// Transport returns an HTTP transport that only connects to allowed
+// addresses. Proxies from the environment are not used, since the guard
+// would then only see the proxy's address.
+func (g *Guard) Transport(timeout time.Duration) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   g.control,
+	}
+	return &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// Client returns an HTTP client that only connects to allowed addresses,
+// including when following redirects
+func (g *Guard) Client(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: g.Transport(timeout),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return g.CheckURL(req.URL.String())
+		},
+	}
+}
+
+// control refuses connections to blocked addresses. It runs for every
+// connection, after the host name was resolved.
+func (g *Guard) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fmt.Errorf("unexpected address %q: %w", address, err)
+	}
+	if !g.Allows(addr) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, addr)
+	}
+	return nil
+}
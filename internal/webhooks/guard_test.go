@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuardAllows(t *testing.T) {
+	guard, err := NewGuard(nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a00:1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, guard.Allows(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestGuardAllowedNetworks(t *testing.T) {
+	guard, err := NewGuard([]string{" 10.0.0.0/8 ", ""})
+	require.NoError(t, err)
+
+	assert.True(t, guard.Allows(netip.MustParseAddr("10.1.2.3")))
+	assert.False(t, guard.Allows(netip.MustParseAddr("192.168.1.1")))
+
+	_, err = NewGuard([]string{"10.0.0.0"})
+	assert.Error(t, err)
+}
+
+func TestGuardCheckURL(t *testing.T) {
+	guard, err := NewGuard(nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, guard.CheckURL("https://example.com/hooks"))
+	assert.NoError(t, guard.CheckURL("http://93.184.216.34/hooks"))
+	assert.Error(t, guard.CheckURL("ftp://example.com/hooks"))
+	assert.Error(t, guard.CheckURL("https:///hooks"))
+	assert.ErrorIs(t, guard.CheckURL("http://127.0.0.1:8080/hooks"), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.CheckURL("http://[::1]/hooks"), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.CheckURL("http://169.254.169.254/latest/meta-data"), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.CheckURL("http://localhost/hooks"), ErrBlockedAddress)
+	assert.ErrorIs(t, guard.CheckURL("http://api.localhost./hooks"), ErrBlockedAddress)
+}
+
+func TestGuardClientRefusesBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	guard, err := NewGuard(nil)
+	require.NoError(t, err)
+	_, err = guard.Client(5 * time.Second).Get(server.URL)
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+
+	guard, err = NewGuard([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	resp, err := guard.Client(5 * time.Second).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestGuardClientRefusesRedirectsToBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data", http.StatusFound)
+	}))
+	defer server.Close()
+
+	guard, err := NewGuard([]string{"127.0.0.0/8"})
+	require.NoError(t, err)
+	_, err = guard.Client(5 * time.Second).Get(server.URL)
+	assert.ErrorIs(t, err, ErrBlockedAddress)
+}
@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-TaskFlow-Event"
+	HeaderDelivery  = "X-TaskFlow-Delivery"
+	HeaderTimestamp = "X-TaskFlow-Timestamp"
+	HeaderSignature = "X-TaskFlow-Signature"
+)
+
+// signaturePrefix names the algorithm in the signature header
+const signaturePrefix = "sha256="
+
+// GenerateSecret returns a new random signing secret
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header value of a request body sent at the given
+// Unix time. The timestamp is signed with the body so a captured request
+// cannot be replayed later with a new timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a delivery and that its timestamp is no
+// older than tolerance. Receivers can use it to authenticate deliveries.
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return false
+		}
+	}
+	return hmac.Equal([]byte(Sign(secret, unix, body)), []byte(signature))
+}
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignIsDeterministic(t *testing.T) {
+	body := []byte(`{"type":"task.created"}`)
+
+	signature := Sign("whsec_test", 1700000000, body)
+
+	assert.True(t, strings.HasPrefix(signature, signaturePrefix))
+	assert.Equal(t, signature, Sign("whsec_test", 1700000000, body))
+	assert.NotEqual(t, signature, Sign("whsec_other", 1700000000, body))
+	assert.NotEqual(t, signature, Sign("whsec_test", 1700000001, body))
+	assert.NotEqual(t, signature, Sign("whsec_test", 1700000000, []byte(`{"type":"task.deleted"}`)))
+}
+
+func TestVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"type":"task.created"}`)
+	now := time.Now().Unix()
+	timestamp := strconv.FormatInt(now, 10)
+	signature := Sign(secret, now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		tolerance time.Duration
+		want      bool
+	}{
+		{"valid", secret, timestamp, signature, body, 5 * time.Minute, true},
+		{"wrong secret", "whsec_other", timestamp, signature, body, 5 * time.Minute, false},
+		{"tampered body", secret, timestamp, signature, []byte(`{"type":"task.deleted"}`), 5 * time.Minute, false},
+		{"replayed with a new timestamp", secret, strconv.FormatInt(now+1, 10), signature, body, 5 * time.Minute, false},
+		{"invalid timestamp", secret, "yesterday", signature, body, 5 * time.Minute, false},
+		{"missing prefix", secret, timestamp, strings.TrimPrefix(signature, signaturePrefix), body, 5 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Verify(tt.secret, tt.timestamp, tt.signature, tt.body, tt.tolerance))
+		})
+	}
+}
+
+func TestVerifyTolerance(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{}`)
+	old := time.Now().Add(-time.Hour).Unix()
+	future := time.Now().Add(time.Hour).Unix()
+
+	assert.False(t, Verify(secret, strconv.FormatInt(old, 10), Sign(secret, old, body), body, 5*time.Minute))
+	assert.False(t, Verify(secret, strconv.FormatInt(future, 10), Sign(secret, future, body), body, 5*time.Minute))
+	// A tolerance of zero skips the age check
+	assert.True(t, Verify(secret, strconv.FormatInt(old, 10), Sign(secret, old, body), body, 0))
+}
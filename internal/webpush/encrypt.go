@@ -0,0 +1,109 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// recordSize is the aes128gcm record size. The whole payload is sent as a
+// single record.
+const recordSize = 4096
+
+// headerSize is the size of the aes128gcm header: the 16-byte salt, the record
+// size, the key id length and the 65-byte server key
+const headerSize = 16 + 4 + 1 + 65
+
+// MaxPayload is the largest plaintext push services must accept. RFC 8291
+// limits the encrypted body to 4096 bytes, which holds the header, the
+// plaintext, its delimiter byte and the 16-byte tag.
+const MaxPayload = 4096 - headerSize - 17
+
+// encrypt encrypts a payload for a subscription with the aes128gcm content
+// encoding (RFC 8188) and the Web Push key derivation (RFC 8291)
+func encrypt(sub *Subscription, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > MaxPayload {
+		return nil, fmt.Errorf("payload of %d bytes exceeds %d", len(plaintext), MaxPayload)
+	}
+
+	clientKey, err := decode(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	clientPublic, err := ecdh.P256().NewPublicKey(clientKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decode(sub.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, errors.New("invalid auth secret")
+	}
+
+	serverPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := serverPrivate.ECDH(clientPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
+	}
+	serverKey := serverPrivate.PublicKey().Bytes()
+
+	// IKM = HKDF(auth_secret, ecdh_secret, "WebPush: info" || 0x00 || ua_public || as_public, 32)
+	info := append(append([]byte("WebPush: info\x00"), clientKey...), serverKey...)
+	ikm, err := derive(authSecret, sharedSecret, info, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, err := derive(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := derive(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last record; no padding is added
+	record := gcm.Seal(nil, nonce, append(append([]byte{}, plaintext...), 0x02), nil)
+
+	// Header: salt || record size || key id length || key id (the server key)
+	var body bytes.Buffer
+	body.Write(salt)
+	binary.Write(&body, binary.BigEndian, uint32(recordSize))
+	body.WriteByte(byte(len(serverKey)))
+	body.Write(serverKey)
+	body.Write(record)
+	return body.Bytes(), nil
+}
+
+// derive runs HKDF-SHA-256
+func derive(salt, secret, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return out, nil
+}
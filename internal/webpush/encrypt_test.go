@@ -0,0 +1,149 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
+)
+
+// decryptForTest decrypts an aes128gcm push message the way a user agent does
+// (RFC 8291 section 3.4 and RFC 8188 section 2)
+func decryptForTest(t *testing.T, uaPrivate *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+
+	require.Greater(t, len(body), 21, "body is shorter than the header")
+	salt := body[:16]
+	recordSize := binary.BigEndian.Uint32(body[16:20])
+	keyIDLength := int(body[20])
+	require.Greater(t, len(body), 21+keyIDLength, "body is shorter than the header")
+	asPublicBytes := body[21 : 21+keyIDLength]
+	record := body[21+keyIDLength:]
+	require.LessOrEqual(t, len(record), int(recordSize), "payload must fit in a single record")
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+	sharedSecret, err := uaPrivate.ECDH(asPublic)
+	require.NoError(t, err)
+
+	hkdfExpand := func(salt, secret, info []byte, length int) []byte {
+		out := make([]byte, length)
+		_, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out)
+		require.NoError(t, err)
+		return out
+	}
+	info := append(append([]byte("WebPush: info\x00"), uaPrivate.PublicKey().Bytes()...), asPublicBytes...)
+	ikm := hkdfExpand(authSecret, sharedSecret, info, 32)
+	cek := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	padded, err := gcm.Open(nil, nonce, record, nil)
+	require.NoError(t, err)
+
+	// The plaintext is followed by the 0x02 delimiter of the last record and
+	// optional zero padding
+	end := len(padded) - 1
+	for end >= 0 && padded[end] == 0 {
+		end--
+	}
+	require.GreaterOrEqual(t, end, 0, "missing padding delimiter")
+	require.Equal(t, byte(0x02), padded[end], "last record must end with the 0x02 delimiter")
+	return padded[:end]
+}
+
+// newTestSubscriber returns a user agent key pair and auth secret, and the
+// subscription they make up
+func newTestSubscriber(t *testing.T) (*ecdh.PrivateKey, []byte, *Subscription) {
+	t.Helper()
+
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	authSecret := make([]byte, 16)
+	_, err = rand.Read(authSecret)
+	require.NoError(t, err)
+
+	return uaPrivate, authSecret, &Subscription{
+		Endpoint: "https://push.example.com/send/abc",
+		P256dh:   encode(uaPrivate.PublicKey().Bytes()),
+		Auth:     encode(authSecret),
+	}
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// The decryptor must accept the example message of RFC 8291 appendix A,
+// so the round trips below check encrypt against the specification
+func TestDecryptRFC8291Example(t *testing.T) {
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94"))
+	require.NoError(t, err)
+	require.Equal(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		encode(uaPrivate.PublicKey().Bytes()))
+
+	body := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+	plaintext := decryptForTest(t, uaPrivate, mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"), body)
+
+	assert.Equal(t, "When I grow up, I want to be a watermelon", string(plaintext))
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	uaPrivate, authSecret, sub := newTestSubscriber(t)
+	plaintext := []byte(`{"title":"Task assigned","body":"Écrire la spécification"}`)
+
+	body, err := encrypt(sub, plaintext)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	assert.Equal(t, byte(65), body[20])
+	assert.Equal(t, plaintext, decryptForTest(t, uaPrivate, authSecret, body))
+
+	// Every message uses a new salt and server key
+	again, err := encrypt(sub, plaintext)
+	require.NoError(t, err)
+	assert.False(t, bytes.Equal(body[:86], again[:86]))
+}
+
+func TestEncryptMaxPayload(t *testing.T) {
+	uaPrivate, authSecret, sub := newTestSubscriber(t)
+	plaintext := bytes.Repeat([]byte("a"), MaxPayload)
+
+	body, err := encrypt(sub, plaintext)
+	require.NoError(t, err)
+	assert.Len(t, body, 4096)
+	assert.Equal(t, plaintext, decryptForTest(t, uaPrivate, authSecret, body))
+
+	_, err = encrypt(sub, append(plaintext, 'a'))
+	assert.Error(t, err)
+}
+
+func TestEncryptRejectsInvalidKeys(t *testing.T) {
+	_, _, sub := newTestSubscriber(t)
+
+	invalid := *sub
+	invalid.P256dh = encode([]byte("not a key"))
+	_, err := encrypt(&invalid, []byte("hello"))
+	assert.Error(t, err)
+
+	invalid = *sub
+	invalid.Auth = encode([]byte("short"))
+	_, err = encrypt(&invalid, []byte("hello"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidExpiry is how long a VAPID token is valid. Push services reject
+// tokens that expire more than 24 hours ahead.
+const vapidExpiry = 12 * time.Hour
+
+// Keys is a VAPID key pair (RFC 8292), encoded as unpadded base64url: the
+// public key as an uncompressed P-256 point and the private key as its
+// 32-byte scalar
+type Keys struct {
+	Public  string
+	Private string
+}
+
+// GenerateKeys creates a new VAPID key pair
+func GenerateKeys() (Keys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return Keys{}, fmt.Errorf("failed to generate VAPID keys: %w", err)
+	}
+	return Keys{
+		Public:  encode(key.PublicKey().Bytes()),
+		Private: encode(key.Bytes()),
+	}, nil
+}
+
+// vapid signs the tokens that identify the application to push services
+type vapid struct {
+	key     *ecdsa.PrivateKey
+	public  string
+	subject string
+}
+
+// newVAPID parses a key pair. The public key may be left out, as it follows
+// from the private key; when given it must match.
+func newVAPID(keys Keys, subject string) (*vapid, error) {
+	raw, err := decode(keys.Private)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	private, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	public := private.PublicKey().Bytes()
+	if keys.Public != "" {
+		configured, err := decode(keys.Public)
+		if err != nil || encode(configured) != encode(public) {
+			return nil, errors.New("VAPID public key does not match the private key")
+		}
+	}
+	if subject == "" {
+		return nil, errors.New("VAPID subject is required")
+	}
+
+	// public is 0x04 || X || Y
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	return &vapid{key: key, public: encode(public), subject: subject}, nil
+}
+
+// authorization returns the Authorization header for a push to endpoint
+func (v *vapid) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidExpiry).Unix(),
+		"sub": v.subject,
+	}).SignedString(v.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", token, v.public), nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decode accepts base64url with or without padding, and standard base64, as
+// browsers and libraries produce all of them
+func decode(s string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+		if b, err := encoding.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("not base64 encoded")
+}
@@ -0,0 +1,224 @@
+// Package webpush sends Web Push messages (RFC 8030) to browser push
+// subscriptions. Payloads are encrypted for the subscription (RFC 8291) and
+// the application identifies itself with VAPID (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+)
+
+var (
+	// ErrGone is returned when the push service no longer knows the
+	// subscription. The subscription should be deleted.
+	ErrGone = errors.New("push subscription has expired or been unsubscribed")
+
+	// ErrPermanent marks failures that sending again cannot fix, such as a
+	// rejected payload or VAPID token
+	ErrPermanent = errors.New("permanent push failure")
+)
+
+// Urgency tells the push service how soon a message must reach the device
+type Urgency string
+
+const (
+	UrgencyVeryLow Urgency = "very-low"
+	UrgencyLow     Urgency = "low"
+	UrgencyNormal  Urgency = "normal"
+	UrgencyHigh    Urgency = "high"
+)
+
+// Subscription is a browser push subscription as returned by
+// PushManager.subscribe()
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Options configure a client
+type Options struct {
+	Keys    Keys
+	Subject string // mailto: or https: contact for the push service operator
+
+	// ServiceURL replaces the scheme and host of every endpoint, so pushes
+	// can be sent to a local stand-in for the push services
+	ServiceURL string
+
+	// Guard keeps pushes on the public internet. Browsers choose the
+	// endpoints, so without it any user could make the server post to
+	// internal addresses. It is required unless ServiceURL is set.
+	Guard *webhooks.Guard
+
+	TTL     time.Duration // how long the push service keeps undelivered messages
+	Timeout time.Duration
+}
+
+// Message is a push message
+type Message struct {
+	Payload []byte
+	// Topic replaces an undelivered message with the same topic
+	Topic   string
+	Urgency Urgency
+}
+
+// Client sends push messages
+type Client struct {
+	vapid      *vapid
+	serviceURL *url.URL
+	guard      *webhooks.Guard
+	ttl        time.Duration
+	http       *http.Client
+}
+
+// New creates a client
+func New(opts Options) (*Client, error) {
+	v, err := newVAPID(opts.Keys, opts.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	client := &Client{
+		vapid: v,
+		guard: opts.Guard,
+		ttl:   opts.TTL,
+		http: &http.Client{
+			Timeout: timeout,
+			// Push services answer directly; a redirect could lead anywhere
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	if opts.ServiceURL != "" {
+		// The stand-in is chosen by the operator, so it may be local
+		if client.serviceURL, err = url.Parse(opts.ServiceURL); err != nil || client.serviceURL.Host == "" {
+			return nil, fmt.Errorf("invalid push service URL %q", opts.ServiceURL)
+		}
+	} else {
+		if opts.Guard == nil {
+			return nil, errors.New("a guard is required to send to push services")
+		}
+		client.http.Transport = opts.Guard.Transport(timeout)
+	}
+	if client.ttl <= 0 {
+		client.ttl = 24 * time.Hour
+	}
+	return client, nil
+}
+
+// PublicKey returns the VAPID public key browsers subscribe with
+func (c *Client) PublicKey() string {
+	return c.vapid.public
+}
+
+// Send encrypts a message for a subscription and sends it to the
+// subscription's push service. It returns ErrGone when the subscription is
+// no longer valid, and wraps ErrPermanent when retrying cannot help.
+func (c *Client) Send(ctx context.Context, sub *Subscription, msg *Message) error {
+	body, err := encrypt(sub, msg.Payload)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	endpoint := sub.Endpoint
+	authorization, err := c.vapid.authorization(endpoint, time.Now())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	if c.serviceURL != nil {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("%w: invalid push endpoint: %v", ErrPermanent, err)
+		}
+		u.Scheme, u.Host = c.serviceURL.Scheme, c.serviceURL.Host
+		u.Path = strings.TrimRight(c.serviceURL.Path, "/") + u.Path
+		endpoint = u.String()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(c.ttl.Seconds())))
+	if msg.Urgency != "" {
+		req.Header.Set("Urgency", string(msg.Urgency))
+	}
+	if msg.Topic != "" {
+		req.Header.Set("Topic", msg.Topic)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, webhooks.ErrBlockedAddress) {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrGone
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	default:
+		return fmt.Errorf("%w: push service returned %d: %s", ErrPermanent, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+}
+
+// CheckEndpoint refuses endpoints that are internal addresses, so they are
+// not stored. Host names are only resolved when sending.
+func (c *Client) CheckEndpoint(endpoint string) error {
+	if c.serviceURL != nil {
+		return nil
+	}
+	if err := c.guard.CheckURL(endpoint); err != nil {
+		if errors.Is(err, webhooks.ErrBlockedAddress) {
+			return errors.New("endpoint must not point to a private or internal address")
+		}
+		return err
+	}
+	return nil
+}
+
+// Validate checks a subscription before it is stored: the endpoint must be
+// an HTTPS URL, p256dh a P-256 public key and auth a 16-byte secret
+func Validate(sub *Subscription) error {
+	u, err := url.Parse(sub.Endpoint)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("endpoint must be an https URL")
+	}
+	key, err := decode(sub.P256dh)
+	if err != nil {
+		return errors.New("keys.p256dh must be base64url encoded")
+	}
+	if _, err := ecdh.P256().NewPublicKey(key); err != nil {
+		return errors.New("keys.p256dh is not a P-256 public key")
+	}
+	if auth, err := decode(sub.Auth); err != nil || len(auth) != 16 {
+		return errors.New("keys.auth must be a base64url encoded 16-byte secret")
+	}
+	return nil
+}
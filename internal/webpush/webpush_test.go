@@ -0,0 +1,158 @@
+package webpush
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/drazan344/taskflow-go/internal/webhooks"
+)
+
+// newTestClient returns a client that sends every push to serviceURL
+func newTestClient(t *testing.T, serviceURL string) *Client {
+	t.Helper()
+
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+	client, err := New(Options{
+		Keys:       keys,
+		Subject:    "mailto:ops@example.com",
+		ServiceURL: serviceURL,
+		TTL:        time.Hour,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestSendDeliversEncryptedMessage(t *testing.T) {
+	uaPrivate, authSecret, sub := newTestSubscriber(t)
+
+	var request *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	err := client.Send(context.Background(), sub, &Message{
+		Payload: []byte(`{"title":"Hello"}`),
+		Topic:   "task-42",
+		Urgency: UrgencyHigh,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, request)
+	assert.Equal(t, "/send/abc", request.URL.Path)
+	assert.Equal(t, "aes128gcm", request.Header.Get("Content-Encoding"))
+	assert.Equal(t, "3600", request.Header.Get("TTL"))
+	assert.Equal(t, "task-42", request.Header.Get("Topic"))
+	assert.Equal(t, "high", request.Header.Get("Urgency"))
+	assert.True(t, strings.HasPrefix(request.Header.Get("Authorization"), "vapid t="))
+	assert.Contains(t, request.Header.Get("Authorization"), "k="+client.PublicKey())
+	assert.Equal(t, `{"title":"Hello"}`, string(decryptForTest(t, uaPrivate, authSecret, body)))
+}
+
+func TestSendMapsStatusCodes(t *testing.T) {
+	tests := []struct {
+		status    int
+		gone      bool
+		permanent bool
+	}{
+		{http.StatusNotFound, true, false},
+		{http.StatusGone, true, false},
+		{http.StatusTooManyRequests, false, false},
+		{http.StatusServiceUnavailable, false, false},
+		{http.StatusBadRequest, false, true},
+		{http.StatusForbidden, false, true},
+		{http.StatusRequestEntityTooLarge, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			_, _, sub := newTestSubscriber(t)
+			err := newTestClient(t, server.URL).Send(context.Background(), sub, &Message{Payload: []byte("hello")})
+
+			require.Error(t, err)
+			assert.Equal(t, tt.gone, errors.Is(err, ErrGone))
+			assert.Equal(t, tt.permanent, errors.Is(err, ErrPermanent))
+		})
+	}
+}
+
+func TestSendRejectsOversizedPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("an oversized payload must not be sent")
+	}))
+	defer server.Close()
+
+	_, _, sub := newTestSubscriber(t)
+	err := newTestClient(t, server.URL).Send(context.Background(), sub, &Message{Payload: make([]byte, MaxPayload+1)})
+	assert.ErrorIs(t, err, ErrPermanent)
+}
+
+func TestSendRefusesInternalEndpoints(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	guard, err := webhooks.NewGuard(nil)
+	require.NoError(t, err)
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+	client, err := New(Options{Keys: keys, Subject: "mailto:ops@example.com", Guard: guard})
+	require.NoError(t, err)
+
+	_, _, sub := newTestSubscriber(t)
+	sub.Endpoint = server.URL + "/send/abc"
+	assert.Error(t, client.CheckEndpoint(sub.Endpoint))
+	assert.Error(t, client.CheckEndpoint("https://169.254.169.254/latest/meta-data"))
+	assert.NoError(t, client.CheckEndpoint("https://fcm.googleapis.com/fcm/send/abc"))
+
+	err = client.Send(context.Background(), sub, &Message{Payload: []byte("hello")})
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.Equal(t, 0, requests)
+}
+
+func TestNewRequiresGuardWithoutServiceURL(t *testing.T) {
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+
+	_, err = New(Options{Keys: keys, Subject: "mailto:ops@example.com"})
+	assert.Error(t, err)
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	_, _, sub := newTestSubscriber(t)
+	err := newTestClient(t, server.URL).Send(context.Background(), sub, &Message{Payload: []byte("hello")})
+
+	assert.ErrorIs(t, err, ErrPermanent)
+	assert.False(t, redirected)
+}
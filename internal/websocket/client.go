@@ -3,6 +3,7 @@ package websocket
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -59,6 +60,18 @@ type Client struct {
 	TenantID uuid.UUID `json:"tenant_id"`
 	Role     string    `json:"role"`
 
+	// DoNotDisturbUntil is shown to other users in presence
+	DoNotDisturbUntil *time.Time `json:"do_not_disturb_until,omitempty"`
+
+	// The authenticated user, for authorising subscriptions
+	user *models.User
+
+	// Subscribed topics, guarded by the room's lock once registered
+	topics map[string]bool
+
+	// Checks subscriptions; nil allows only the user topic
+	authorize Authorizer
+
 	// Connection metadata
 	IPAddress string    `json:"ip_address"`
 	UserAgent string    `json:"user_agent"`
@@ -70,13 +83,17 @@ type Client struct {
 
 // ClientInfo represents client information sent to other clients
 type ClientInfo struct {
-	UserID      uuid.UUID `json:"user_id"`
-	UserName    string    `json:"user_name"`
-	ConnectedAt time.Time `json:"connected_at"`
+	UserID            uuid.UUID  `json:"user_id"`
+	UserName          string     `json:"user_name"`
+	ConnectedAt       time.Time  `json:"connected_at"`
+	DoNotDisturb      bool       `json:"do_not_disturb"`
+	DoNotDisturbUntil *time.Time `json:"do_not_disturb_until,omitempty"`
 }
 
-// HandleWebSocket handles WebSocket upgrade and client management
-func HandleWebSocket(hub *Hub, logger *logger.Logger) gin.HandlerFunc {
+// HandleWebSocket handles WebSocket upgrade and client management. Clients
+// start subscribed to their user topic and subscribe to others with
+// subscribe messages, which authorize checks.
+func HandleWebSocket(hub *Hub, logger *logger.Logger, authorize Authorizer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get user information from auth middleware
 		userID, exists := c.Get("user_id")
@@ -119,17 +136,21 @@ func HandleWebSocket(hub *Hub, logger *logger.Logger) gin.HandlerFunc {
 
 		// Create client
 		client := &Client{
-			conn:        conn,
-			send:        make(chan []byte, 256),
-			hub:         hub,
-			UserID:      uid,
-			UserName:    getUsername(user), // Helper function to extract username
-			TenantID:    tid,
-			Role:        getRole(user),      // Helper function to extract role
-			IPAddress:   c.ClientIP(),
-			UserAgent:   c.Request.UserAgent(),
-			ConnectedAt: time.Now(),
-			logger:      logger,
+			conn:              conn,
+			send:              make(chan []byte, 256),
+			hub:               hub,
+			UserID:            uid,
+			UserName:          getUsername(user), // Helper function to extract username
+			TenantID:          tid,
+			Role:              getRole(user),      // Helper function to extract role
+			DoNotDisturbUntil: getDoNotDisturbUntil(user),
+			user:              getUser(user),
+			topics:            map[string]bool{UserTopic(uid): true},
+			authorize:         authorize,
+			IPAddress:         c.ClientIP(),
+			UserAgent:         c.Request.UserAgent(),
+			ConnectedAt:       time.Now(),
+			logger:            logger,
 		}
 
 		// Register client with hub
@@ -179,6 +200,7 @@ func (c *Client) readPump() {
 		incomingMessage.TenantID = c.TenantID
 		incomingMessage.Timestamp = time.Now().Unix()
 		incomingMessage.MessageID = uuid.New().String()
+		incomingMessage.Seq = 0
 
 		// Handle different message types
 		c.handleMessage(&incomingMessage)
@@ -234,38 +256,140 @@ func (c *Client) handleMessage(message *Message) {
 	switch message.Type {
 	case MessageTypePing:
 		c.handlePing()
+	case MessageTypeSubscribe:
+		c.handleSubscribe(message)
+	case MessageTypeUnsubscribe:
+		c.handleUnsubscribe(message)
 	case MessageTypeTyping:
 		c.handleTyping(message)
 	default:
 		c.logger.WithField("message_type", message.Type).
 			Debug("Received WebSocket message")
-		
-		// Broadcast the message to the tenant room
-		c.hub.broadcast <- message
+
+		c.publish(message)
 	}
 }
 
-// handlePing responds to ping messages
-func (c *Client) handlePing() {
-	pongMessage := &Message{
-		Type:      MessageTypePong,
-		UserID:    c.UserID,
-		TenantID:  c.TenantID,
-		Timestamp: time.Now().Unix(),
-		MessageID: uuid.New().String(),
-	}
+// topicsRequest is the data of subscribe and unsubscribe messages
+type topicsRequest struct {
+	Topics []string `json:"topics"`
+}
 
-	messageBytes, err := json.Marshal(pongMessage)
+// topicError is a topic the client could not subscribe to
+type topicError struct {
+	Topic string `json:"topic"`
+	Error string `json:"error"`
+}
+
+// parseTopics reads the topics of a subscribe or unsubscribe message
+func parseTopics(message *Message) ([]string, error) {
+	data, err := json.Marshal(message.Data)
 	if err != nil {
-		c.logger.WithError(err).Error("Failed to marshal pong message")
+		return nil, err
+	}
+	var request topicsRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, err
+	}
+	return request.Topics, nil
+}
+
+// handleSubscribe subscribes the client to the topics it may see and
+// replies with those granted and those denied
+func (c *Client) handleSubscribe(message *Message) {
+	topics, err := parseTopics(message)
+	if err != nil || len(topics) == 0 {
+		c.sendError("Subscribe requires a list of topics")
 		return
 	}
 
-	select {
-	case c.send <- messageBytes:
-	default:
-		close(c.send)
+	granted := make([]string, 0, len(topics))
+	denied := make([]topicError, 0)
+	for _, topic := range topics {
+		normalized, err := NormalizeTopic(topic)
+		if err == nil {
+			err = c.authorizeTopic(normalized)
+		}
+		if err != nil {
+			denied = append(denied, topicError{Topic: topic, Error: err.Error()})
+			continue
+		}
+		granted = append(granted, normalized)
+	}
+	if len(granted) > 0 {
+		c.hub.subscribe(c, granted)
+	}
+
+	c.reply(MessageTypeSubscribed, map[string]interface{}{
+		"topics": granted,
+		"denied": denied,
+	})
+}
+
+// handleUnsubscribe unsubscribes the client from topics. The user topic is
+// kept.
+func (c *Client) handleUnsubscribe(message *Message) {
+	topics, err := parseTopics(message)
+	if err != nil || len(topics) == 0 {
+		c.sendError("Unsubscribe requires a list of topics")
+		return
+	}
+
+	removed := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		normalized, err := NormalizeTopic(topic)
+		if err != nil || normalized == UserTopic(c.UserID) {
+			continue
+		}
+		removed = append(removed, normalized)
+	}
+	if len(removed) > 0 {
+		c.hub.unsubscribe(c, removed)
 	}
+
+	c.reply(MessageTypeUnsubscribed, map[string]interface{}{
+		"topics": removed,
+	})
+}
+
+// authorizeTopic checks that the client may subscribe to a topic
+func (c *Client) authorizeTopic(topic string) error {
+	if topic == UserTopic(c.UserID) {
+		return nil
+	}
+	if c.authorize == nil || c.user == nil {
+		return errors.New("access denied")
+	}
+	return c.authorize(c.user, topic)
+}
+
+// publish sends a message from the client on the topics it names, or the
+// tenant topic when it names none, limited to those it is subscribed to
+func (c *Client) publish(message *Message) {
+	requested := message.Topics
+	if len(requested) == 0 {
+		requested = []string{TopicTenant}
+	}
+
+	subscribed := c.hub.subscriptions(c)
+	topics := make([]string, 0, len(requested))
+	for _, topic := range requested {
+		if normalized, err := NormalizeTopic(topic); err == nil && subscribed[normalized] {
+			topics = append(topics, normalized)
+		}
+	}
+	if len(topics) == 0 {
+		c.sendError("Not subscribed to the message's topics")
+		return
+	}
+
+	message.Topics = topics
+	c.hub.broadcast <- message
+}
+
+// handlePing responds to ping messages
+func (c *Client) handlePing() {
+	c.reply(MessageTypePong, nil)
 }
 
 // handleTyping handles typing indicator messages
@@ -275,43 +399,41 @@ func (c *Client) handleTyping(message *Message) {
 	message.UserID = c.UserID
 	message.TenantID = c.TenantID
 	message.Timestamp = time.Now().Unix()
-	
-	c.hub.broadcast <- message
+
+	c.publish(message)
 }
 
 // sendError sends an error message to the client
 func (c *Client) sendError(errorMsg string) {
-	errorMessage := &Message{
-		Type:      MessageTypeError,
+	c.reply(MessageTypeError, map[string]interface{}{
+		"error": errorMsg,
+	})
+}
+
+// reply sends a message to this client only
+func (c *Client) reply(messageType MessageType, data interface{}) {
+	c.hub.reply(c, &Message{
+		Type:      messageType,
 		UserID:    c.UserID,
 		TenantID:  c.TenantID,
 		Timestamp: time.Now().Unix(),
 		MessageID: uuid.New().String(),
-		Data: map[string]interface{}{
-			"error": errorMsg,
-		},
-	}
-
-	messageBytes, err := json.Marshal(errorMessage)
-	if err != nil {
-		c.logger.WithError(err).Error("Failed to marshal error message")
-		return
-	}
-
-	select {
-	case c.send <- messageBytes:
-	default:
-		close(c.send)
-	}
+		Data:      data,
+	})
 }
 
 // GetInfo returns client information
 func (c *Client) GetInfo() ClientInfo {
-	return ClientInfo{
+	info := ClientInfo{
 		UserID:      c.UserID,
 		UserName:    c.UserName,
 		ConnectedAt: c.ConnectedAt,
 	}
+	if c.DoNotDisturbUntil != nil && time.Now().Before(*c.DoNotDisturbUntil) {
+		info.DoNotDisturb = true
+		info.DoNotDisturbUntil = c.DoNotDisturbUntil
+	}
+	return info
 }
 
 // Helper functions
@@ -328,4 +450,16 @@ func getRole(user interface{}) string {
 		return string(u.Role)
 	}
 	return "user"
+}
+
+func getUser(user interface{}) *models.User {
+	u, _ := user.(*models.User)
+	return u
+}
+
+func getDoNotDisturbUntil(user interface{}) *time.Time {
+	if u, ok := user.(*models.User); ok && u.DoNotDisturb(time.Now()) {
+		return u.DoNotDisturbUntil
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,391 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/drazan344/taskflow-go/internal/database"
+)
+
+const (
+	// clusterBuffer is how many messages can wait to be relayed to other
+	// nodes
+	clusterBuffer = 1024
+
+	// clusterTimeout bounds the Redis calls made while answering presence
+	// queries
+	clusterTimeout = 2 * time.Second
+)
+
+// ClusterOptions configure how the hubs of several API nodes share their
+// messages and presence through Redis
+type ClusterOptions struct {
+	// Prefix namespaces the Redis channel and keys of the cluster
+	Prefix string
+	// NodeID identifies this node; messages it relays are not delivered
+	// back to it
+	NodeID string
+	// PresenceTTL is how long a node's connections are listed after its
+	// last heartbeat
+	PresenceTTL time.Duration
+}
+
+// cluster relays messages between nodes and keeps presence in Redis
+type cluster struct {
+	redis  *database.Redis
+	opts   ClusterOptions
+	relay  chan *envelope
+	dirty  chan uuid.UUID // tenants whose presence on this node changed
+	done   <-chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// envelope carries a message, a do-not-disturb change or a revocation of
+// access between nodes
+type envelope struct {
+	Node         string           `json:"node"`
+	Message      *Message         `json:"message,omitempty"`
+	DoNotDisturb *doNotDisturb    `json:"do_not_disturb,omitempty"`
+	Reauthorize  *reauthorization `json:"reauthorize,omitempty"`
+}
+
+// doNotDisturb is a user's new do-not-disturb, applied to their connections
+// on every node
+type doNotDisturb struct {
+	TenantID uuid.UUID  `json:"tenant_id"`
+	UserID   uuid.UUID  `json:"user_id"`
+	Until    *time.Time `json:"until,omitempty"`
+}
+
+// reauthorization asks every node to check subscriptions again; see
+// Hub.Reauthorize
+type reauthorization struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Topic    string    `json:"topic,omitempty"`
+}
+
+// StartCluster relays the hub's messages to the hubs of other nodes through
+// Redis pub/sub, and keeps the hub's connections in Redis with a heartbeat,
+// so broadcasts reach clients on every node and presence covers the whole
+// cluster. It must be called before Run; StopCluster stops it.
+func (h *Hub) StartCluster(ctx context.Context, rdb *database.Redis, opts ClusterOptions) error {
+	if opts.Prefix == "" {
+		opts.Prefix = "taskflow:ws"
+	}
+	if opts.NodeID == "" {
+		host, _ := os.Hostname()
+		opts.NodeID = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
+	}
+	if opts.PresenceTTL <= 0 {
+		opts.PresenceTTL = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	c := &cluster{
+		redis:  rdb,
+		opts:   opts,
+		relay:  make(chan *envelope, clusterBuffer),
+		dirty:  make(chan uuid.UUID, clusterBuffer),
+		done:   ctx.Done(),
+		cancel: cancel,
+	}
+
+	// Wait until subscribed, so messages relayed once this returns arrive
+	pubsub := rdb.Subscribe(ctx, c.channel())
+	if _, err := pubsub.Receive(ctx); err != nil {
+		cancel()
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to %s: %w", c.channel(), err)
+	}
+
+	h.cluster = c
+	c.wg.Add(2)
+	go h.receive(ctx, pubsub)
+	go h.publish(ctx)
+
+	h.logger.WithField("node_id", opts.NodeID).Info("WebSocket hub joined cluster")
+	return nil
+}
+
+// StopCluster stops relaying messages and removes this node's connections
+// from the cluster's presence
+func (h *Hub) StopCluster() {
+	if h.cluster == nil {
+		return
+	}
+	h.cluster.cancel()
+	h.cluster.wg.Wait()
+}
+
+// nextSeqScript increments the cluster's message counter, raising it to the
+// clock in microseconds first, so numbers keep increasing across restarts
+// and stay above those a node handed out while Redis was unreachable
+var nextSeqScript = redis.NewScript(`
+local seq = redis.call('INCR', KEYS[1])
+local now = tonumber(ARGV[1])
+if seq < now then
+	redis.call('SET', KEYS[1], ARGV[1])
+	seq = now
+end
+return seq
+`)
+
+// nextSeq returns the next sequence number of the cluster's messages
+func (c *cluster) nextSeq() (uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	seq, err := nextSeqScript.Run(ctx, c.redis.Client, []string{c.seqKey()}, time.Now().UnixMicro()).Uint64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to number message: %w", err)
+	}
+	return seq, nil
+}
+
+// channel is the pub/sub channel messages are relayed on
+func (c *cluster) channel() string {
+	return c.opts.Prefix + ":relay"
+}
+
+// presenceKey is the set of nodes with connections of a tenant
+func (c *cluster) presenceKey(tenantID uuid.UUID) string {
+	return fmt.Sprintf("%s:presence:%s", c.opts.Prefix, tenantID)
+}
+
+// nodeKey holds a node's connections of a tenant
+func (c *cluster) nodeKey(tenantID uuid.UUID, node string) string {
+	return c.presenceKey(tenantID) + ":" + node
+}
+
+// seqKey is the counter the messages of every node are numbered from
+func (c *cluster) seqKey() string {
+	return c.opts.Prefix + ":seq"
+}
+
+// relay queues an envelope for the other nodes
+func (h *Hub) relay(env *envelope) {
+	c := h.cluster
+	if c == nil {
+		return
+	}
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	env.Node = c.opts.NodeID
+	select {
+	case c.relay <- env:
+	default:
+		h.logger.WithField("node_id", c.opts.NodeID).
+			Warn("Cluster relay channel is full")
+	}
+}
+
+// relayMessage queues a message for the other nodes
+func (h *Hub) relayMessage(message *Message) {
+	h.relay(&envelope{Message: message})
+}
+
+// presenceChanged marks a tenant's presence on this node for writing to
+// Redis. When too many are waiting, the next heartbeat writes it.
+func (h *Hub) presenceChanged(tenantID uuid.UUID) {
+	if h.cluster == nil {
+		return
+	}
+	select {
+	case h.cluster.dirty <- tenantID:
+	default:
+	}
+}
+
+// receive delivers the messages relayed by other nodes to the clients on
+// this node
+func (h *Hub) receive(ctx context.Context, pubsub *redis.PubSub) {
+	defer h.cluster.wg.Done()
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			h.deliver(msg.Payload)
+		}
+	}
+}
+
+// deliver handles one envelope from the relay channel
+func (h *Hub) deliver(payload string) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		h.logger.WithError(err).Warn("Failed to decode relayed WebSocket message")
+		return
+	}
+	if env.Node == h.cluster.opts.NodeID {
+		return // relayed by this node
+	}
+
+	switch {
+	case env.DoNotDisturb != nil:
+		h.setLocalDoNotDisturb(env.DoNotDisturb.TenantID, env.DoNotDisturb.UserID, env.DoNotDisturb.Until)
+	case env.Reauthorize != nil:
+		// Checked before delivering what follows, which the revoked topics
+		// must no longer receive
+		h.reauthorize(env.Reauthorize.TenantID, env.Reauthorize.UserID, env.Reauthorize.Topic)
+	case env.Message != nil:
+		if env.Message.Seq != 0 {
+			h.history.addNumbered(env.Message)
+		}
+		h.sendToRoom(env.Message)
+	}
+}
+
+// publish relays queued messages to the other nodes and writes this node's
+// presence to Redis when it changes and on every heartbeat
+func (h *Hub) publish(ctx context.Context) {
+	c := h.cluster
+	defer c.wg.Done()
+
+	heartbeat := time.NewTicker(c.opts.PresenceTTL / 3)
+	defer heartbeat.Stop()
+
+	// Tenants this node has listed connections for
+	listed := make(map[uuid.UUID]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Leave the cluster's presence now rather than when it expires
+			clearCtx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+			for tenantID := range listed {
+				c.clearPresence(clearCtx, tenantID)
+			}
+			cancel()
+			return
+
+		case env := <-c.relay:
+			data, err := json.Marshal(env)
+			if err != nil {
+				h.logger.WithError(err).Error("Failed to encode WebSocket message for relay")
+				continue
+			}
+			if err := c.redis.Publish(ctx, c.channel(), data); err != nil {
+				h.logger.WithError(err).Warn("Failed to relay WebSocket message")
+			}
+
+		case tenantID := <-c.dirty:
+			h.writePresence(ctx, tenantID, listed)
+
+		case <-heartbeat.C:
+			for _, tenantID := range h.localTenants() {
+				listed[tenantID] = true
+			}
+			for tenantID := range listed {
+				h.writePresence(ctx, tenantID, listed)
+			}
+		}
+	}
+}
+
+// writePresence lists this node's connections of a tenant in Redis, or
+// removes the listing when there are none
+func (h *Hub) writePresence(ctx context.Context, tenantID uuid.UUID, listed map[uuid.UUID]bool) {
+	c := h.cluster
+	infos := h.localConnections(tenantID)
+	if len(infos) == 0 {
+		if listed[tenantID] {
+			c.clearPresence(ctx, tenantID)
+			delete(listed, tenantID)
+		}
+		return
+	}
+
+	data, err := json.Marshal(infos)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to encode WebSocket presence")
+		return
+	}
+	pipe := c.redis.Client.TxPipeline()
+	pipe.Set(ctx, c.nodeKey(tenantID, c.opts.NodeID), data, c.opts.PresenceTTL)
+	pipe.SAdd(ctx, c.presenceKey(tenantID), c.opts.NodeID)
+	pipe.Expire(ctx, c.presenceKey(tenantID), c.opts.PresenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		h.logger.WithError(err).WithField("tenant_id", tenantID).Warn("Failed to write WebSocket presence")
+		return
+	}
+	listed[tenantID] = true
+}
+
+// clearPresence removes this node's connections of a tenant from Redis
+func (c *cluster) clearPresence(ctx context.Context, tenantID uuid.UUID) {
+	pipe := c.redis.Client.TxPipeline()
+	pipe.Del(ctx, c.nodeKey(tenantID, c.opts.NodeID))
+	pipe.SRem(ctx, c.presenceKey(tenantID), c.opts.NodeID)
+	pipe.Exec(ctx)
+}
+
+// remoteConnections returns the connections of a tenant on the other nodes
+func (h *Hub) remoteConnections(tenantID uuid.UUID) ([]ClientInfo, error) {
+	c := h.cluster
+	ctx, cancel := context.WithTimeout(context.Background(), clusterTimeout)
+	defer cancel()
+
+	nodes, err := c.redis.Client.SMembers(ctx, c.presenceKey(tenantID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster nodes: %w", err)
+	}
+	var others, keys []string
+	for _, node := range nodes {
+		if node != c.opts.NodeID {
+			others = append(others, node)
+			keys = append(keys, c.nodeKey(tenantID, node))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := c.redis.Client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load cluster presence: %w", err)
+	}
+	now := time.Now()
+	var infos, nodeInfos []ClientInfo
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			// The node stopped sending heartbeats
+			c.redis.Client.SRem(ctx, c.presenceKey(tenantID), others[i])
+			continue
+		}
+		nodeInfos = nodeInfos[:0]
+		if err := json.Unmarshal([]byte(data), &nodeInfos); err != nil {
+			h.logger.WithError(err).WithField("node_id", others[i]).Warn("Failed to decode WebSocket presence")
+			continue
+		}
+		for _, info := range nodeInfos {
+			// Do-not-disturb may have ended since the node wrote it
+			info.DoNotDisturb = info.DoNotDisturbUntil != nil && now.Before(*info.DoNotDisturbUntil)
+			if !info.DoNotDisturb {
+				info.DoNotDisturbUntil = nil
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
@@ -0,0 +1,207 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/drazan344/taskflow-go/internal/database"
+	"github.com/drazan344/taskflow-go/pkg/logger"
+)
+
+const testPresenceTTL = 30 * time.Second
+
+// newClusterHub starts a hub that joins the cluster on the given Redis as
+// node. Heartbeats are far apart, so presence only changes when clients
+// connect or when the test moves the Redis clock.
+func newClusterHub(t *testing.T, server *miniredis.Miniredis, node string) *Hub {
+	t.Helper()
+
+	rdb := &database.Redis{Client: redis.NewClient(&redis.Options{Addr: server.Addr()})}
+	t.Cleanup(func() { rdb.Client.Close() })
+
+	hub := NewHub(logger.New("error", "json"))
+	go hub.Run()
+	require.NoError(t, hub.StartCluster(context.Background(), rdb, ClusterOptions{
+		Prefix:      "test:ws",
+		NodeID:      node,
+		PresenceTTL: testPresenceTTL,
+	}))
+	t.Cleanup(hub.StopCluster)
+	return hub
+}
+
+// connect registers a client of a user, subscribed to the tenant and user
+// topics, without a network connection. It returns once the hub announced
+// the client.
+func connect(t *testing.T, hub *Hub, tenantID, userID uuid.UUID) *Client {
+	t.Helper()
+
+	client := &Client{
+		send:        make(chan []byte, 64),
+		hub:         hub,
+		UserID:      userID,
+		UserName:    "User " + userID.String()[:8],
+		TenantID:    tenantID,
+		topics:      map[string]bool{TopicTenant: true, UserTopic(userID): true},
+		ConnectedAt: time.Now(),
+		logger:      hub.logger,
+	}
+	hub.register <- client
+	receive(t, client, MessageTypeUserJoined)
+	return client
+}
+
+// receive returns the next message of a type the client is sent
+func receive(t *testing.T, client *Client, messageType MessageType) *Message {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case data := <-client.send:
+			var message Message
+			require.NoError(t, json.Unmarshal(data, &message))
+			if message.Type == messageType {
+				return &message
+			}
+		case <-timeout:
+			t.Fatalf("no %s message received", messageType)
+			return nil
+		}
+	}
+}
+
+// assertNothingReceived checks that the client is sent no message of a type
+// for a while
+func assertNothingReceived(t *testing.T, client *Client, messageType MessageType) {
+	t.Helper()
+
+	timeout := time.After(200 * time.Millisecond)
+	for {
+		select {
+		case data := <-client.send:
+			var message Message
+			require.NoError(t, json.Unmarshal(data, &message))
+			assert.NotEqual(t, messageType, message.Type, "unexpected %s message", messageType)
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestClusterFanOut(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newClusterHub(t, server, "node-1")
+	second := newClusterHub(t, server, "node-2")
+
+	tenantID := uuid.New()
+	local := connect(t, first, tenantID, uuid.New())
+	remote := connect(t, second, tenantID, uuid.New())
+	other := connect(t, second, uuid.New(), uuid.New())
+
+	first.BroadcastToTenant(tenantID, MessageTypeNotification, map[string]string{"text": "hello"})
+
+	message := receive(t, remote, MessageTypeNotification)
+	assert.Equal(t, tenantID, message.TenantID)
+	assert.Equal(t, map[string]interface{}{"text": "hello"}, message.Data)
+	receive(t, local, MessageTypeNotification)
+	assertNothingReceived(t, other, MessageTypeNotification)
+
+	// Messages to a user reach their connections on every node
+	second.BroadcastToUser(tenantID, local.UserID, MessageTypeNotification, map[string]string{"text": "for you"})
+
+	message = receive(t, local, MessageTypeNotification)
+	assert.Equal(t, map[string]interface{}{"text": "for you"}, message.Data)
+	assertNothingReceived(t, remote, MessageTypeNotification)
+}
+
+func TestClusterDoesNotEchoToSender(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newClusterHub(t, server, "node-1")
+	newClusterHub(t, server, "node-2")
+
+	tenantID := uuid.New()
+	local := connect(t, first, tenantID, uuid.New())
+
+	first.BroadcastToTenant(tenantID, MessageTypeNotification, map[string]string{"text": "once"})
+
+	receive(t, local, MessageTypeNotification)
+	// The relayed copy comes back on the channel but is not delivered again
+	assertNothingReceived(t, local, MessageTypeNotification)
+}
+
+func TestClusterPresence(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newClusterHub(t, server, "node-1")
+	second := newClusterHub(t, server, "node-2")
+
+	tenantID := uuid.New()
+	local := connect(t, first, tenantID, uuid.New())
+	remote := connect(t, second, tenantID, uuid.New())
+
+	assert.Eventually(t, func() bool {
+		return len(first.GetOnlineUsers(tenantID)) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []uuid.UUID{local.UserID, remote.UserID}, first.GetOnlineUsers(tenantID))
+
+	// A node that stops sending heartbeats drops out once its presence
+	// expires
+	server.FastForward(testPresenceTTL + time.Second)
+
+	assert.Equal(t, []uuid.UUID{local.UserID}, first.GetOnlineUsers(tenantID))
+	assert.Equal(t, []uuid.UUID{remote.UserID}, second.GetOnlineUsers(tenantID))
+}
+
+func TestClusterPresenceClearedOnStop(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newClusterHub(t, server, "node-1")
+	second := newClusterHub(t, server, "node-2")
+
+	tenantID := uuid.New()
+	connect(t, second, tenantID, uuid.New())
+
+	assert.Eventually(t, func() bool {
+		return first.GetClientCount(tenantID) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	second.StopCluster()
+
+	assert.Equal(t, 0, first.GetClientCount(tenantID))
+}
+
+func TestClusterNumbersMessagesFromOneCounter(t *testing.T) {
+	server := miniredis.RunT(t)
+	first := newClusterHub(t, server, "node-1")
+	second := newClusterHub(t, server, "node-2")
+
+	tenantID := uuid.New()
+	client := connect(t, first, tenantID, uuid.New())
+
+	// Messages sent one after the other on different nodes are numbered in
+	// that order, whatever the clocks of the nodes say
+	first.BroadcastToTenant(tenantID, MessageTypeNotification, map[string]string{"text": "first"})
+	firstSeq := receive(t, client, MessageTypeNotification).Seq
+	second.BroadcastToTenant(tenantID, MessageTypeNotification, map[string]string{"text": "second"})
+	secondSeq := receive(t, client, MessageTypeNotification).Seq
+
+	assert.NotZero(t, firstSeq)
+	assert.Greater(t, secondSeq, firstSeq)
+	counter, err := server.Get("test:ws:seq")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(secondSeq, 10), counter)
+
+	// The relayed message is kept for streams that resume on this node
+	missed, complete := first.history.since(tenantID, map[string]bool{TopicTenant: true}, firstSeq)
+	require.True(t, complete)
+	require.Len(t, missed, 1)
+	assert.Equal(t, secondSeq, missed[0].Seq)
+}
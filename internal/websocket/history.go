@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// historySize is how many recent messages are kept for streams that
+	// reconnect
+	historySize = 1000
+
+	// historyTTL is how long a message is kept for streams that reconnect
+	historyTTL = 5 * time.Minute
+)
+
+// record is a message kept in the history
+type record struct {
+	message *Message
+	at      time.Time
+}
+
+// history numbers messages and keeps the most recent ones, so a stream that
+// reconnects can receive what it missed. Sequence numbers follow the clock
+// in microseconds and never go below a number already seen, so they keep
+// increasing across restarts. In a cluster, messages are numbered by the
+// cluster's counter instead and kept with addNumbered.
+type history struct {
+	mu      sync.Mutex
+	seq     uint64
+	records []record
+}
+
+// newHistory creates an empty history
+func newHistory() *history {
+	return &history{}
+}
+
+// add numbers a message and keeps it
+func (h *history) add(message *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	if now := uint64(time.Now().UnixMicro()); now > h.seq {
+		h.seq = now
+	}
+	message.Seq = h.seq
+	h.keep(message)
+}
+
+// addNumbered keeps a message numbered by the cluster's counter
+func (h *history) addNumbered(message *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if message.Seq > h.seq {
+		h.seq = message.Seq
+	}
+	h.keep(message)
+}
+
+// keep appends a record, dropping the oldest beyond historySize. The caller
+// holds the lock.
+func (h *history) keep(message *Message) {
+	h.records = append(h.records, record{message: message, at: time.Now()})
+	if len(h.records) > historySize {
+		h.records = h.records[len(h.records)-historySize:]
+	}
+}
+
+// since returns the kept messages of a tenant after seq that were sent on
+// any of the topics, in order. It reports false when messages after seq may
+// no longer be kept, or seq was not handed out by this history, so some may
+// be missing.
+func (h *history) since(tenantID uuid.UUID, topics map[string]bool, seq uint64) ([]*Message, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if seq > h.seq {
+		return nil, false
+	}
+
+	cutoff := time.Now().Add(-historyTTL)
+	// Nothing is missing when seq is the last number handed out, or a
+	// message up to seq is still kept
+	complete := seq == h.seq
+	var messages []*Message
+	for _, r := range h.records {
+		if r.at.Before(cutoff) {
+			continue
+		}
+		if r.message.Seq <= seq {
+			complete = true
+			continue
+		}
+		if r.message.TenantID != tenantID {
+			continue
+		}
+		for _, topic := range r.message.Topics {
+			if topics[topic] {
+				messages = append(messages, r.message)
+				break
+			}
+		}
+	}
+	if !complete {
+		return nil, false
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i].Seq < messages[j].Seq })
+	return messages, true
+}
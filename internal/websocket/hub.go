@@ -17,13 +17,21 @@ const (
 	MessageTypeTaskUpdate      MessageType = "task_update"
 	MessageTypeTaskCreate      MessageType = "task_create"
 	MessageTypeTaskDelete      MessageType = "task_delete"
+	MessageTypeChecklist       MessageType = "checklist_update"
+	MessageTypeComment         MessageType = "comment_added"
+	MessageTypeProjectUpdate   MessageType = "project_update"
 	MessageTypeNotification    MessageType = "notification"
 	MessageTypeUserJoined      MessageType = "user_joined"
 	MessageTypeUserLeft        MessageType = "user_left"
+	MessageTypePresence        MessageType = "presence"
 	MessageTypeTyping          MessageType = "typing"
 	MessageTypePing            MessageType = "ping"
 	MessageTypePong            MessageType = "pong"
 	MessageTypeError           MessageType = "error"
+	MessageTypeSubscribe       MessageType = "subscribe"
+	MessageTypeUnsubscribe     MessageType = "unsubscribe"
+	MessageTypeSubscribed      MessageType = "subscribed"
+	MessageTypeUnsubscribed    MessageType = "unsubscribed"
 )
 
 // Message represents a WebSocket message
@@ -35,6 +43,13 @@ type Message struct {
 	Timestamp int64                  `json:"timestamp"`
 	MessageID string                 `json:"message_id"`
 	Meta      map[string]interface{} `json:"meta,omitempty"`
+
+	// Topics the message is sent on; see ParseTopic
+	Topics []string `json:"topics,omitempty"`
+
+	// Seq orders the messages of the hub, so a stream can resume after the
+	// last message it received
+	Seq uint64 `json:"seq,omitempty"`
 }
 
 // Hub maintains the set of active clients and broadcasts messages to the clients
@@ -51,6 +66,12 @@ type Hub struct {
 	// Inbound messages from the clients
 	broadcast chan *Message
 
+	// Recent messages for streams that reconnect
+	history *history
+
+	// Hubs of other nodes, when running as a cluster
+	cluster *cluster
+
 	// Logger
 	logger *logger.Logger
 
@@ -66,6 +87,9 @@ type TenantRoom struct {
 	// Connected clients in this tenant
 	clients map[*Client]bool
 
+	// Subscribed clients by topic
+	topics map[string]map[*Client]bool
+
 	// Broadcast channel for this tenant
 	broadcast chan *Message
 
@@ -80,6 +104,7 @@ func NewHub(logger *logger.Logger) *Hub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan *Message),
+		history:    newHistory(),
 		logger:     logger,
 	}
 }
@@ -111,6 +136,7 @@ func (h *Hub) registerClient(client *Client) {
 		room = &TenantRoom{
 			TenantID:  client.TenantID,
 			clients:   make(map[*Client]bool),
+			topics:    make(map[string]map[*Client]bool),
 			broadcast: make(chan *Message, 256),
 		}
 		h.tenants[client.TenantID] = room
@@ -121,7 +147,7 @@ func (h *Hub) registerClient(client *Client) {
 
 	// Add client to room
 	room.mu.Lock()
-	room.clients[client] = true
+	room.add(client)
 	room.mu.Unlock()
 
 	h.logger.WithFields(map[string]interface{}{
@@ -137,12 +163,13 @@ func (h *Hub) registerClient(client *Client) {
 		TenantID:  client.TenantID,
 		Timestamp: getCurrentTimestamp(),
 		MessageID: generateMessageID(),
-		Data: map[string]interface{}{
-			"user_id": client.UserID,
-			"user_name": client.UserName,
-		},
+		Data: client.GetInfo(),
+		Topics: []string{TopicTenant},
 	}
-	
+	h.number(joinMessage)
+	h.relayMessage(joinMessage)
+	h.presenceChanged(client.TenantID)
+
 	room.broadcast <- joinMessage
 }
 
@@ -160,8 +187,7 @@ func (h *Hub) unregisterClient(client *Client) {
 	defer room.mu.Unlock()
 
 	if _, ok := room.clients[client]; ok {
-		delete(room.clients, client)
-		close(client.send)
+		room.remove(client)
 
 		h.logger.WithFields(map[string]interface{}{
 			"user_id":   client.UserID,
@@ -180,8 +206,12 @@ func (h *Hub) unregisterClient(client *Client) {
 				"user_id": client.UserID,
 				"user_name": client.UserName,
 			},
+			Topics: []string{TopicTenant},
 		}
-		
+		h.number(leaveMessage)
+		h.relayMessage(leaveMessage)
+		h.presenceChanged(client.TenantID)
+
 		room.broadcast <- leaveMessage
 
 		// If no clients left, clean up the room
@@ -191,15 +221,21 @@ func (h *Hub) unregisterClient(client *Client) {
 	}
 }
 
-// broadcastMessage broadcasts a message to all clients in the tenant
+// broadcastMessage broadcasts a message from a client to the clients
+// subscribed to its topics
 func (h *Hub) broadcastMessage(message *Message) {
+	h.relayMessage(message)
+
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	room, exists := h.tenants[message.TenantID]
 	if !exists {
-		h.logger.WithField("tenant_id", message.TenantID).
-			Warn("Attempted to broadcast to non-existent tenant room")
+		// In a cluster the tenant's clients may all be on other nodes
+		if h.cluster == nil {
+			h.logger.WithField("tenant_id", message.TenantID).
+				Warn("Attempted to broadcast to non-existent tenant room")
+		}
 		return
 	}
 
@@ -213,130 +249,320 @@ func (h *Hub) broadcastMessage(message *Message) {
 	}
 }
 
-// BroadcastToTenant sends a message to all clients in a tenant
+// Broadcast sends a message to the clients of a tenant subscribed to any of
+// the topics. A client subscribed to several of them receives it once.
+func (h *Hub) Broadcast(tenantID uuid.UUID, topics []string, messageType MessageType, data interface{}) {
+	h.send(&Message{
+		Type:      messageType,
+		TenantID:  tenantID,
+		Timestamp: getCurrentTimestamp(),
+		MessageID: generateMessageID(),
+		Data:      data,
+		Topics:    topics,
+	})
+}
+
+// BroadcastToTenant sends a message to the clients subscribed to the tenant
+// topic
 func (h *Hub) BroadcastToTenant(tenantID uuid.UUID, messageType MessageType, data interface{}) {
-	message := &Message{
+	h.Broadcast(tenantID, []string{TopicTenant}, messageType, data)
+}
+
+// BroadcastToUser sends a message to a specific user (if online)
+func (h *Hub) BroadcastToUser(tenantID, userID uuid.UUID, messageType MessageType, data interface{}) {
+	h.send(&Message{
 		Type:      messageType,
+		UserID:    userID,
 		TenantID:  tenantID,
 		Timestamp: getCurrentTimestamp(),
 		MessageID: generateMessageID(),
 		Data:      data,
+		Topics:    []string{UserTopic(userID)},
+	})
+}
+
+// send numbers a message and delivers it on every node. It is kept even when
+// nobody is subscribed, for streams about to reconnect.
+func (h *Hub) send(message *Message) {
+	h.number(message)
+	h.relayMessage(message)
+	h.sendToRoom(message)
+}
+
+// number gives a message its sequence number and keeps it in the history.
+// In a cluster the numbers come from one counter in Redis, so every node
+// orders messages the same way; when Redis cannot be reached the node
+// numbers the message itself.
+func (h *Hub) number(message *Message) {
+	if h.cluster != nil {
+		seq, err := h.cluster.nextSeq()
+		if err == nil {
+			message.Seq = seq
+			h.history.addNumbered(message)
+			return
+		}
+		h.logger.WithError(err).Warn("Failed to number message from the cluster counter")
 	}
+	h.history.add(message)
+}
 
-	select {
-	case h.broadcast <- message:
-	default:
-		h.logger.WithField("tenant_id", tenantID).
-			Warn("Hub broadcast channel is full")
+// GetOnlineUsers returns a list of online users in a tenant
+func (h *Hub) GetOnlineUsers(tenantID uuid.UUID) []uuid.UUID {
+	userMap := make(map[uuid.UUID]bool)
+	for _, info := range h.connections(tenantID) {
+		userMap[info.UserID] = true
 	}
+
+	users := make([]uuid.UUID, 0, len(userMap))
+	for userID := range userMap {
+		users = append(users, userID)
+	}
+
+	return users
 }
 
-// BroadcastToUser sends a message to a specific user (if online)
-func (h *Hub) BroadcastToUser(tenantID, userID uuid.UUID, messageType MessageType, data interface{}) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// GetPresence returns the online users in a tenant with their
+// do-not-disturb status
+func (h *Hub) GetPresence(tenantID uuid.UUID) []ClientInfo {
+	// A user with several connections is listed once, as of the first
+	byUser := make(map[uuid.UUID]ClientInfo)
+	for _, info := range h.connections(tenantID) {
+		first, seen := byUser[info.UserID]
+		if !seen || info.ConnectedAt.Before(first.ConnectedAt) {
+			byUser[info.UserID] = info
+		}
+	}
 
-	room, exists := h.tenants[tenantID]
-	if !exists {
-		return
+	presence := make([]ClientInfo, 0, len(byUser))
+	for _, info := range byUser {
+		presence = append(presence, info)
 	}
+	return presence
+}
 
-	room.mu.RLock()
-	defer room.mu.RUnlock()
+// SetDoNotDisturb updates a user's do-not-disturb on their connections and
+// tells the tenant when the user is online. A nil until turns it off.
+func (h *Hub) SetDoNotDisturb(tenantID, userID uuid.UUID, until *time.Time) {
+	info := h.setLocalDoNotDisturb(tenantID, userID, until)
+	if h.cluster != nil {
+		h.relay(&envelope{DoNotDisturb: &doNotDisturb{TenantID: tenantID, UserID: userID, Until: until}})
+		if info == nil {
+			info = h.remoteInfo(tenantID, userID, until)
+		}
+	}
+	if info == nil {
+		return
+	}
 
 	message := &Message{
-		Type:      messageType,
+		Type:      MessageTypePresence,
 		UserID:    userID,
 		TenantID:  tenantID,
 		Timestamp: getCurrentTimestamp(),
 		MessageID: generateMessageID(),
-		Data:      data,
+		Data:      info,
+		Topics:    []string{TopicTenant},
 	}
+	h.send(message)
+}
 
-	messageBytes, err := json.Marshal(message)
-	if err != nil {
-		h.logger.WithError(err).Error("Failed to marshal message")
-		return
+// setLocalDoNotDisturb updates a user's do-not-disturb on their connections
+// on this node and returns their presence, or nil when they have none
+func (h *Hub) setLocalDoNotDisturb(tenantID, userID uuid.UUID, until *time.Time) *ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	room, exists := h.tenants[tenantID]
+	if !exists {
+		return nil
 	}
 
-	// Send to specific user's clients
+	room.mu.Lock()
+	defer room.mu.Unlock()
+
+	var info *ClientInfo
 	for client := range room.clients {
 		if client.UserID == userID {
-			select {
-			case client.send <- messageBytes:
-			default:
-				close(client.send)
-				delete(room.clients, client)
-			}
+			client.DoNotDisturbUntil = until
+			clientInfo := client.GetInfo()
+			info = &clientInfo
 		}
 	}
+	if info != nil {
+		h.presenceChanged(tenantID)
+	}
+	return info
 }
 
-// GetOnlineUsers returns a list of online users in a tenant
-func (h *Hub) GetOnlineUsers(tenantID uuid.UUID) []uuid.UUID {
+// remoteInfo returns the presence of a user connected only to other nodes,
+// with their new do-not-disturb, or nil when they are offline
+func (h *Hub) remoteInfo(tenantID, userID uuid.UUID, until *time.Time) *ClientInfo {
+	infos, err := h.remoteConnections(tenantID)
+	if err != nil {
+		h.logger.WithError(err).WithField("tenant_id", tenantID).Warn("Failed to load cluster presence")
+		return nil
+	}
+
+	var info *ClientInfo
+	for i := range infos {
+		if infos[i].UserID == userID && (info == nil || infos[i].ConnectedAt.Before(info.ConnectedAt)) {
+			info = &infos[i]
+		}
+	}
+	if info == nil {
+		return nil
+	}
+	info.DoNotDisturb = until != nil && time.Now().Before(*until)
+	info.DoNotDisturbUntil = nil
+	if info.DoNotDisturb {
+		info.DoNotDisturbUntil = until
+	}
+	return info
+}
+
+// sendToRoom queues a message for the clients of its tenant on this node
+func (h *Hub) sendToRoom(message *Message) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	room, exists := h.tenants[tenantID]
+	room, exists := h.tenants[message.TenantID]
 	if !exists {
-		return []uuid.UUID{}
+		return
 	}
+	select {
+	case room.broadcast <- message:
+	default:
+		h.logger.WithField("tenant_id", message.TenantID).
+			Warn("Tenant room broadcast channel is full")
+	}
+}
 
-	room.mu.RLock()
-	defer room.mu.RUnlock()
+// GetClientCount returns the number of connected clients for a tenant
+func (h *Hub) GetClientCount(tenantID uuid.UUID) int {
+	return len(h.connections(tenantID))
+}
 
-	userMap := make(map[uuid.UUID]bool)
-	for client := range room.clients {
-		userMap[client.UserID] = true
+// connections returns the connections of a tenant, on every node when
+// running as a cluster. If the other nodes cannot be reached, only this
+// node's connections are returned.
+func (h *Hub) connections(tenantID uuid.UUID) []ClientInfo {
+	infos := h.localConnections(tenantID)
+	if h.cluster == nil {
+		return infos
 	}
 
-	users := make([]uuid.UUID, 0, len(userMap))
-	for userID := range userMap {
-		users = append(users, userID)
+	remote, err := h.remoteConnections(tenantID)
+	if err != nil {
+		h.logger.WithError(err).WithField("tenant_id", tenantID).Warn("Failed to load cluster presence")
+		return infos
 	}
-
-	return users
+	return append(infos, remote...)
 }
 
-// GetClientCount returns the number of connected clients for a tenant
-func (h *Hub) GetClientCount(tenantID uuid.UUID) int {
+// localConnections returns the connections of a tenant on this node
+func (h *Hub) localConnections(tenantID uuid.UUID) []ClientInfo {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	room, exists := h.tenants[tenantID]
 	if !exists {
-		return 0
+		return nil
 	}
 
 	room.mu.RLock()
 	defer room.mu.RUnlock()
 
-	return len(room.clients)
+	infos := make([]ClientInfo, 0, len(room.clients))
+	for client := range room.clients {
+		infos = append(infos, client.GetInfo())
+	}
+	return infos
+}
+
+// localTenants returns the tenants with connections on this node
+func (h *Hub) localTenants() []uuid.UUID {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	tenants := make([]uuid.UUID, 0, len(h.tenants))
+	for tenantID := range h.tenants {
+		tenants = append(tenants, tenantID)
+	}
+	return tenants
 }
 
 // run handles broadcasting for a tenant room
 func (tr *TenantRoom) run() {
 	for message := range tr.broadcast {
-		tr.mu.RLock()
-		
 		messageBytes, err := json.Marshal(message)
 		if err != nil {
 			log.Printf("Error marshaling message: %v", err)
-			tr.mu.RUnlock()
 			continue
 		}
 
-		// Send message to all clients in this tenant room
-		for client := range tr.clients {
+		tr.mu.Lock()
+
+		// Send message to the clients subscribed to its topics, once each
+		recipients := make(map[*Client]bool)
+		for _, topic := range message.Topics {
+			for client := range tr.topics[topic] {
+				recipients[client] = true
+			}
+		}
+		for client := range recipients {
 			select {
 			case client.send <- messageBytes:
 			default:
-				close(client.send)
-				delete(tr.clients, client)
+				tr.remove(client)
 			}
 		}
-		
-		tr.mu.RUnlock()
+
+		tr.mu.Unlock()
+	}
+}
+
+// add adds a client to the room with the topics it subscribed to. The caller
+// holds the lock.
+func (tr *TenantRoom) add(client *Client) {
+	tr.clients[client] = true
+	for topic := range client.topics {
+		tr.subscribe(client, topic)
+	}
+}
+
+// remove drops a client from the room and closes its send channel. The
+// caller holds the lock.
+func (tr *TenantRoom) remove(client *Client) {
+	delete(tr.clients, client)
+	for topic := range client.topics {
+		tr.unindex(client, topic)
+	}
+	close(client.send)
+}
+
+// subscribe subscribes a client to a topic. The caller holds the lock.
+func (tr *TenantRoom) subscribe(client *Client, topic string) {
+	client.topics[topic] = true
+	subscribers, exists := tr.topics[topic]
+	if !exists {
+		subscribers = make(map[*Client]bool)
+		tr.topics[topic] = subscribers
+	}
+	subscribers[client] = true
+}
+
+// unsubscribe unsubscribes a client from a topic. The caller holds the lock.
+func (tr *TenantRoom) unsubscribe(client *Client, topic string) {
+	delete(client.topics, topic)
+	tr.unindex(client, topic)
+}
+
+// unindex removes a client from the index of a topic
+func (tr *TenantRoom) unindex(client *Client, topic string) {
+	subscribers := tr.topics[topic]
+	delete(subscribers, client)
+	if len(subscribers) == 0 {
+		delete(tr.topics, topic)
 	}
 }
 
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stream receives the messages a WebSocket connection of the user would, for
+// transports that cannot hold a WebSocket such as Server-Sent Events. A
+// stream is a client of the hub without a connection, so it shares the hub's
+// fan-out and shows in presence like any other connection.
+type Stream struct {
+	client *Client
+}
+
+// NewStream registers a stream for a user with the hub, subscribed to the
+// user topic and the given topics, which the caller has authorised. user is
+// the authenticated user as set by the auth middleware, and authorize
+// checks the topics again when access is revoked. Close must be called when
+// the stream ends.
+func (h *Hub) NewStream(tenantID, userID uuid.UUID, user interface{}, topics []string, authorize Authorizer, ipAddress, userAgent string) *Stream {
+	subscribed := map[string]bool{UserTopic(userID): true}
+	for _, topic := range topics {
+		subscribed[topic] = true
+	}
+
+	client := &Client{
+		send:              make(chan []byte, 256),
+		hub:               h,
+		UserID:            userID,
+		UserName:          getUsername(user),
+		TenantID:          tenantID,
+		Role:              getRole(user),
+		DoNotDisturbUntil: getDoNotDisturbUntil(user),
+		user:              getUser(user),
+		topics:            subscribed,
+		authorize:         authorize,
+		IPAddress:         ipAddress,
+		UserAgent:         userAgent,
+		ConnectedAt:       time.Now(),
+		logger:            h.logger,
+	}
+	h.register <- client
+
+	return &Stream{client: client}
+}
+
+// Messages returns the encoded messages for the stream. The channel is
+// closed when the hub drops the stream for falling behind.
+func (s *Stream) Messages() <-chan []byte {
+	return s.client.send
+}
+
+// Since returns the kept messages after seq on the topics the stream is
+// subscribed to, in order. It reports false when some may be missing.
+func (s *Stream) Since(seq uint64) ([]*Message, bool) {
+	return s.client.hub.history.since(s.client.TenantID, s.client.hub.subscriptions(s.client), seq)
+}
+
+// Close unregisters the stream from the hub
+func (s *Stream) Close() {
+	s.client.hub.unregister <- s.client
+}
@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// subscribe adds topics to a client's subscriptions. The caller has
+// authorised them.
+func (h *Hub) subscribe(client *Client, topics []string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	room, registered := h.room(client)
+	if !registered {
+		// Indexed when the hub registers the client
+		for _, topic := range topics {
+			client.topics[topic] = true
+		}
+		return
+	}
+	defer room.mu.Unlock()
+
+	for _, topic := range topics {
+		room.subscribe(client, topic)
+	}
+}
+
+// unsubscribe removes topics from a client's subscriptions
+func (h *Hub) unsubscribe(client *Client, topics []string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	room, registered := h.room(client)
+	if !registered {
+		for _, topic := range topics {
+			delete(client.topics, topic)
+		}
+		return
+	}
+	defer room.mu.Unlock()
+
+	for _, topic := range topics {
+		room.unsubscribe(client, topic)
+	}
+}
+
+// subscriptions returns the topics a client is subscribed to
+func (h *Hub) subscriptions(client *Client) map[string]bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	room, registered := h.room(client)
+	if registered {
+		defer room.mu.Unlock()
+	}
+
+	topics := make(map[string]bool, len(client.topics))
+	for topic := range client.topics {
+		topics[topic] = true
+	}
+	return topics
+}
+
+// room returns the room of a client, locked, when the client is registered
+// in it. The caller holds the hub's read lock and unlocks the room.
+func (h *Hub) room(client *Client) (*TenantRoom, bool) {
+	room, exists := h.tenants[client.TenantID]
+	if !exists {
+		return nil, false
+	}
+	room.mu.Lock()
+	if _, ok := room.clients[client]; !ok {
+		room.mu.Unlock()
+		return nil, false
+	}
+	return room, true
+}
+
+// reply sends a message to one client, unless the hub has dropped it
+func (h *Hub) reply(client *Client, message *Message) {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to marshal message")
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	room, registered := h.room(client)
+	if !registered {
+		return
+	}
+	defer room.mu.Unlock()
+
+	select {
+	case client.send <- messageBytes:
+	default:
+		room.remove(client)
+	}
+}
+
+// Reauthorize checks the subscriptions of a tenant's clients on every node
+// again and drops the topics they may no longer see. It is called when
+// access is taken away, since subscriptions are authorised when they are
+// made. userID limits the check to one user's clients and topic to one
+// topic; zero values check all of them.
+func (h *Hub) Reauthorize(tenantID, userID uuid.UUID, topic string) {
+	h.relay(&envelope{Reauthorize: &reauthorization{TenantID: tenantID, UserID: userID, Topic: topic}})
+	h.reauthorize(tenantID, userID, topic)
+}
+
+// reauthorize checks the subscriptions of a tenant's clients on this node
+func (h *Hub) reauthorize(tenantID, userID uuid.UUID, topic string) {
+	type check struct {
+		client *Client
+		topics []string
+	}
+	var checks []check
+
+	h.mu.RLock()
+	if room, exists := h.tenants[tenantID]; exists {
+		room.mu.RLock()
+		for client := range room.clients {
+			if client.authorize == nil || (userID != uuid.Nil && client.UserID != userID) {
+				continue
+			}
+			var topics []string
+			for subscribed := range client.topics {
+				// Clients always keep their own user topic
+				if (topic == "" || subscribed == topic) && subscribed != UserTopic(client.UserID) {
+					topics = append(topics, subscribed)
+				}
+			}
+			if len(topics) > 0 {
+				checks = append(checks, check{client: client, topics: topics})
+			}
+		}
+		room.mu.RUnlock()
+	}
+	h.mu.RUnlock()
+
+	// Authorisers query the database, so they run without the locks
+	for _, check := range checks {
+		var revoked []string
+		for _, topic := range check.topics {
+			if err := check.client.authorize(check.client.user, topic); err != nil {
+				revoked = append(revoked, topic)
+			}
+		}
+		if len(revoked) == 0 {
+			continue
+		}
+
+		h.unsubscribe(check.client, revoked)
+		h.reply(check.client, &Message{
+			Type:      MessageTypeUnsubscribed,
+			UserID:    check.client.UserID,
+			TenantID:  check.client.TenantID,
+			Timestamp: getCurrentTimestamp(),
+			MessageID: generateMessageID(),
+			Data: map[string]interface{}{
+				"topics": revoked,
+				"reason": "access revoked",
+			},
+		})
+		h.logger.WithFields(map[string]interface{}{
+			"user_id":   check.client.UserID,
+			"tenant_id": check.client.TenantID,
+			"topics":    revoked,
+		}).Info("Revoked WebSocket subscriptions")
+	}
+}
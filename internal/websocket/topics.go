@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/drazan344/taskflow-go/internal/models"
+)
+
+// Messages are sent on topics, and clients receive the messages of the
+// topics they subscribed to. Every client is subscribed to its own user
+// topic.
+const (
+	// TopicTenant carries tenant-wide messages: presence and changes to
+	// public projects and tasks outside any project
+	TopicTenant = "tenant"
+
+	TopicKindTenant  = "tenant"
+	TopicKindProject = "project"
+	TopicKindTask    = "task"
+	TopicKindUser    = "user"
+)
+
+// ErrInvalidTopic is returned for topics that are not tenant,
+// project:<id>, task:<id> or user:<id>
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// Authorizer checks that a user may subscribe to a topic. The error is
+// shown to the client.
+type Authorizer func(user *models.User, topic string) error
+
+// ProjectTopic carries changes to a project and its tasks
+func ProjectTopic(projectID uuid.UUID) string {
+	return TopicKindProject + ":" + projectID.String()
+}
+
+// TaskTopic carries changes to a task, its comments and its checklist
+func TaskTopic(taskID uuid.UUID) string {
+	return TopicKindTask + ":" + taskID.String()
+}
+
+// UserTopic carries messages for one user, such as notifications
+func UserTopic(userID uuid.UUID) string {
+	return TopicKindUser + ":" + userID.String()
+}
+
+// ParseTopic splits a topic into its kind and ID. The tenant topic has no ID.
+func ParseTopic(topic string) (string, uuid.UUID, error) {
+	if topic == TopicTenant {
+		return TopicKindTenant, uuid.Nil, nil
+	}
+	kind, value, found := strings.Cut(topic, ":")
+	if !found {
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+	switch kind {
+	case TopicKindProject, TopicKindTask, TopicKindUser:
+	default:
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return "", uuid.Nil, ErrInvalidTopic
+	}
+	return kind, id, nil
+}
+
+// NormalizeTopic returns the canonical form of a topic
+func NormalizeTopic(topic string) (string, error) {
+	kind, id, err := ParseTopic(topic)
+	if err != nil {
+		return "", err
+	}
+	if kind == TopicKindTenant {
+		return TopicTenant, nil
+	}
+	return kind + ":" + id.String(), nil
+}
+
+// ContentTopics returns the topics a change to a project, or to a task and
+// its content, is sent on. Either ID may be nil. Changes to public projects,
+// or to tasks outside any project, also go to the tenant topic.
+func ContentTopics(projectID, taskID *uuid.UUID, public bool) []string {
+	var topics []string
+	if taskID != nil {
+		topics = append(topics, TaskTopic(*taskID))
+	}
+	if projectID != nil {
+		topics = append(topics, ProjectTopic(*projectID))
+	}
+	if public {
+		topics = append(topics, TopicTenant)
+	}
+	return topics
+}
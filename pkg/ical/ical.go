@@ -0,0 +1,271 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateTimeFormat = "20060102T150405"
+	utcFormat      = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// ComponentType is the kind of calendar component emitted for an entry
+type ComponentType string
+
+const (
+	ComponentEvent ComponentType = "VEVENT"
+	ComponentTodo  ComponentType = "VTODO"
+)
+
+// Calendar is an RFC 5545 iCalendar object
+type Calendar struct {
+	ProdID   string
+	Name     string
+	Location *time.Location
+	Entries  []Entry
+}
+
+// Entry is a single VEVENT or VTODO
+type Entry struct {
+	Type        ComponentType
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       *time.Time
+	Due         time.Time
+	AllDay      bool
+	Completed   *time.Time
+	Status      string
+	Priority    int
+	Categories  []string
+	Modified    time.Time
+}
+
+// Write serialises the calendar to w using CRLF line endings and line folding
+func (c *Calendar) Write(w io.Writer) error {
+	lw := &lineWriter{w: w}
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + c.ProdID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME:" + escapeText(c.Name))
+	}
+	if loc != time.UTC {
+		lw.line("X-WR-TIMEZONE:" + loc.String())
+		writeTimezone(lw, loc, time.Now().In(loc).Year())
+	}
+
+	now := time.Now()
+	for _, e := range c.Entries {
+		typ := e.Type
+		if typ == "" {
+			typ = ComponentEvent
+		}
+
+		lw.line("BEGIN:" + string(typ))
+		lw.line("UID:" + e.UID)
+		// DTSTAMP follows the entry rather than the clock, so an unchanged
+		// calendar renders the same bytes on every request
+		stamp := e.Modified
+		if stamp.IsZero() {
+			stamp = now
+		}
+		lw.line("DTSTAMP:" + stamp.UTC().Format(utcFormat))
+		if !e.Modified.IsZero() {
+			lw.line("LAST-MODIFIED:" + e.Modified.UTC().Format(utcFormat))
+		}
+		lw.line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.URL != "" {
+			lw.line("URL:" + e.URL)
+		}
+
+		switch typ {
+		case ComponentTodo:
+			if e.Start != nil {
+				lw.line(formatTime("DTSTART", *e.Start, loc, e.AllDay))
+			}
+			lw.line(formatTime("DUE", e.Due, loc, e.AllDay))
+			if e.Completed != nil {
+				lw.line("COMPLETED:" + e.Completed.UTC().Format(utcFormat))
+			}
+		default:
+			start := e.Due
+			if e.Start != nil {
+				start = *e.Start
+			}
+			lw.line(formatTime("DTSTART", start, loc, e.AllDay))
+			if e.AllDay {
+				lw.line(formatTime("DTEND", e.Due.AddDate(0, 0, 1), loc, true))
+			} else {
+				lw.line(formatTime("DTEND", e.Due, loc, false))
+			}
+		}
+
+		if e.Status != "" {
+			lw.line("STATUS:" + e.Status)
+		}
+		if e.Priority > 0 {
+			lw.line(fmt.Sprintf("PRIORITY:%d", e.Priority))
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, cat := range e.Categories {
+				escaped[i] = escapeText(cat)
+			}
+			lw.line("CATEGORIES:" + strings.Join(escaped, ","))
+		}
+		lw.line("END:" + string(typ))
+	}
+
+	lw.line("END:VCALENDAR")
+	return lw.err
+}
+
+// formatTime renders a DATE or DATE-TIME property in the calendar timezone
+func formatTime(name string, t time.Time, loc *time.Location, allDay bool) string {
+	local := t.In(loc)
+	if allDay {
+		return name + ";VALUE=DATE:" + local.Format("20060102")
+	}
+	if loc == time.UTC {
+		return name + ":" + local.Format(utcFormat)
+	}
+	return name + ";TZID=" + loc.String() + ":" + local.Format(dateTimeFormat)
+}
+
+// writeTimezone emits a VTIMEZONE describing the offsets of loc in the given year
+func writeTimezone(lw *lineWriter, loc *time.Location, year int) {
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + loc.String())
+
+	jan := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	jul := time.Date(year, time.July, 1, 0, 0, 0, 0, loc)
+	janName, janOffset := jan.Zone()
+	_, julOffset := jul.Zone()
+
+	if janOffset == julOffset {
+		lw.line("BEGIN:STANDARD")
+		lw.line("DTSTART:19700101T000000")
+		lw.line("TZOFFSETFROM:" + formatOffset(janOffset))
+		lw.line("TZOFFSETTO:" + formatOffset(janOffset))
+		lw.line("TZNAME:" + janName)
+		lw.line("END:STANDARD")
+		lw.line("END:VTIMEZONE")
+		return
+	}
+
+	// Find both transitions of the year and describe them as yearly rules
+	first := findTransition(jan, jul)
+	second := findTransition(jul, time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc))
+	for _, tr := range []time.Time{first, second} {
+		before := tr.Add(-time.Second)
+		_, fromOffset := before.Zone()
+		name, toOffset := tr.Zone()
+
+		component := "STANDARD"
+		if toOffset > fromOffset {
+			component = "DAYLIGHT"
+		}
+
+		// DTSTART is expressed in the local time in effect before the transition
+		local := time.Unix(tr.Unix()+int64(fromOffset), 0).UTC()
+
+		lw.line("BEGIN:" + component)
+		lw.line("DTSTART:" + local.Format(dateTimeFormat))
+		lw.line("TZOFFSETFROM:" + formatOffset(fromOffset))
+		lw.line("TZOFFSETTO:" + formatOffset(toOffset))
+		lw.line("TZNAME:" + name)
+		lw.line(fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%s", int(local.Month()), byDay(local)))
+		lw.line("END:" + component)
+	}
+
+	lw.line("END:VTIMEZONE")
+}
+
+// findTransition binary searches the first instant after from whose offset differs from from's
+func findTransition(from, to time.Time) time.Time {
+	_, offset := from.Zone()
+	lo, hi := from.Unix(), to.Unix()
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if _, o := time.Unix(mid, 0).In(from.Location()).Zone(); o == offset {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return time.Unix(hi, 0).In(from.Location())
+}
+
+// byDay returns an RRULE BYDAY value such as 2SU or -1SU for the given date
+func byDay(t time.Time) string {
+	day := strings.ToUpper(t.Weekday().String()[:2])
+	if t.AddDate(0, 0, 7).Month() != t.Month() {
+		return "-1" + day
+	}
+	return fmt.Sprintf("%d%s", (t.Day()-1)/7+1, day)
+}
+
+// formatOffset renders a UTC offset in seconds as +HHMM
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, (seconds%3600)/60)
+}
+
+// escapeText escapes TEXT values as required by RFC 5545 section 3.3.11
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// lineWriter folds content lines at 75 octets and terminates them with CRLF
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+
+	_, lw.err = io.WriteString(lw.w, b.String())
+}
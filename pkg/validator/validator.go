@@ -78,6 +78,8 @@ func formatValidationError(err validator.FieldError) string {
 		return fmt.Sprintf("%s must be a valid status", field)
 	case "hexcolor":
 		return fmt.Sprintf("%s must be a valid hex color (e.g., #FF5733)", field)
+	case "datetime":
+		return fmt.Sprintf("%s must match the format %s", field, err.Param())
 	default:
 		return fmt.Sprintf("%s is invalid", field)
 	}
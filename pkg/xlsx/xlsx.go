@@ -0,0 +1,207 @@
+// Package xlsx writes Office Open XML spreadsheets made of text cells. Rows
+// are streamed to the output, one worksheet after another.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSheetName = 31
+	maxCellChars = 32767
+)
+
+// ErrNoSheet is returned when a row is written before any sheet was added
+var ErrNoSheet = errors.New("xlsx: no sheet to write to")
+
+// Writer streams worksheets into an .xlsx file
+type Writer struct {
+	zip    *zip.Writer
+	sheets []string
+	sheet  io.Writer
+	row    int
+}
+
+// NewWriter creates a spreadsheet writer for w
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zip: zip.NewWriter(w)}
+}
+
+// AddSheet starts a new worksheet. Rows written afterwards are added to it.
+func (w *Writer) AddSheet(name string) error {
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	w.sheets = append(w.sheets, w.uniqueName(name))
+	sheet, err := w.zip.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)))
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(sheet, xml.Header+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+
+	w.sheet = sheet
+	w.row = 0
+	return nil
+}
+
+// WriteRow appends a row of text cells to the current worksheet
+func (w *Writer) WriteRow(values []string) error {
+	if w.sheet == nil {
+		return ErrNoSheet
+	}
+	w.row++
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<row r="%d">`, w.row)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		if utf8.RuneCountInString(value) > maxCellChars {
+			value = string([]rune(value)[:maxCellChars])
+		}
+		fmt.Fprintf(&buf, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, ColumnName(i), w.row)
+		if err := xml.EscapeText(&buf, []byte(value)); err != nil {
+			return err
+		}
+		buf.WriteString(`</t></is></c>`)
+	}
+	buf.WriteString(`</row>`)
+
+	_, err := w.sheet.Write(buf.Bytes())
+	return err
+}
+
+// Close finishes the current worksheet, writes the workbook and flushes the file
+func (w *Writer) Close() error {
+	if len(w.sheets) == 0 {
+		if err := w.AddSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(xml.Header +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(xml.Header +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+
+	for i, name := range w.sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		workbook.WriteString(`<sheet name="`)
+		xml.EscapeText(&workbook, []byte(name))
+		fmt.Fprintf(&workbook, `" sheetId="%d" r:id="rId%d"/>`, n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", xml.Header +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+	}
+	for _, part := range parts {
+		f, err := w.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	return w.zip.Close()
+}
+
+// endSheet closes the XML of the current worksheet
+func (w *Writer) endSheet() error {
+	if w.sheet == nil {
+		return nil
+	}
+	_, err := io.WriteString(w.sheet, `</sheetData></worksheet>`)
+	w.sheet = nil
+	return err
+}
+
+// uniqueName makes a valid sheet name that is not used yet
+func (w *Writer) uniqueName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncate(name, maxSheetName)
+	for n := 2; w.hasSheet(candidate); n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		candidate = truncate(name, maxSheetName-len(suffix)) + suffix
+	}
+	return candidate
+}
+
+// hasSheet checks if a sheet name is taken, ignoring case like Excel does
+func (w *Writer) hasSheet(name string) bool {
+	for _, sheet := range w.sheets {
+		if strings.EqualFold(sheet, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnName returns the letters of a zero-based column index, e.g. 0 is A and 27 is AB
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}